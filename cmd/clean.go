@@ -0,0 +1,91 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Clean flags
+	cleanOlderThan string
+	cleanAll       bool
+)
+
+// cleanCmd prunes workspaces preserved with --keep (or by a failed run)
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove kept workspaces",
+	Long: `Remove workspaces kept with --keep or by a failed run.
+
+The age of a workspace is read from the timestamp in its run ID
+(rr-YYYYMMDD-HHMM-xxx). Workspaces are looked up under --workspace-dir
+(or RDR_WORKSPACE_DIR, default: the OS temp dir).
+
+Examples:
+  rdr clean                    # remove workspaces older than 7 days
+  rdr clean --older-than 12h
+  rdr clean --all`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseDir, err := workspace.ResolveBaseDir(workspaceDir)
+		if err != nil {
+			return err
+		}
+
+		if cleanAll {
+			if err := workspace.CleanupAll(baseDir); err != nil {
+				return err
+			}
+			fmt.Printf("Removed all workspaces in %s\n", baseDir)
+			return nil
+		}
+
+		maxAge, err := parseAge(cleanOlderThan)
+		if err != nil {
+			return err
+		}
+
+		removed, err := workspace.CleanupOlderThan(baseDir, maxAge)
+		for _, runID := range removed {
+			fmt.Printf("  → Removed %s\n", runID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d workspace(s) older than %s\n", len(removed), cleanOlderThan)
+		return nil
+	},
+}
+
+func init() {
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "7d", "Remove workspaces older than this age (e.g. 7d, 12h, 30m)")
+	cleanCmd.Flags().BoolVar(&cleanAll, "all", false, "Remove all workspaces regardless of age")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// parseAge parses a Go duration, also accepting whole days ("7d")
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (examples: 7d, 12h, 30m)", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (examples: 7d, 12h, 30m)", value)
+	}
+	return age, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd generates shell completion scripts
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell autocompletion",
+	Long: `Generate the autocompletion script for rdr for the given shell.
+
+Completes subcommands, paths, flag values such as --provider and
+--container-engine, and run IDs for --resume.
+
+Examples:
+  # Bash (current session)
+  source <(rdr completion bash)
+
+  # Zsh (add to ~/.zshrc)
+  rdr completion zsh > "${fpath[1]}/_rdr"
+
+  # Fish
+  rdr completion fish > ~/.config/fish/completions/rdr.fish
+
+  # PowerShell
+  rdr completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		default:
+			return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish, powershell)", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// registerCompletions adds dynamic completion for arguments and flag values.
+// Called from root.go's init once the persistent flags are defined.
+func registerCompletions() {
+	// Positional [path|url] arguments complete as files and directories
+	for _, c := range []*cobra.Command{rootCmd, runCmd, planCmd} {
+		c.ValidArgsFunction = completePathArg
+	}
+	validateCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+	}
+
+	_ = rootCmd.RegisterFlagCompletionFunc("provider", completeProviders)
+	_ = rootCmd.RegisterFlagCompletionFunc("llm-provider", completeProviders)
+	_ = rootCmd.RegisterFlagCompletionFunc("container-engine", cobra.FixedCompletions(
+		[]string{"auto", "docker", "podman"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("isolate", cobra.FixedCompletions(
+		[]string{"docker"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("strategy", cobra.FixedCompletions(
+		[]string{"auto", "readme", "files"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("max-risk", cobra.FixedCompletions(
+		[]string{"low", "medium", "high", "critical"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions(
+		[]string{"auto", "bash", "sh", "pwsh", "cmd"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("workspace-dir", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+	_ = rootCmd.RegisterFlagCompletionFunc("resume", completeRunIDs)
+	_ = rootCmd.RegisterFlagCompletionFunc("record", cobra.FixedCompletions(
+		[]string{"tar.gz", "tgz"}, cobra.ShellCompDirectiveFilterFileExt))
+	_ = rootCmd.RegisterFlagCompletionFunc("plan", cobra.FixedCompletions(
+		[]string{"json", "yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt))
+}
+
+// completePathArg completes the single [path|url] argument with paths
+func completePathArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+// completeProviders offers the supported LLM provider names
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, 0, len(llm.SupportedProviders))
+	for _, p := range llm.SupportedProviders {
+		names = append(names, string(p))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunIDs offers the kept workspaces that can be resumed
+func completeRunIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	baseDir, err := workspace.ResolveBaseDir(workspaceDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	infos, _ := workspace.List(baseDir)
+
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.HasPlan {
+			ids = append(ids, info.RunID)
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/plan"
+)
+
+// editFileName is the file the plan is edited in, next to run-plan.json
+const editFileName = "run-plan.edit.json"
+
+// editorCommand returns the --edit editor: $VISUAL, $EDITOR, then a
+// platform default. The value may include arguments (e.g. "code --wait").
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editRunPlan opens the plan in the editor until it passes validation (the
+// schema and the security policy). An invalid plan is re-opened as written,
+// with the errors listed in comments above it. The edited plan is returned
+// with its risk levels and sudo flags recomputed.
+func editRunPlan(runPlan *llm.RunPlan, dir string) (*llm.RunPlan, *plan.ValidationResult, error) {
+	body, err := json.MarshalIndent(runPlan, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode plan: %w", err)
+	}
+	body = append(body, '\n')
+
+	path := filepath.Join(dir, editFileName)
+	defer os.Remove(path)
+
+	validator := plan.NewValidator()
+	var problems []string
+	for {
+		if err := os.WriteFile(path, append([]byte(plan.EditHeader(problems)), body...), 0644); err != nil {
+			return nil, nil, fmt.Errorf("failed to write plan for editing: %w", err)
+		}
+
+		editor := editorCommand()
+		cmd := osexec.Command(editor[0], append(editor[1:], path)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, nil, fmt.Errorf("editor %s failed: %w", editor[0], err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read edited plan: %w", err)
+		}
+		// An editor that returns at once (e.g. a GUI editor without --wait)
+		// would otherwise re-open the same invalid plan forever
+		stripped := plan.StripComments(data)
+		if problems != nil && bytes.Equal(stripped, body) {
+			return nil, nil, fmt.Errorf("aborted: edited plan is still invalid and was not changed")
+		}
+		body = stripped
+
+		edited, err := plan.ParseEdited(data)
+		if errors.Is(err, plan.ErrEditAborted) {
+			return nil, nil, fmt.Errorf("aborted: %w", err)
+		}
+		if err != nil {
+			problems = []string{err.Error()}
+			noticef("  → ✗ %s; re-opening the editor\n", err)
+			continue
+		}
+
+		result := validator.Validate(edited)
+		if !result.Valid {
+			problems = result.Errors
+			noticef("  → ✗ Edited plan is invalid (%d error(s)); re-opening the editor\n", len(result.Errors))
+			continue
+		}
+
+		return validator.EnhancePlan(edited), result, nil
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"os"
+)
+
+// stdin is shared by all prompts so input buffered by one is not lost
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line from stdin. It gives up when ctx is cancelled
+// (Ctrl+C) instead of blocking until Enter is pressed.
+func readLine(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := stdin.ReadString('\n')
+		done <- result{line, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.line, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
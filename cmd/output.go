@@ -0,0 +1,62 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Output formats for --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// validateOutputFormat checks the --output value
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (supported: text, json)", outputFormat)
+	}
+}
+
+// isQuiet reports whether progress output is suppressed (--quiet, implied
+// by --output json)
+func isQuiet() bool {
+	return quietFlag || outputFormat == outputJSON
+}
+
+// console is where human-readable messages go. With --output json stdout
+// carries only the report, so messages move to stderr.
+func console() io.Writer {
+	if outputFormat == outputJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// progressWriter is console(), or io.Discard with --quiet
+func progressWriter() io.Writer {
+	if isQuiet() {
+		return io.Discard
+	}
+	return console()
+}
+
+// progressf prints phase headers and progress lines, hidden by --quiet
+func progressf(format string, args ...any) {
+	if !isQuiet() {
+		fmt.Fprintf(console(), format, args...)
+	}
+}
+
+// noticef prints warnings, prompts and summaries, which --quiet keeps
+func noticef(format string, args ...any) {
+	fmt.Fprintf(console(), format, args...)
+}
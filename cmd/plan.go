@@ -0,0 +1,119 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/export"
+	"github.com/sony-level/readme-runner/internal/fetcher"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Plan export flags
+	exportFormat string
+	exportDir    string
+
+	// checkPrereqs checks the plan's prerequisites instead of (or before)
+	// exporting it; planJSON is the --json shorthand for --output json
+	checkPrereqs bool
+	planJSON     bool
+)
+
+// planCmd generates and validates a plan, then exports it instead of executing
+var planCmd = &cobra.Command{
+	Use:   "plan [path|url]",
+	Short: "Generate a plan and export it or check its prerequisites",
+	Long: `Analyze a repository and generate a validated installation plan,
+then export it instead of executing it.
+
+--check-prereqs checks the tools the plan needs, including their minimum
+versions, and exits non-zero if one is missing, unusable or too old. With
+--json the run report lists each tool's status, for CI gating.
+
+Supported export formats:
+  devcontainer   .devcontainer/devcontainer.json (image, features, postCreateCommand, forwardPorts)
+  yaml           run-plan.yaml, the plan itself for review and editing (see rdr validate)
+
+Files are written to the project directory, or to the current directory
+for a URL, unless --export-dir is given.
+
+Examples:
+  rdr plan . --export devcontainer
+  rdr plan . --export yaml
+  rdr plan https://github.com/user/repo --export devcontainer --export-dir ./out
+  rdr plan . --check-prereqs --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true, // unmet prerequisites are not a usage error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat == "" && !checkPrereqs {
+			return fmt.Errorf("--export or --check-prereqs is required (export formats: %s)", strings.Join(export.SupportedFormats, ", "))
+		}
+		if exportFormat != "" && !export.IsSupportedFormat(exportFormat) {
+			return fmt.Errorf("unsupported export format %q (supported: %s)", exportFormat, strings.Join(export.SupportedFormats, ", "))
+		}
+
+		if planJSON {
+			outputFormat = outputJSON
+		}
+
+		inputPath := "."
+		if len(args) > 0 {
+			inputPath = args[0]
+		}
+		// A cloned repository only lives in the workspace
+		if exportDir == "" {
+			exportDir = "."
+			if fetcher.DetectSourceType(inputPath) == fetcher.SourceTypeLocal {
+				exportDir = inputPath
+			}
+		}
+		return executeRun(cmd.Context(), inputPath)
+	},
+}
+
+func init() {
+	planCmd.Flags().StringVar(&exportFormat, "export", "", "Export format: devcontainer, yaml")
+	planCmd.Flags().StringVar(&exportDir, "export-dir", "", "Directory to write exported files to (default: the project directory, or . for a URL)")
+	planCmd.Flags().BoolVar(&checkPrereqs, "check-prereqs", false, "Check the plan's prerequisites and their minimum versions; fail if one is not met")
+	planCmd.Flags().BoolVar(&planJSON, "json", false, "Print the run report as JSON (same as --output json)")
+	rootCmd.AddCommand(planCmd)
+}
+
+// exportRunPlan writes the validated plan in the requested export format,
+// if any (--check-prereqs alone only checks)
+func exportRunPlan(runPlan *llm.RunPlan) error {
+	if exportFormat == "" {
+		return nil
+	}
+	// With --check-prereqs, phase 5 was the prerequisites check
+	phase := 5
+	if checkPrereqs {
+		phase = 6
+	}
+	progressf("\n[%d/7] Export\n", phase)
+
+	switch exportFormat {
+	case export.FormatDevcontainer:
+		path, err := export.WriteDevcontainer(runPlan, exportDir)
+		if err != nil {
+			return fmt.Errorf("failed to export plan: %w", err)
+		}
+		noticef("  → Exported devcontainer: %s\n", path)
+	case export.FormatYAML:
+		path, err := export.WriteYAML(runPlan, exportDir)
+		if err != nil {
+			return fmt.Errorf("failed to export plan: %w", err)
+		}
+		noticef("  → Exported plan: %s\n", path)
+	default:
+		return fmt.Errorf("unsupported export format %q", exportFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	// preparing is set by 'rdr prepare', which stops after prerequisites
+	preparing   bool
+	autoInstall bool
+)
+
+// prepareCmd gets the machine ready for a project without running it
+var prepareCmd = &cobra.Command{
+	Use:   "prepare [path|url]",
+	Short: "Install a project's prerequisites without running it",
+	Long: `Analyze a repository and generate a plan to learn which tools it needs,
+then check them and stop before executing anything.
+
+Missing tools are only installed with --auto-install, using the host
+package manager (brew on macOS, apt or dnf on Linux, winget on Windows).
+The install commands are listed and confirmed first (unless --yes), and
+sudo is asked for like any plan step. Tools that are outdated, unusable or
+have no package are reported with their install guide. rdr prepare exits
+non-zero until every prerequisite is ready.
+
+Examples:
+  rdr prepare .
+  rdr prepare . --auto-install
+  rdr prepare https://github.com/user/repo --auto-install --yes`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true, // unmet prerequisites are not a usage error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preparing = true
+		// --auto-install is the consent to change the host
+		if autoInstall && !cmd.Flags().Changed("dry-run") {
+			dryRun = false
+		}
+
+		inputPath := "."
+		if len(args) > 0 {
+			inputPath = args[0]
+		}
+		return executeRun(cmd.Context(), inputPath)
+	},
+}
+
+func init() {
+	prepareCmd.Flags().BoolVar(&autoInstall, "auto-install", false, "Install missing tools with the host package manager")
+	rootCmd.AddCommand(prepareCmd)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/pipeline"
+	"github.com/sony-level/readme-runner/internal/workspace"
+)
+
+// recordBundle returns the pipeline's Record hook for --record: it writes a
+// .tar.gz with what a maintainer needs to reproduce the run. Everything sits
+// under a directory named after the run ID:
+//
+//	meta.json          source, stack, provider and model, outcome
+//	profile.json       the project profile the plan was built from
+//	prompt.txt         the prompt built for the provider (prompts/<dir>.txt per --monorepo subproject)
+//	report.json        the --output json run report
+//	plan/, logs/       the workspace's plans, execution state and logs
+func recordBundle(archivePath string) func(report *pipeline.Report) error {
+	return func(report *pipeline.Report) error {
+		files, err := recordFiles(report)
+		if err != nil {
+			return fmt.Errorf("--record: %w", err)
+		}
+		if err := writeTarGz(archivePath, report.Workspace.RunID, files); err != nil {
+			return fmt.Errorf("--record: %w", err)
+		}
+		noticef("  → Run recorded to %s\n", archivePath)
+		return nil
+	}
+}
+
+// recordFiles collects the bundle's files by their path in the archive
+func recordFiles(report *pipeline.Report) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		files[name] = append(data, '\n')
+		return nil
+	}
+
+	if err := addJSON(workspace.MetaFileName, report.Meta); err != nil {
+		return nil, err
+	}
+	if report.Profile != nil {
+		if err := addJSON("profile.json", report.Profile); err != nil {
+			return nil, err
+		}
+	}
+	for dir, prompt := range report.Prompts {
+		name := "prompt.txt"
+		if dir != "." {
+			name = path.Join("prompts", dir+".txt")
+		}
+		files[name] = []byte(prompt)
+	}
+	runReport := newRunReport(report.Meta, report.Workspace, report.Plan, report.Validation, report.Execution, report.Selection, report.Prerequisites)
+	if err := addJSON("report.json", runReport); err != nil {
+		return nil, err
+	}
+
+	ws := report.Workspace
+	for _, dir := range []string{ws.PlanPath(), ws.LogsPath()} {
+		err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(ws.Path, p)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = data
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+	}
+	return files, nil
+}
+
+// writeTarGz writes files into a gzipped tarball under the root directory
+func writeTarGz(archivePath, root string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    path.Join(root, name),
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", archivePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/plan"
+	"github.com/sony-level/readme-runner/internal/prereq"
+	"github.com/sony-level/readme-runner/internal/security"
+	"github.com/sony-level/readme-runner/internal/workspace"
+)
+
+// Step statuses in the run report
+const (
+	stepPlanned = "planned" // dry run
+	stepOK      = "ok"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+	stepNotRun  = "not_run" // execution stopped before the step
+)
+
+// runReport is the document printed by --output json
+type runReport struct {
+	RunID         string       `json:"run_id"`
+	Source        string       `json:"source"`
+	SourceType    string       `json:"source_type"`
+	Stack         string       `json:"stack,omitempty"`
+	Provider      string       `json:"provider,omitempty"`
+	Model         string       `json:"model,omitempty"`
+	ProjectType   string       `json:"project_type,omitempty"`
+	DryRun        bool         `json:"dry_run"`
+	InPlace       bool         `json:"in_place,omitempty"`
+	Success       bool         `json:"success"`
+	Error         string       `json:"error,omitempty"`
+	Workspace     string       `json:"workspace"`
+	WorkspaceKept bool         `json:"workspace_kept"`
+	StartedAt     time.Time    `json:"started_at"`
+	EndedAt       time.Time    `json:"ended_at"`
+	Ports         []int        `json:"ports,omitempty"`
+	Notes         []string     `json:"notes,omitempty"`
+	Steps         []reportStep `json:"steps"`
+
+	Warnings []security.Warning `json:"warnings,omitempty"` // validation warnings left after --suppress
+
+	ProviderSelection *reportProvider `json:"provider_selection,omitempty"` // nil when no provider was resolved (--plan)
+
+	Prerequisites []reportPrereq `json:"prerequisites,omitempty"` // empty until prerequisites are checked
+
+	Services   []exec.ComposeService `json:"services,omitempty"`    // left running by compose up -d
+	PortStatus []exec.PortStatus     `json:"port_status,omitempty"` // plan ports probed after the run
+	Processes  []exec.RunningProcess `json:"processes,omitempty"`   // step processes still running
+}
+
+// reportStep is a plan step and, when executed, its outcome
+type reportStep struct {
+	ID         string        `json:"id"`
+	Cmd        string        `json:"cmd"`
+	Cwd        string        `json:"cwd,omitempty"`
+	Risk       llm.RiskLevel `json:"risk"`
+	Sudo       bool          `json:"requires_sudo,omitempty"`
+	Status     string        `json:"status"`
+	ExitCode   int           `json:"exit_code,omitempty"`
+	DurationMs int64         `json:"duration_ms,omitempty"`
+	SkipReason string        `json:"skip_reason,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// reportProvider is how the provider that generated the plan was chosen
+type reportProvider struct {
+	Provider      string `json:"provider"` // the resolved provider, before any fallback
+	Source        string `json:"source"`   // cli, env, config, offline or auto
+	AutoReason    string `json:"auto_reason,omitempty"`
+	Fallback      bool   `json:"fallback"` // the plan came from the mock provider instead
+	FallbackFrom  string `json:"fallback_from,omitempty"`
+	FallbackError string `json:"fallback_error,omitempty"`
+	RateLimited   bool   `json:"rate_limited,omitempty"`
+	TimedOut      bool   `json:"timed_out,omitempty"`
+	Model         string `json:"model,omitempty"`
+	ModelSource   string `json:"model_source,omitempty"`
+}
+
+// reportPrereq is a checked prerequisite
+type reportPrereq struct {
+	Name        string `json:"name"`
+	Found       bool   `json:"found"`
+	Version     string `json:"version,omitempty"`
+	Path        string `json:"path,omitempty"`
+	MinVersion  string `json:"min_version,omitempty"`
+	MeetsMin    *bool  `json:"meets_min_version"` // null without a min_version or a comparable version
+	Unreachable bool   `json:"unreachable,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// newReportPrereqs converts the prerequisite check (nil for none)
+func newReportPrereqs(summary *prereq.CheckSummary) []reportPrereq {
+	if summary == nil {
+		return nil
+	}
+	prereqs := make([]reportPrereq, 0, len(summary.Results))
+	for _, result := range summary.Results {
+		rp := reportPrereq{
+			Name:        result.Name,
+			Found:       result.Found,
+			Version:     result.Version,
+			Path:        result.Path,
+			MinVersion:  result.MinVersion,
+			Unreachable: result.Unreachable,
+		}
+		if meets, known := result.MeetsMinVersion(); known {
+			rp.MeetsMin = &meets
+		}
+		if result.Error != nil {
+			rp.Error = result.Error.Error()
+		}
+		prereqs = append(prereqs, rp)
+	}
+	return prereqs
+}
+
+// newReportProvider converts the provider selection info (nil stays nil)
+func newReportProvider(info *llm.ProviderSelectionInfo) *reportProvider {
+	if info == nil {
+		return nil
+	}
+	return &reportProvider{
+		Provider:      string(info.Provider),
+		Source:        info.Source,
+		AutoReason:    info.AutoReason,
+		Fallback:      info.WasFallback,
+		FallbackFrom:  info.FallbackFrom,
+		FallbackError: info.FallbackError,
+		RateLimited:   info.RateLimited,
+		TimedOut:      info.TimedOut,
+		Model:         info.Model,
+		ModelSource:   info.ModelSource,
+	}
+}
+
+// newRunReport builds the report from the run metadata, the plan and its
+// validation (nil if planning failed), the execution result (nil for dry
+// runs), how the provider was chosen (nil if none was resolved) and the
+// prerequisite check (nil if it did not run)
+func newRunReport(meta *workspace.Meta, ws *workspace.Workspace, runPlan *llm.RunPlan, validation *plan.ValidationResult, execResult *exec.ExecutionResult, selection *llm.ProviderSelectionInfo, prereqs *prereq.CheckSummary) *runReport {
+	report := &runReport{
+		RunID:         meta.RunID,
+		Source:        meta.Source,
+		SourceType:    meta.SourceType,
+		Stack:         meta.Stack,
+		Provider:      meta.Provider,
+		Model:         meta.Model,
+		DryRun:        meta.DryRun,
+		InPlace:       meta.InPlace,
+		Success:       meta.Success != nil && *meta.Success,
+		Error:         meta.Error,
+		Workspace:     ws.Path,
+		WorkspaceKept: ws.ShouldKeep(),
+		StartedAt:     meta.StartedAt,
+		Steps:         []reportStep{},
+
+		ProviderSelection: newReportProvider(selection),
+		Prerequisites:     newReportPrereqs(prereqs),
+	}
+	if meta.EndedAt != nil {
+		report.EndedAt = *meta.EndedAt
+	}
+	if runPlan == nil {
+		return report
+	}
+
+	report.ProjectType = runPlan.ProjectType
+	report.Ports = runPlan.Ports
+	report.Notes = runPlan.Notes
+	if validation != nil {
+		report.Warnings = validation.Warnings
+	}
+
+	results := make(map[string]*exec.StepResult)
+	if execResult != nil {
+		report.Services = execResult.Services
+		report.PortStatus = execResult.PortStatus
+		report.Processes = execResult.Processes
+		for _, result := range execResult.StepResults {
+			results[result.StepID] = result
+		}
+	}
+
+	for _, step := range runPlan.Steps {
+		rs := reportStep{
+			ID:     step.ID,
+			Cmd:    step.Cmd,
+			Cwd:    step.Cwd,
+			Risk:   step.Risk,
+			Sudo:   step.RequiresSudo,
+			Status: stepPlanned,
+		}
+		if execResult != nil {
+			rs.Status = stepNotRun
+		}
+		if result, ok := results[step.ID]; ok {
+			switch {
+			case result.Skipped:
+				rs.Status = stepSkipped
+				rs.SkipReason = result.SkipReason
+			case result.Success:
+				rs.Status = stepOK
+			default:
+				rs.Status = stepFailed
+			}
+			rs.ExitCode = result.ExitCode
+			rs.DurationMs = result.Duration.Milliseconds()
+			if result.Error != nil {
+				rs.Error = result.Error.Error()
+			}
+		}
+		report.Steps = append(report.Steps, rs)
+	}
+
+	return report
+}
+
+// writeRunReport prints the report as indented JSON
+func writeRunReport(w io.Writer, report *runReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run report: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
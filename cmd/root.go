@@ -5,8 +5,18 @@ Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/pipeline"
+	"github.com/sony-level/readme-runner/internal/scanner"
 	"github.com/spf13/cobra"
 )
 
@@ -14,17 +24,53 @@ var (
 	// Global flags
 	keepWorkspace bool
 	dryRun        bool
-	verbose       bool
+	verbosity     int
 	yesFlag       bool
+	listSteps     bool
+	explainFlag   bool
+	editPlanFlag  bool
+	resumeRunID   string
+	planPath      string
+	recordPath    string
+	inPlace       bool
+	incremental   bool
+	workspaceDir  string
+	quietFlag     bool
+	outputFormat  string
+	monorepoMode  bool
+	excludeDirs   []string
+	suppressCodes []string
+	assumeStack   string
+	maxParallel   int
+	maxRetries    int
+	detachFlag    bool
+	leaveRunning  bool
+	stepTimeout   time.Duration
+	globalTimeout time.Duration
+	clarityLimit  float64
+	claritySet    bool // --clarity-threshold was given (it overrides the config file)
+	strategyName  string
 
 	// LLM flags
 	llmProvider string
 	llmEndpoint string
 	llmModel    string
 	llmToken    string
+	llmTimeout  time.Duration
+	offlineMode bool
+	maxPrompt   int
 
 	// Security flags
-	allowSudo bool
+	allowSudo   bool
+	maxRiskName string
+
+	// Isolation flags
+	isolateMode      string
+	shellName        string
+	containerImage   string
+	sandboxEnabled   bool
+	sandboxNoNetwork bool
+	containerEngine  string
 )
 
 // rootCmd represents the base command - runs directly without subcommand
@@ -46,20 +92,38 @@ Examples:
   rdr . --keep --verbose
   rdr https://gitlab.com/user/repo -y`,
 	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		claritySet = cmd.Flags().Changed("clarity-threshold")
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Default to current directory if no argument provided
 		inputPath := "."
 		if len(args) > 0 {
 			inputPath = args[0]
 		}
-		return executeRun(inputPath)
+		return executeRun(cmd.Context(), inputPath)
 	},
 }
 
+// exitInterrupted is the exit status of an interrupted run (128 + SIGINT)
+const exitInterrupted = 130
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Ctrl+C (SIGINT) or SIGTERM cancels the context shared by all pipeline
+// phases; a second signal exits at once.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if errors.Is(err, pipeline.ErrInterrupted) {
+		os.Exit(exitInterrupted)
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -75,19 +139,57 @@ func GetLLMToken() string {
 
 func init() {
 	// Persistent flags - available to all subcommands
-	rootCmd.PersistentFlags().BoolVar(&keepWorkspace, "keep", false, "Keep workspace directory after execution (.rr-temp/<run-id>)")
+	rootCmd.PersistentFlags().BoolVar(&keepWorkspace, "keep", false, "Keep workspace directory after execution (<workspace-dir>/.rr-temp/<run-id>)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", true, "Show plan without executing (default: true)")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose output, repeat for more: -v phase detail, -vv prompt and profile, -vvv raw LLM traffic and step env")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Only print warnings, errors, prompts and the final summary")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "Output format: text, json (json prints a run report on stdout and implies --quiet)")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Auto-accept prompts (except security-critical)")
+	rootCmd.PersistentFlags().BoolVar(&listSteps, "list-steps", false, "Show a numbered step list (ID, risk, sudo, command) and confirm the whole plan once before executing")
+	rootCmd.PersistentFlags().BoolVar(&explainFlag, "explain", false, "Show why each step is in the plan: its kind, what the security check detected and, for README-first plans, the README line it came from")
+	rootCmd.PersistentFlags().BoolVar(&editPlanFlag, "edit", false, "Open the generated plan in $EDITOR before running it (re-validated after editing)")
+	rootCmd.PersistentFlags().StringVar(&workspaceDir, "workspace-dir", "", "Base directory for run workspaces (or env: RDR_WORKSPACE_DIR; default: OS temp dir)")
+	rootCmd.PersistentFlags().BoolVar(&inPlace, "in-place", false, "Run a local project in its own directory instead of a workspace copy (not sandboxed: steps change your source tree)")
+	rootCmd.PersistentFlags().BoolVar(&incremental, "incremental", false, "With --in-place, skip install/build steps whose inputs are unchanged since the last run")
+	rootCmd.PersistentFlags().StringVar(&resumeRunID, "resume", "", "Resume a failed run by run ID, skipping steps that already completed")
+	rootCmd.PersistentFlags().StringVar(&planPath, "plan", "", "Run this plan file (JSON or YAML) instead of generating one; - reads it from stdin")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "Save the plan, project profile, provider and model, prompt, run report and logs to this .tar.gz for bug reports")
+	rootCmd.PersistentFlags().IntVar(&maxParallel, "parallel", 1, "Run up to N independent steps at once (plans with depends_on, e.g. --monorepo subprojects)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", 0, "Stop the run once N step retries (prompted or automatic) have been made across all steps (0 = no limit)")
+	rootCmd.PersistentFlags().BoolVar(&detachFlag, "detach", false, "Keep server steps running in the background once they are up, so later steps can use them (stopped after the last step)")
+	rootCmd.PersistentFlags().BoolVar(&leaveRunning, "leave-running", false, "Leave servers started by the plan running after rdr exits instead of stopping them")
+	rootCmd.PersistentFlags().DurationVar(&stepTimeout, "step-timeout", exec.DefaultStepTimeout, "Default timeout per step, e.g. 15m (a step's own timeout in the plan wins; capped at 30m)")
+	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "global-timeout", 0, "Timeout for the whole execution phase, e.g. 1h (0 = no limit)")
+	rootCmd.PersistentFlags().Float64Var(&clarityLimit, "clarity-threshold", llm.ClarityThreshold, "Minimum README clarity score (0-1) for the README-first strategy (or config: clarity_threshold)")
+	rootCmd.PersistentFlags().StringVar(&strategyName, "strategy", string(llm.StrategyAuto), "Planning source: auto (by clarity score), readme (force README-first), files (force project-file signals)")
+	rootCmd.PersistentFlags().BoolVar(&monorepoMode, "monorepo", false, "Plan each top-level subdirectory with its own manifest (e.g. frontend/, backend/) separately")
+	rootCmd.PersistentFlags().StringSliceVar(&suppressCodes, "suppress", nil, "Silence validation warnings by code, e.g. SEC004,PLAN003; repeatable")
+	rootCmd.PersistentFlags().StringVar(&assumeStack, "assume-stack", "", "Plan the project as this stack when detection picks the wrong one: "+strings.Join(scanner.Stacks, ", "))
+	rootCmd.PersistentFlags().StringSliceVar(&excludeDirs, "exclude", nil, "Directories to skip when scanning, by name (examples) or path from the root (docs/demo); repeatable")
 
 	// LLM provider flags
-	// Default is empty string to enable auto-selection: anthropic > openai > mistral > ollama > mock
-	rootCmd.PersistentFlags().StringVar(&llmProvider, "llm-provider", "", "LLM provider: anthropic, openai, mistral, ollama, http, mock (default: auto-select)")
+	// Default is empty string to enable auto-selection: anthropic > openai > mistral > cohere > ollama > mock
+	rootCmd.PersistentFlags().StringVar(&llmProvider, "llm-provider", "", "LLM provider: anthropic, openai, mistral, cohere, ollama, http, mock (default: auto-select)")
 	rootCmd.PersistentFlags().StringVar(&llmProvider, "provider", "", "Alias for --llm-provider")
 	rootCmd.PersistentFlags().StringVar(&llmEndpoint, "llm-endpoint", "", "HTTP endpoint for custom LLM provider")
 	rootCmd.PersistentFlags().StringVar(&llmModel, "llm-model", "", "Model name for LLM provider")
+	rootCmd.PersistentFlags().DurationVar(&llmTimeout, "provider-timeout", 0, "How long to wait for the LLM per request, e.g. 2m (or env: RD_LLM_TIMEOUT; default 60s, at most 5m); not a step timeout")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Force the offline mock provider, ignoring API keys (or env: RDR_OFFLINE=1)")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "no-llm", false, "Alias for --offline")
+	rootCmd.PersistentFlags().IntVar(&maxPrompt, "max-prompt-tokens", llm.DefaultMaxPromptTokens, "Ask before sending a larger prompt (estimated tokens) to a hosted provider (0 = never ask)")
 	rootCmd.PersistentFlags().StringVar(&llmToken, "llm-token", "", "Authentication token for LLM (or env: ANTHROPIC_API_KEY, OPENAI_API_KEY, etc.)")
 
 	// Security flags
 	rootCmd.PersistentFlags().BoolVar(&allowSudo, "allow-sudo", false, "Allow sudo commands without confirmation prompts")
+	rootCmd.PersistentFlags().StringVar(&maxRiskName, "max-risk", "", "Confirm steps above this risk before executing, abort with --yes: low, medium, high, critical")
+
+	// Isolation flags
+	rootCmd.PersistentFlags().StringVar(&isolateMode, "isolate", "", "Run the plan in an isolated environment: docker")
+	rootCmd.PersistentFlags().StringVar(&shellName, "shell", "auto", "Shell for host commands: auto, bash, sh, pwsh, cmd (auto: cmd on Windows, sh elsewhere)")
+	rootCmd.PersistentFlags().StringVar(&containerImage, "container-image", "", "Image for --isolate docker (default: based on project type)")
+	rootCmd.PersistentFlags().BoolVar(&sandboxEnabled, "sandbox", false, "Confine non-sudo steps with bwrap/firejail (Linux): writes limited to the workspace")
+	rootCmd.PersistentFlags().StringVar(&containerEngine, "container-engine", "auto", "Engine for docker commands in plans: auto, docker, podman (auto uses podman when docker is not installed)")
+	rootCmd.PersistentFlags().BoolVar(&sandboxNoNetwork, "sandbox-no-network", false, "Disable network access inside the sandbox (implies --sandbox)")
+
+	registerCompletions()
 }
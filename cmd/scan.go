@@ -0,0 +1,154 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/fetcher"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/stacks"
+	"github.com/spf13/cobra"
+)
+
+// scanJSON is the --json shorthand for --output json
+var scanJSON bool
+
+// scanCmd prints rdr's analysis of a project without planning it
+var scanCmd = &cobra.Command{
+	Use:   "scan [path]",
+	Short: "Scan a local project and print the detected files, profile and stacks",
+	Long: `Scan a local project the way phase 2 of a run does and print what was
+found: the README and its clarity score, project files, the project profile
+and the stack detection. Nothing is copied, planned or executed.
+
+With --json (or --output json) the analysis is printed as one JSON document
+with the keys scan, readme_clarity and stacks, for tools that build on
+rdr's analysis. The format only ever gains fields.
+
+Examples:
+  rdr scan
+  rdr scan ./project --json | jq '.stacks.dominant.name'
+  rdr scan . --exclude examples --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPath := "."
+		if len(args) > 0 {
+			inputPath = args[0]
+		}
+		return scanProject(inputPath)
+	},
+}
+
+func init() {
+	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "Print the analysis as JSON (same as --output json)")
+	rootCmd.AddCommand(scanCmd)
+}
+
+// scanReport is the document printed by 'rdr scan --json'
+type scanReport struct {
+	Scan          *scanner.ScanResult    `json:"scan"`
+	ReadmeClarity *float64               `json:"readme_clarity"` // null without a README
+	Stacks        stacks.DetectionResult `json:"stacks"`
+}
+
+// scanProject scans a local directory in place and prints the analysis
+func scanProject(path string) error {
+	if fetcher.DetectSourceType(path) != fetcher.SourceTypeLocal {
+		return fmt.Errorf("rdr scan works on local paths; clone %s first or use 'rdr %s --keep -v'", path, path)
+	}
+	if err := fetcher.ValidateLocalPath(path); err != nil {
+		return err
+	}
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	excludes := append([]string{}, scanner.DefaultExcludeDirs...)
+	excludes = append(excludes, scanner.NormalizeExcludeDirs(excludeDirs)...)
+	result, err := scanner.Scan(&scanner.ScanConfig{
+		RootPath:    root,
+		MaxDepth:    3,
+		ExcludeDirs: excludes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	stack, err := scanner.ParseStack(assumeStack)
+	if err != nil {
+		return fmt.Errorf("--assume-stack: %w", err)
+	}
+	if result.Profile != nil {
+		result.Profile.AssumeStack(stack)
+	}
+
+	report := &scanReport{Scan: result}
+	if result.ReadmeFile != nil {
+		score := llm.CalculateClarityScore(result.ReadmeFile)
+		report.ReadmeClarity = &score
+	}
+	if result.Profile != nil {
+		report.Stacks = stacks.AssumeStack(stacks.NewAggregator().Detect(result.Profile), stack)
+	}
+
+	if scanJSON || outputFormat == outputJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode scan: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return nil
+	}
+
+	printScanReport(report)
+	return nil
+}
+
+// printScanReport prints the analysis for people
+func printScanReport(report *scanReport) {
+	result := report.Scan
+	fmt.Printf("Scanned %s: %d files in %d directories\n", result.RootPath, result.TotalFiles, result.TotalDirs)
+
+	if result.ReadmeFile != nil {
+		fmt.Printf("  → README: %s (clarity score: %.2f, threshold: %.2f)\n",
+			result.ReadmeFile.RelPath, *report.ReadmeClarity, llm.ClarityThreshold)
+	} else {
+		fmt.Printf("  → README: none\n")
+	}
+	if result.License != nil && result.License.SPDX != "" {
+		fmt.Printf("  → License: %s\n", result.License.SPDX)
+	}
+
+	if profile := result.Profile; profile != nil {
+		fmt.Printf("  → Stack: %s\n", profile.Stack)
+		if profile.Framework != "" {
+			fmt.Printf("  → Framework: %s\n", profile.Framework)
+		}
+		if len(profile.Languages) > 0 {
+			fmt.Printf("  → Languages: %s\n", strings.Join(profile.Languages, ", "))
+		}
+		if len(profile.Tools) > 0 {
+			fmt.Printf("  → Tools: %s\n", strings.Join(profile.Tools, ", "))
+		}
+		if len(profile.Signals) > 0 {
+			fmt.Printf("  → Signals: %s\n", strings.Join(profile.Signals, ", "))
+		}
+	}
+
+	if report.Stacks.Dominant.Name != "" {
+		fmt.Printf("  → Dominant stack: %s (confidence: %.2f)\n",
+			report.Stacks.Dominant.Name, report.Stacks.Dominant.Confidence)
+		if report.Stacks.Explanation != "" {
+			fmt.Printf("    %s\n", report.Stacks.Explanation)
+		}
+	}
+}
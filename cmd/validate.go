@@ -0,0 +1,104 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/plan"
+	"github.com/sony-level/readme-runner/internal/security"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd lints a plan file without generating or executing anything
+var validateCmd = &cobra.Command{
+	Use:   "validate <plan.json|plan.yaml>",
+	Short: "Validate a plan file without running it",
+	Long: `Load a RunPlan file (for example a hand-edited plan/run-plan.json, or a
+run-plan.yaml from rdr plan --export yaml) and check it against the plan
+schema and the security policy. Files ending in .yaml or .yml are read as
+YAML, anything else as JSON.
+
+All errors and warnings are printed along with the risk summary.
+The command exits non-zero if the plan has any error.
+
+Examples:
+  rdr validate run-plan.json
+  rdr validate run-plan.yaml
+  rdr validate /tmp/.rr-temp/rr-20260203-1542-abc/plan/run-plan.json`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true, // a failed validation is not a usage error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validatePlanFile(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validatePlanFile runs the phase-4 validation on a saved plan
+func validatePlanFile(path string) error {
+	runPlan, err := plan.LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	exec.SetColor(exec.ColorSupported(os.Stdout))
+	fmt.Printf("Plan: %s\n", path)
+	fmt.Printf("  → %s project with %d steps\n", runPlan.ProjectType, len(runPlan.Steps))
+
+	suppressed, err := plan.ParseWarningCodes(suppressCodes)
+	if err != nil {
+		return fmt.Errorf("--suppress: %w", err)
+	}
+	validator := plan.NewValidator()
+	validator.SetSuppressed(suppressed)
+	validationResult := validator.Validate(runPlan)
+
+	if len(validationResult.Errors) > 0 {
+		fmt.Println("  → Errors:")
+		for _, err := range validationResult.Errors {
+			fmt.Printf("      • %s\n", err)
+		}
+	}
+
+	if len(validationResult.Warnings) > 0 || len(validationResult.PortConflicts) > 0 {
+		fmt.Println("  → Warnings:")
+		for _, warn := range validationResult.Warnings {
+			fmt.Printf("      • %s\n", warn)
+		}
+		for _, conflict := range validationResult.PortConflicts {
+			fmt.Printf("      • %s\n", conflict)
+		}
+	}
+	if validationResult.Suppressed > 0 {
+		fmt.Printf("  → %d warning(s) suppressed by --suppress\n", validationResult.Suppressed)
+	}
+
+	fmt.Printf("  → Risk summary: %s\n", exec.FormatRiskSummary(
+		validationResult.RiskReport.Low,
+		validationResult.RiskReport.Medium,
+		validationResult.RiskReport.High,
+		validationResult.RiskReport.Critical))
+
+	if explainFlag {
+		fmt.Printf("  → Why each step (--explain):\n%s", plan.FormatExplanations(validator.Explain(runPlan, nil), "      "))
+	}
+
+	if runPlan.HasSudoSteps() {
+		fmt.Printf("  → ⚠ Plan contains %d step(s) requiring sudo\n", security.CountSudoSteps(runPlan))
+	}
+
+	if !validationResult.Valid {
+		fmt.Println("  → ✗ Plan is invalid")
+		return fmt.Errorf("plan validation failed: %d error(s)", len(validationResult.Errors))
+	}
+
+	fmt.Println("  → ✓ Plan is valid")
+	return nil
+}
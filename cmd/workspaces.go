@@ -0,0 +1,86 @@
+/*
+Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sony-level/readme-runner/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// workspacesCmd lists workspaces preserved with --keep or by a failed run
+var workspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "List kept workspaces",
+	Long: `List the workspaces kept under --workspace-dir (or RDR_WORKSPACE_DIR,
+default: the OS temp dir) with their run ID, creation time, whether a plan
+was saved, the outcome of the run and its source.
+
+A run ID from this list can be passed to --resume, and old workspaces
+can be removed with 'rdr clean'.
+
+Examples:
+  rdr workspaces
+  rdr workspaces --workspace-dir /mnt/fast/rdr`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseDir, err := workspace.ResolveBaseDir(workspaceDir)
+		if err != nil {
+			return err
+		}
+		return listWorkspaces(baseDir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workspacesCmd)
+}
+
+// listWorkspaces prints one line per workspace
+func listWorkspaces(baseDir string) error {
+	infos, err := workspace.List(baseDir)
+	if err != nil {
+		return err
+	}
+
+	if len(infos) == 0 {
+		fmt.Printf("No workspaces in %s\n", baseDir)
+		return nil
+	}
+
+	fmt.Printf("Workspaces in %s:\n\n", baseDir)
+	fmt.Printf("  %-22s  %-16s  %-4s  %-8s  %s\n", "RUN ID", "CREATED", "PLAN", "STATUS", "SOURCE")
+	for _, info := range infos {
+		plan := "no"
+		if info.HasPlan {
+			plan = "yes"
+		}
+		source := "(unknown)"
+		if info.Meta != nil && info.Meta.Source != "" {
+			source = info.Meta.Source
+		}
+		fmt.Printf("  %-22s  %-16s  %-4s  %-8s  %s\n",
+			info.RunID, info.CreatedAt.Format("2006-01-02 15:04"), plan, runStatus(info.Meta), source)
+	}
+	return nil
+}
+
+// runStatus summarizes the recorded outcome of a run
+func runStatus(meta *workspace.Meta) string {
+	switch {
+	case meta == nil:
+		return "unknown"
+	case meta.Success == nil:
+		return "running" // or rdr was interrupted
+	case !*meta.Success:
+		return "failed"
+	case meta.DryRun:
+		return "dry-run"
+	default:
+		return "ok"
+	}
+}
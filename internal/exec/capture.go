@@ -0,0 +1,86 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Bounded capture of step output
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// DefaultMaxOutputBytes is the default cap on captured stdout/stderr per step
+const DefaultMaxOutputBytes = 1024 * 1024
+
+// cappedBuffer keeps the head and tail of a stream within a byte limit.
+// Output past the limit is dropped from the middle and reported by a
+// "[... N bytes truncated ...]" marker. A limit <= 0 keeps everything.
+// It is safe for concurrent use: a detached step reads it while its output
+// is still being streamed in.
+type cappedBuffer struct {
+	mu      sync.Mutex
+	limit   int
+	head    []byte
+	tail    []byte
+	dropped int64
+}
+
+func newCappedBuffer(limit int) *cappedBuffer {
+	return &cappedBuffer{limit: limit}
+}
+
+// WriteString appends s, dropping middle bytes once over the limit
+func (b *cappedBuffer) WriteString(s string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit <= 0 {
+		b.head = append(b.head, s...)
+		return
+	}
+
+	if room := b.limit/2 - len(b.head); room > 0 {
+		n := min(room, len(s))
+		b.head = append(b.head, s[:n]...)
+		s = s[n:]
+	}
+	b.tail = append(b.tail, s...)
+
+	// Compact only once the tail doubles, so trimming stays amortized O(1)
+	if keep := b.tailLimit(); len(b.tail) > 2*keep {
+		drop := len(b.tail) - keep
+		b.dropped += int64(drop)
+		b.tail = append(b.tail[:0], b.tail[drop:]...)
+	}
+}
+
+// tailLimit is how many trailing bytes are kept
+func (b *cappedBuffer) tailLimit() int {
+	return b.limit - b.limit/2
+}
+
+// String returns the captured output, with a marker where bytes were dropped.
+// The tail starts at a line boundary so the last lines stay intact.
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tail := b.tail
+	dropped := b.dropped
+	if keep := b.tailLimit(); b.limit > 0 && len(tail) > keep {
+		dropped += int64(len(tail) - keep)
+		tail = tail[len(tail)-keep:]
+	}
+	if dropped == 0 {
+		return string(b.head) + string(tail)
+	}
+
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		dropped += int64(i + 1)
+		tail = tail[i+1:]
+	}
+
+	head := string(b.head)
+	if head != "" && head[len(head)-1] != '\n' {
+		head += "\n"
+	}
+	return head + fmt.Sprintf("[... %d bytes truncated ...]\n", dropped) + string(tail)
+}
@@ -0,0 +1,102 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// ANSI colors for risk levels and step results on a terminal
+
+package exec
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// ANSI color codes
+const (
+	colorReset     = "\033[0m"
+	colorGreen     = "\033[32m"
+	colorYellow    = "\033[33m"
+	colorRed       = "\033[31m"
+	colorBrightRed = "\033[1;91m"
+)
+
+// colorEnabled is off by default so output written to files, pipes and
+// tests stays plain; the CLI turns it on for a terminal (SetColor)
+var colorEnabled atomic.Bool
+
+// SetColor turns colored output of the Format and DryRunDisplay functions
+// on or off
+func SetColor(enabled bool) {
+	colorEnabled.Store(enabled)
+}
+
+// ColorSupported reports whether w is a terminal that should get colors:
+// NO_COLOR is unset or empty (https://no-color.org), TERM is not "dumb" and,
+// on Windows, the terminal is Windows Terminal, whose console handles ANSI
+// codes. Redirected output is never colored.
+func ColorSupported(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if runtime.GOOS == "windows" && os.Getenv("WT_SESSION") == "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in an ANSI color when colors are on
+func colorize(color, s string) string {
+	if !colorEnabled.Load() {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// riskColor is the color of a risk level: green, yellow, red and bright red
+// for low to critical
+func riskColor(level llm.RiskLevel) string {
+	switch level {
+	case llm.RiskLow:
+		return colorGreen
+	case llm.RiskMedium:
+		return colorYellow
+	case llm.RiskHigh:
+		return colorRed
+	case llm.RiskCritical:
+		return colorBrightRed
+	}
+	return ""
+}
+
+// ColorRisk returns text, e.g. a risk level or a padded table column, in
+// the color of level. Unknown levels are left plain.
+func ColorRisk(level llm.RiskLevel, text string) string {
+	color := riskColor(level)
+	if color == "" {
+		return text
+	}
+	return colorize(color, text)
+}
+
+// FormatRiskSummary returns the step counts per risk level, e.g.
+// "Low=2, Medium=0, High=1, Critical=0", with the non-zero counts colored
+func FormatRiskSummary(low, medium, high, critical int) string {
+	part := func(level llm.RiskLevel, name string, count int) string {
+		text := fmt.Sprintf("%s=%d", name, count)
+		if count == 0 {
+			return text
+		}
+		return ColorRisk(level, text)
+	}
+	return part(llm.RiskLow, "Low", low) + ", " +
+		part(llm.RiskMedium, "Medium", medium) + ", " +
+		part(llm.RiskHigh, "High", high) + ", " +
+		part(llm.RiskCritical, "Critical", critical)
+}
@@ -0,0 +1,222 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Compose service discovery for detached "compose up" steps
+
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// composeUpPattern matches a docker/podman compose up command and captures
+// everything before "up" (engine, "compose" and global flags such as -f)
+var composeUpPattern = regexp.MustCompile(`^((?:docker|podman)\s+compose(?:\s+-{1,2}[\w-]+(?:[ =][^\s-]\S*)?)*)\s+up(\s|$)`)
+
+// composePsTimeout bounds the "compose ps" call made after execution
+const composePsTimeout = 10 * time.Second
+
+// ComposeService is a service started by a detached compose up step
+type ComposeService struct {
+	Name   string   `json:"name"`
+	State  string   `json:"state"`
+	Health string   `json:"health,omitempty"`
+	URLs   []string `json:"urls,omitempty"` // http://localhost:<port> per published TCP port
+}
+
+// composePrefix returns the part of a compose up command before "up"
+// (e.g. "docker compose -f compose.prod.yml"), or "" for other commands
+func composePrefix(cmd string) string {
+	match := composeUpPattern.FindStringSubmatch(strings.TrimSpace(cmd))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// IsComposeUp reports whether cmd is a single docker/podman compose up command
+func IsComposeUp(cmd string) bool {
+	return composePrefix(cmd) != "" && !strings.ContainsAny(cmd, ";&|")
+}
+
+// IsDetachedComposeUp reports whether cmd is a compose up run with -d/--detach
+func IsDetachedComposeUp(cmd string) bool {
+	if !IsComposeUp(cmd) {
+		return false
+	}
+	for _, field := range strings.Fields(cmd) {
+		if field == "-d" || field == "--detach" {
+			return true
+		}
+	}
+	return false
+}
+
+// composePsEntry is one service in the "compose ps --format json" output
+type composePsEntry struct {
+	Service    string `json:"Service"`
+	State      string `json:"State"`
+	Health     string `json:"Health"`
+	Publishers []struct {
+		PublishedPort int    `json:"PublishedPort"`
+		Protocol      string `json:"Protocol"`
+	} `json:"Publishers"`
+}
+
+// parseComposePs parses "compose ps --format json": a JSON array (older
+// Compose releases) or one JSON object per line
+func parseComposePs(data []byte) ([]ComposeService, error) {
+	var entries []composePsEntry
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("invalid compose ps output: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var entry composePsEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return nil, fmt.Errorf("invalid compose ps output: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	services := make([]ComposeService, 0, len(entries))
+	for _, entry := range entries {
+		service := ComposeService{Name: entry.Service, State: entry.State, Health: entry.Health}
+		seen := make(map[int]bool)
+		var ports []int
+		for _, pub := range entry.Publishers {
+			// IPv4 and IPv6 bindings list the same port twice
+			if pub.PublishedPort > 0 && pub.Protocol != "udp" && !seen[pub.PublishedPort] {
+				seen[pub.PublishedPort] = true
+				ports = append(ports, pub.PublishedPort)
+			}
+		}
+		sort.Ints(ports)
+		for _, port := range ports {
+			service.URLs = append(service.URLs, fmt.Sprintf("http://localhost:%d", port))
+		}
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+// collectComposeServices lists the services of the plan's detached compose
+// up steps that succeeded, for the post-run report. Errors are ignored: the
+// report then only shows the plan's ports.
+func (r *Runner) collectComposeServices(ctx context.Context, plan *llm.RunPlan, result *ExecutionResult, env []string) {
+	if r.config.Mode != ModeExecute || r.config.Isolation == IsolationDocker {
+		return
+	}
+
+	succeeded := make(map[string]bool)
+	for _, stepResult := range result.StepResults {
+		if stepResult.Success && !stepResult.Skipped {
+			succeeded[stepResult.StepID] = true
+		}
+	}
+
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		if !succeeded[step.ID] || !IsDetachedComposeUp(step.Cmd) {
+			continue
+		}
+		workDir, err := resolveStepDir(r.config.WorkingDir, step)
+		if err != nil {
+			continue
+		}
+
+		psCtx, cancel := context.WithTimeout(ctx, composePsTimeout)
+		args := r.config.Shell.Args(composePrefix(step.Cmd) + " ps --format json")
+		cmd := exec.CommandContext(psCtx, args[0], args[1:]...)
+		cmd.Dir = workDir
+		cmd.Env = env
+		output, err := cmd.Output()
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		services, err := parseComposePs(output)
+		if err != nil {
+			continue
+		}
+		result.Services = append(result.Services, services...)
+		result.ComposeDown = append(result.ComposeDown, composeDownCommand(step, workDir))
+	}
+}
+
+// composePathFlags are the compose options that take a path, relative to
+// the directory compose runs in
+var composePathFlags = map[string]bool{"-f": true, "--file": true, "--env-file": true, "--project-directory": true}
+
+// composeDownCommand is the command that stops a compose up step's
+// services. It runs from any directory: the step's directory is passed as
+// --project-directory, which also keeps the project name compose derives
+// from it, and the compose files are made absolute.
+func composeDownCommand(step *llm.Step, workDir string) string {
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+	absPath := func(path string) string {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		return QuoteArg(path)
+	}
+
+	// "docker compose" or "podman compose", then its options
+	fields := strings.Fields(composePrefix(step.Cmd))
+	args := []string{fields[0], fields[1]}
+	var options []string
+	hasProjectDir := false
+	for i := 2; i < len(fields); i++ {
+		name, value, inline := strings.Cut(fields[i], "=")
+		if !composePathFlags[name] {
+			options = append(options, fields[i])
+			continue
+		}
+		hasProjectDir = hasProjectDir || name == "--project-directory"
+		switch {
+		case inline:
+			options = append(options, name+"="+absPath(value))
+		case i+1 < len(fields):
+			i++
+			options = append(options, name, absPath(fields[i]))
+		default:
+			options = append(options, fields[i])
+		}
+	}
+	if !hasProjectDir {
+		args = append(args, "--project-directory", QuoteArg(workDir))
+	}
+	args = append(args, options...)
+	return strings.Join(append(args, "down"), " ")
+}
+
+// QuoteArg single-quotes an argument that a POSIX shell would split or
+// expand, and returns any other argument as is
+func QuoteArg(arg string) string {
+	if strings.ContainsAny(arg, " \t'\"$`\\*?[]{}()<>|&;#~!") {
+		return shellQuote(arg)
+	}
+	return arg
+}
@@ -0,0 +1,296 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Container-backed step execution for --isolate docker
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// ContainerWorkDir is where the workspace is mounted inside the container
+const ContainerWorkDir = "/workspace"
+
+// containerStartTimeout bounds image pull + container startup
+const containerStartTimeout = 5 * time.Minute
+
+// Compile-time interface checks
+var _ StepRunner = (*ContainerRunner)(nil)
+var _ PlanExecutor = (*ContainerRunner)(nil)
+
+// defaultContainerImages maps project types to base images
+var defaultContainerImages = map[string]string{
+	"node":   "node:20",
+	"python": "python:3.12",
+	"go":     "golang:1.22",
+	"rust":   "rust:1",
+	"dotnet": "mcr.microsoft.com/dotnet/sdk:8.0",
+	"ruby":   "ruby:3.3",
+	"php":    "php:8.3-cli",
+}
+
+// DefaultContainerImage is used when the project type has no dedicated image
+const DefaultContainerImage = "ubuntu:24.04"
+
+// ContainerRunner runs plan steps inside a throwaway Docker container
+// that mounts the workspace. Output streaming, timeouts, retries and
+// failure prompts are handled by the wrapped Runner.
+type ContainerRunner struct {
+	runner      *Runner
+	image       string
+	containerID string
+	env         map[string]string
+	ports       []int
+}
+
+// NewContainerRunner creates a container-backed runner.
+// Returns an error if Docker is not available.
+func NewContainerRunner(config *RunnerConfig) (*ContainerRunner, error) {
+	if err := CheckDockerAvailable(); err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		config = &RunnerConfig{Mode: ModeExecute, StepTimeout: DefaultStepTimeout}
+	}
+	config.Isolation = IsolationDocker
+
+	c := &ContainerRunner{
+		runner: NewRunner(config),
+		image:  config.ContainerImage,
+	}
+	c.runner.buildCommand = c.execCommand
+	c.runner.killInside = c.killStep
+
+	return c, nil
+}
+
+// CheckDockerAvailable verifies the docker CLI is installed and the daemon responds
+func CheckDockerAvailable() error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("docker not found in PATH (required for --isolate docker)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := exec.CommandContext(ctx, "docker", "info", "--format", "{{.ServerVersion}}").Run(); err != nil {
+		return fmt.Errorf("docker daemon is not reachable: %w", err)
+	}
+	return nil
+}
+
+// ContainerImageFor returns the base image used for a project type
+func ContainerImageFor(projectType string) string {
+	if image, ok := defaultContainerImages[projectType]; ok {
+		return image
+	}
+	return DefaultContainerImage
+}
+
+// SetSudoPrompt sets the sudo confirmation prompt function
+func (c *ContainerRunner) SetSudoPrompt(fn SudoPromptFunc) {
+	c.runner.SetSudoPrompt(fn)
+}
+
+// SetFailurePrompt sets the failure handling prompt function
+func (c *ContainerRunner) SetFailurePrompt(fn FailurePromptFunc) {
+	c.runner.SetFailurePrompt(fn)
+}
+
+// Image returns the image used (or to be used) for the container
+func (c *ContainerRunner) Image() string {
+	return c.image
+}
+
+// RunStep executes a single step inside the container, starting it if needed.
+// This method satisfies the StepRunner interface.
+func (c *ContainerRunner) RunStep(ctx context.Context, step *llm.Step, opts *RunOptions) (*StepResult, error) {
+	if c.containerID == "" {
+		if opts != nil {
+			c.env = opts.Environment
+		}
+		if err := c.Start(ctx, ""); err != nil {
+			return nil, err
+		}
+	}
+	return c.runner.RunStep(ctx, step, opts)
+}
+
+// Execute runs all steps in the plan inside a fresh container
+func (c *ContainerRunner) Execute(plan *llm.RunPlan) *ExecutionResult {
+	return c.ExecuteWithContext(context.Background(), plan)
+}
+
+// ExecuteWithContext starts a container, runs the plan in it and removes it
+func (c *ContainerRunner) ExecuteWithContext(ctx context.Context, plan *llm.RunPlan) *ExecutionResult {
+	if plan.ProjectType == "docker" {
+		return containerFailure(fmt.Errorf("docker projects cannot run with --isolate docker (they already run in containers)"))
+	}
+
+	c.env = plan.Env
+	c.ports = plan.Ports
+
+	if err := c.Start(ctx, plan.ProjectType); err != nil {
+		return containerFailure(err)
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove container: %v\n", err)
+		}
+	}()
+
+	return c.runner.ExecuteWithContext(ctx, plan)
+}
+
+// Start creates the throwaway container with the workspace mounted
+func (c *ContainerRunner) Start(ctx context.Context, projectType string) error {
+	if c.containerID != "" {
+		return nil
+	}
+	if c.image == "" {
+		c.image = ContainerImageFor(projectType)
+	}
+
+	workDir, err := filepath.Abs(c.runner.config.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	args := []string{
+		"run", "-d", "--rm",
+		"-v", workDir + ":" + ContainerWorkDir,
+		"-w", ContainerWorkDir,
+		"--entrypoint", "sleep",
+	}
+	for _, port := range c.ports {
+		args = append(args, "-p", fmt.Sprintf("%d:%d", port, port))
+	}
+	args = append(args, c.image, "infinity")
+
+	startCtx, cancel := context.WithTimeout(ctx, containerStartTimeout)
+	defer cancel()
+
+	if c.runner.config.Verbose {
+		fmt.Fprintf(c.runner.output(), "    Starting container: docker %s\n", strings.Join(args, " "))
+	}
+
+	out, err := exec.CommandContext(startCtx, "docker", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("failed to start container from %s: %s", c.image, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("failed to start container from %s: %w", c.image, err)
+	}
+
+	c.containerID = strings.TrimSpace(string(out))
+	return nil
+}
+
+// Close removes the container. Files written to the mounted workspace
+// are handed back to the current user first so workspace cleanup works.
+func (c *ContainerRunner) Close() error {
+	if c.containerID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if uid, gid := os.Getuid(), os.Getgid(); uid > 0 {
+		owner := fmt.Sprintf("%d:%d", uid, gid)
+		_ = exec.CommandContext(ctx, "docker", "exec", c.containerID, "chown", "-R", owner, ContainerWorkDir).Run()
+	}
+
+	err := exec.CommandContext(ctx, "docker", "rm", "-f", c.containerID).Run()
+	c.containerID = ""
+	if err != nil {
+		return fmt.Errorf("docker rm failed: %w", err)
+	}
+	return nil
+}
+
+// execCommand builds a `docker exec` command for a step.
+// Only config and plan environment variables are forwarded; the host
+// environment stays on the host.
+func (c *ContainerRunner) execCommand(step *llm.Step, workDir string) *exec.Cmd {
+	containerDir := ContainerWorkDir
+	if step.Cwd != "" && step.Cwd != "." {
+		containerDir = path.Join(ContainerWorkDir, filepath.ToSlash(step.Cwd))
+	}
+
+	args := []string{"exec", "-w", containerDir}
+	for _, kv := range containerEnv(c.runner.config.Environment, c.env) {
+		args = append(args, "-e", kv)
+	}
+	for _, kv := range c.runner.exportedPairs() {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, "-e", EnvFileVar+"="+path.Join(ContainerWorkDir, envFileName(step)))
+	// Killing the docker exec client leaves the step running in the
+	// container; the wrapper records its PID so killStep can stop it
+	wrapper := "echo $$ > " + shellQuote(stepPIDFile(step)) + `; exec sh -c "$1"`
+	args = append(args, c.containerID, "sh", "-c", wrapper, "sh", step.Cmd)
+
+	cmd := exec.Command("docker", args...)
+	cmd.Dir = c.runner.config.WorkingDir
+	return cmd
+}
+
+// stepPIDFile is where the exec wrapper of a step records its PID in the
+// container
+func stepPIDFile(step *llm.Step) string {
+	return "/tmp/rr-" + strings.ReplaceAll(step.ID, "/", "-") + ".pid"
+}
+
+// killStep stops a timed-out or auto-stopped step inside the container:
+// its process group, or the process alone if it does not lead one
+func (c *ContainerRunner) killStep(step *llm.Step) {
+	if c.containerID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pidFile := shellQuote(stepPIDFile(step))
+	script := `pid=$(cat ` + pidFile + ` 2>/dev/null) || exit 0; ` +
+		`kill -TERM -"$pid" 2>/dev/null || kill -TERM "$pid" 2>/dev/null; rm -f ` + pidFile
+	_ = exec.CommandContext(ctx, "docker", "exec", c.containerID, "sh", "-c", script).Run()
+}
+
+// containerEnv merges config and plan env into sorted KEY=VALUE pairs
+func containerEnv(configEnv, planEnv map[string]string) []string {
+	merged := make(map[string]string, len(configEnv)+len(planEnv))
+	for k, v := range configEnv {
+		merged[k] = v
+	}
+	for k, v := range planEnv {
+		merged[k] = v
+	}
+
+	env := make([]string, 0, len(merged))
+	for k, v := range merged {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// containerFailure builds a failed result when the container cannot be used
+func containerFailure(err error) *ExecutionResult {
+	result := NewExecutionResult()
+	result.AddStepResult(&StepResult{
+		StepID: "container",
+		Error:  err,
+	})
+	return result
+}
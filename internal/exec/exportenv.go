@@ -0,0 +1,153 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Environment exported by a step to the steps that follow it
+
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// EnvFileVar names the file a step appends KEY=value lines to. The
+// variables are added to the environment of every later step, like
+// $GITHUB_ENV in GitHub Actions:
+//
+//	echo "DATABASE_URL=postgres://localhost/app" >> "$RDR_ENV"
+const EnvFileVar = "RDR_ENV"
+
+// maxEnvFileSize bounds how much of an env file is read
+const maxEnvFileSize = 64 * 1024
+
+// envFileName returns the name of a step's env file in the working directory
+// (the workspace is the only place a sandboxed or containerized step can write)
+func envFileName(step *llm.Step) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, step.ID)
+	return ".rdr-env-" + name
+}
+
+// createEnvFile creates the empty env file for a step. Returns "" if the
+// file cannot be created; the step then runs without RDR_ENV.
+func (r *Runner) createEnvFile(step *llm.Step) string {
+	if r.config.WorkingDir == "" {
+		return ""
+	}
+	path := filepath.Join(r.config.WorkingDir, envFileName(step))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return ""
+	}
+	file.Close()
+	return path
+}
+
+// exportedPairs returns the variables exported so far as sorted KEY=value pairs
+func (r *Runner) exportedPairs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pairs := make([]string, 0, len(r.exported))
+	for key, value := range r.exported {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// stepEnv returns the environment of a host step: the merged env, the
+// variables exported by earlier steps and RDR_ENV
+func (r *Runner) stepEnv(mergedEnv []string, envFile string) []string {
+	env := mergedEnv
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append(env[:len(env):len(env)], r.exportedPairs()...)
+	if envFile != "" {
+		env = append(env, EnvFileVar+"="+envFile)
+	}
+	return env
+}
+
+// collectExports merges a successful step's ExportEnv and the lines it
+// wrote to its env file into the environment of the following steps
+func (r *Runner) collectExports(step *llm.Step, envFile string) {
+	exports := make(map[string]string, len(step.ExportEnv))
+	for key, value := range step.ExportEnv {
+		exports[key] = value
+	}
+	if envFile != "" {
+		fileExports, err := parseEnvFile(envFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: step %s: %v\n", step.ID, err)
+		}
+		for key, value := range fileExports {
+			exports[key] = value
+		}
+	}
+	if len(exports) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(exports))
+	r.mu.Lock()
+	if r.exported == nil {
+		r.exported = make(map[string]string)
+	}
+	for key, value := range exports {
+		r.exported[key] = value
+		keys = append(keys, key)
+	}
+	r.mu.Unlock()
+
+	if r.config.Verbose {
+		sort.Strings(keys)
+		fmt.Fprintf(r.output(), "    Exported: %s\n", strings.Join(keys, ", "))
+	}
+}
+
+// parseEnvFile reads KEY=value lines. Blank lines, comments and an
+// "export " prefix are allowed; surrounding quotes are removed from values.
+func parseEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	exports := make(map[string]string)
+	scanner := bufio.NewScanner(io.LimitReader(file, maxEnvFileSize))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !llm.ValidEnvKey(key) {
+			return exports, fmt.Errorf("%s line %d: expected KEY=value", EnvFileVar, lineNum)
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		exports[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return exports, fmt.Errorf("%s: %w", EnvFileVar, err)
+	}
+	return exports, nil
+}
@@ -0,0 +1,152 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// HTTP health-check polling for the run step
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// DefaultHealthCheckTimeout is how long to poll when the plan sets no timeout
+const DefaultHealthCheckTimeout = 30 * time.Second
+
+// healthCheckInterval is the delay between polling attempts
+const healthCheckInterval = 500 * time.Millisecond
+
+// HealthCheckResult contains the outcome of polling a health-check URL
+type HealthCheckResult struct {
+	URL            string
+	Healthy        bool
+	StatusCode     int // Last status code received (0 = no response)
+	ExpectedStatus int
+	Attempts       int
+	Duration       time.Duration
+	Error          error
+}
+
+// PollHealthCheck polls the URL until it returns the expected status,
+// the health-check timeout elapses or ctx is cancelled
+func PollHealthCheck(ctx context.Context, hc *llm.HealthCheck) *HealthCheckResult {
+	result := &HealthCheckResult{
+		URL:            hc.URL,
+		ExpectedStatus: hc.ExpectedStatus,
+	}
+	if result.ExpectedStatus == 0 {
+		result.ExpectedStatus = http.StatusOK
+	}
+
+	timeout := DefaultHealthCheckTimeout
+	if hc.Timeout > 0 {
+		timeout = time.Duration(hc.Timeout) * time.Second
+	}
+
+	startTime := time.Now()
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	for {
+		result.Attempts++
+
+		req, err := http.NewRequestWithContext(pollCtx, http.MethodGet, hc.URL, nil)
+		if err != nil {
+			result.Error = fmt.Errorf("invalid health-check URL: %w", err)
+			result.Duration = time.Since(startTime)
+			return result
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			result.StatusCode = resp.StatusCode
+			if resp.StatusCode == result.ExpectedStatus {
+				result.Healthy = true
+				result.Error = nil
+				result.Duration = time.Since(startTime)
+				return result
+			}
+			result.Error = fmt.Errorf("got status %d, expected %d", resp.StatusCode, result.ExpectedStatus)
+		} else if pollCtx.Err() == nil {
+			result.Error = err
+		}
+
+		select {
+		case <-pollCtx.Done():
+			if result.Error == nil {
+				result.Error = fmt.Errorf("no response within %v", timeout)
+			}
+			result.Duration = time.Since(startTime)
+			return result
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
+
+// healthMonitor polls a health check while the run step executes
+type healthMonitor struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	result *HealthCheckResult
+}
+
+// startHealthCheck begins polling in the background. A healthy response
+// signals the running step as ready, the same way a framework ready line does.
+func (r *Runner) startHealthCheck(ctx context.Context, hc *llm.HealthCheck, stepID string) *healthMonitor {
+	pollCtx, cancel := context.WithCancel(ctx)
+	ready := make(chan struct{}, 1)
+	m := &healthMonitor{cancel: cancel, done: make(chan struct{})}
+
+	r.mu.Lock()
+	r.healthReady = ready
+	r.healthStepID = stepID
+	r.mu.Unlock()
+
+	go func() {
+		defer close(m.done)
+		m.result = PollHealthCheck(pollCtx, hc)
+		if m.result.Healthy {
+			ready <- struct{}{}
+		}
+	}()
+
+	return m
+}
+
+// waitHealthCheck returns the health-check result once the run step is over.
+// Polling continues after a successful step (detached servers) and is
+// stopped right away if the step failed.
+func (r *Runner) waitHealthCheck(m *healthMonitor, stepResult *StepResult) *HealthCheckResult {
+	r.mu.Lock()
+	r.healthReady = nil
+	r.healthStepID = ""
+	r.mu.Unlock()
+
+	if !stepResult.Success {
+		m.cancel()
+	}
+	<-m.done
+	m.cancel()
+
+	if !m.result.Healthy && !stepResult.Success {
+		m.result.Error = fmt.Errorf("run step failed before the app became healthy")
+	}
+	return m.result
+}
+
+// FormatHealthCheckResult returns a one-line health-check summary
+func FormatHealthCheckResult(result *HealthCheckResult) string {
+	if result.Healthy {
+		return fmt.Sprintf("✓ %s responded %d (after %v)", result.URL, result.StatusCode, result.Duration.Round(time.Millisecond))
+	}
+	msg := fmt.Sprintf("✗ %s did not become healthy", result.URL)
+	if result.Error != nil {
+		msg += fmt.Sprintf(": %s", result.Error.Error())
+	}
+	return msg
+}
@@ -0,0 +1,120 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Post-run inspection of listening ports and processes left running
+
+package exec
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// portProbeTimeout bounds the connection attempt made per plan port
+const portProbeTimeout = 500 * time.Millisecond
+
+// PortStatus is whether a plan port accepted connections after the run
+type PortStatus struct {
+	Port        int  `json:"port"`
+	Listening   bool `json:"listening"`
+	InUseBefore bool `json:"in_use_before,omitempty"` // Already listening before the first step
+}
+
+// RunningProcess is a step's process group left running after the run
+// (RunnerConfig.LeaveRunning), such as a server started with "&"
+type RunningProcess struct {
+	StepID string `json:"step_id"`
+	PID    int    `json:"pid"`          // Process group ID on Unix, process ID on Windows
+	Stop   string `json:"stop_command"` // Command that stops it
+
+	LogFile string `json:"log_file,omitempty"` // Output of a detached step, which is not read through rdr
+}
+
+// spawnedProcess is the process started for a step
+type spawnedProcess struct {
+	stepID  string
+	pid     int
+	logFile string
+}
+
+// probePort reports whether something on localhost accepts connections on port
+func probePort(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), portProbeTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// listeningPorts returns the ports that accept connections
+func listeningPorts(ports []int) map[int]bool {
+	listening := make(map[int]bool)
+	for _, port := range ports {
+		if probePort(port) {
+			listening[port] = true
+		}
+	}
+	return listening
+}
+
+// inspectsHost reports whether post-run inspection applies: steps ran on
+// this machine rather than in a container that is removed afterwards
+func (r *Runner) inspectsHost() bool {
+	return r.config.Mode == ModeExecute && r.config.Isolation != IsolationDocker
+}
+
+// recordSpawned remembers the process group of a step that exited on its
+// own or was left running, for the post-run inspection. Groups that were
+// killed are not recorded.
+func (r *Runner) recordSpawned(stepID string, pid int, logFile string) {
+	if !r.inspectsHost() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spawned = append(r.spawned, spawnedProcess{stepID: stepID, pid: pid, logFile: logFile})
+}
+
+// inspectAfterRun stops step processes that are still alive (a server
+// started with "&", a daemon that forked) or, with LeaveRunning, lists
+// them. It then replaces the plan's speculative ports with what is actually
+// listening. before holds the ports already listening before the first step.
+func (r *Runner) inspectAfterRun(result *ExecutionResult, before map[int]bool) {
+	if !r.inspectsHost() {
+		return
+	}
+
+	r.mu.Lock()
+	spawned := r.spawned
+	r.spawned = nil
+	r.mu.Unlock()
+
+	for _, proc := range spawned {
+		if !processGroupAlive(proc.pid) {
+			continue
+		}
+		if !r.config.LeaveRunning {
+			fmt.Fprintf(r.output(), "  → Stopping processes left by step %s (PID %d)\n", proc.stepID, proc.pid)
+			terminateProcessGroup(proc.pid, stopGracePeriod)
+			continue
+		}
+		result.Processes = append(result.Processes, RunningProcess{
+			StepID:  proc.stepID,
+			PID:     proc.pid,
+			Stop:    stopProcessCommand(proc.pid),
+			LogFile: proc.logFile,
+		})
+	}
+	sort.Slice(result.Processes, func(i, j int) bool { return result.Processes[i].PID < result.Processes[j].PID })
+
+	listening := listeningPorts(result.Ports)
+	for _, port := range result.Ports {
+		result.PortStatus = append(result.PortStatus, PortStatus{
+			Port:        port,
+			Listening:   listening[port],
+			InUseBefore: before[port],
+		})
+	}
+
+}
@@ -0,0 +1,186 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Worker-pool execution of independent plan steps
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// stepState tracks a step in a parallel run
+type stepState int
+
+const (
+	stepPending stepState = iota
+	stepRunning
+	stepSucceeded // completed, skipped by the user or by --resume
+	stepFailed
+)
+
+// stepDone reports a finished step to the scheduler
+type stepDone struct {
+	index   int
+	ok      bool
+	aborted bool
+}
+
+// executeParallel runs up to MaxParallel steps at once. A step starts once
+// every step in its depends_on has succeeded (or was skipped); a step that
+// depends on a failed step is skipped. After an abort, cancellation or
+// global timeout no new steps start, and running steps are waited for.
+func (r *Runner) executeParallel(ctx context.Context, plan *llm.RunPlan, result *ExecutionResult, mergedEnv []string) {
+	states := make([]stepState, len(plan.Steps))
+	indexByID := make(map[string]int, len(plan.Steps))
+	for i := range plan.Steps {
+		indexByID[plan.Steps[i].ID] = i
+	}
+
+	// Steps finished by a previous run are not executed again
+	for i := range plan.Steps {
+		if r.config.SkipSteps[plan.Steps[i].ID] {
+			r.recordResumeSkip(result, &plan.Steps[i])
+			states[i] = stepSucceeded
+		}
+	}
+
+	r.prefixOutput = true
+	defer func() { r.prefixOutput = false }()
+
+	done := make(chan stepDone)
+	running := 0
+	stopped := false
+
+	for {
+		if !stopped {
+			select {
+			case <-ctx.Done():
+				r.resultMu.Lock()
+				markStopped(result, ctx)
+				r.resultMu.Unlock()
+				stopped = true
+			default:
+			}
+		}
+
+		// Start every step whose dependencies are satisfied
+		for i := range plan.Steps {
+			if stopped || running >= r.config.MaxParallel {
+				break
+			}
+			if states[i] != stepPending {
+				continue
+			}
+
+			step := &plan.Steps[i]
+			ready, failedDep := dependenciesDone(step, states, indexByID)
+			if failedDep != "" {
+				states[i] = stepFailed
+				r.recordDependencySkip(result, step, failedDep)
+				continue
+			}
+			if !ready {
+				continue
+			}
+
+			states[i] = stepRunning
+			running++
+			go func(index int, step *llm.Step) {
+				stepResult, resultIndex := r.runPlanStep(ctx, plan, step, mergedEnv, result)
+				if stepResult.Cancelled {
+					done <- stepDone{index: index}
+					return
+				}
+				aborted := false
+				if !stepResult.Success && !stepResult.Skipped {
+					aborted = r.handleStepFailure(ctx, step, stepResult, mergedEnv, result, resultIndex)
+				}
+				r.resultMu.Lock()
+				final := result.StepResults[resultIndex]
+				r.resultMu.Unlock()
+				done <- stepDone{index: index, ok: final.Success || final.Skipped, aborted: aborted}
+			}(i, step)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		finished := <-done
+		running--
+		if finished.ok {
+			states[finished.index] = stepSucceeded
+		} else {
+			states[finished.index] = stepFailed
+		}
+		if finished.aborted {
+			r.resultMu.Lock()
+			result.AbortedByUser = !result.RetryBudgetExceeded
+			r.resultMu.Unlock()
+			stopped = true
+		}
+	}
+}
+
+// dependenciesDone reports whether all of a step's dependencies succeeded,
+// or the ID of one that failed
+func dependenciesDone(step *llm.Step, states []stepState, indexByID map[string]int) (ready bool, failedDep string) {
+	ready = true
+	for _, dep := range step.DependsOn {
+		index, ok := indexByID[dep]
+		if !ok {
+			continue // rejected by plan validation; do not block on it
+		}
+		switch states[index] {
+		case stepFailed:
+			return false, dep
+		case stepSucceeded:
+		default:
+			ready = false
+		}
+	}
+	return ready, ""
+}
+
+// recordDependencySkip records a step skipped because a dependency failed
+func (r *Runner) recordDependencySkip(result *ExecutionResult, step *llm.Step, failedDep string) {
+	skipped := &StepResult{
+		StepID:     step.ID,
+		Skipped:    true,
+		SkipReason: fmt.Sprintf("depends on %s, which did not complete", failedDep),
+	}
+	r.resultMu.Lock()
+	result.AddStepResult(skipped)
+	r.resultMu.Unlock()
+	r.notifyStepComplete(step, skipped)
+}
+
+// stepOutput returns the writer for a step's output lines, prefixed with
+// the step ID while steps run in parallel
+func (r *Runner) stepOutput(step *llm.Step, out io.Writer) io.Writer {
+	if !r.prefixOutput {
+		return out
+	}
+	return &prefixWriter{out: out, prefix: "[" + step.ID + "] ", mu: &r.outputMu}
+}
+
+// prefixWriter writes each line with a prefix. streamOutput writes one
+// line per Write call; the shared mutex keeps lines from interleaving.
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	mu     *sync.Mutex
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return 0, err
+	}
+	return w.out.Write(p)
+}
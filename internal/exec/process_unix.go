@@ -6,8 +6,11 @@
 package exec
 
 import (
+	"errors"
+	"fmt"
 	"os/exec"
 	"syscall"
+	"time"
 )
 
 // setPlatformProcessGroup configures the command to run in its own process group.
@@ -18,6 +21,11 @@ func setPlatformProcessGroup(cmd *exec.Cmd) {
 	}
 }
 
+// attachProcessGroup is a no-op on Unix: the group is created at start
+func attachProcessGroup(cmd *exec.Cmd) func() {
+	return func() {}
+}
+
 // killProcessGroup kills the entire process group associated with the command.
 // On Unix, we use negative PID to signal the entire process group.
 func killProcessGroup(cmd *exec.Cmd) error {
@@ -53,3 +61,32 @@ func interruptProcessGroup(cmd *exec.Cmd) error {
 	// Send SIGINT to the entire process group
 	return syscall.Kill(-pgid, syscall.SIGINT)
 }
+
+// processGroupAlive reports whether any process of the group led by pid is
+// still running. The shell itself has been reaped, so a live group means a
+// child it started in the background.
+func processGroupAlive(pid int) bool {
+	err := syscall.Kill(-pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// stopProcessCommand is the command that stops the process group led by pid
+func stopProcessCommand(pid int) string {
+	return fmt.Sprintf("kill -- -%d", pid)
+}
+
+// terminateProcessGroup sends SIGTERM to the process group led by pid and
+// SIGKILL if it is still running after grace
+func terminateProcessGroup(pid int, grace time.Duration) {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		return
+	}
+	deadline := time.Now().Add(grace)
+	for time.Now().Before(deadline) {
+		if !processGroupAlive(pid) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
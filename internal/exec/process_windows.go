@@ -1,40 +1,128 @@
 // Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
-// Windows-specific process handling
+// Windows-specific process handling using job objects
 
 //go:build windows
 
 package exec
 
 import (
+	"fmt"
 	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
 )
 
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259
+
+// jobs maps a started command to the job object holding its process tree
+var jobs sync.Map // *exec.Cmd -> windows.Handle
+
 // setPlatformProcessGroup configures platform-specific process attributes.
-// On Windows, we don't set up process groups the same way as Unix.
-// The CommandContext will handle termination via TerminateProcess.
+// The process gets its own console process group so that Ctrl+Break can be
+// delivered to it without reaching rdr.
 func setPlatformProcessGroup(cmd *exec.Cmd) {
-	// Windows doesn't use Unix-style process groups
-	// exec.CommandContext handles termination differently on Windows
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// attachProcessGroup places a started command in a new job object. Windows
+// has no process groups: children join their parent's job, so terminating
+// the job kills the whole tree. The returned function releases the job
+// without killing anything that is still running.
+//
+// A child spawned in the short window between Start and the assignment is
+// not part of the job; shells do not spawn that early in practice.
+func attachProcessGroup(cmd *exec.Cmd) func() {
+	if cmd.Process == nil {
+		return func() {}
+	}
+
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return func() {}
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return func() {}
+	}
+	defer windows.CloseHandle(process)
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		windows.CloseHandle(job)
+		return func() {}
+	}
+
+	jobs.Store(cmd, job)
+	return func() {
+		if _, ok := jobs.LoadAndDelete(cmd); ok {
+			windows.CloseHandle(job)
+		}
+	}
 }
 
-// killProcessGroup kills the process and its children.
-// On Windows, we rely on the default behavior of Process.Kill()
-// which calls TerminateProcess.
+// killProcessGroup kills the process and its children by terminating its
+// job object, falling back to TerminateProcess on the shell alone
 func killProcessGroup(cmd *exec.Cmd) error {
 	if cmd.Process == nil {
 		return nil
 	}
+	if job, ok := jobs.Load(cmd); ok {
+		if err := windows.TerminateJobObject(job.(windows.Handle), 1); err == nil {
+			return nil
+		}
+	}
 	return cmd.Process.Kill()
 }
 
 // interruptProcessGroup attempts to gracefully stop the process.
-// On Windows, there's no direct equivalent to SIGINT for console apps
-// without a console, so we fall back to Kill.
+// Ctrl+Break is sent to the process group created at start; console-less
+// processes cannot receive it, so the job is killed if that fails.
 func interruptProcessGroup(cmd *exec.Cmd) error {
 	if cmd.Process == nil {
 		return nil
 	}
-	// Windows doesn't have a clean way to send Ctrl+C to a process
-	// without a console, so we just kill it
-	return cmd.Process.Kill()
+	if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(cmd.Process.Pid)); err == nil {
+		return nil
+	}
+	return killProcessGroup(cmd)
+}
+
+// processGroupAlive reports whether the process pid is still running. The
+// job object is released once the step ends, so children of an exited
+// shell are not tracked.
+func processGroupAlive(pid int) bool {
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(process)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(process, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stopProcessCommand is the command that stops pid and its children
+func stopProcessCommand(pid int) string {
+	return fmt.Sprintf("taskkill /PID %d /T /F", pid)
+}
+
+// terminateProcessGroup terminates the process pid. Windows has no signal
+// to ask a console-less process to shut down, so grace is not used.
+func terminateProcessGroup(pid int, grace time.Duration) {
+	process, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(process)
+	_ = windows.TerminateProcess(process, 1)
 }
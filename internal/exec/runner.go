@@ -11,6 +11,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,7 +30,20 @@ type Runner struct {
 	sudoPrompt     SudoPromptFunc
 	failurePrompt  FailurePromptFunc
 	sudoApproveAll bool
+	buildCommand   func(step *llm.Step, workDir string) *exec.Cmd
+	killInside     func(step *llm.Step) // also stops a killed step where it really runs (--isolate docker)
+	sandboxTool    string
+	healthReady    chan struct{}
+	healthStepID   string // step whose readiness healthReady signals
+	prefixOutput   bool   // prefix step output with the step ID (parallel runs)
 	mu             sync.Mutex
+	promptMu       sync.Mutex        // keeps concurrent prompts from interleaving
+	resultMu       sync.Mutex        // guards the ExecutionResult of parallel steps
+	callbackMu     sync.Mutex        // serializes OnStepStart/OnStepComplete
+	outputMu       sync.Mutex        // serializes prefixed output lines
+	exported       map[string]string // env exported by completed steps (guarded by mu)
+	background     []*backgroundStep // detached steps still running (guarded by mu)
+	spawned        []spawnedProcess  // step processes, checked after the run (guarded by mu)
 }
 
 // NewRunner creates a new step runner
@@ -40,11 +55,23 @@ func NewRunner(config *RunnerConfig) *Runner {
 		}
 	}
 
-	return &Runner{
+	r := &Runner{
 		config:        config,
 		sudoPrompt:    DefaultSudoPrompt(),
 		failurePrompt: DefaultFailurePrompt(),
 	}
+	r.buildCommand = r.hostCommand
+	r.setupSandbox()
+
+	return r
+}
+
+// output returns where step output and runner messages are written
+func (r *Runner) output() io.Writer {
+	if r.config.Output != nil {
+		return r.config.Output
+	}
+	return os.Stdout
 }
 
 // SetSudoPrompt sets the sudo confirmation prompt function
@@ -119,131 +146,353 @@ func (r *Runner) ExecuteWithContext(ctx context.Context, plan *llm.RunPlan) *Exe
 		defer cancel()
 	}
 
+	// Detached servers only live as long as the plan (unless LeaveRunning),
+	// also when the run is interrupted
+	defer r.stopBackground()
+
+	// Ports already taken are not credited to the run
+	var portsBefore map[int]bool
+	if r.inspectsHost() {
+		portsBefore = listeningPorts(plan.Ports)
+	}
+
 	// Merge environment: process env + config env + plan env
 	mergedEnv := r.buildMergedEnv(plan.Env)
 
+	// Plans with depends_on can run independent steps concurrently
+	if r.config.MaxParallel > 1 && plan.HasDependencies() {
+		r.executeParallel(ctx, plan, result, mergedEnv)
+	} else {
+		r.executeSequential(ctx, plan, result, mergedEnv)
+	}
+
+	result.TotalTime = time.Since(startTime)
+
+	// Mark as failed if aborted, timed out or out of retries
+	if result.AbortedByUser || result.TimeoutReached || result.RetryBudgetExceeded {
+		result.Success = false
+	}
+
+	if result.Success {
+		r.collectComposeServices(ctx, plan, result, mergedEnv)
+	}
+
+	// Stop what the steps left behind, then inspect what is still up
+	r.stopBackground()
+	r.inspectAfterRun(result, portsBefore)
+
+	return result
+}
+
+// executeSequential runs the plan steps one after another, in order
+func (r *Runner) executeSequential(ctx context.Context, plan *llm.RunPlan, result *ExecutionResult, mergedEnv []string) {
 	for i := range plan.Steps {
 		// Check for cancellation before starting step
 		select {
 		case <-ctx.Done():
-			result.AbortedByUser = true
-			if ctx.Err() == context.DeadlineExceeded {
-				result.TimeoutReached = true
-			}
-			break
+			markStopped(result, ctx)
 		default:
 		}
 
-		if result.AbortedByUser {
+		if result.AbortedByUser || result.TimeoutReached || result.RetryBudgetExceeded {
 			break
 		}
 
 		step := &plan.Steps[i]
 
-		// Callback: step starting
-		if r.config.OnStepStart != nil {
-			r.config.OnStepStart(step)
+		// Steps finished by a previous run are not executed again
+		if r.config.SkipSteps[step.ID] {
+			r.recordResumeSkip(result, step)
+			continue
 		}
 
-		// Execute the step with context and merged env
-		stepResult := r.executeStepWithContext(ctx, step, mergedEnv)
-		result.AddStepResult(stepResult)
-
-		// Callback: step complete
-		if r.config.OnStepComplete != nil {
-			r.config.OnStepComplete(step, stepResult)
-		}
+		stepResult, index := r.runPlanStep(ctx, plan, step, mergedEnv, result)
 
 		// Handle cancellation
 		if stepResult.Cancelled {
-			result.AbortedByUser = true
-			if stepResult.Error != nil && strings.Contains(stepResult.Error.Error(), "timeout") {
-				result.TimeoutReached = true
-			}
+			markStopped(result, ctx)
 			break
 		}
 
 		// Handle failure
 		if !stepResult.Success && !stepResult.Skipped {
-			if stepResult.Error != nil && stepResult.Error.Error() == "aborted by user" {
-				result.AbortedByUser = true
+			if r.handleStepFailure(ctx, step, stepResult, mergedEnv, result, index) {
+				result.AbortedByUser = !result.RetryBudgetExceeded
 				break
 			}
+		}
+	}
+}
 
-			// Try deterministic auto-recovery for common startup failures before prompting.
-			if recoveredResult, recovered := r.tryAutoRecoverStep(ctx, step, stepResult, mergedEnv); recovered {
-				stepResult = recoveredResult
-				result.StepResults[len(result.StepResults)-1] = stepResult
-				if result.FailedStep != nil && result.FailedStep.StepID == step.ID {
-					result.FailedStep = stepResult
-				}
+// recordResumeSkip records a step completed by a previous run as skipped
+func (r *Runner) recordResumeSkip(result *ExecutionResult, step *llm.Step) {
+	r.resultMu.Lock()
+	defer r.resultMu.Unlock()
+	result.AddStepResult(&StepResult{
+		StepID:     step.ID,
+		Success:    true,
+		Skipped:    true,
+		SkipReason: ResumeSkipReason,
+	})
+}
 
-				if stepResult.Success {
-					result.Failed--
-					result.Completed++
-					result.Success = result.Failed == 0
-					if result.FailedStep != nil && result.FailedStep.StepID == step.ID {
-						result.FailedStep = nil
-					}
-					continue
-				}
-			}
+// runPlanStep runs one step with its callbacks and health check, records
+// the result and returns it with its index in result.StepResults
+func (r *Runner) runPlanStep(ctx context.Context, plan *llm.RunPlan, step *llm.Step, mergedEnv []string, result *ExecutionResult) (*StepResult, int) {
+	// Callback: step starting
+	r.notifyStepStart(step)
 
-			// Ask user how to proceed (unless auto-yes)
-			// Loop to allow multiple retries
-		retryLoop:
-			for !r.config.AutoYes {
-				choice := r.failurePrompt(step, stepResult)
-				switch choice {
-				case FailureChoiceRetry:
-					// Retry the step
-					stepResult = r.executeStepWithContext(ctx, step, mergedEnv)
-					result.StepResults[len(result.StepResults)-1] = stepResult
-					if stepResult.Success {
-						result.Failed--
-						result.Completed++
-						result.Success = len(result.StepResults) == result.Completed+result.Skipped
-						break retryLoop // Success, exit retry loop
-					}
-					// Still failed, continue retry loop to prompt again
-					continue
-				case FailureChoiceSkip:
-					// Convert failed step to skipped step
-					stepResult.Skipped = true
-					stepResult.SkipReason = "Skipped by user after failure"
-					result.StepResults[len(result.StepResults)-1] = stepResult
-					result.Failed--
-					result.Skipped++
-					// Clear FailedStep if this was the first failure
-					if result.FailedStep == stepResult {
-						result.FailedStep = nil
-					}
-					// Recalculate success: success if all non-skipped steps completed
-					result.Success = result.Failed == 0
-					break retryLoop
-				case FailureChoiceContinue:
-					// Continue to next step (step remains marked as failed)
-					break retryLoop
-				case FailureChoiceAbort:
-					result.AbortedByUser = true
-					break retryLoop
-				}
-			}
+	// Poll the plan's health check while the run step starts the app
+	var health *healthMonitor
+	if plan.HealthCheck != nil && r.config.Mode == ModeExecute && shouldAutoStopOnReady(step) {
+		health = r.startHealthCheck(ctx, plan.HealthCheck, step.ID)
+	}
 
-			if result.AbortedByUser {
-				break
+	// Execute the step with context and merged env
+	stepResult := r.executeStepWithContext(ctx, step, mergedEnv)
+
+	var healthResult *HealthCheckResult
+	if health != nil {
+		healthResult = r.waitHealthCheck(health, stepResult)
+	}
+
+	r.resultMu.Lock()
+	if healthResult != nil {
+		result.HealthCheck = healthResult
+	}
+	result.AddStepResult(stepResult)
+	index := len(result.StepResults) - 1
+	r.resultMu.Unlock()
+
+	// Callback: step complete
+	r.notifyStepComplete(step, stepResult)
+
+	return stepResult, index
+}
+
+// handleStepFailure tries auto-recovery for a failed step, then asks the
+// failure prompt how to proceed (unless AutoYes). The step's entry at index
+// in result.StepResults is updated with the outcome. Returns true if the
+// run must stop: the user aborted it, or a retry went over the budget
+// (result.RetryBudgetExceeded).
+func (r *Runner) handleStepFailure(ctx context.Context, step *llm.Step, stepResult *StepResult, mergedEnv []string, result *ExecutionResult, index int) bool {
+	if stepResult.Error != nil && stepResult.Error.Error() == "aborted by user" {
+		return true
+	}
+
+	// Try deterministic auto-recovery for common startup failures before prompting.
+	if recoveredResult, recovered := r.tryAutoRecoverStep(ctx, step, stepResult, mergedEnv, result); recovered {
+		stepResult = recoveredResult
+		r.replaceStepResult(result, index, stepResult)
+		if stepResult.Success {
+			return false
+		}
+	}
+	if r.retryBudgetExceeded(result) {
+		return true
+	}
+
+	// Ask user how to proceed (unless auto-yes)
+	// Loop to allow multiple retries
+	for !r.config.AutoYes {
+		r.promptMu.Lock()
+		choice := r.failurePrompt(step, stepResult)
+		r.promptMu.Unlock()
+
+		switch choice {
+		case FailureChoiceRetry:
+			if !r.takeRetry(result) {
+				return true
 			}
+			// Retry the step
+			stepResult = r.executeStepWithContext(ctx, step, mergedEnv)
+			r.replaceStepResult(result, index, stepResult)
+			if stepResult.Success {
+				return false // Success, exit retry loop
+			}
+			// Still failed, continue retry loop to prompt again
+			continue
+		case FailureChoiceSkip:
+			// Convert failed step to skipped step
+			skipped := *stepResult
+			skipped.Skipped = true
+			skipped.SkipReason = "Skipped by user after failure"
+			r.replaceStepResult(result, index, &skipped)
+			return false
+		case FailureChoiceContinue:
+			// Continue to next step (step remains marked as failed)
+			return false
+		case FailureChoiceAbort:
+			return true
 		}
 	}
+	return false
+}
 
-	result.TotalTime = time.Since(startTime)
+// takeRetry counts a retry attempt against MaxRetriesTotal. Once the budget
+// is used up it marks result.RetryBudgetExceeded and returns false.
+func (r *Runner) takeRetry(result *ExecutionResult) bool {
+	r.resultMu.Lock()
+	defer r.resultMu.Unlock()
+	if r.config.MaxRetriesTotal > 0 && result.Retries >= r.config.MaxRetriesTotal {
+		if !result.RetryBudgetExceeded {
+			fmt.Fprintf(r.output(), "    ⊘ Retry budget exhausted (%d retries across all steps)\n", result.Retries)
+		}
+		result.RetryBudgetExceeded = true
+		return false
+	}
+	result.Retries++
+	return true
+}
 
-	// Mark as failed if aborted or timed out
-	if result.AbortedByUser || result.TimeoutReached {
-		result.Success = false
+// retryBudgetExceeded reports whether a retry was refused (takeRetry)
+func (r *Runner) retryBudgetExceeded(result *ExecutionResult) bool {
+	r.resultMu.Lock()
+	defer r.resultMu.Unlock()
+	return result.RetryBudgetExceeded
+}
+
+// replaceStepResult swaps the result recorded at index and updates counters
+func (r *Runner) replaceStepResult(result *ExecutionResult, index int, updated *StepResult) {
+	r.resultMu.Lock()
+	defer r.resultMu.Unlock()
+	result.ReplaceStepResult(index, updated)
+}
+
+// notifyStepStart calls OnStepStart; callbacks are serialized so they can
+// share state even when steps run in parallel
+func (r *Runner) notifyStepStart(step *llm.Step) {
+	if r.config.OnStepStart != nil {
+		r.callbackMu.Lock()
+		defer r.callbackMu.Unlock()
+		r.config.OnStepStart(step)
 	}
+}
 
-	return result
+// notifyStepComplete calls OnStepComplete (serialized like notifyStepStart)
+func (r *Runner) notifyStepComplete(step *llm.Step, result *StepResult) {
+	if r.config.OnStepComplete != nil {
+		r.callbackMu.Lock()
+		defer r.callbackMu.Unlock()
+		r.config.OnStepComplete(step, result)
+	}
+}
+
+// markStopped records why execution stopped early: a deadline (global
+// timeout) sets TimeoutReached, any other cancellation is a user abort
+func markStopped(result *ExecutionResult, ctx context.Context) {
+	if ctx.Err() == context.DeadlineExceeded {
+		result.TimeoutReached = true
+		result.AbortedByUser = false
+	} else {
+		result.AbortedByUser = true
+	}
+}
+
+// resolveStepDir returns the directory a step runs in: its cwd joined to
+// the runner's working directory. A cwd that resolves outside the working
+// directory, including through a symlink, is an error.
+func resolveStepDir(workDir string, step *llm.Step) (string, error) {
+	if step.Cwd == "" || step.Cwd == "." {
+		return workDir, nil
+	}
+	if llm.CwdEscapes(step.Cwd) {
+		return "", fmt.Errorf("step %s: cwd %q is outside the working directory", step.ID, step.Cwd)
+	}
+
+	dir := filepath.Join(workDir, step.Cwd)
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return dir, nil // missing directories fail when the command starts
+	}
+	root, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return dir, nil
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || llm.CwdEscapes(rel) {
+		return "", fmt.Errorf("step %s: cwd %q resolves to %s, outside the working directory", step.ID, step.Cwd, resolved)
+	}
+	return dir, nil
+}
+
+// envOverride is a config or plan variable set on top of the process env
+type envOverride struct {
+	Key     string
+	Value   string
+	Source  string // "config" or "plan"
+	Shadows string // what it replaces: "process env", "config" or ""
+}
+
+// envOverrides lists the variables buildMergedEnv adds to the process env,
+// sorted by key. A plan value replaces a config value for the same key.
+func envOverrides(configEnv, planEnv map[string]string) []envOverride {
+	byKey := make(map[string]envOverride)
+	for key, value := range configEnv {
+		o := envOverride{Key: key, Value: value, Source: "config"}
+		if _, ok := os.LookupEnv(key); ok {
+			o.Shadows = "process env"
+		}
+		byKey[key] = o
+	}
+	for key, value := range planEnv {
+		o := envOverride{Key: key, Value: value, Source: "plan"}
+		if _, ok := configEnv[key]; ok {
+			o.Shadows = "config"
+		} else if _, ok := os.LookupEnv(key); ok {
+			o.Shadows = "process env"
+		}
+		byKey[key] = o
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	overrides := make([]envOverride, 0, len(keys))
+	for _, key := range keys {
+		overrides = append(overrides, byKey[key])
+	}
+	return overrides
+}
+
+// writeStepEnv prints the variables a step's env adds to or changes in the
+// process env, sorted, with sensitive values redacted
+func writeStepEnv(out io.Writer, env []string) {
+	process := make(map[string]bool)
+	for _, pair := range os.Environ() {
+		process[pair] = true
+	}
+
+	// Later entries win, as they do for the child process
+	values := make(map[string]string)
+	for _, pair := range env {
+		if process[pair] {
+			continue
+		}
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			values[key] = value
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(out, "    Env:\n")
+	for _, key := range keys {
+		value := values[key]
+		if isSensitiveKey(key) {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(out, "      %s=%s\n", key, value)
+	}
 }
 
 // buildMergedEnv creates a merged environment from process env, config env, and plan env
@@ -299,14 +548,38 @@ func (r *Runner) executeStepWithContext(ctx context.Context, step *llm.Step, mer
 		return result
 	}
 
+	// Reject a cwd outside the working directory before prompting for sudo
+	if _, err := resolveStepDir(r.config.WorkingDir, step); err != nil {
+		result.Success = false
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// Host-level sudo cannot be granted from inside a container
+	if step.RequiresSudo && r.config.Isolation == IsolationDocker {
+		result.Success = false
+		result.Error = fmt.Errorf("sudo steps are not allowed with --isolate docker")
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
 	// Check sudo requirement
 	if step.RequiresSudo && !r.config.AllowSudo {
+		// Held for the whole prompt so parallel sudo steps ask one at a
+		// time, and an "allow all" answer applies to the steps waiting
+		r.promptMu.Lock()
 		r.mu.Lock()
 		approveAll := r.sudoApproveAll
 		r.mu.Unlock()
 
+		var choice SudoChoice = SudoChoiceAllow
+		if !approveAll {
+			choice = r.sudoPrompt(step)
+		}
+		r.promptMu.Unlock()
+
 		if !approveAll {
-			choice := r.sudoPrompt(step)
 			switch choice {
 			case SudoChoiceAllow:
 				// Continue with this step
@@ -337,6 +610,7 @@ func (r *Runner) executeStepWithContext(ctx context.Context, step *llm.Step, mer
 	result.Stderr = cmdResult.Stderr
 	result.Error = cmdResult.Error
 	result.Cancelled = cmdResult.Cancelled
+	result.Detached = cmdResult.Detached
 	result.Duration = time.Since(startTime)
 
 	return result
@@ -350,6 +624,8 @@ type CommandResult struct {
 	Stderr    string
 	Error     error
 	Cancelled bool
+	Detached  bool
+	LogFile   string // where the output of a step left running goes
 }
 
 // runCommand executes a shell command (backward compatibility wrapper)
@@ -364,88 +640,160 @@ func (r *Runner) runCommandWithContext(ctx context.Context, step *llm.Step, merg
 	result := &CommandResult{}
 
 	// Determine working directory
-	workDir := r.config.WorkingDir
-	if step.Cwd != "" && step.Cwd != "." {
-		workDir = filepath.Join(r.config.WorkingDir, step.Cwd)
+	workDir, err := resolveStepDir(r.config.WorkingDir, step)
+	if err != nil {
+		result.Error = err
+		result.ExitCode = -1
+		return result
 	}
 
-	// Get timeout and create timeout context
+	// Get timeout and create timeout context. stepCtx derives from ctx, so the
+	// global deadline also stops an in-flight step (not only between steps).
 	timeout := GetStepTimeout(step, r.config.StepTimeout)
 	stepCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Create command with OS-appropriate shell
+	// Create command (host shell or container exec)
 	// Note: We don't use exec.CommandContext because it only kills the direct
 	// child process, not the entire process group. Instead, we manage
 	// cancellation manually using process groups.
-	var cmd *exec.Cmd
-	if isWindows() {
-		cmd = exec.Command("cmd", "/C", step.Cmd)
-	} else {
-		cmd = exec.Command("sh", "-c", step.Cmd)
-	}
-	cmd.Dir = workDir
+	cmd := r.buildCommand(step, workDir)
 
 	// Set up process group for proper child process termination
 	setPlatformProcessGroup(cmd)
 
-	// Set merged environment if provided
-	if mergedEnv != nil {
-		cmd.Env = mergedEnv
+	// Merged environment plus what earlier steps exported. The step can
+	// export more through the $RDR_ENV file, read back if it succeeds.
+	envFile := r.createEnvFile(step)
+	if envFile != "" {
+		defer func() {
+			if result.Success {
+				r.collectExports(step, envFile)
+			}
+			os.Remove(envFile)
+		}()
 	}
-
-	// Set up pipes for stdout/stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		result.Error = fmt.Errorf("failed to create stdout pipe: %w", err)
-		return result
+	cmd.Env = r.stepEnv(mergedEnv, envFile)
+	if r.config.ShowEnv {
+		writeStepEnv(r.output(), cmd.Env)
 	}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		result.Error = fmt.Errorf("failed to create stderr pipe: %w", err)
-		return result
+	autoStopOnReady := shouldAutoStopOnReady(step)
+
+	// With Detach (or the step's own detach), a server that is up (or still
+	// running after detachStartupWait) is left in the background instead of stopped
+	detach := (r.config.Detach || step.Detach) && autoStopOnReady
+
+	// A step that may be left running after rdr exits must not write to
+	// pipes rdr reads, or it dies of SIGPIPE on its next line once rdr is
+	// gone. Its output goes to a log file, followed while the step runs.
+	leaveRunning := detach && r.config.LeaveRunning
+	var stdout, stderr io.ReadCloser
+	var pipes []io.Closer
+	var logReader *os.File
+	if leaveRunning {
+		logFile, err := r.createStepLog(step)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		defer logFile.Close()
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		if logReader, err = os.Open(logFile.Name()); err != nil {
+			result.Error = fmt.Errorf("failed to open step log: %w", err)
+			return result
+		}
+		result.LogFile = logFile.Name()
+	} else {
+		// Set up pipes for stdout/stderr
+		stdout, err = cmd.StdoutPipe()
+		if err != nil {
+			result.Error = fmt.Errorf("failed to create stdout pipe: %w", err)
+			return result
+		}
+
+		stderr, err = cmd.StderrPipe()
+		if err != nil {
+			result.Error = fmt.Errorf("failed to create stderr pipe: %w", err)
+			return result
+		}
+		pipes = []io.Closer{stdout, stderr}
 	}
 
 	// Start command
 	if err := cmd.Start(); err != nil {
+		if logReader != nil {
+			logReader.Close()
+		}
 		result.Error = fmt.Errorf("failed to start command: %w", err)
 		return result
 	}
+	// Track the process tree where the OS needs it (job object on Windows)
+	release := attachProcessGroup(cmd)
+	detached := false
+	defer func() {
+		if !detached {
+			release()
+		}
+	}()
 
 	// Channel to signal when command completes
 	done := make(chan error, 1)
 	ready := make(chan struct{}, 1)
-	autoStopOnReady := shouldAutoStopOnReady(step)
+	var startupWait <-chan time.Time
+	if detach {
+		timer := time.NewTimer(detachStartupWait)
+		defer timer.Stop()
+		startupWait = timer.C
+	}
 
-	// Read output concurrently
-	var stdoutBuf, stderrBuf strings.Builder
-	var wg sync.WaitGroup
-	wg.Add(2)
+	// Health-check readiness (nil channel when no health check is running)
+	r.mu.Lock()
+	var healthReady chan struct{}
+	if r.healthStepID == step.ID {
+		healthReady = r.healthReady
+	}
+	r.mu.Unlock()
 
-	go func() {
-		defer wg.Done()
-		r.streamOutput(stdout, &stdoutBuf, os.Stdout, func(line string) {
-			if autoStopOnReady && isNextReadyLine(line) {
-				select {
-				case ready <- struct{}{}:
-				default:
-				}
+	// Read output concurrently
+	stdoutBuf := newCappedBuffer(r.maxOutputBytes())
+	stderrBuf := newCappedBuffer(r.maxOutputBytes())
+	onLine := func(line string) {
+		if autoStopOnReady && isReadyLine(line) {
+			select {
+			case ready <- struct{}{}:
+			default:
 			}
-		})
-	}()
+		}
+	}
+	var wg sync.WaitGroup
 
-	go func() {
-		defer wg.Done()
-		r.streamOutput(stderr, &stderrBuf, os.Stderr, func(line string) {
-			if autoStopOnReady && isNextReadyLine(line) {
-				select {
-				case ready <- struct{}{}:
-				default:
-				}
-			}
+	// stopTail stops following the log file, once the rest of it is read
+	stopTail := func() {}
+	if leaveRunning {
+		tailStop := make(chan struct{})
+		tailDone := make(chan struct{})
+		go func() {
+			defer close(tailDone)
+			defer logReader.Close()
+			r.tailLog(logReader, stdoutBuf, r.stepOutput(step, r.output()), onLine, tailStop)
+		}()
+		stopTail = sync.OnceFunc(func() {
+			close(tailStop)
+			<-tailDone
 		})
-	}()
+	} else {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.streamOutput(stdout, stdoutBuf, r.stepOutput(step, r.output()), onLine)
+		}()
+		go func() {
+			defer wg.Done()
+			r.streamOutput(stderr, stderrBuf, r.stepOutput(step, os.Stderr), onLine)
+		}()
+	}
 
 	// Wait for command completion in a goroutine
 	go func() {
@@ -453,32 +801,61 @@ func (r *Runner) runCommandWithContext(ctx context.Context, step *llm.Step, merg
 		done <- cmd.Wait() // Then wait for command
 	}()
 
+	// stopWhenReady stops a server that is up and blocking, treating it as
+	// success. With Detach it keeps running until the plan is done.
+	stopWhenReady := func() *CommandResult {
+		if detach {
+			detached = true
+			r.addBackground(&backgroundStep{
+				stepID: step.ID, cmd: cmd, done: done, pipes: pipes, release: release,
+			})
+			stopTail()
+			if leaveRunning {
+				r.recordSpawned(step.ID, cmd.Process.Pid, result.LogFile)
+				fmt.Fprintf(r.output(), "  → %s keeps running in the background (left running, output in %s)\n", step.ID, result.LogFile)
+			} else {
+				fmt.Fprintf(r.output(), "  → %s keeps running in the background (stopped after the last step)\n", step.ID)
+			}
+			result.Stdout = stdoutBuf.String()
+			result.Stderr = stderrBuf.String()
+			result.Success = true
+			result.Detached = true
+			return result
+		}
+		r.killStep(step, cmd)
+		waitAfterKill(done, pipes...)
+		stopTail()
+		result.Stdout = stdoutBuf.String()
+		result.Stderr = stderrBuf.String()
+		result.Success = true
+		result.ExitCode = 0
+		return result
+	}
+
 	// Wait for either command completion or context cancellation
 	var waitErr error
 	select {
 	case waitErr = <-done:
-		// Command completed normally (success or failure)
+		// Command completed normally (success or failure). Anything it
+		// started in the background is still in its process group.
+		r.recordSpawned(step.ID, cmd.Process.Pid, result.LogFile)
 	case <-ready:
 		// The command appears to have successfully started a server and is now
 		// blocking (e.g. `npm start` for Next.js). Stop it and treat as success.
-		if killErr := killProcessGroup(cmd); killErr != nil {
-			_ = killErr
-		}
-		<-done
-		result.Stdout = stdoutBuf.String()
-		result.Stderr = stderrBuf.String()
-		result.Success = true
-		result.ExitCode = 0
-		return result
+		return stopWhenReady()
+	case <-healthReady:
+		// The plan's health-check URL responded as expected
+		return stopWhenReady()
+	case <-startupWait:
+		// Still running without a readiness line: assume it is serving
+		return stopWhenReady()
 	case <-stepCtx.Done():
 		// Context was cancelled (timeout or manual cancellation)
 		// Kill the entire process group
-		if killErr := killProcessGroup(cmd); killErr != nil {
-			// Log but don't fail - the process might have already exited
-			_ = killErr
-		}
+		r.killStep(step, cmd)
 		// Wait for the command to actually terminate
-		<-done
+		waitAfterKill(done, pipes...)
+		stopTail()
 		// Determine if this was a timeout or cancellation
 		if ctx.Err() != nil {
 			result.Cancelled = true
@@ -495,6 +872,7 @@ func (r *Runner) runCommandWithContext(ctx context.Context, step *llm.Step, merg
 		return result
 	}
 
+	stopTail()
 	result.Stdout = stdoutBuf.String()
 	result.Stderr = stderrBuf.String()
 
@@ -524,46 +902,226 @@ func (r *Runner) runCommandWithContext(ctx context.Context, step *llm.Step, merg
 	return result
 }
 
-// isWindows returns true if running on Windows
-func isWindows() bool {
-	return strings.Contains(strings.ToLower(os.Getenv("OS")), "windows")
+// killStep kills a step's process group and, through killInside, what the
+// step runs outside of it. Errors are ignored: the process may have exited.
+func (r *Runner) killStep(step *llm.Step, cmd *exec.Cmd) {
+	_ = killProcessGroup(cmd)
+	if r.killInside != nil {
+		r.killInside(step)
+	}
+}
+
+// killGracePeriod bounds how long to wait for output after killing a step.
+// Detached grandchildren (setsid, daemons) can keep the pipes open after
+// the process group is gone, which would otherwise block forever.
+const killGracePeriod = 2 * time.Second
+
+// waitAfterKill waits for a killed command, closing its pipes if needed
+func waitAfterKill(done <-chan error, pipes ...io.Closer) {
+	select {
+	case <-done:
+		return
+	case <-time.After(killGracePeriod):
+	}
+	for _, pipe := range pipes {
+		_ = pipe.Close()
+	}
+	<-done
 }
 
-// streamOutput reads from a pipe and writes to both a buffer and output
-func (r *Runner) streamOutput(pipe io.ReadCloser, buf *strings.Builder, out io.Writer, onLine func(line string)) {
+// hostCommand creates a command running the step with the configured shell
+// (cmd on Windows and sh elsewhere by default)
+func (r *Runner) hostCommand(step *llm.Step, workDir string) *exec.Cmd {
+	args := r.config.Shell.Args(step.Cmd)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = workDir
+	return cmd
+}
+
+// maxOutputBytes returns the cap on captured output per stream
+func (r *Runner) maxOutputBytes() int {
+	if r.config.MaxOutputBytes != 0 {
+		return r.config.MaxOutputBytes
+	}
+	return DefaultMaxOutputBytes
+}
+
+// streamOutput reads from a pipe and writes to both output and a capped
+// buffer: every line is streamed live, only the head and tail are kept
+func (r *Runner) streamOutput(pipe io.ReadCloser, buf *cappedBuffer, out io.Writer, onLine func(line string)) {
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
-		line := scanner.Text()
-		buf.WriteString(line)
-		buf.WriteString("\n")
+		r.handleOutputLine(scanner.Text(), buf, out, onLine)
+	}
+}
+
+// handleOutputLine captures, reports and streams one line of step output
+func (r *Runner) handleOutputLine(line string, buf *cappedBuffer, out io.Writer, onLine func(line string)) {
+	buf.WriteString(line)
+	buf.WriteString("\n")
+
+	if onLine != nil {
+		onLine(line)
+	}
+
+	// Only write to output if verbose or in execute mode
+	if r.config.Verbose || r.config.Mode == ModeExecute {
+		fmt.Fprintln(out, line)
+	}
+}
 
-		if onLine != nil {
-			onLine(line)
+// logPollInterval is how often tailLog looks for new output in a step log
+const logPollInterval = 100 * time.Millisecond
+
+// tailLog follows a step log file like streamOutput follows a pipe, until
+// stop is closed. What was written by then is read before it returns.
+func (r *Runner) tailLog(file *os.File, buf *cappedBuffer, out io.Writer, onLine func(line string), stop <-chan struct{}) {
+	reader := bufio.NewReader(file)
+	var partial string
+	// readLines handles the complete lines written so far
+	readLines := func() {
+		for {
+			chunk, err := reader.ReadString('\n')
+			partial += chunk
+			if err != nil {
+				return
+			}
+			r.handleOutputLine(strings.TrimRight(partial, "\r\n"), buf, out, onLine)
+			partial = ""
 		}
+	}
 
-		// Only write to output if verbose or in execute mode
-		if r.config.Verbose || r.config.Mode == ModeExecute {
-			fmt.Fprintln(out, line)
+	for {
+		readLines()
+		select {
+		case <-stop:
+			readLines()
+			if partial != "" {
+				r.handleOutputLine(strings.TrimRight(partial, "\r\n"), buf, out, onLine)
+			}
+			return
+		case <-time.After(logPollInterval):
 		}
 	}
 }
 
+// createStepLog creates the log file of a step left running: <id>.log in
+// LogDir, or a temporary file without one
+func (r *Runner) createStepLog(step *llm.Step) (*os.File, error) {
+	name := strings.ReplaceAll(step.ID, "/", "-")
+	var file *os.File
+	var err error
+	if r.config.LogDir != "" {
+		file, err = os.Create(filepath.Join(r.config.LogDir, name+".log"))
+	} else {
+		file, err = os.CreateTemp("", "rdr-"+name+"-*.log")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create step log: %w", err)
+	}
+	return file, nil
+}
+
 func shouldAutoStopOnReady(step *llm.Step) bool {
 	if step == nil {
 		return false
 	}
+	// A detached step is a server by the plan's own account
+	if step.Detach {
+		return true
+	}
 	// The "run" step is typically expected to start the app and may not exit.
-	// For common frameworks (e.g. Next.js), we treat readiness output as success.
-	if strings.EqualFold(step.ID, "run") {
+	// For common frameworks (e.g. Next.js, Phoenix), we treat readiness output as success.
+	// In a monorepo plan this is each subproject's "run" step (e.g. "web/run").
+	if _, id := llm.SplitStepID(step.ID); strings.EqualFold(id, "run") {
 		return true
 	}
-	return false
+	// Servers under another ID ("serve", "dev", "up") would otherwise block
+	// until their timeout
+	return llm.IsLongRunningStep(step)
+}
+
+// detachStartupWait is how long a detached step may run without a
+// readiness line before it is assumed to be serving
+const detachStartupWait = 10 * time.Second
+
+// backgroundStep is a detached step's process, stopped by stopBackground
+type backgroundStep struct {
+	stepID  string
+	cmd     *exec.Cmd
+	done    <-chan error
+	pipes   []io.Closer
+	release func()
+}
+
+// addBackground records a detached step
+func (r *Runner) addBackground(step *backgroundStep) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.background = append(r.background, step)
+}
+
+// stopBackground stops the detached steps, most recent first. With
+// LeaveRunning they are only released and keep running after rdr exits.
+func (r *Runner) stopBackground() {
+	r.mu.Lock()
+	steps := r.background
+	r.background = nil
+	r.mu.Unlock()
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if r.config.LeaveRunning {
+			step.release()
+			continue
+		}
+		fmt.Fprintf(r.output(), "  → Stopping background step %s\n", step.stepID)
+		stopGracefully(step.cmd, step.done, step.pipes...)
+		step.release()
+	}
+}
+
+// stopGracePeriod is how long a stopped server may take to shut down after
+// an interrupt before it is killed
+const stopGracePeriod = 3 * time.Second
+
+// stopGracefully interrupts a command's process group so servers can shut
+// down cleanly, and kills it if it is still running after stopGracePeriod
+func stopGracefully(cmd *exec.Cmd, done <-chan error, pipes ...io.Closer) {
+	if err := interruptProcessGroup(cmd); err == nil {
+		select {
+		case <-done:
+			return
+		case <-time.After(stopGracePeriod):
+		}
+	}
+	_ = killProcessGroup(cmd)
+	waitAfterKill(done, pipes...)
 }
 
-func isNextReadyLine(line string) bool {
-	l := strings.ToLower(line)
-	// Next.js prints: "ready started server on ..." or "ready - started server on ..."
-	return strings.Contains(l, "ready") && strings.Contains(l, "started server")
+// readyPatterns match the line a framework dev server prints once it is
+// accepting connections
+var readyPatterns = []*regexp.Regexp{
+	// Next.js: "ready started server on ..." or "ready - started server on ..."
+	regexp.MustCompile(`(?i)ready.*started server`),
+	// Phoenix: "Running MyAppWeb.Endpoint with cowboy 2.10.0 at http://localhost:4000"
+	regexp.MustCompile(`(?i)\brunning\s+\S+.*\b(at|using)\s+(https?://)?[\w.\-\[\]:]+:\d+`),
+	// Deno.serve, Fresh, Bun.serve apps: "Listening on http://localhost:8000/"
+	// Puma (Rails): "* Listening on http://127.0.0.1:3000" or "tcp://0.0.0.0:3000"
+	regexp.MustCompile(`(?i)\blistening on\s+((https?|tcp|ssl)://)?[\w.\-\[\]:]+:\d+`),
+	// Elysia: "Elysia is running at localhost:3000", Hono: "Started server http://localhost:3000"
+	regexp.MustCompile(`(?i)\b(is running|started server|server (is )?running)\s+((at|on)\s+)?(https?://)?[\w.\-\[\]:]+:\d+`),
+	// Vite (bun run dev / deno task dev): "➜  Local:   http://localhost:5173/"
+	regexp.MustCompile(`(?i)^\s*(➜\s+)?local:\s+https?://`),
+}
+
+func isReadyLine(line string) bool {
+	for _, pattern := range readyPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
 }
 
 // FormatStepResult returns a human-readable step result
@@ -571,14 +1129,14 @@ func FormatStepResult(result *StepResult) string {
 	var sb strings.Builder
 
 	if result.Skipped {
-		sb.WriteString(fmt.Sprintf("⊘ %s: Skipped", result.StepID))
+		sb.WriteString(colorize(colorYellow, "⊘") + fmt.Sprintf(" %s: Skipped", result.StepID))
 		if result.SkipReason != "" {
 			sb.WriteString(fmt.Sprintf(" (%s)", result.SkipReason))
 		}
 	} else if result.Success {
-		sb.WriteString(fmt.Sprintf("✓ %s: Success", result.StepID))
+		sb.WriteString(colorize(colorGreen, "✓") + fmt.Sprintf(" %s: Success", result.StepID))
 	} else {
-		sb.WriteString(fmt.Sprintf("✗ %s: Failed", result.StepID))
+		sb.WriteString(colorize(colorRed, "✗") + fmt.Sprintf(" %s: Failed", result.StepID))
 		if result.Error != nil {
 			sb.WriteString(fmt.Sprintf(" - %s", result.Error.Error()))
 		}
@@ -600,6 +1158,8 @@ func FormatExecutionResult(result *ExecutionResult) string {
 		sb.WriteString("✓ All steps completed successfully\n")
 	} else if result.TimeoutReached {
 		sb.WriteString("⏱ Execution stopped: global timeout reached\n")
+	} else if result.RetryBudgetExceeded {
+		sb.WriteString(fmt.Sprintf("⊘ Execution stopped: retry budget exhausted after %d retries across all steps\n", result.Retries))
 	} else if result.AbortedByUser {
 		sb.WriteString("⊘ Execution aborted by user\n")
 	} else {
@@ -612,6 +1172,10 @@ func FormatExecutionResult(result *ExecutionResult) string {
 	sb.WriteString(fmt.Sprintf("  Skipped:   %d\n", result.Skipped))
 	sb.WriteString(fmt.Sprintf("\nTotal time: %v\n", result.TotalTime.Round(time.Millisecond)))
 
+	if result.HealthCheck != nil {
+		sb.WriteString(fmt.Sprintf("\nHealth check: %s\n", FormatHealthCheckResult(result.HealthCheck)))
+	}
+
 	if result.FailedStep != nil {
 		sb.WriteString(fmt.Sprintf("\nFailed at step: %s\n", result.FailedStep.StepID))
 		if result.FailedStep.Stderr != "" {
@@ -633,8 +1197,36 @@ func FormatExecutionResult(result *ExecutionResult) string {
 		sb.WriteString("Next Steps\n")
 		sb.WriteString("─────────────────────────────────────\n")
 
-		// Show exposed ports
-		if len(result.Ports) > 0 {
+		// Show compose services, or else the plan's ports
+		if len(result.Services) > 0 {
+			sb.WriteString("\nCompose services:\n")
+			for _, service := range result.Services {
+				state := service.State
+				if service.Health != "" {
+					state += ", " + service.Health
+				}
+				sb.WriteString(fmt.Sprintf("  • %s (%s)\n", service.Name, state))
+				for _, url := range service.URLs {
+					sb.WriteString(fmt.Sprintf("    → %s\n", url))
+				}
+			}
+			sb.WriteString("\nThe services keep running. Stop them with:\n")
+			for _, down := range result.ComposeDown {
+				sb.WriteString(fmt.Sprintf("  %s\n", down))
+			}
+		} else if len(result.PortStatus) > 0 {
+			sb.WriteString("\nPorts:\n")
+			for _, status := range result.PortStatus {
+				state := "not listening"
+				switch {
+				case status.Listening && status.InUseBefore:
+					state = "listening, but already in use before the run"
+				case status.Listening:
+					state = "listening"
+				}
+				sb.WriteString(fmt.Sprintf("  → http://localhost:%d (%s)\n", status.Port, state))
+			}
+		} else if len(result.Ports) > 0 {
 			sb.WriteString("\nApplication may be available at:\n")
 			for _, port := range result.Ports {
 				sb.WriteString(fmt.Sprintf("  → http://localhost:%d\n", port))
@@ -649,25 +1241,110 @@ func FormatExecutionResult(result *ExecutionResult) string {
 			}
 		}
 
-		if len(result.Ports) == 0 && len(result.Notes) == 0 {
+		if len(result.Services) == 0 && len(result.Ports) == 0 && len(result.Notes) == 0 && len(result.Processes) == 0 {
 			sb.WriteString("\n  Check application output for next steps.\n")
 		}
 	}
 
+	// Processes outlive failed runs too
+	if len(result.Processes) > 0 {
+		sb.WriteString("\nStill running:\n")
+		for _, proc := range result.Processes {
+			sb.WriteString(fmt.Sprintf("  • %s: PID %d (stop with: %s)\n", proc.StepID, proc.PID, proc.Stop))
+			if proc.LogFile != "" {
+				sb.WriteString(fmt.Sprintf("    output: %s\n", proc.LogFile))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// maxListedCommand is the longest command shown by FormatStepList
+const maxListedCommand = 72
+
+// FormatStepList returns a compact numbered list of the plan's steps with
+// their risk and sudo flag. Steps in skip (already completed) are marked.
+func FormatStepList(plan *llm.RunPlan, skip map[string]bool) string {
+	idWidth := len("STEP")
+	for _, step := range plan.Steps {
+		idWidth = max(idWidth, len(step.ID))
+	}
+	numWidth := len(fmt.Sprint(len(plan.Steps)))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  %*s  %-*s  %-8s  %-4s  %s\n", numWidth, "#", idWidth, "STEP", "RISK", "SUDO", "COMMAND"))
+	for i, step := range plan.Steps {
+		sudo := ""
+		if step.RequiresSudo {
+			sudo = "yes"
+		}
+		cmd := strings.Join(strings.Fields(step.Cmd), " ")
+		// Cut by rune so a multi-byte character is not split
+		if runes := []rune(cmd); len(runes) > maxListedCommand {
+			cmd = string(runes[:maxListedCommand-3]) + "..."
+		}
+		if step.Cwd != "" && step.Cwd != "." {
+			cmd += fmt.Sprintf(" (in %s)", step.Cwd)
+		}
+		if skip[step.ID] {
+			cmd += " [done]"
+		}
+		// Padded before coloring, which adds invisible characters
+		risk := ColorRisk(step.Risk, fmt.Sprintf("%-8s", step.Risk))
+		sb.WriteString(fmt.Sprintf("  %*d  %-*s  %s  %-4s  %s\n", numWidth, i+1, idWidth, step.ID, risk, sudo, cmd))
+	}
 	return sb.String()
 }
 
 // DryRunDisplay shows what would be executed in dry-run mode
 func DryRunDisplay(plan *llm.RunPlan, workDir string) string {
+	return DryRunDisplayWithSandbox(plan, workDir, nil)
+}
+
+// DryRunDisplayWithSandbox shows what would be executed, including sandbox wrapping
+func DryRunDisplayWithSandbox(plan *llm.RunPlan, workDir string, sandbox *SandboxConfig) string {
+	return DryRunDisplayWithOptions(plan, workDir, &DryRunOptions{Sandbox: sandbox})
+}
+
+// DryRunOptions controls what DryRunDisplayWithOptions shows
+type DryRunOptions struct {
+	Sandbox     *SandboxConfig    // Show sandbox wrapping (nil = none)
+	Environment map[string]string // Config-level env (RunnerConfig.Environment)
+	Detailed    bool              // Show each step's resolved cwd and the env overrides
+	Shell       Shell             // Shell host commands would run with
+}
+
+// DryRunDisplayWithOptions shows what would be executed. In detailed mode it
+// also shows the absolute directory each step runs in and the config/plan
+// env applied on top of the process environment (sensitive values redacted).
+func DryRunDisplayWithOptions(plan *llm.RunPlan, workDir string, opts *DryRunOptions) string {
+	if opts == nil {
+		opts = &DryRunOptions{}
+	}
+	sandbox := opts.Sandbox
+
 	var sb strings.Builder
 
+	sandboxTool := ""
+	if sandbox != nil && sandbox.Enabled {
+		sandboxTool = DetectSandboxTool(sandbox.Tool)
+	}
+
 	sb.WriteString("\n╔══════════════════════════════════════════════════════════════╗\n")
 	sb.WriteString("║                    DRY-RUN MODE                              ║\n")
 	sb.WriteString("║              No commands will be executed                    ║\n")
 	sb.WriteString("╚══════════════════════════════════════════════════════════════╝\n\n")
 
 	sb.WriteString(fmt.Sprintf("Project type: %s\n", plan.ProjectType))
-	sb.WriteString(fmt.Sprintf("Working directory: %s\n\n", workDir))
+	sb.WriteString(fmt.Sprintf("Working directory: %s\n", workDir))
+	if opts.Shell != ShellAuto {
+		sb.WriteString(fmt.Sprintf("Shell: %s\n", opts.Shell))
+	}
+	if desc := DescribeSandbox(sandbox); desc != "" {
+		sb.WriteString(fmt.Sprintf("Sandbox: %s\n", desc))
+	}
+	sb.WriteString("\n")
 
 	// Prerequisites
 	if len(plan.Prerequisites) > 0 {
@@ -693,10 +1370,35 @@ func DryRunDisplay(plan *llm.RunPlan, workDir string) string {
 		if step.Cwd != "" && step.Cwd != "." {
 			sb.WriteString(fmt.Sprintf("      Directory: %s\n", step.Cwd))
 		}
-		sb.WriteString(fmt.Sprintf("      Risk: %s\n", step.Risk))
+		if opts.Detailed {
+			if dir, err := resolveStepDir(workDir, &step); err != nil {
+				sb.WriteString(fmt.Sprintf("      Resolved cwd: ✗ %v\n", err))
+			} else {
+				sb.WriteString(fmt.Sprintf("      Resolved cwd: %s\n", dir))
+			}
+		}
+		if len(step.DependsOn) > 0 {
+			sb.WriteString(fmt.Sprintf("      Depends on: %s\n", strings.Join(step.DependsOn, ", ")))
+		}
+		if len(step.ExportEnv) > 0 {
+			keys := make([]string, 0, len(step.ExportEnv))
+			for key := range step.ExportEnv {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			sb.WriteString(fmt.Sprintf("      Exports: %s\n", strings.Join(keys, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf("      Risk: %s\n", ColorRisk(step.Risk, string(step.Risk))))
 		if step.RequiresSudo {
 			sb.WriteString("      ⚠ Requires sudo\n")
 		}
+		if sandboxTool != "" {
+			if step.RequiresSudo {
+				sb.WriteString("      Sandbox: not applied (requires sudo)\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("      Sandboxed: %s\n", sandboxPreview(sandboxTool, workDir, sandbox, opts.Shell, &step)))
+			}
+		}
 		if step.Description != "" {
 			sb.WriteString(fmt.Sprintf("      Description: %s\n", step.Description))
 		}
@@ -715,6 +1417,25 @@ func DryRunDisplay(plan *llm.RunPlan, workDir string) string {
 		}
 	}
 
+	// Effective overrides, in the order buildMergedEnv applies them
+	if opts.Detailed {
+		overrides := envOverrides(opts.Environment, plan.Env)
+		if len(overrides) > 0 {
+			sb.WriteString("\nEnvironment overrides (applied to every step, plan wins over config):\n")
+			for _, o := range overrides {
+				displayValue := o.Value
+				if isSensitiveKey(o.Key) {
+					displayValue = "[REDACTED]"
+				}
+				sb.WriteString(fmt.Sprintf("  %s=%s (%s", o.Key, displayValue, o.Source))
+				if o.Shadows != "" {
+					sb.WriteString(", overrides " + o.Shadows)
+				}
+				sb.WriteString(")\n")
+			}
+		}
+	}
+
 	// Ports
 	if len(plan.Ports) > 0 {
 		sb.WriteString("\nExposed ports:\n")
@@ -723,6 +1444,15 @@ func DryRunDisplay(plan *llm.RunPlan, workDir string) string {
 		}
 	}
 
+	// Health check
+	if plan.HealthCheck != nil {
+		expected := plan.HealthCheck.ExpectedStatus
+		if expected == 0 {
+			expected = 200
+		}
+		sb.WriteString(fmt.Sprintf("\nHealth check (after run step):\n  • GET %s (expect %d)\n", plan.HealthCheck.URL, expected))
+	}
+
 	// Notes
 	if len(plan.Notes) > 0 {
 		sb.WriteString("\nNotes:\n")
@@ -734,51 +1464,18 @@ func DryRunDisplay(plan *llm.RunPlan, workDir string) string {
 	return sb.String()
 }
 
+// stepTypeMarkers are the visual markers shown for each step kind
+var stepTypeMarkers = map[llm.StepKind]string{
+	llm.StepKindInstall: "[📦 INSTALL]",
+	llm.StepKindBuild:   "[🔨 BUILD]",
+	llm.StepKindTest:    "[🧪 TEST]",
+	llm.StepKindRun:     "[🚀 RUN]",
+	llm.StepKindSetup:   "[⚙️ SETUP]",
+}
+
 // getStepTypeMarker returns a visual marker for the step type
 func getStepTypeMarker(step *llm.Step) string {
-	id := strings.ToLower(step.ID)
-	cmd := strings.ToLower(step.Cmd)
-
-	// Install-like steps
-	if strings.Contains(id, "install") || strings.Contains(id, "deps") ||
-		strings.Contains(cmd, "npm install") || strings.Contains(cmd, "npm ci") ||
-		strings.Contains(cmd, "yarn install") || strings.Contains(cmd, "pnpm install") ||
-		strings.Contains(cmd, "pip install") || strings.Contains(cmd, "poetry install") ||
-		strings.Contains(cmd, "go mod download") || strings.Contains(cmd, "cargo fetch") ||
-		strings.Contains(cmd, "bundle install") || strings.Contains(cmd, "composer install") {
-		return "[📦 INSTALL]"
-	}
-
-	// Build steps
-	if strings.Contains(id, "build") || strings.Contains(id, "compile") ||
-		strings.Contains(cmd, "go build") || strings.Contains(cmd, "cargo build") ||
-		strings.Contains(cmd, "npm run build") || strings.Contains(cmd, "yarn build") ||
-		strings.Contains(cmd, "make build") || strings.Contains(cmd, "docker build") {
-		return "[🔨 BUILD]"
-	}
-
-	// Test steps
-	if strings.Contains(id, "test") ||
-		strings.Contains(cmd, "go test") || strings.Contains(cmd, "npm test") ||
-		strings.Contains(cmd, "pytest") || strings.Contains(cmd, "cargo test") {
-		return "[🧪 TEST]"
-	}
-
-	// Run/start steps
-	if strings.Contains(id, "run") || strings.Contains(id, "start") || strings.Contains(id, "serve") ||
-		strings.Contains(cmd, "npm start") || strings.Contains(cmd, "npm run dev") ||
-		strings.Contains(cmd, "docker compose up") || strings.Contains(cmd, "docker-compose up") ||
-		strings.Contains(cmd, "docker run") || strings.Contains(cmd, "./") {
-		return "[🚀 RUN]"
-	}
-
-	// Setup/config steps
-	if strings.Contains(id, "setup") || strings.Contains(id, "config") || strings.Contains(id, "init") ||
-		strings.Contains(id, "venv") || strings.Contains(cmd, "venv") {
-		return "[⚙️ SETUP]"
-	}
-
-	return ""
+	return stepTypeMarkers[llm.ClassifyStep(step)]
 }
 
 // isSensitiveKey checks if an env var key might contain sensitive data
@@ -798,14 +1495,19 @@ func isSensitiveKey(key string) bool {
 
 // tryAutoRecoverStep attempts deterministic recovery for known command failures.
 // Returns (result, true) when a recovery attempt was made.
-func (r *Runner) tryAutoRecoverStep(ctx context.Context, step *llm.Step, failed *StepResult, mergedEnv []string) (*StepResult, bool) {
+func (r *Runner) tryAutoRecoverStep(ctx context.Context, step *llm.Step, failed *StepResult, mergedEnv []string, result *ExecutionResult) (*StepResult, bool) {
+	reason := "Next.js production start requires a build"
 	buildCmd, ok := inferRecoveryBuildCommand(step, failed)
 	if !ok {
-		return failed, false
+		var dir string
+		if dir, buildCmd, ok = inferStaticBuildCommand(r.config.WorkingDir, step); !ok {
+			return failed, false
+		}
+		reason = fmt.Sprintf("%s/ is served before it is built", dir)
 	}
 
-	fmt.Printf("    ↻ Auto-recovery: Next.js production start requires a build\n")
-	fmt.Printf("    ↻ Running: %s\n", buildCmd)
+	fmt.Fprintf(r.output(), "    ↻ Auto-recovery: %s\n", reason)
+	fmt.Fprintf(r.output(), "    ↻ Running: %s\n", buildCmd)
 
 	buildStep := &llm.Step{
 		ID:   step.ID + "_autobuild",
@@ -816,7 +1518,7 @@ func (r *Runner) tryAutoRecoverStep(ctx context.Context, step *llm.Step, failed
 
 	buildResult := r.executeStepWithContext(ctx, buildStep, mergedEnv)
 	if !buildResult.Success {
-		fmt.Printf("    ↻ Auto-recovery failed: %s\n", buildResult.Error)
+		fmt.Fprintf(r.output(), "    ↻ Auto-recovery failed: %s\n", buildResult.Error)
 		combined := *failed
 		combined.Stderr = strings.TrimSpace(strings.Join([]string{
 			failed.Stderr,
@@ -826,10 +1528,13 @@ func (r *Runner) tryAutoRecoverStep(ctx context.Context, step *llm.Step, failed
 		return &combined, true
 	}
 
-	fmt.Printf("    ↻ Build succeeded, retrying original step\n")
+	if !r.takeRetry(result) {
+		return failed, true
+	}
+	fmt.Fprintf(r.output(), "    ↻ Build succeeded, retrying original step\n")
 	retried := r.executeStepWithContext(ctx, step, mergedEnv)
 	if retried.Success {
-		fmt.Printf("    ↻ Auto-recovery succeeded\n")
+		fmt.Fprintf(r.output(), "    ↻ Auto-recovery succeeded\n")
 	}
 
 	return retried, true
@@ -0,0 +1,170 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Linux sandbox wrapping with bubblewrap / firejail
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// Supported sandbox tools, in order of preference
+const (
+	SandboxBwrap    = "bwrap"
+	SandboxFirejail = "firejail"
+)
+
+// SandboxConfig configures filesystem/network confinement of host commands
+type SandboxConfig struct {
+	Enabled   bool   // Wrap non-sudo steps in a sandbox
+	NoNetwork bool   // Disable network access inside the sandbox
+	Tool      string // Sandbox tool to use (empty = auto-detect)
+}
+
+// DetectSandboxTool returns the sandbox tool to use, or "" if none is available.
+// Sandboxing is only supported on Linux.
+func DetectSandboxTool(preferred string) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	candidates := []string{SandboxBwrap, SandboxFirejail}
+	if preferred != "" {
+		candidates = []string{preferred}
+	}
+
+	for _, tool := range candidates {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return ""
+}
+
+// SandboxArgs returns the wrapper arguments placed before `sh -c <cmd>`.
+// Writes are confined to workspaceDir (and a private /tmp); everything
+// else is mounted read-only.
+func SandboxArgs(tool, workspaceDir, stepDir string, noNetwork bool) []string {
+	switch tool {
+	case SandboxBwrap:
+		args := []string{
+			"bwrap",
+			"--ro-bind", "/", "/",
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+		}
+		// Hide the user's home directory; the workspace is re-bound below
+		// in case it lives under $HOME
+		if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+			args = append(args, "--tmpfs", home)
+		}
+		args = append(args,
+			"--bind", workspaceDir, workspaceDir,
+			"--chdir", stepDir,
+			"--die-with-parent",
+		)
+		if noNetwork {
+			args = append(args, "--unshare-net")
+		}
+		return args
+	case SandboxFirejail:
+		args := []string{
+			"firejail", "--quiet", "--noprofile",
+			"--read-only=/",
+			"--read-write=" + workspaceDir,
+			"--private-tmp",
+		}
+		// Hide the user's home directory: a workspace under $HOME is
+		// whitelisted, which leaves the rest of it empty; otherwise home is
+		// replaced by an empty private one
+		if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+			if rel, err := filepath.Rel(home, workspaceDir); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+				args = append(args, "--whitelist="+workspaceDir)
+			} else {
+				args = append(args, "--private")
+			}
+		}
+		if noNetwork {
+			args = append(args, "--net=none")
+		}
+		return append(args, "--")
+	}
+	return nil
+}
+
+// DescribeSandbox returns a one-line description of the sandbox in use
+func DescribeSandbox(sandbox *SandboxConfig) string {
+	if sandbox == nil || !sandbox.Enabled {
+		return ""
+	}
+
+	tool := DetectSandboxTool(sandbox.Tool)
+	if tool == "" {
+		return "requested but no sandbox tool found (bwrap/firejail); commands run unconfined"
+	}
+
+	desc := fmt.Sprintf("%s (writes confined to workspace", tool)
+	if sandbox.NoNetwork {
+		desc += ", network disabled"
+	}
+	return desc + ")"
+}
+
+// setupSandbox switches the runner to sandboxed host commands when possible
+func (r *Runner) setupSandbox() {
+	sandbox := r.config.Sandbox
+	if sandbox == nil || !sandbox.Enabled || r.config.Isolation != IsolationNone {
+		return
+	}
+
+	tool := DetectSandboxTool(sandbox.Tool)
+	if tool == "" {
+		fmt.Fprintf(os.Stderr, "Warning: sandbox requested but neither bwrap nor firejail is available; running commands unconfined\n")
+		return
+	}
+
+	r.sandboxTool = tool
+	r.buildCommand = r.sandboxCommand
+}
+
+// sandboxCommand wraps non-sudo steps with the detected sandbox tool
+func (r *Runner) sandboxCommand(step *llm.Step, workDir string) *exec.Cmd {
+	// sudo cannot gain privileges inside bwrap/firejail (no_new_privs)
+	if step.RequiresSudo {
+		return r.hostCommand(step, workDir)
+	}
+
+	workspaceDir, err := filepath.Abs(r.config.WorkingDir)
+	if err != nil {
+		workspaceDir = r.config.WorkingDir
+	}
+
+	args := SandboxArgs(r.sandboxTool, workspaceDir, workDir, r.config.Sandbox.NoNetwork)
+	args = append(args, r.config.Shell.Args(step.Cmd)...)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = workDir
+	return cmd
+}
+
+// sandboxPreview returns the wrapped command line for DryRunDisplay
+func sandboxPreview(tool, workDir string, sandbox *SandboxConfig, shell Shell, step *llm.Step) string {
+	stepDir := workDir
+	if step.Cwd != "" && step.Cwd != "." {
+		stepDir = filepath.Join(workDir, step.Cwd)
+	}
+	args := append(SandboxArgs(tool, workDir, stepDir, sandbox.NoNetwork), shell.Args("")...)
+	return strings.Join(args[:len(args)-1], " ") + " " + shellQuote(step.Cmd)
+}
+
+// shellQuote single-quotes a string for display
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
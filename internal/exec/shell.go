@@ -0,0 +1,72 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Shell selection for host commands
+
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Shell is the interpreter host commands run with
+type Shell string
+
+const (
+	// ShellAuto uses cmd on Windows and sh elsewhere
+	ShellAuto Shell = ""
+	ShellBash Shell = "bash"
+	ShellSh   Shell = "sh"
+	ShellPwsh Shell = "pwsh"
+	ShellCmd  Shell = "cmd"
+)
+
+// ParseShell converts a CLI value into a Shell
+func ParseShell(value string) (Shell, error) {
+	switch shell := Shell(strings.ToLower(strings.TrimSpace(value))); shell {
+	case ShellAuto, ShellBash, ShellSh, ShellPwsh, ShellCmd:
+		return shell, nil
+	case "auto":
+		return ShellAuto, nil
+	default:
+		return ShellAuto, fmt.Errorf("unknown shell %q (supported: bash, sh, pwsh, cmd)", value)
+	}
+}
+
+// DefaultShell returns the shell used when none is configured
+func DefaultShell() Shell {
+	if runtime.GOOS == "windows" {
+		return ShellCmd
+	}
+	return ShellSh
+}
+
+// Resolve returns the shell itself, or the platform default for ShellAuto
+func (s Shell) Resolve() Shell {
+	if s == ShellAuto {
+		return DefaultShell()
+	}
+	return s
+}
+
+// Args returns the argv that runs command with the shell
+func (s Shell) Args(command string) []string {
+	switch shell := s.Resolve(); shell {
+	case ShellCmd:
+		return []string{"cmd", "/C", command}
+	case ShellPwsh:
+		return []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", command}
+	default:
+		return []string{string(shell), "-c", command}
+	}
+}
+
+// CheckShell verifies the shell is installed
+func CheckShell(s Shell) error {
+	shell := s.Resolve()
+	if _, err := exec.LookPath(string(shell)); err != nil {
+		return fmt.Errorf("shell %s is not installed or not in PATH", shell)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Persisted execution state for resuming a failed run
+
+package exec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// ResumeSkipReason is the skip reason for steps completed in a previous run
+const ResumeSkipReason = "Completed in previous run"
+
+// ExecutionState records which plan steps completed in a run
+type ExecutionState struct {
+	RunID     string          `json:"run_id"`
+	PlanHash  string          `json:"plan_hash"`
+	Completed []CompletedStep `json:"completed"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CompletedStep identifies a finished step. Hash covers the command, cwd
+// and plan env so an edited step is re-run on resume.
+type CompletedStep struct {
+	ID          string    `json:"id"`
+	Hash        string    `json:"hash"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// NewExecutionState creates an empty state for a plan
+func NewExecutionState(runID string, plan *llm.RunPlan) *ExecutionState {
+	return &ExecutionState{
+		RunID:     runID,
+		PlanHash:  PlanHash(plan),
+		Completed: []CompletedStep{},
+	}
+}
+
+// LoadExecutionState reads a state file. Returns nil, nil if it does not exist.
+func LoadExecutionState(path string) (*ExecutionState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read execution state: %w", err)
+	}
+
+	var state ExecutionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid execution state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file atomically
+func (s *ExecutionState) Save(path string) error {
+	s.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode execution state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write execution state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// MarkCompleted records a successful step (idempotent)
+func (s *ExecutionState) MarkCompleted(plan *llm.RunPlan, step *llm.Step) {
+	s.MarkCompletedAt(plan, step, time.Now())
+}
+
+// MarkCompletedAt records a step that completed at a given time, such as a
+// step skipped because an earlier run completed it
+func (s *ExecutionState) MarkCompletedAt(plan *llm.RunPlan, step *llm.Step, at time.Time) {
+	hash := StepHash(plan, step)
+	for i := range s.Completed {
+		if s.Completed[i].ID == step.ID {
+			s.Completed[i].Hash = hash
+			return
+		}
+	}
+	s.Completed = append(s.Completed, CompletedStep{
+		ID:          step.ID,
+		Hash:        hash,
+		CompletedAt: at,
+	})
+}
+
+// CompletedAt returns when a step completed in the recorded run, if it did
+// and is unchanged in plan
+func (s *ExecutionState) CompletedAt(plan *llm.RunPlan, step *llm.Step) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	hash := StepHash(plan, step)
+	for _, c := range s.Completed {
+		if c.ID == step.ID && c.Hash == hash {
+			return c.CompletedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Record marks every step that succeeded in an execution result
+func (s *ExecutionState) Record(plan *llm.RunPlan, result *ExecutionResult) {
+	for _, stepResult := range result.StepResults {
+		if !stepResult.Success || stepResult.Skipped {
+			continue
+		}
+		for i := range plan.Steps {
+			if plan.Steps[i].ID == stepResult.StepID {
+				s.MarkCompleted(plan, &plan.Steps[i])
+				break
+			}
+		}
+	}
+}
+
+// ResumableSteps returns the IDs of the leading steps that completed in a
+// previous run and are unchanged in plan. Execution resumes at the first
+// step not in the set. planChanged reports whether the plan differs from
+// the one the state was recorded against.
+func (s *ExecutionState) ResumableSteps(plan *llm.RunPlan) (skip map[string]bool, planChanged bool) {
+	skip = make(map[string]bool)
+	if s == nil {
+		return skip, false
+	}
+
+	done := make(map[string]string, len(s.Completed))
+	for _, c := range s.Completed {
+		done[c.ID] = c.Hash
+	}
+
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		hash, ok := done[step.ID]
+		if !ok || hash != StepHash(plan, step) {
+			break
+		}
+		skip[step.ID] = true
+	}
+
+	return skip, s.PlanHash != PlanHash(plan)
+}
+
+// PlanHash returns a stable fingerprint of the whole plan
+func PlanHash(plan *llm.RunPlan) string {
+	data, _ := json.Marshal(plan)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// StepHash returns a fingerprint of what a step runs: ID, command, cwd,
+// sudo flag and the plan-level environment
+func StepHash(plan *llm.RunPlan, step *llm.Step) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%v\x00", step.ID, step.Cmd, step.Cwd, step.RequiresSudo)
+
+	keys := make([]string, 0, len(plan.Env))
+	for k := range plan.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\x00", k, plan.Env[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,120 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Auto-recovery for static sites served before they are built
+
+package exec
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// staticServers are the commands that serve a directory of built files
+var staticServers = []string{"serve", "http-server", "live-server", "sirv"}
+
+// staticBuildDirs are the output directories of static-site builds
+var staticBuildDirs = []string{"dist", "build"}
+
+// staticBuildScripts are the package.json scripts tried, in order, to
+// produce the served directory
+var staticBuildScripts = []string{"build", "generate"}
+
+// inferStaticBuildCommand returns the package.json build script that
+// produces the directory a failed serve step points at ("serve dist",
+// "npx http-server build"), when that directory does not exist
+func inferStaticBuildCommand(workDir string, step *llm.Step) (dir string, buildCmd string, ok bool) {
+	if step == nil {
+		return "", "", false
+	}
+	dir = servedBuildDir(step.Cmd)
+	if dir == "" {
+		return "", "", false
+	}
+
+	stepDir, err := resolveStepDir(workDir, step)
+	if err != nil {
+		return "", "", false
+	}
+	if _, err := os.Stat(filepath.Join(stepDir, dir)); !os.IsNotExist(err) {
+		return "", "", false
+	}
+
+	script := packageBuildScript(stepDir)
+	if script == "" {
+		return "", "", false
+	}
+	return dir, packageManagerRun(stepDir, script), true
+}
+
+// servedBuildDir returns the build directory a static server is pointed
+// at in cmd, or "" if cmd does not serve one
+func servedBuildDir(cmd string) string {
+	fields := strings.Fields(cmd)
+	for i, field := range fields {
+		if !containsString(staticServers, filepath.Base(field)) {
+			continue
+		}
+		for _, arg := range fields[i+1:] {
+			if arg == "&&" || arg == "||" || arg == ";" || arg == "|" {
+				break
+			}
+			arg = strings.TrimSuffix(strings.TrimPrefix(arg, "./"), "/")
+			if containsString(staticBuildDirs, arg) {
+				return arg
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// packageBuildScript returns the first of staticBuildScripts defined in
+// the package.json of dir, or ""
+func packageBuildScript(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	for _, name := range staticBuildScripts {
+		if _, ok := pkg.Scripts[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// packageManagerRun returns the command running script with the package
+// manager whose lockfile is in dir (npm without one)
+func packageManagerRun(dir, script string) string {
+	lockfiles := []struct{ name, run string }{
+		{"pnpm-lock.yaml", "pnpm run "},
+		{"yarn.lock", "yarn "},
+		{"bun.lockb", "bun run "},
+		{"bun.lock", "bun run "},
+	}
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(filepath.Join(dir, lockfile.name)); err == nil {
+			return lockfile.run + script
+		}
+	}
+	return "npm run " + script
+}
+
+// containsString checks if a slice contains a specific string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
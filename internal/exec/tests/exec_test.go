@@ -4,12 +4,23 @@
 package tests
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	osexec "os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sony-level/readme-runner/internal/exec"
 	"github.com/sony-level/readme-runner/internal/llm"
@@ -201,6 +212,18 @@ func TestGetStepTimeout(t *testing.T) {
 			defaultTimeout: 5 * time.Minute,
 			expected:       exec.MaxStepTimeout,
 		},
+		{
+			name:           "step timeout overrides configured default",
+			step:           &llm.Step{Timeout: 60},
+			defaultTimeout: 20 * time.Minute,
+			expected:       time.Minute,
+		},
+		{
+			name:           "cap configured default at max",
+			step:           &llm.Step{},
+			defaultTimeout: 2 * time.Hour,
+			expected:       exec.MaxStepTimeout,
+		},
 		{
 			name:           "fallback to default constant",
 			step:           &llm.Step{},
@@ -247,6 +270,38 @@ func TestDryRunDisplay(t *testing.T) {
 	}
 }
 
+func TestFormatStepList(t *testing.T) {
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "deps", Cmd: "sudo apt-get install -y build-essential", Cwd: ".", Risk: llm.RiskHigh, RequiresSudo: true},
+			{ID: "install", Cmd: "npm ci", Cwd: "web", Risk: llm.RiskMedium},
+			{ID: "run", Cmd: "npm start " + strings.Repeat("--flag ", 20), Cwd: ".", Risk: llm.RiskLow},
+			{ID: "greet", Cmd: "echo " + strings.Repeat("é", 80), Cwd: ".", Risk: llm.RiskLow},
+		},
+	}
+
+	output := exec.FormatStepList(plan, map[string]bool{"deps": true})
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 steps, got:\n%s", output)
+	}
+	if !strings.Contains(lines[1], "1  deps") || !strings.Contains(lines[1], "high") || !strings.Contains(lines[1], "yes") || !strings.Contains(lines[1], "[done]") {
+		t.Errorf("unexpected sudo step line %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "npm ci (in web)") {
+		t.Errorf("unexpected cwd step line %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "...") || len(lines[3]) > 120 {
+		t.Errorf("long command not shortened: %q", lines[3])
+	}
+	if !utf8.ValidString(lines[4]) || !strings.Contains(lines[4], "é...") {
+		t.Errorf("multi-byte command not shortened by rune: %q", lines[4])
+	}
+}
+
 func TestFormatStepResult(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -402,6 +457,10 @@ func TestGlobalTimeout(t *testing.T) {
 	if !result.TimeoutReached {
 		t.Error("TimeoutReached should be true")
 	}
+
+	if result.AbortedByUser {
+		t.Error("A global timeout should not be reported as a user abort")
+	}
 }
 
 func TestMergedEnvironment(t *testing.T) {
@@ -786,6 +845,65 @@ func TestFailureRetryBehavior(t *testing.T) {
 	}
 }
 
+// TestRetryBudget tests that MaxRetriesTotal stops a retry loop
+func TestRetryBudget(t *testing.T) {
+	var output bytes.Buffer
+	config := &exec.RunnerConfig{
+		Mode:            exec.ModeExecute,
+		WorkingDir:      "/tmp",
+		StepTimeout:     10 * time.Second,
+		MaxRetriesTotal: 2,
+		Output:          &output,
+	}
+
+	runner := exec.NewRunner(config)
+
+	// Always retry: only the budget ends the loop
+	prompts := 0
+	runner.SetFailurePrompt(func(step *llm.Step, result *exec.StepResult) exec.FailureChoice {
+		prompts++
+		if prompts > 10 {
+			return exec.FailureChoiceAbort
+		}
+		return exec.FailureChoiceRetry
+	})
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "will-fail", Cmd: "exit 1", Cwd: "."},
+			{ID: "never-runs", Cmd: "echo hi", Cwd: "."},
+		},
+	}
+
+	result := runner.Execute(plan)
+
+	if prompts != 3 {
+		t.Errorf("Expected 3 prompts (2 retries, then the refused one), got %d", prompts)
+	}
+	if result.Retries != 2 {
+		t.Errorf("Retries = %d, want 2", result.Retries)
+	}
+	if !result.RetryBudgetExceeded {
+		t.Error("Expected RetryBudgetExceeded")
+	}
+	if result.AbortedByUser {
+		t.Error("Running out of retries is not a user abort")
+	}
+	if result.Success {
+		t.Error("Expected the run to fail")
+	}
+	if len(result.StepResults) != 1 {
+		t.Errorf("Expected the run to stop at the failing step, got %d results", len(result.StepResults))
+	}
+
+	report := exec.FormatExecutionResult(result)
+	if !strings.Contains(report, "retry budget exhausted after 2 retries") {
+		t.Errorf("Expected the abort cause in the report, got:\n%s", report)
+	}
+}
+
 // TestFailureContinueBehavior tests continue on failure
 func TestFailureContinueBehavior(t *testing.T) {
 	config := &exec.RunnerConfig{
@@ -938,6 +1056,79 @@ exit 1
 	}
 }
 
+func TestAutoRecoveryStaticServeMissingBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+
+	scripts := map[string]string{
+		// serve fails like http-server when the directory is absent
+		"serve": `#!/bin/sh
+if [ ! -d "$2" ]; then
+  echo "Error: directory $2 not found" >&2
+  exit 1
+fi
+echo "serving $2"
+`,
+		"npm": `#!/bin/sh
+if [ "$1 $2" = "run build" ]; then
+  mkdir -p dist
+  touch build-ran
+  exit 0
+fi
+echo "unexpected args: $@" >&2
+exit 1
+`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0o755); err != nil {
+			t.Fatalf("failed to write fake %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"build": "vite build"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newRunner := func() *exec.Runner {
+		return exec.NewRunner(&exec.RunnerConfig{
+			Mode:        exec.ModeExecute,
+			WorkingDir:  tempDir,
+			StepTimeout: 10 * time.Second,
+			AutoYes:     true,
+			Environment: map[string]string{
+				"PATH": binDir + ":" + os.Getenv("PATH"),
+			},
+		})
+	}
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "serve", Cmd: "serve -s ./dist", Cwd: "."},
+		},
+	}
+
+	result := newRunner().Execute(plan)
+	if !result.Success {
+		t.Fatalf("expected auto-recovery to succeed, got failure: %+v", result.FailedStep)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "build-ran")); err != nil {
+		t.Fatalf("expected the build script to run, got: %v", err)
+	}
+
+	// Only dist/ and build/ are build outputs
+	if err := os.Remove(filepath.Join(tempDir, "build-ran")); err != nil {
+		t.Fatal(err)
+	}
+	plan.Steps[0].Cmd = "serve -s public"
+	result = newRunner().Execute(plan)
+	if result.Success {
+		t.Fatal("expected serving a directory that is not a build output to fail")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "build-ran")); err == nil {
+		t.Error("build script should only run for a missing dist/build directory")
+	}
+}
+
 // TestStepTimeoutEnforcement verifies per-step timeout is enforced.
 // Process group handling ensures all child processes are killed on timeout.
 func TestStepTimeoutEnforcement(t *testing.T) {
@@ -1097,6 +1288,10 @@ func TestCancellationDuringExecution(t *testing.T) {
 		t.Error("Should be marked as aborted by user")
 	}
 
+	if result.TimeoutReached {
+		t.Error("User cancellation should not be reported as a timeout")
+	}
+
 	if result.Success {
 		t.Error("Cancelled execution should not succeed")
 	}
@@ -1212,6 +1407,95 @@ sleep 30
 	}
 }
 
+func TestRunStepAutoStopsOnPhoenixReady(t *testing.T) {
+	tempDir := t.TempDir()
+	mixPath := filepath.Join(tempDir, "mix")
+
+	// Simulate `mix phx.server`: prints the endpoint line then blocks.
+	script := `#!/bin/sh
+echo "[info] Running DemoWeb.Endpoint with cowboy 2.10.0 at http://localhost:4000 (http)"
+sleep 30
+`
+
+	if err := os.WriteFile(mixPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake mix: %v", err)
+	}
+
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		StepTimeout: 1 * time.Second, // Would fail without auto-stop-on-ready
+		AutoYes:     true,
+		Environment: map[string]string{
+			"PATH": tempDir + ":" + os.Getenv("PATH"),
+		},
+	}
+
+	runner := exec.NewRunner(config)
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "elixir",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "mix phx.server", Cwd: "."},
+		},
+	}
+
+	start := time.Now()
+	result := runner.Execute(plan)
+	elapsed := time.Since(start)
+
+	if !result.Success {
+		t.Fatalf("expected run step to succeed after readiness, got failure: %+v", result.FailedStep)
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected early stop after readiness, took %v", elapsed)
+	}
+}
+
+func TestRunStepAutoStopsOnServerReady(t *testing.T) {
+	tests := []struct {
+		tool string
+		cmd  string
+		line string
+	}{
+		{"deno", "deno task start", "Listening on http://0.0.0.0:8000/"},
+		{"bun", "bun run start", "🦊 Elysia is running at localhost:3000"},
+		{"bun", "bun run start", "Started server http://localhost:3000"},
+		{"bun", "bun run dev", "  ➜  Local:   http://localhost:5173/"},
+		{"rails", "rails server", "* Listening on http://127.0.0.1:3000"},
+		{"rails", "rails server", "* Listening on tcp://0.0.0.0:3000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			tempDir := t.TempDir()
+			script := "#!/bin/sh\necho \"" + tt.line + "\"\nsleep 30\n"
+			if err := os.WriteFile(filepath.Join(tempDir, tt.tool), []byte(script), 0o755); err != nil {
+				t.Fatalf("failed to write fake %s: %v", tt.tool, err)
+			}
+
+			runner := exec.NewRunner(&exec.RunnerConfig{
+				Mode:        exec.ModeExecute,
+				WorkingDir:  tempDir,
+				StepTimeout: 1 * time.Second, // Would fail without auto-stop-on-ready
+				AutoYes:     true,
+				Environment: map[string]string{
+					"PATH": tempDir + ":" + os.Getenv("PATH"),
+				},
+			})
+			result := runner.Execute(&llm.RunPlan{
+				Version:     "1",
+				ProjectType: "node",
+				Steps:       []llm.Step{{ID: "run", Cmd: tt.cmd, Cwd: "."}},
+			})
+
+			if !result.Success {
+				t.Fatalf("expected run step to succeed after readiness, got failure: %+v", result.FailedStep)
+			}
+		})
+	}
+}
+
 // TestAbortedByUserMarksFailure tests that abort sets success to false
 func TestAbortedByUserMarksFailure(t *testing.T) {
 	config := &exec.RunnerConfig{
@@ -1244,3 +1528,1204 @@ func TestAbortedByUserMarksFailure(t *testing.T) {
 		t.Error("Should be marked as aborted by user")
 	}
 }
+
+func TestParseIsolationMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected exec.IsolationMode
+		wantErr  bool
+	}{
+		{"", exec.IsolationNone, false},
+		{"none", exec.IsolationNone, false},
+		{"docker", exec.IsolationDocker, false},
+		{"Docker", exec.IsolationDocker, false},
+		{"vm", exec.IsolationNone, true},
+	}
+
+	for _, tt := range tests {
+		mode, err := exec.ParseIsolationMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIsolationMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if mode != tt.expected {
+			t.Errorf("ParseIsolationMode(%q) = %q, want %q", tt.input, mode, tt.expected)
+		}
+	}
+}
+
+func TestContainerImageFor(t *testing.T) {
+	if got := exec.ContainerImageFor("node"); got != "node:20" {
+		t.Errorf("Expected node:20, got %s", got)
+	}
+	if got := exec.ContainerImageFor("mixed"); got != exec.DefaultContainerImage {
+		t.Errorf("Expected default image, got %s", got)
+	}
+}
+
+func TestIsolationRejectsSudoSteps(t *testing.T) {
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		StepTimeout: 10 * time.Second,
+		AllowSudo:   true,
+		AutoYes:     true,
+		Isolation:   exec.IsolationDocker,
+	}
+
+	runner := exec.NewRunner(config)
+	runner.SetSudoPrompt(func(step *llm.Step) exec.SudoChoice {
+		t.Error("Sudo prompt should not be shown in isolated mode")
+		return exec.SudoChoiceAllow
+	})
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "sudo", Cmd: "sudo echo test", Cwd: ".", RequiresSudo: true},
+		},
+	}
+
+	result := runner.Execute(plan)
+
+	if result.Success {
+		t.Error("Sudo step should be rejected in isolated mode")
+	}
+	if result.FailedStep == nil || !strings.Contains(result.FailedStep.Error.Error(), "isolate") {
+		t.Errorf("Expected isolation rejection error, got %+v", result.FailedStep)
+	}
+}
+
+func TestSandboxArgs(t *testing.T) {
+	args := exec.SandboxArgs(exec.SandboxBwrap, "/work", "/work/web", true)
+	joined := strings.Join(args, " ")
+
+	if args[0] != "bwrap" {
+		t.Fatalf("Expected bwrap wrapper, got %v", args)
+	}
+	if !strings.Contains(joined, "--ro-bind / /") {
+		t.Error("Expected read-only root bind")
+	}
+	if !strings.Contains(joined, "--bind /work /work") {
+		t.Error("Expected writable workspace bind")
+	}
+	if !strings.Contains(joined, "--chdir /work/web") {
+		t.Error("Expected chdir to step directory")
+	}
+	if !strings.Contains(joined, "--unshare-net") {
+		t.Error("Expected network to be disabled")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if joined := strings.Join(exec.SandboxArgs(exec.SandboxBwrap, "/work", "/work", false), " "); !strings.Contains(joined, "--tmpfs "+home) {
+		t.Errorf("Expected bwrap to hide the home directory: %s", joined)
+	}
+
+	args = exec.SandboxArgs(exec.SandboxFirejail, "/work", "/work", false)
+	joined = strings.Join(args, " ")
+	if !strings.Contains(joined, "--read-write=/work") {
+		t.Error("Expected firejail to allow writes to the workspace")
+	}
+	if !slices.Contains(args, "--private") {
+		t.Errorf("Expected firejail to hide the home directory: %s", joined)
+	}
+	if strings.Contains(joined, "--net=none") {
+		t.Error("Network should not be disabled")
+	}
+
+	// A workspace under $HOME stays visible, the rest of home is hidden
+	workspace := filepath.Join(home, "rdr", "run")
+	args = exec.SandboxArgs(exec.SandboxFirejail, workspace, workspace, false)
+	if !slices.Contains(args, "--whitelist="+workspace) || slices.Contains(args, "--private") {
+		t.Errorf("Expected firejail to whitelist the workspace under home: %v", args)
+	}
+
+	if exec.SandboxArgs("unknown", "/work", "/work", false) != nil {
+		t.Error("Unknown tool should produce no wrapper")
+	}
+}
+
+func TestDryRunDisplayWithoutSandbox(t *testing.T) {
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm ci", Cwd: "."},
+		},
+	}
+
+	output := exec.DryRunDisplayWithSandbox(plan, "/tmp/test", nil)
+	if strings.Contains(output, "Sandbox") {
+		t.Error("Sandbox should not be shown when disabled")
+	}
+
+	output = exec.DryRunDisplayWithSandbox(plan, "/tmp/test", &exec.SandboxConfig{Enabled: true})
+	if !strings.Contains(output, "Sandbox:") {
+		t.Error("Expected sandbox status line when enabled")
+	}
+}
+
+func TestDryRunDisplayDetailed(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm ci", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: "web/app"},
+		},
+		Env: map[string]string{"NODE_ENV": "development", "API_TOKEN": "s3cret", "HOME": "/srv"},
+	}
+
+	output := exec.DryRunDisplayWithOptions(plan, "/tmp/ws/repo", &exec.DryRunOptions{
+		Environment: map[string]string{"NODE_ENV": "production", "CI": "1"},
+		Detailed:    true,
+	})
+
+	for _, want := range []string{
+		"Resolved cwd: /tmp/ws/repo\n",
+		"Resolved cwd: " + filepath.Join("/tmp/ws/repo", "web/app"),
+		"CI=1 (config)",
+		"NODE_ENV=development (plan, overrides config)",
+		"HOME=/srv (plan, overrides process env)",
+		"API_TOKEN=[REDACTED] (plan)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("detailed dry-run missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "s3cret") {
+		t.Error("sensitive value should be redacted")
+	}
+
+	// Without Detailed the summary stays as before
+	output = exec.DryRunDisplayWithOptions(plan, "/tmp/ws/repo", nil)
+	if strings.Contains(output, "Resolved cwd") || strings.Contains(output, "Environment overrides") {
+		t.Error("resolved cwd and overrides should only be shown in detailed mode")
+	}
+}
+
+func TestHealthCheckStopsRunStepWhenHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		StepTimeout: 10 * time.Second,
+		AutoYes:     true,
+	}
+
+	runner := exec.NewRunner(config)
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "sleep 30", Cwd: "."},
+		},
+		HealthCheck: &llm.HealthCheck{URL: server.URL, Timeout: 5},
+	}
+
+	start := time.Now()
+	result := runner.Execute(plan)
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("Run step should stop once the health check succeeds")
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got failure: %+v", result.FailedStep)
+	}
+	if result.HealthCheck == nil || !result.HealthCheck.Healthy {
+		t.Fatalf("Expected healthy result, got %+v", result.HealthCheck)
+	}
+	if !strings.Contains(exec.FormatExecutionResult(result), "Health check: ✓") {
+		t.Error("Execution summary should report the health check")
+	}
+}
+
+func TestHealthCheckUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	result := exec.PollHealthCheck(context.Background(), &llm.HealthCheck{URL: server.URL, Timeout: 1})
+
+	if result.Healthy {
+		t.Error("503 should not be healthy")
+	}
+	if result.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected last status 503, got %d", result.StatusCode)
+	}
+	if !strings.Contains(exec.FormatHealthCheckResult(result), "did not become healthy") {
+		t.Error("Expected failure summary")
+	}
+}
+
+// TestGlobalTimeoutKillsBlockingRunStep verifies an in-flight step that never
+// prints a readiness line is stopped by the global deadline, not the step timeout
+func TestGlobalTimeoutKillsBlockingRunStep(t *testing.T) {
+	config := &exec.RunnerConfig{
+		Mode:          exec.ModeExecute,
+		WorkingDir:    t.TempDir(),
+		StepTimeout:   30 * time.Second,
+		GlobalTimeout: 500 * time.Millisecond,
+		AutoYes:       true,
+	}
+
+	runner := exec.NewRunner(config)
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "sleep 30", Cwd: "."},
+		},
+	}
+
+	start := time.Now()
+	result := runner.Execute(plan)
+	elapsed := time.Since(start)
+
+	if elapsed > 3*time.Second {
+		t.Fatalf("Global timeout should kill the in-flight step, took %v", elapsed)
+	}
+	if !result.TimeoutReached {
+		t.Error("TimeoutReached should be true")
+	}
+	if result.AbortedByUser {
+		t.Error("AbortedByUser should be false on global timeout")
+	}
+	if result.Success {
+		t.Error("Execution should not succeed after global timeout")
+	}
+	if len(result.StepResults) != 1 || !result.StepResults[0].Cancelled {
+		t.Fatalf("Expected the run step to be cancelled, got %+v", result.StepResults)
+	}
+	if !strings.Contains(result.StepResults[0].Error.Error(), "global timeout") {
+		t.Errorf("Expected global timeout error, got %v", result.StepResults[0].Error)
+	}
+}
+
+// TestGlobalTimeoutWithDetachedChild verifies the abort completes even when a
+// detached grandchild keeps the output pipes open
+func TestGlobalTimeoutWithDetachedChild(t *testing.T) {
+	if _, err := osexec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not available")
+	}
+
+	config := &exec.RunnerConfig{
+		Mode:          exec.ModeExecute,
+		WorkingDir:    t.TempDir(),
+		StepTimeout:   30 * time.Second,
+		GlobalTimeout: 500 * time.Millisecond,
+		AutoYes:       true,
+	}
+
+	runner := exec.NewRunner(config)
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "setsid sleep 20", Cwd: "."},
+		},
+	}
+
+	start := time.Now()
+	result := runner.Execute(plan)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Abort should not wait for the detached child, took %v", elapsed)
+	}
+	if !result.TimeoutReached {
+		t.Error("TimeoutReached should be true")
+	}
+}
+
+// TestTimeoutReportNotAborted verifies the summary for a timeout does not
+// claim a user abort
+func TestTimeoutReportNotAborted(t *testing.T) {
+	result := exec.NewExecutionResult()
+	result.Success = false
+	result.TimeoutReached = true
+
+	output := exec.FormatExecutionResult(result)
+
+	if strings.Contains(output, "aborted by user") {
+		t.Error("Timeout summary should not mention a user abort")
+	}
+}
+
+func TestExecutionStateResumableSteps(t *testing.T) {
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm ci", Cwd: "."},
+			{ID: "build", Cmd: "npm run build", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: "."},
+		},
+	}
+
+	state := exec.NewExecutionState("rr-test", plan)
+	state.MarkCompleted(plan, &plan.Steps[0])
+	state.MarkCompleted(plan, &plan.Steps[1])
+
+	path := filepath.Join(t.TempDir(), "execution-state.json")
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := exec.LoadExecutionState(path)
+	if err != nil {
+		t.Fatalf("LoadExecutionState() error = %v", err)
+	}
+
+	skip, changed := loaded.ResumableSteps(plan)
+	if changed {
+		t.Error("Unchanged plan should not be reported as changed")
+	}
+	if !skip["install"] || !skip["build"] || skip["run"] {
+		t.Errorf("Skip set = %v, want install and build", skip)
+	}
+
+	// Editing a step re-runs it and everything after it
+	plan.Steps[1].Cmd = "npm run build:prod"
+	skip, changed = loaded.ResumableSteps(plan)
+	if !changed {
+		t.Error("Edited plan should be reported as changed")
+	}
+	if !skip["install"] || skip["build"] {
+		t.Errorf("Skip set after edit = %v, want only install", skip)
+	}
+
+	// A different plan env invalidates every step
+	plan.Env = map[string]string{"NODE_ENV": "production"}
+	if skip, _ = loaded.ResumableSteps(plan); len(skip) != 0 {
+		t.Errorf("Skip set after env change = %v, want empty", skip)
+	}
+}
+
+func TestLoadExecutionStateMissing(t *testing.T) {
+	state, err := exec.LoadExecutionState(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || state != nil {
+		t.Errorf("LoadExecutionState() = %v, %v; want nil, nil", state, err)
+	}
+}
+
+func TestRunnerSkipsCompletedSteps(t *testing.T) {
+	tmpDir := t.TempDir()
+	marker := filepath.Join(tmpDir, "first-ran")
+
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tmpDir,
+		AutoYes:     true,
+		StepTimeout: 10 * time.Second,
+		SkipSteps:   map[string]bool{"first": true},
+	}
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "test",
+		Steps: []llm.Step{
+			{ID: "first", Cmd: "touch " + marker, Cwd: "."},
+			{ID: "second", Cmd: "echo second", Cwd: "."},
+		},
+	}
+
+	result := exec.NewRunner(config).Execute(plan)
+
+	if !result.Success {
+		t.Fatalf("Expected success, got %+v", result)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Completed step should not run again")
+	}
+	if result.Skipped != 1 || result.Completed != 1 {
+		t.Errorf("Skipped=%d Completed=%d, want 1 and 1", result.Skipped, result.Completed)
+	}
+	if result.StepResults[0].SkipReason != exec.ResumeSkipReason {
+		t.Errorf("SkipReason = %q", result.StepResults[0].SkipReason)
+	}
+}
+
+func TestRunnerParallelIndependentSteps(t *testing.T) {
+	tempDir := t.TempDir()
+	var output bytes.Buffer
+
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		AutoYes:     true,
+		StepTimeout: 10 * time.Second,
+		MaxParallel: 2,
+		Output:      &output,
+	}
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "web/install", Cmd: "sleep 1 && touch web-installed", Cwd: "."},
+			{ID: "web/run", Cmd: "test -f web-installed && echo web ready", Cwd: ".", DependsOn: []string{"web/install"}},
+			{ID: "api/build", Cmd: "sleep 1 && echo api built", Cwd: "."},
+		},
+	}
+
+	start := time.Now()
+	result := exec.NewRunner(config).Execute(plan)
+	elapsed := time.Since(start)
+
+	if !result.Success || result.Completed != 3 {
+		t.Fatalf("expected 3 completed steps, got completed=%d failed=%d (%+v)", result.Completed, result.Failed, result.FailedStep)
+	}
+	// web/install and api/build sleep concurrently
+	if elapsed > 1900*time.Millisecond {
+		t.Errorf("independent steps did not run in parallel, took %v", elapsed)
+	}
+	for _, want := range []string{"[web/run] web ready", "[api/build] api built"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestRunnerParallelSkipsDependentsOfFailedStep(t *testing.T) {
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		AutoYes:     true,
+		StepTimeout: 10 * time.Second,
+		MaxParallel: 4,
+		Output:      io.Discard,
+	}
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "a/install", Cmd: "exit 1", Cwd: "."},
+			{ID: "a/run", Cmd: "echo unreachable", Cwd: ".", DependsOn: []string{"a/install"}},
+			{ID: "b/build", Cmd: "echo ok", Cwd: "."},
+		},
+	}
+
+	result := exec.NewRunner(config).Execute(plan)
+
+	if result.Success {
+		t.Fatal("expected failure")
+	}
+	if result.Failed != 1 || result.Completed != 1 || result.Skipped != 1 {
+		t.Errorf("unexpected counters: completed=%d failed=%d skipped=%d", result.Completed, result.Failed, result.Skipped)
+	}
+	for _, stepResult := range result.StepResults {
+		if stepResult.StepID == "a/run" && (!stepResult.Skipped || !strings.Contains(stepResult.SkipReason, "a/install")) {
+			t.Errorf("a/run should be skipped because a/install failed, got %+v", stepResult)
+		}
+	}
+	if result.FailedStep == nil || result.FailedStep.StepID != "a/install" {
+		t.Errorf("FailedStep = %+v, want a/install", result.FailedStep)
+	}
+}
+
+func TestRunnerParallelSudoPromptsDoNotInterleave(t *testing.T) {
+	config := &exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		AutoYes:     true,
+		StepTimeout: 10 * time.Second,
+		MaxParallel: 3,
+		Output:      io.Discard,
+	}
+
+	runner := exec.NewRunner(config)
+
+	var active, maxActive, prompts int32
+	runner.SetSudoPrompt(func(step *llm.Step) exec.SudoChoice {
+		n := atomic.AddInt32(&active, 1)
+		if n > atomic.LoadInt32(&maxActive) {
+			atomic.StoreInt32(&maxActive, n)
+		}
+		atomic.AddInt32(&prompts, 1)
+		time.Sleep(100 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return exec.SudoChoiceAllowAll
+	})
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "setup", Cmd: "true", Cwd: "."},
+			{ID: "one", Cmd: "true", Cwd: ".", RequiresSudo: true, DependsOn: []string{"setup"}},
+			{ID: "two", Cmd: "true", Cwd: ".", RequiresSudo: true, DependsOn: []string{"setup"}},
+			{ID: "three", Cmd: "true", Cwd: ".", RequiresSudo: true, DependsOn: []string{"setup"}},
+		},
+	}
+
+	result := runner.Execute(plan)
+
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result.FailedStep)
+	}
+	if maxActive != 1 {
+		t.Errorf("sudo prompts overlapped: %d at once", maxActive)
+	}
+	if prompts != 1 {
+		t.Errorf("expected one prompt with AllowAll, got %d", prompts)
+	}
+}
+
+func TestRunnerRejectsCwdOutsideWorkingDir(t *testing.T) {
+	root := t.TempDir()
+	workDir := filepath.Join(root, "repo")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{filepath.Join(workDir, "sub"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(workDir, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		cwd string
+		ok  bool
+	}{
+		{"sub", true},
+		{"sub/..", true},
+		{"..", false},
+		{"sub/../../outside", false},
+		{outside, false},
+		{"link", false},
+	}
+
+	for _, tt := range tests {
+		runner := exec.NewRunner(&exec.RunnerConfig{
+			Mode:        exec.ModeExecute,
+			WorkingDir:  workDir,
+			StepTimeout: 10 * time.Second,
+			AutoYes:     true,
+		})
+		result := runner.Execute(&llm.RunPlan{
+			Version:     "1",
+			ProjectType: "mixed",
+			Steps:       []llm.Step{{ID: "pwd", Cmd: "pwd", Cwd: tt.cwd}},
+		})
+
+		if result.Success != tt.ok {
+			t.Errorf("cwd %q: expected success=%v, got %v (stdout %q)", tt.cwd, tt.ok, result.Success, result.StepResults[0].Stdout)
+			continue
+		}
+		if !tt.ok {
+			stepErr := result.StepResults[0].Error
+			if stepErr == nil || !strings.Contains(stepErr.Error(), "outside the working directory") {
+				t.Errorf("cwd %q: unexpected error %v", tt.cwd, stepErr)
+			}
+		}
+	}
+}
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    exec.Shell
+		wantErr bool
+	}{
+		{"", exec.ShellAuto, false},
+		{"auto", exec.ShellAuto, false},
+		{"Bash", exec.ShellBash, false},
+		{"pwsh", exec.ShellPwsh, false},
+		{"cmd", exec.ShellCmd, false},
+		{"zsh", exec.ShellAuto, true},
+	}
+
+	for _, tt := range tests {
+		got, err := exec.ParseShell(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseShell(%q) = %q, %v; want %q (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if got := exec.ShellPwsh.Args("echo hi"); got[0] != "pwsh" || got[len(got)-1] != "echo hi" {
+		t.Errorf("unexpected pwsh args: %v", got)
+	}
+}
+
+func TestRunnerUsesConfiguredShell(t *testing.T) {
+	if err := exec.CheckShell(exec.ShellBash); err != nil {
+		t.Skip(err)
+	}
+
+	var out bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		StepTimeout: 10 * time.Second,
+		AutoYes:     true,
+		Shell:       exec.ShellBash,
+		Output:      &out,
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps:       []llm.Step{{ID: "bash", Cmd: "echo ${BASH_VERSION:+bash}", Cwd: "."}},
+	})
+
+	if !result.Success {
+		t.Fatalf("step failed: %v", result.StepResults[0].Error)
+	}
+	if strings.TrimSpace(result.StepResults[0].Stdout) != "bash" {
+		t.Errorf("expected the step to run under bash, got %q", result.StepResults[0].Stdout)
+	}
+}
+
+func TestRunnerCapsCapturedOutput(t *testing.T) {
+	var live bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:           exec.ModeExecute,
+		WorkingDir:     t.TempDir(),
+		StepTimeout:    10 * time.Second,
+		AutoYes:        true,
+		MaxOutputBytes: 1000,
+		Output:         &live,
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "chatty", Cmd: "seq 1 5000; seq 1 5000 >&2; exit 1", Cwd: "."},
+		},
+	})
+
+	stepResult := result.StepResults[0]
+	for name, captured := range map[string]string{"stdout": stepResult.Stdout, "stderr": stepResult.Stderr} {
+		if len(captured) > 1100 {
+			t.Errorf("%s: captured %d bytes, expected about 1000", name, len(captured))
+		}
+		if !strings.Contains(captured, "bytes truncated ...]") {
+			t.Errorf("%s: missing truncation marker", name)
+		}
+		if !strings.HasPrefix(captured, "1\n2\n") || !strings.HasSuffix(captured, "\n4999\n5000\n") {
+			t.Errorf("%s: expected head and tail to be kept, got %q...%q", name, captured[:10], captured[len(captured)-10:])
+		}
+	}
+
+	// Everything is still streamed live
+	if !strings.Contains(live.String(), "\n2500\n") {
+		t.Error("expected the full output to be streamed")
+	}
+
+	// The failure summary still shows the last stderr lines
+	if summary := exec.FormatExecutionResult(result); !strings.Contains(summary, "5000") {
+		t.Errorf("expected stderr tail in summary, got:\n%s", summary)
+	}
+}
+
+func TestRunnerExportsEnvToLaterSteps(t *testing.T) {
+	if err := exec.CheckShell(exec.ShellSh); err != nil {
+		t.Skip(err)
+	}
+
+	workDir := t.TempDir()
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  workDir,
+		StepTimeout: 10 * time.Second,
+		AutoYes:     true,
+		Output:      &bytes.Buffer{},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "export", Cmd: `echo "GREETING=hello" >> "$RDR_ENV"`, Cwd: ".", ExportEnv: map[string]string{"STATIC": "x"}},
+			{ID: "use", Cmd: `echo "$GREETING-$STATIC"`, Cwd: "."},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("plan failed: %+v", result.StepResults)
+	}
+	if got := strings.TrimSpace(result.StepResults[1].Stdout); got != "hello-x" {
+		t.Errorf("expected exported variables in the second step, got %q", got)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(workDir, ".rdr-env-*")); len(leftovers) > 0 {
+		t.Errorf("env files were not removed: %v", leftovers)
+	}
+}
+
+func TestRunnerShowEnvPrintsOverrides(t *testing.T) {
+	var out bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  t.TempDir(),
+		StepTimeout: 10 * time.Second,
+		ShowEnv:     true,
+		Output:      &out,
+	})
+
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Env:         map[string]string{"APP_MODE": "dev", "API_TOKEN": "hunter2"},
+		Steps:       []llm.Step{{ID: "env", Cmd: "true", Cwd: "."}},
+	})
+	if !result.Success {
+		t.Fatal("step should succeed")
+	}
+
+	got := out.String()
+	for _, want := range []string{"Env:", "APP_MODE=dev", "API_TOKEN=[REDACTED]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("output leaks a secret:\n%s", got)
+	}
+}
+
+func TestRunStepAutoStopsOnServerStepWithOtherID(t *testing.T) {
+	tempDir := t.TempDir()
+	script := "#!/bin/sh\necho \"Listening on http://localhost:8000\"\nsleep 30\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "app"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		StepTimeout: 1 * time.Second, // Would fail without auto-stop-on-ready
+		AutoYes:     true,
+		Environment: map[string]string{"PATH": tempDir + ":" + os.Getenv("PATH")},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps:       []llm.Step{{ID: "web", Cmd: "app serve", Cwd: "."}},
+	})
+
+	if !result.Success {
+		t.Fatalf("expected the serve step to succeed after readiness, got failure: %+v", result.FailedStep)
+	}
+}
+
+func TestRunnerDetachKeepsServerRunning(t *testing.T) {
+	tempDir := t.TempDir()
+	// The server touches a heartbeat file until it is stopped
+	script := "#!/bin/sh\necho \"Listening on http://localhost:8000\"\nn=0\nwhile true; do n=$((n+1)); echo $n > beat; sleep 0.05; done\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "app"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		StepTimeout: 5 * time.Second,
+		AutoYes:     true,
+		Detach:      true,
+		Output:      &out,
+		Environment: map[string]string{"PATH": tempDir + ":" + os.Getenv("PATH")},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "app serve", Cwd: "."},
+			// The server is still up while later steps run
+			{ID: "check", Cmd: "a=$(cat beat); sleep 0.3; test \"$(cat beat)\" != \"$a\"", Cwd: "."},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v\n%s", result.FailedStep, out.String())
+	}
+	if !result.StepResults[0].Detached {
+		t.Error("expected the run step to be detached")
+	}
+	if !strings.Contains(out.String(), "Stopping background step run") {
+		t.Errorf("expected the server to be stopped after the last step, got:\n%s", out.String())
+	}
+
+	before, _ := os.ReadFile(filepath.Join(tempDir, "beat"))
+	time.Sleep(300 * time.Millisecond)
+	after, _ := os.ReadFile(filepath.Join(tempDir, "beat"))
+	if string(before) != string(after) {
+		t.Error("server still running after Execute")
+	}
+}
+
+func TestRunnerLeaveRunningDetachedLogsToFile(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := t.TempDir()
+	// The server logs a line until it is stopped
+	script := "#!/bin/sh\necho \"Listening on http://localhost:8000\"\nn=0\nwhile true; do n=$((n+1)); echo \"request $n\"; sleep 0.05; done\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "app"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:         exec.ModeExecute,
+		WorkingDir:   tempDir,
+		StepTimeout:  5 * time.Second,
+		AutoYes:      true,
+		Detach:       true,
+		LeaveRunning: true,
+		LogDir:       logDir,
+		Output:       &out,
+		Environment:  map[string]string{"PATH": tempDir + ":" + os.Getenv("PATH")},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps:       []llm.Step{{ID: "run", Cmd: "app serve", Cwd: "."}},
+	})
+	for _, proc := range result.Processes {
+		defer osexec.Command("sh", "-c", proc.Stop).Run()
+	}
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v\n%s", result.FailedStep, out.String())
+	}
+	logFile := filepath.Join(logDir, "run.log")
+	if len(result.Processes) != 1 || result.Processes[0].LogFile != logFile {
+		t.Fatalf("Processes = %+v, want run with log file %s", result.Processes, logFile)
+	}
+	if !strings.Contains(out.String(), "left running, output in "+logFile) {
+		t.Errorf("expected the log file to be reported, got:\n%s", out.String())
+	}
+	if !strings.Contains(result.StepResults[0].Stdout, "Listening on") {
+		t.Errorf("Stdout = %q, want the readiness line read from the log", result.StepResults[0].Stdout)
+	}
+
+	// The server keeps logging after the runner is done with it
+	before, _ := os.ReadFile(logFile)
+	time.Sleep(300 * time.Millisecond)
+	after, _ := os.ReadFile(logFile)
+	if len(after) <= len(before) {
+		t.Error("server stopped logging once it was left running")
+	}
+}
+
+func TestRunnerStepDetach(t *testing.T) {
+	tempDir := t.TempDir()
+	script := "#!/bin/sh\necho \"Listening on http://localhost:8000\"\nn=0\nwhile true; do n=$((n+1)); echo $n > beat; sleep 0.05; done\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "app"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without --detach, a step with "detach" is still kept in the background,
+	// even when its command is not recognized as a server
+	var out bytes.Buffer
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		StepTimeout: 5 * time.Second,
+		AutoYes:     true,
+		Output:      &out,
+		Environment: map[string]string{"PATH": tempDir + ":" + os.Getenv("PATH")},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "backend", Cmd: "app", Cwd: ".", Detach: true},
+			{ID: "check", Cmd: "a=$(cat beat); sleep 0.3; test \"$(cat beat)\" != \"$a\"", Cwd: "."},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v\n%s", result.FailedStep, out.String())
+	}
+	if !result.StepResults[0].Detached {
+		t.Error("expected the backend step to be detached")
+	}
+}
+
+func TestRunnerListsComposeServices(t *testing.T) {
+	tempDir := t.TempDir()
+	// Fake docker: "compose up" succeeds, "compose ps" prints one JSON
+	// object per line like Compose v2
+	script := `#!/bin/sh
+case "$*" in
+*" ps --format json")
+  echo '{"Service":"web","State":"running","Health":"healthy","Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"},{"URL":"::","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"}]}'
+  echo '{"Service":"db","State":"running","Health":"","Publishers":[{"URL":"","TargetPort":5432,"PublishedPort":0,"Protocol":"tcp"}]}'
+  ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  tempDir,
+		AutoYes:     true,
+		Output:      io.Discard,
+		Environment: map[string]string{"PATH": tempDir + ":" + os.Getenv("PATH")},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "docker",
+		Steps:       []llm.Step{{ID: "run", Cmd: "docker compose -f compose.yml up -d --wait", Cwd: "."}},
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v", result.FailedStep)
+	}
+	if len(result.Services) != 2 || result.Services[0].Name != "db" || result.Services[1].Name != "web" {
+		t.Fatalf("Services = %+v, want db and web", result.Services)
+	}
+	if urls := result.Services[1].URLs; len(urls) != 1 || urls[0] != "http://localhost:8080" {
+		t.Errorf("web URLs = %v, want [http://localhost:8080]", urls)
+	}
+	if len(result.Services[0].URLs) != 0 {
+		t.Errorf("db URLs = %v, want none (port not published)", result.Services[0].URLs)
+	}
+
+	summary := exec.FormatExecutionResult(result)
+	down := "docker compose --project-directory " + tempDir + " -f " + filepath.Join(tempDir, "compose.yml") + " down"
+	for _, want := range []string{"web (running, healthy)", "→ http://localhost:8080", down} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+// freePort returns a localhost port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestRunnerInspectsPortsAndProcesses(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Already listening before the run
+	before, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer before.Close()
+	beforePort := before.Addr().(*net.TCPAddr).Port
+
+	// Opened by the "run" step, and never opened
+	startedPort := freePort(t)
+	unusedPort := freePort(t)
+	var started net.Listener
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:         exec.ModeExecute,
+		WorkingDir:   tempDir,
+		AutoYes:      true,
+		LeaveRunning: true,
+		Output:       io.Discard,
+		OnStepStart: func(step *llm.Step) {
+			if step.ID == "run" {
+				started, _ = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", startedPort))
+			}
+		},
+	})
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "build", Cmd: "echo built", Cwd: "."},
+			{ID: "run", Cmd: "sleep 30 >/dev/null 2>&1 &", Cwd: "."},
+		},
+		Ports: []int{startedPort, unusedPort, beforePort},
+	})
+	if started != nil {
+		defer started.Close()
+	}
+	for _, proc := range result.Processes {
+		defer osexec.Command("sh", "-c", proc.Stop).Run()
+	}
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v", result.FailedStep)
+	}
+
+	want := []exec.PortStatus{
+		{Port: startedPort, Listening: true},
+		{Port: unusedPort},
+		{Port: beforePort, Listening: true, InUseBefore: true},
+	}
+	if len(result.PortStatus) != len(want) {
+		t.Fatalf("PortStatus = %+v, want %+v", result.PortStatus, want)
+	}
+	for i := range want {
+		if result.PortStatus[i] != want[i] {
+			t.Errorf("PortStatus[%d] = %+v, want %+v", i, result.PortStatus[i], want[i])
+		}
+	}
+
+	// Only the backgrounded sleep outlives its step
+	if len(result.Processes) != 1 || result.Processes[0].StepID != "run" || result.Processes[0].PID <= 0 {
+		t.Fatalf("Processes = %+v, want the run step's sleep", result.Processes)
+	}
+
+	summary := exec.FormatExecutionResult(result)
+	for _, want := range []string{
+		fmt.Sprintf("http://localhost:%d (listening)", startedPort),
+		fmt.Sprintf("http://localhost:%d (not listening)", unusedPort),
+		"already in use before the run",
+		"Still running:",
+		fmt.Sprintf("run: PID %d (stop with: %s)", result.Processes[0].PID, result.Processes[0].Stop),
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "may be available at") {
+		t.Errorf("summary still speculates about ports:\n%s", summary)
+	}
+}
+
+func TestRunnerStopsLeftoverProcesses(t *testing.T) {
+	tempDir := t.TempDir()
+	var out bytes.Buffer
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:       exec.ModeExecute,
+		WorkingDir: tempDir,
+		AutoYes:    true,
+		Output:     &out,
+	})
+	// $$ is the step shell, which leads the step's process group
+	result := runner.Execute(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps:       []llm.Step{{ID: "run", Cmd: "echo $$ > pgid; sleep 30 >/dev/null 2>&1 &", Cwd: "."}},
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %+v", result.FailedStep)
+	}
+	if len(result.Processes) != 0 {
+		t.Errorf("Processes = %+v, want none without LeaveRunning", result.Processes)
+	}
+	if !strings.Contains(out.String(), "Stopping processes left by step run") {
+		t.Errorf("output missing the stop message:\n%s", out.String())
+	}
+
+	pgid, err := os.ReadFile(filepath.Join(tempDir, "pgid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := "kill -0 -- -" + strings.TrimSpace(string(pgid))
+	if err := osexec.Command("sh", "-c", probe).Run(); err == nil {
+		osexec.Command("sh", "-c", "kill -9 -- -"+strings.TrimSpace(string(pgid))).Run()
+		t.Error("the backgrounded sleep is still running")
+	}
+}
+
+func TestColorRisk(t *testing.T) {
+	runPlan := &llm.RunPlan{Version: "1", ProjectType: "go", Steps: []llm.Step{
+		{ID: "build", Cmd: "go build", Cwd: ".", Risk: llm.RiskLow},
+		{ID: "wipe", Cmd: "rm -rf build", Cwd: ".", Risk: llm.RiskCritical},
+	}}
+	plainList := exec.FormatStepList(runPlan, nil)
+
+	if got := exec.ColorRisk(llm.RiskHigh, "high"); got != "high" {
+		t.Errorf("ColorRisk() with colors off = %q, want plain", got)
+	}
+
+	exec.SetColor(true)
+	t.Cleanup(func() { exec.SetColor(false) })
+
+	if got, want := exec.ColorRisk(llm.RiskCritical, "critical"), "\033[1;91mcritical\033[0m"; got != want {
+		t.Errorf("ColorRisk(critical) = %q, want %q", got, want)
+	}
+	if got := exec.ColorRisk("", "none"); got != "none" {
+		t.Errorf("ColorRisk(unknown) = %q, want plain", got)
+	}
+	if got, want := exec.FormatRiskSummary(1, 0, 0, 1), "\033[32mLow=1\033[0m, Medium=0, High=0, \033[1;91mCritical=1\033[0m"; got != want {
+		t.Errorf("FormatRiskSummary() = %q, want %q", got, want)
+	}
+
+	// Colors wrap the padded column, so the table lines up once they are stripped
+	ansi := regexp.MustCompile("\033\\[[0-9;]*m")
+	if got := ansi.ReplaceAllString(exec.FormatStepList(runPlan, nil), ""); got != plainList {
+		t.Errorf("colored step list without colors =\n%s\nwant\n%s", got, plainList)
+	}
+
+	// Redirected output is never colored
+	if exec.ColorSupported(&bytes.Buffer{}) {
+		t.Error("ColorSupported(buffer) = true, want false")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if exec.ColorSupported(os.Stdout) {
+		t.Error("ColorSupported() = true with NO_COLOR set")
+	}
+}
+
+func TestContainerRunnerKillsTimedOutStepInContainer(t *testing.T) {
+	// The fake docker logs its arguments and runs exec'd shell commands on
+	// the host, as if the container were the host
+	bin := t.TempDir()
+	log := filepath.Join(bin, "docker.log")
+	script := `#!/bin/sh
+echo "$*" >> ` + log + `
+case "$1" in
+info) echo 24.0.0 ;;
+run) echo cid123 ;;
+exec)
+	shift
+	while [ $# -gt 0 ]; do
+		case "$1" in -w|-e) shift 2 ;; *) break ;; esac
+	done
+	shift
+	[ "$1" = sh ] && exec "$@"
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	runner, err := exec.NewContainerRunner(&exec.RunnerConfig{
+		Mode:           exec.ModeExecute,
+		WorkingDir:     t.TempDir(),
+		StepTimeout:    10 * time.Second,
+		AutoYes:        true,
+		ContainerImage: "alpine",
+	})
+	if err != nil {
+		t.Fatalf("NewContainerRunner() error = %v", err)
+	}
+
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps:       []llm.Step{{ID: "rr-kill-test", Cmd: "sleep 10", Cwd: ".", Timeout: 1}},
+	}
+	if result := runner.Execute(plan); result.Failed != 1 {
+		t.Fatalf("Expected the step to time out, got %+v", result)
+	}
+
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(calls), "exec cid123 sh -c pid=$(cat '/tmp/rr-rr-kill-test.pid'") ||
+		!strings.Contains(string(calls), `kill -TERM -"$pid"`) {
+		t.Errorf("Expected the step to be killed inside the container, docker calls:\n%s", calls)
+	}
+}
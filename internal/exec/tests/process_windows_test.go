@@ -0,0 +1,61 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Tests for process tree termination on Windows
+
+//go:build windows
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// TestJobObjectKillsChildren verifies that a child started by cmd /C is
+// killed along with the shell when the step times out
+func TestJobObjectKillsChildren(t *testing.T) {
+	workDir := t.TempDir()
+	markerFile := filepath.Join(workDir, "marker.txt")
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  workDir,
+		StepTimeout: 30 * time.Second,
+		AutoYes:     true,
+	})
+
+	// The background child writes the marker after ~3 seconds unless killed;
+	// the shell itself blocks for 30 seconds
+	plan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{
+				ID:      "spawn-child",
+				Cmd:     `start /B cmd /C "ping -n 4 127.0.0.1 > nul & echo alive > marker.txt" & ping -n 30 127.0.0.1 > nul`,
+				Cwd:     ".",
+				Timeout: 1,
+			},
+		},
+	}
+
+	start := time.Now()
+	result := runner.Execute(plan)
+	if result.Success {
+		t.Fatal("expected the step to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("timeout did not stop the shell promptly, took %v", elapsed)
+	}
+
+	// Give a surviving child time to write the marker
+	time.Sleep(5 * time.Second)
+
+	if _, err := os.Stat(markerFile); err == nil {
+		t.Error("child process was not killed - marker file was created")
+	}
+}
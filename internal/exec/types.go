@@ -5,6 +5,9 @@ package exec
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/sony-level/readme-runner/internal/llm"
@@ -29,6 +32,28 @@ const (
 	ModeExecute
 )
 
+// IsolationMode determines where commands are executed
+type IsolationMode string
+
+const (
+	// IsolationNone runs commands directly on the host
+	IsolationNone IsolationMode = ""
+	// IsolationDocker runs commands inside a throwaway Docker container
+	IsolationDocker IsolationMode = "docker"
+)
+
+// ParseIsolationMode converts a CLI value into an IsolationMode
+func ParseIsolationMode(value string) (IsolationMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "none":
+		return IsolationNone, nil
+	case "docker":
+		return IsolationDocker, nil
+	default:
+		return IsolationNone, fmt.Errorf("unknown isolation mode %q (supported: docker)", value)
+	}
+}
+
 // StepRunner is the interface for executing individual steps
 type StepRunner interface {
 	// RunStep executes a single step with the given options
@@ -53,16 +78,29 @@ type RunOptions struct {
 
 // RunnerConfig configures the executor
 type RunnerConfig struct {
-	Mode           ExecutionMode
-	WorkingDir     string            // Base working directory (usually workspace repo path)
-	Environment    map[string]string // Plan-level environment variables
-	AutoYes        bool              // Auto-accept non-sudo prompts
-	AllowSudo      bool              // Skip sudo confirmation prompts
-	Verbose        bool              // Enable verbose output
-	StepTimeout    time.Duration     // Default timeout per step
-	GlobalTimeout  time.Duration     // Global execution timeout (0 = no limit)
-	OnStepStart    func(step *llm.Step)
-	OnStepComplete func(step *llm.Step, result *StepResult)
+	Mode            ExecutionMode
+	WorkingDir      string            // Base working directory (usually workspace repo path)
+	Environment     map[string]string // Plan-level environment variables
+	AutoYes         bool              // Auto-accept non-sudo prompts
+	AllowSudo       bool              // Skip sudo confirmation prompts
+	Verbose         bool              // Enable verbose output
+	ShowEnv         bool              // Print each step's env overrides before it runs (-vvv)
+	StepTimeout     time.Duration     // Default timeout per step
+	GlobalTimeout   time.Duration     // Global execution timeout (0 = no limit)
+	Isolation       IsolationMode     // Where commands run (host or container)
+	Shell           Shell             // Shell for host commands (default: cmd on Windows, sh elsewhere)
+	ContainerImage  string            // Image for container isolation (empty = based on project type)
+	Sandbox         *SandboxConfig    // Optional bwrap/firejail confinement for host commands
+	SkipSteps       map[string]bool   // Step IDs completed in a previous run (--resume)
+	MaxParallel     int               // Steps run at once for plans with depends_on (0/1 = sequential)
+	MaxRetriesTotal int               // Retry attempts allowed across all steps, prompted or automatic (0 = no limit)
+	Detach          bool              // Keep long-running steps in the background once up, stopped after the last step
+	LeaveRunning    bool              // Leave detached steps and step processes running after the plan instead of stopping them
+	MaxOutputBytes  int               // Cap on captured stdout/stderr per step (0 = DefaultMaxOutputBytes, <0 = no cap)
+	LogDir          string            // Log files of detached steps left running (LeaveRunning); "" = temp files
+	Output          io.Writer         // Step stdout and runner messages (default: os.Stdout)
+	OnStepStart     func(step *llm.Step)
+	OnStepComplete  func(step *llm.Step, result *StepResult)
 }
 
 // StepResult contains the result of executing a single step
@@ -72,6 +110,7 @@ type StepResult struct {
 	Skipped    bool
 	SkipReason string
 	Cancelled  bool
+	Detached   bool // Left running in the background (RunnerConfig.Detach)
 	ExitCode   int
 	Duration   time.Duration
 	Stdout     string
@@ -81,18 +120,25 @@ type StepResult struct {
 
 // ExecutionResult contains the complete execution result
 type ExecutionResult struct {
-	Success        bool
-	TotalSteps     int
-	Completed      int
-	Failed         int
-	Skipped        int
-	TotalTime      time.Duration
-	StepResults    []*StepResult
-	FailedStep     *StepResult
-	AbortedByUser  bool
-	TimeoutReached bool
-	Ports          []int    // Ports from the plan for post-execution report
-	Notes          []string // Notes from the plan for post-execution report
+	Success             bool
+	TotalSteps          int
+	Completed           int
+	Failed              int
+	Skipped             int
+	TotalTime           time.Duration
+	StepResults         []*StepResult
+	FailedStep          *StepResult
+	AbortedByUser       bool
+	TimeoutReached      bool
+	RetryBudgetExceeded bool               // Stopped because RunnerConfig.MaxRetriesTotal was used up
+	Retries             int                // Retry attempts made across all steps
+	Ports               []int              // Ports from the plan for post-execution report
+	Notes               []string           // Notes from the plan for post-execution report
+	HealthCheck         *HealthCheckResult // Result of polling plan.HealthCheck (nil if not configured)
+	Services            []ComposeService   // Services left running by detached compose up steps
+	ComposeDown         []string           // Commands that stop those services
+	PortStatus          []PortStatus       // Plan ports probed after the run (empty if not inspected)
+	Processes           []RunningProcess   // Step processes still running after the run
 }
 
 // NewExecutionResult creates an empty execution result
@@ -121,6 +167,41 @@ func (r *ExecutionResult) AddStepResult(result *StepResult) {
 	}
 }
 
+// ReplaceStepResult replaces the result at index (e.g. after a retry) and
+// updates the counters, FailedStep and Success accordingly
+func (r *ExecutionResult) ReplaceStepResult(index int, updated *StepResult) {
+	old := r.StepResults[index]
+	switch {
+	case old.Skipped:
+		r.Skipped--
+	case old.Success:
+		r.Completed--
+	default:
+		r.Failed--
+	}
+
+	r.StepResults[index] = updated
+	switch {
+	case updated.Skipped:
+		r.Skipped++
+	case updated.Success:
+		r.Completed++
+	default:
+		r.Failed++
+	}
+
+	if r.FailedStep == old || r.FailedStep == nil {
+		r.FailedStep = nil
+		for _, result := range r.StepResults {
+			if !result.Success && !result.Skipped {
+				r.FailedStep = result
+				break
+			}
+		}
+	}
+	r.Success = r.Failed == 0
+}
+
 // SudoChoice represents the user's choice for sudo handling
 type SudoChoice int
 
@@ -193,17 +274,18 @@ func DefaultFailurePrompt() FailurePromptFunc {
 	}
 }
 
-// GetStepTimeout returns the timeout for a step
+// GetStepTimeout returns the timeout for a step. A plan step's own Timeout
+// overrides defaultTimeout (RunnerConfig.StepTimeout, set by --step-timeout);
+// either way the result is capped at MaxStepTimeout.
 func GetStepTimeout(step *llm.Step, defaultTimeout time.Duration) time.Duration {
+	timeout := DefaultStepTimeout
 	if step.Timeout > 0 {
-		timeout := time.Duration(step.Timeout) * time.Second
-		if timeout > MaxStepTimeout {
-			return MaxStepTimeout
-		}
-		return timeout
+		timeout = time.Duration(step.Timeout) * time.Second
+	} else if defaultTimeout > 0 {
+		timeout = defaultTimeout
 	}
-	if defaultTimeout > 0 {
-		return defaultTimeout
+	if timeout > MaxStepTimeout {
+		return MaxStepTimeout
 	}
-	return DefaultStepTimeout
+	return timeout
 }
@@ -0,0 +1,160 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Devcontainer export from a validated RunPlan
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// FormatDevcontainer is the export format for .devcontainer/devcontainer.json
+const FormatDevcontainer = "devcontainer"
+
+// SupportedFormats lists all export formats
+var SupportedFormats = []string{FormatDevcontainer, FormatYAML}
+
+// DefaultDevcontainerImage is used when the project type has no dedicated image
+const DefaultDevcontainerImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// devcontainerImages maps project types to official devcontainer base images
+var devcontainerImages = map[string]string{
+	"node":   "mcr.microsoft.com/devcontainers/javascript-node:1",
+	"python": "mcr.microsoft.com/devcontainers/python:1",
+	"go":     "mcr.microsoft.com/devcontainers/go:1",
+	"rust":   "mcr.microsoft.com/devcontainers/rust:1",
+}
+
+// devcontainerFeatures maps prerequisite names to devcontainer features
+var devcontainerFeatures = map[string]string{
+	"node":           "ghcr.io/devcontainers/features/node:1",
+	"python":         "ghcr.io/devcontainers/features/python:1",
+	"go":             "ghcr.io/devcontainers/features/go:1",
+	"cargo":          "ghcr.io/devcontainers/features/rust:1",
+	"rustc":          "ghcr.io/devcontainers/features/rust:1",
+	"docker":         "ghcr.io/devcontainers/features/docker-in-docker:2",
+	"docker-compose": "ghcr.io/devcontainers/features/docker-in-docker:2",
+}
+
+// Devcontainer is the subset of the devcontainer.json schema we generate
+type Devcontainer struct {
+	Name              string                    `json:"name"`
+	Image             string                    `json:"image"`
+	Features          map[string]map[string]any `json:"features,omitempty"`
+	PostCreateCommand string                    `json:"postCreateCommand,omitempty"`
+	ForwardPorts      []int                     `json:"forwardPorts,omitempty"`
+}
+
+// IsSupportedFormat checks if an export format is known
+func IsSupportedFormat(format string) bool {
+	for _, f := range SupportedFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDevcontainer builds a devcontainer definition from a plan
+func NewDevcontainer(plan *llm.RunPlan) *Devcontainer {
+	dc := &Devcontainer{
+		Name:         fmt.Sprintf("%s project (generated by rdr)", plan.ProjectType),
+		Image:        DefaultDevcontainerImage,
+		Features:     make(map[string]map[string]any),
+		ForwardPorts: plan.Ports,
+	}
+
+	if image, ok := devcontainerImages[plan.ProjectType]; ok {
+		dc.Image = image
+	}
+
+	for _, prereq := range plan.Prerequisites {
+		feature, ok := devcontainerFeatures[strings.ToLower(prereq.Name)]
+		if !ok || dc.imageProvides(prereq.Name) {
+			continue
+		}
+
+		options := dc.Features[feature]
+		if options == nil {
+			options = make(map[string]any)
+		}
+		if prereq.MinVersion != "" {
+			options["version"] = prereq.MinVersion
+		}
+		dc.Features[feature] = options
+	}
+
+	dc.PostCreateCommand = postCreateCommand(plan.Steps)
+
+	return dc
+}
+
+// imageProvides returns true if the base image already ships the tool
+func (d *Devcontainer) imageProvides(tool string) bool {
+	tool = strings.ToLower(tool)
+	switch d.Image {
+	case devcontainerImages["node"]:
+		return tool == "node"
+	case devcontainerImages["python"]:
+		return tool == "python"
+	case devcontainerImages["go"]:
+		return tool == "go"
+	case devcontainerImages["rust"]:
+		return tool == "cargo" || tool == "rustc"
+	}
+	return false
+}
+
+// postCreateCommand chains the install/build steps of a plan.
+// Run steps are left out since they start the app rather than prepare it,
+// and sudo steps are left to features.
+func postCreateCommand(steps []llm.Step) string {
+	var cmds []string
+	for _, step := range steps {
+		if step.RequiresSudo || isRunStep(step) {
+			continue
+		}
+		cmd := step.Cmd
+		if step.Cwd != "" && step.Cwd != "." {
+			cmd = fmt.Sprintf("(cd %s && %s)", exec.QuoteArg(step.Cwd), step.Cmd)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// isRunStep checks if a step starts the application
+func isRunStep(step llm.Step) bool {
+	switch strings.ToLower(step.ID) {
+	case "run", "start", "serve", "dev":
+		return true
+	}
+	return false
+}
+
+// WriteDevcontainer writes .devcontainer/devcontainer.json under dir
+// and returns the path of the written file
+func WriteDevcontainer(plan *llm.RunPlan, dir string) (string, error) {
+	data, err := json.MarshalIndent(NewDevcontainer(plan), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal devcontainer: %w", err)
+	}
+
+	targetDir := filepath.Join(dir, ".devcontainer")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", targetDir, err)
+	}
+
+	path := filepath.Join(targetDir, "devcontainer.json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,131 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Tests for plan export formats
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sony-level/readme-runner/internal/export"
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+func TestNewDevcontainerNode(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "node", Reason: "Runtime", MinVersion: "20"},
+			{Name: "docker", Reason: "Database"},
+		},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm ci", Cwd: "."},
+			{ID: "build", Cmd: "npm run build", Cwd: "web"},
+			{ID: "docs", Cmd: "npm run docs", Cwd: "my docs; rm -rf ~"},
+			{ID: "system", Cmd: "sudo apt-get install -y libpq-dev", RequiresSudo: true},
+			{ID: "run", Cmd: "npm start", Cwd: "."},
+		},
+		Ports: []int{3000},
+	}
+
+	dc := export.NewDevcontainer(runPlan)
+
+	if dc.Image != "mcr.microsoft.com/devcontainers/javascript-node:1" {
+		t.Errorf("Expected node image, got %s", dc.Image)
+	}
+	if _, ok := dc.Features["ghcr.io/devcontainers/features/node:1"]; ok {
+		t.Error("Node feature should not be added on a node image")
+	}
+	if _, ok := dc.Features["ghcr.io/devcontainers/features/docker-in-docker:2"]; !ok {
+		t.Error("Expected docker-in-docker feature")
+	}
+
+	expected := "npm ci && (cd web && npm run build) && (cd 'my docs; rm -rf ~' && npm run docs)"
+	if dc.PostCreateCommand != expected {
+		t.Errorf("Expected postCreateCommand %q, got %q", expected, dc.PostCreateCommand)
+	}
+
+	if len(dc.ForwardPorts) != 1 || dc.ForwardPorts[0] != 3000 {
+		t.Errorf("Expected forwardPorts [3000], got %v", dc.ForwardPorts)
+	}
+}
+
+func TestNewDevcontainerMixedUsesFeatures(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "go", MinVersion: "1.22"},
+			{Name: "python"},
+		},
+		Steps: []llm.Step{
+			{ID: "build", Cmd: "go build ./..."},
+		},
+	}
+
+	dc := export.NewDevcontainer(runPlan)
+
+	if dc.Image != export.DefaultDevcontainerImage {
+		t.Errorf("Expected default image, got %s", dc.Image)
+	}
+
+	goFeature, ok := dc.Features["ghcr.io/devcontainers/features/go:1"]
+	if !ok {
+		t.Fatal("Expected go feature")
+	}
+	if goFeature["version"] != "1.22" {
+		t.Errorf("Expected go feature version 1.22, got %v", goFeature["version"])
+	}
+	if _, ok := dc.Features["ghcr.io/devcontainers/features/python:1"]; !ok {
+		t.Error("Expected python feature")
+	}
+}
+
+func TestWriteDevcontainer(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps: []llm.Step{
+			{ID: "deps", Cmd: "go mod download"},
+			{ID: "run", Cmd: "go run ."},
+		},
+		Ports: []int{8080},
+	}
+
+	path, err := export.WriteDevcontainer(runPlan, tmpDir)
+	if err != nil {
+		t.Fatalf("WriteDevcontainer failed: %v", err)
+	}
+
+	if path != filepath.Join(tmpDir, ".devcontainer", "devcontainer.json") {
+		t.Errorf("Unexpected path: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read exported file: %v", err)
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Exported file is not valid JSON: %v", err)
+	}
+
+	if parsed["postCreateCommand"] != "go mod download" {
+		t.Errorf("Unexpected postCreateCommand: %v", parsed["postCreateCommand"])
+	}
+}
+
+func TestIsSupportedFormat(t *testing.T) {
+	if !export.IsSupportedFormat("devcontainer") {
+		t.Error("devcontainer should be supported")
+	}
+	if export.IsSupportedFormat("nix") {
+		t.Error("nix should not be supported")
+	}
+}
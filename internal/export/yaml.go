@@ -0,0 +1,33 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// YAML export of a validated RunPlan
+
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/plan"
+)
+
+// FormatYAML is the export format for a hand-editable run-plan.yaml
+const FormatYAML = "yaml"
+
+// YAMLPlanFile is the file written by the YAML export
+const YAMLPlanFile = "run-plan.yaml"
+
+// WriteYAML writes the plan to run-plan.yaml in dir and returns its path.
+// The file can be reviewed, edited and passed back to rdr validate.
+func WriteYAML(runPlan *llm.RunPlan, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, YAMLPlanFile)
+	if err := plan.SaveFile(runPlan, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
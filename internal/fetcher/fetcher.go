@@ -4,6 +4,7 @@
 package fetcher
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +14,12 @@ import (
 
 // Fetch fetches a project from a GitHub/GitLab URL or local path
 func Fetch(config *FetchConfig) (*FetchResult, error) {
+	return FetchWithContext(context.Background(), config)
+}
+
+// FetchWithContext fetches a project, stopping the clone or copy when ctx
+// is cancelled or its deadline passes
+func FetchWithContext(ctx context.Context, config *FetchConfig) (*FetchResult, error) {
 	if config == nil {
 		return nil, fmt.Errorf("fetch config is nil")
 	}
@@ -21,7 +28,7 @@ func Fetch(config *FetchConfig) (*FetchResult, error) {
 		return nil, fmt.Errorf("source is empty")
 	}
 
-	if config.Destination == "" {
+	if config.Destination == "" && !config.InPlace {
 		return nil, fmt.Errorf("destination is empty")
 	}
 
@@ -35,9 +42,12 @@ func Fetch(config *FetchConfig) (*FetchResult, error) {
 
 	switch sourceType {
 	case SourceTypeGitHub, SourceTypeGitLab:
-		return fetchFromGit(config, sourceType)
+		if config.InPlace {
+			return nil, fmt.Errorf("in-place mode needs a local path, not a %s URL", sourceType)
+		}
+		return fetchFromGit(ctx, config, sourceType)
 	case SourceTypeLocal:
-		return fetchFromLocal(config)
+		return fetchFromLocal(ctx, config)
 	default:
 		return nil, fmt.Errorf("unknown source type for: %s", config.Source)
 	}
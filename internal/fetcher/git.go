@@ -4,6 +4,7 @@
 package fetcher
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -12,7 +13,7 @@ import (
 )
 
 // fetchFromGit clones a repository from GitHub or GitLab
-func fetchFromGit(config *FetchConfig, sourceType string) (*FetchResult, error) {
+func fetchFromGit(ctx context.Context, config *FetchConfig, sourceType string) (*FetchResult, error) {
 	// Parse and normalize the URL
 	repoInfo, err := ParseGitURL(config.Source)
 	if err != nil {
@@ -47,7 +48,7 @@ func fetchFromGit(config *FetchConfig, sourceType string) (*FetchResult, error)
 	}
 
 	// Clone the repository
-	_, err = git.PlainClone(config.Destination, false, cloneOpts)
+	_, err = git.PlainCloneContext(ctx, config.Destination, false, cloneOpts)
 	if err != nil {
 		// Clean up partial clone on failure
 		_ = os.RemoveAll(config.Destination)
@@ -55,7 +56,7 @@ func fetchFromGit(config *FetchConfig, sourceType string) (*FetchResult, error)
 	}
 
 	// Count files in the cloned repository
-	fileCount, byteCount, err := countFiles(config.Destination)
+	fileCount, byteCount, err := countFiles(ctx, config.Destination)
 	if err != nil {
 		// Non-fatal error, just log it
 		if config.Verbose {
@@ -78,11 +79,11 @@ func fetchFromGit(config *FetchConfig, sourceType string) (*FetchResult, error)
 }
 
 // countFiles counts files and total bytes in a directory
-func countFiles(dir string) (int, int64, error) {
+func countFiles(ctx context.Context, dir string) (int, int64, error) {
 	var fileCount int
 	var byteCount int64
 
-	err := walkDir(dir, func(path string, info os.FileInfo) error {
+	err := walkDir(ctx, dir, func(path string, info os.FileInfo) error {
 		if !info.IsDir() {
 			fileCount++
 			byteCount += info.Size()
@@ -5,6 +5,7 @@ package fetcher
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -27,7 +28,7 @@ var defaultSkipPatterns = []string{
 }
 
 // fetchFromLocal copies a local directory to the destination
-func fetchFromLocal(config *FetchConfig) (*FetchResult, error) {
+func fetchFromLocal(ctx context.Context, config *FetchConfig) (*FetchResult, error) {
 	// Validate source path
 	if err := ValidateLocalPath(config.Source); err != nil {
 		return nil, err
@@ -39,6 +40,10 @@ func fetchFromLocal(config *FetchConfig) (*FetchResult, error) {
 		return nil, fmt.Errorf("failed to resolve source path: %w", err)
 	}
 
+	if config.InPlace {
+		return useInPlace(config.Source, srcPath), nil
+	}
+
 	// Load gitignore patterns if present
 	ignorePatterns := loadGitignore(srcPath)
 	ignorePatterns = append(ignorePatterns, defaultSkipPatterns...)
@@ -58,7 +63,7 @@ func fetchFromLocal(config *FetchConfig) (*FetchResult, error) {
 	var filesCopied int
 	var bytesCopied int64
 
-	err = walkDir(srcPath, func(path string, info os.FileInfo) error {
+	err = walkDir(ctx, srcPath, func(path string, info os.FileInfo) error {
 		// Get relative path from source
 		relPath, err := filepath.Rel(srcPath, path)
 		if err != nil {
@@ -116,12 +121,27 @@ func fetchFromLocal(config *FetchConfig) (*FetchResult, error) {
 	}, nil
 }
 
+// useInPlace returns the result of an in-place fetch: the source directory
+// is used as it is
+func useInPlace(source, srcPath string) *FetchResult {
+	return &FetchResult{
+		Source:      source,
+		Destination: srcPath,
+		SourceType:  SourceTypeLocal,
+		IsGitRepo:   isGitRepository(srcPath),
+		InPlace:     true,
+	}
+}
+
 // walkDir walks a directory tree, calling walkFn for each file or directory
-func walkDir(root string, walkFn func(path string, info os.FileInfo) error) error {
+func walkDir(ctx context.Context, root string, walkFn func(path string, info os.FileInfo) error) error {
 	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		return walkFn(path, info)
 	})
 }
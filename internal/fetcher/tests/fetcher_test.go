@@ -4,6 +4,8 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -282,6 +284,44 @@ func TestFetchFromLocal(t *testing.T) {
 	}
 }
 
+func TestFetchInPlace(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "README.md"), []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	destPath := filepath.Join(t.TempDir(), "repo")
+
+	result, err := fetcher.Fetch(&fetcher.FetchConfig{
+		Source:      srcDir,
+		Destination: destPath,
+		InPlace:     true,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !result.InPlace {
+		t.Error("InPlace = false, want true")
+	}
+	if result.Destination != srcDir {
+		t.Errorf("Destination = %q, want the source %q", result.Destination, srcDir)
+	}
+	if result.FilesCopied != 0 {
+		t.Errorf("FilesCopied = %d, want 0", result.FilesCopied)
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Error("in-place fetch should not create the destination")
+	}
+
+	// Git sources have no original directory to run in
+	_, err = fetcher.Fetch(&fetcher.FetchConfig{
+		Source:  "https://github.com/user/repo",
+		InPlace: true,
+	})
+	if err == nil {
+		t.Error("in-place fetch of a git URL should return error")
+	}
+}
+
 func TestFetch_Validation(t *testing.T) {
 	// Nil config
 	_, err := fetcher.Fetch(nil)
@@ -307,3 +347,25 @@ func TestFetch_Validation(t *testing.T) {
 		t.Error("Fetch with empty destination should return error")
 	}
 }
+
+func TestFetchWithContext_Cancelled(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "README.md"), []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	destPath := filepath.Join(t.TempDir(), "repo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := fetcher.FetchWithContext(ctx, &fetcher.FetchConfig{
+		Source:      srcDir,
+		Destination: destPath,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchWithContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Error("partial copy should be removed")
+	}
+}
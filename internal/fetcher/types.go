@@ -35,6 +35,7 @@ type FetchConfig struct {
 	Verbose     bool      // Enable verbose logging
 	Progress    io.Writer // Progress output (optional, defaults to io.Discard)
 	ShallowClone bool     // Use shallow clone for git (depth=1)
+	InPlace      bool     // Use a local source where it is instead of copying it (Destination is ignored)
 }
 
 // FetchResult contains the result of a fetch operation
@@ -45,6 +46,7 @@ type FetchResult struct {
 	IsGitRepo   bool   // Whether source was a git repository
 	FilesCopied int    // Number of files copied
 	BytesCopied int64  // Total bytes copied
+	InPlace     bool   // Destination is the source directory itself (nothing copied)
 }
 
 // GitRepoInfo contains parsed git repository information
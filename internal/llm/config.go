@@ -4,10 +4,16 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +28,101 @@ type Config struct {
 	Token    string            `json:"token" yaml:"token"`
 	Timeout  string            `json:"timeout" yaml:"timeout"` // e.g., "60s"
 	Keys     map[string]string `json:"keys" yaml:"keys"`       // provider-specific keys
+
+	Headers    map[string]string `json:"headers" yaml:"headers"`         // extra HTTP headers
+	AuthScheme string            `json:"auth_scheme" yaml:"auth_scheme"` // bearer, raw, none
+	Format     string            `json:"format" yaml:"format"`           // HTTP provider body: openai, anthropic, ollama-generate
+
+	TokenFile  string `json:"token_file" yaml:"token_file"`   // file holding the token (mode 0600)
+	KeyCommand string `json:"key_command" yaml:"key_command"` // command printing the token, e.g. "pass show anthropic"
+
+	MaxRetries   *int   `json:"max_retries" yaml:"max_retries"`     // retries per request, 0 = none (default 1)
+	RetryBackoff string `json:"retry_backoff" yaml:"retry_backoff"` // first pause, doubled per retry, e.g. "2s"
+
+	ClarityThreshold *float64 `json:"clarity_threshold" yaml:"clarity_threshold"` // README-first threshold (0-1)
+
+	Tools []ToolConfig `json:"tools" yaml:"tools"` // extra prerequisites, merged over the built-in tools
+}
+
+// ToolConfig is a prerequisite defined in the config file, such as an
+// in-house CLI. An entry named like a built-in tool overrides the fields it
+// sets and keeps the others.
+type ToolConfig struct {
+	Name         string   `json:"name" yaml:"name"`
+	Command      string   `json:"command" yaml:"command"` // defaults to name
+	VersionCmd   string   `json:"version_cmd" yaml:"version_cmd"`
+	Alternatives []string `json:"alternatives" yaml:"alternatives"`
+	InstallGuide string   `json:"install_guide" yaml:"install_guide"`
+}
+
+// keyCommandTimeout bounds how long key_command may run
+const keyCommandTimeout = 10 * time.Second
+
+// ResolveToken returns the token configured in the file: token, else the
+// content of token_file, else the output of key_command. Returns "" when
+// none is set. Errors never include the token itself.
+func (c *Config) ResolveToken() (string, error) {
+	switch {
+	case c.Token != "":
+		return c.Token, nil
+	case c.TokenFile != "":
+		return readTokenFile(c.TokenFile)
+	case c.KeyCommand != "":
+		return runKeyCommand(c.KeyCommand)
+	}
+	return "", nil
+}
+
+// readTokenFile reads a token from a file that only its owner can read
+func readTokenFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("token_file: %w", err)
+	}
+	// Windows has no Unix permission bits to check
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("token_file %s is accessible by other users (mode %04o); run chmod 600 %s", path, info.Mode().Perm(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("token_file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token_file %s is empty", path)
+	}
+	return token, nil
+}
+
+// runKeyCommand returns the trimmed stdout of a shell command
+func runKeyCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr // prompts from e.g. gpg-agent stay visible
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("key_command %q failed: %w", command, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("key_command %q printed nothing", command)
+	}
+	return token, nil
 }
 
 // ConfigPaths returns the paths to check for config files in order
@@ -102,11 +203,50 @@ func loadConfigFromPath(path string) (*Config, error) {
 
 // ProviderSelectionInfo contains details about how a provider was selected
 type ProviderSelectionInfo struct {
-	Provider     ProviderType
-	Source       string // "cli", "env", "config", "auto"
-	AutoReason   string // Reason for auto-selection (if applicable)
-	WasFallback  bool   // True if fell back from another provider
-	FallbackFrom string // Original provider if fallback occurred
+	Provider      ProviderType
+	Source        string // "cli", "env", "config", "auto"
+	AutoReason    string // Reason for auto-selection (if applicable)
+	WasFallback   bool   // True if fell back from another provider
+	FallbackFrom  string // Original provider if fallback occurred
+	FallbackError string // Why the original provider was not used
+	RateLimited   bool   // The original provider kept answering HTTP 429
+	TimedOut      bool   // The original provider did not answer within the timeout
+	Model         string // Model in use, when known
+	ModelSource   string // How the model was chosen (e.g. auto-picked)
+	ModelError    string // Problem found while choosing a model
+	TokenError    string // Problem reading token_file or key_command
+}
+
+// recordFallback notes that provider from was replaced by the mock provider
+func (info *ProviderSelectionInfo) recordFallback(from string, err error) {
+	if info == nil {
+		return
+	}
+	info.WasFallback = true
+	info.FallbackFrom = from
+	if err != nil {
+		info.FallbackError = err.Error()
+		info.RateLimited = errors.Is(err, ErrRateLimited)
+		info.TimedOut = errors.Is(err, ErrTimeout)
+	}
+}
+
+// FallbackSummary describes a fallback in one line ("" if none occurred)
+func (info *ProviderSelectionInfo) FallbackSummary() string {
+	if info == nil || !info.WasFallback {
+		return ""
+	}
+	summary := fmt.Sprintf("provider %s was not used", info.FallbackFrom)
+	if info.FallbackError != "" {
+		summary += ": " + info.FallbackError
+	}
+	if info.RateLimited {
+		summary += " (still rate limited after all retries; try again later or raise max_retries)"
+	}
+	if info.TimedOut {
+		summary += " (slow models may need a longer --provider-timeout or RD_LLM_TIMEOUT)"
+	}
+	return summary + "; plan generated offline by the mock provider from project files"
 }
 
 // ResolveProviderConfig creates a ProviderConfig with proper precedence:
@@ -116,8 +256,25 @@ func ResolveProviderConfig(cliProvider, cliEndpoint, cliModel, cliToken string,
 	return config
 }
 
+// OfflineEnvVar forces the mock provider when set to a true value (e.g. 1)
+const OfflineEnvVar = "RDR_OFFLINE"
+
+// OfflineRequested reports whether RDR_OFFLINE asks for offline mode
+func OfflineRequested() bool {
+	offline, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(OfflineEnvVar)))
+	return err == nil && offline
+}
+
 // ResolveProviderConfigWithInfo creates a ProviderConfig with selection info
 func ResolveProviderConfigWithInfo(cliProvider, cliEndpoint, cliModel, cliToken string, cliTimeout time.Duration, verbose bool) (*ProviderConfig, *ProviderSelectionInfo) {
+	return ResolveProviderConfigWithOffline(cliProvider, cliEndpoint, cliModel, cliToken, cliTimeout, verbose, false)
+}
+
+// ResolveProviderConfigWithOffline is ResolveProviderConfigWithInfo with an
+// offline switch (--offline). Offline mode, also enabled by RDR_OFFLINE,
+// forces the mock provider whatever the flags, env and available keys say;
+// nothing is probed over the network.
+func ResolveProviderConfigWithOffline(cliProvider, cliEndpoint, cliModel, cliToken string, cliTimeout time.Duration, verbose, offline bool) (*ProviderConfig, *ProviderSelectionInfo) {
 	// Load config file (optional)
 	fileCfg, _ := LoadConfig()
 
@@ -146,14 +303,30 @@ func ResolveProviderConfigWithInfo(cliProvider, cliEndpoint, cliModel, cliToken
 		if fileCfg.Endpoint != "" {
 			config.Endpoint = fileCfg.Endpoint
 		}
-		if fileCfg.Token != "" {
-			config.Token = fileCfg.Token
-		}
 		if fileCfg.Timeout != "" {
 			if d, err := time.ParseDuration(fileCfg.Timeout); err == nil {
 				config.Timeout = d
 			}
 		}
+		for name, value := range fileCfg.Headers {
+			config.setHeader(name, value)
+		}
+		if scheme, err := ParseAuthScheme(fileCfg.AuthScheme); err == nil {
+			config.AuthScheme = scheme
+		}
+		if format, err := ParseHTTPFormat(fileCfg.Format); err == nil {
+			config.Format = format
+		}
+		if fileCfg.MaxRetries != nil {
+			if n, err := ParseMaxRetries(strconv.Itoa(*fileCfg.MaxRetries)); err == nil {
+				config.MaxRetries = n
+			}
+		}
+		if fileCfg.RetryBackoff != "" {
+			if d, err := time.ParseDuration(fileCfg.RetryBackoff); err == nil {
+				config.RetryBackoff = d
+			}
+		}
 	}
 
 	// Apply environment variables (medium priority)
@@ -176,6 +349,33 @@ func ResolveProviderConfigWithInfo(cliProvider, cliEndpoint, cliModel, cliToken
 			config.Timeout = d
 		}
 	}
+	if envHeaders := os.Getenv("RD_LLM_HEADERS"); envHeaders != "" {
+		if headers, err := ParseHeaders(envHeaders); err == nil {
+			for name, value := range headers {
+				config.setHeader(name, value)
+			}
+		}
+	}
+	if envScheme := os.Getenv("RD_LLM_AUTH_SCHEME"); envScheme != "" {
+		if scheme, err := ParseAuthScheme(envScheme); err == nil {
+			config.AuthScheme = scheme
+		}
+	}
+	if envFormat := os.Getenv("RD_LLM_FORMAT"); envFormat != "" {
+		if format, err := ParseHTTPFormat(envFormat); err == nil {
+			config.Format = format
+		}
+	}
+	if envRetries := os.Getenv("RD_LLM_RETRIES"); envRetries != "" {
+		if n, err := ParseMaxRetries(envRetries); err == nil {
+			config.MaxRetries = n
+		}
+	}
+	if envBackoff := os.Getenv("RD_LLM_RETRY_BACKOFF"); envBackoff != "" {
+		if d, err := time.ParseDuration(envBackoff); err == nil {
+			config.RetryBackoff = d
+		}
+	}
 
 	// Apply CLI flags (highest priority)
 	if cliProvider != "" {
@@ -202,6 +402,24 @@ func ResolveProviderConfigWithInfo(cliProvider, cliEndpoint, cliModel, cliToken
 		selectionInfo.Source = "config"
 	}
 
+	// Offline mode wins over everything, including an explicit provider
+	if offline || OfflineRequested() {
+		config.Type = ProviderMock
+		selectionInfo.Source = "offline"
+		selectionInfo.Provider = config.Type
+		return config.WithDefaults(), selectionInfo
+	}
+
+	// The config file token comes last; token_file and key_command are only
+	// read when no CLI or env token is set
+	if config.Token == "" && fileCfg != nil {
+		if token, err := fileCfg.ResolveToken(); err != nil {
+			selectionInfo.TokenError = err.Error()
+		} else {
+			config.Token = token
+		}
+	}
+
 	// Auto-select provider if not explicitly set
 	if !providerExplicitlySet || config.Type == "" {
 		selectedProvider, reason := autoSelectProviderWithReason(config, verbose)
@@ -210,20 +428,33 @@ func ResolveProviderConfigWithInfo(cliProvider, cliEndpoint, cliModel, cliToken
 		selectionInfo.AutoReason = reason
 	}
 
+	// Ollama needs a locally installed model; pick one when none was given
+	if config.Type == ProviderOllama {
+		resolveOllamaModel(config, selectionInfo)
+	}
+
 	selectionInfo.Provider = config.Type
 
 	return config.WithDefaults(), selectionInfo
 }
 
+// setHeader adds an extra request header, replacing any existing value
+func (c *ProviderConfig) setHeader(name, value string) {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+	c.Headers[name] = value
+}
+
 // autoSelectProvider chooses the best available provider
-// Priority: anthropic > openai > mistral > ollama > mock
+// Priority: anthropic > openai > mistral > cohere > ollama > mock
 func autoSelectProvider(config *ProviderConfig) ProviderType {
 	provider, _ := autoSelectProviderWithReason(config, false)
 	return provider
 }
 
 // autoSelectProviderWithReason chooses the best available provider and returns the reason
-// Priority: anthropic > openai > mistral > ollama > mock
+// Priority: anthropic > openai > mistral > cohere > ollama > mock
 func autoSelectProviderWithReason(config *ProviderConfig, verbose bool) (ProviderType, string) {
 	// Check for Anthropic key (preferred - best for structured JSON output)
 	if os.Getenv("ANTHROPIC_API_KEY") != "" {
@@ -240,8 +471,17 @@ func autoSelectProviderWithReason(config *ProviderConfig, verbose bool) (Provide
 		return ProviderMistral, "MISTRAL_API_KEY found"
 	}
 
-	// Check if Ollama is running locally (no API key needed)
+	// Check for Cohere key
+	if os.Getenv("COHERE_API_KEY") != "" {
+		return ProviderCohere, "COHERE_API_KEY found"
+	}
+
+	// Check if Ollama is running locally with a usable model (no API key needed)
 	if IsOllamaAvailable() {
+		models, err := ListOllamaModels(OllamaBaseURL(""))
+		if err == nil && SelectOllamaModel(models) == "" {
+			return ProviderMock, "local Ollama instance detected but " + ErrNoOllamaModels.Error() + "; using offline mode"
+		}
 		return ProviderOllama, "local Ollama instance detected"
 	}
 
@@ -251,21 +491,37 @@ func autoSelectProviderWithReason(config *ProviderConfig, verbose bool) (Provide
 
 // GetProviderSelectionDescription returns a human-readable description of provider selection
 func GetProviderSelectionDescription(info *ProviderSelectionInfo) string {
+	var desc string
 	switch info.Source {
 	case "cli":
-		return "specified via --provider/--llm-provider flag"
+		desc = "specified via --provider/--llm-provider flag"
 	case "env":
-		return "specified via RD_LLM_PROVIDER environment variable"
+		desc = "specified via RD_LLM_PROVIDER environment variable"
 	case "config":
-		return "specified in config file"
+		desc = "specified in config file"
+	case "offline":
+		desc = "forced offline mode (--offline or " + OfflineEnvVar + ")"
 	case "auto":
 		if info.AutoReason != "" {
-			return "auto-selected: " + info.AutoReason
+			desc = "auto-selected: " + info.AutoReason
+		} else {
+			desc = "auto-selected based on available API keys"
 		}
-		return "auto-selected based on available API keys"
 	default:
-		return "default"
+		desc = "default"
+	}
+
+	if info.Model != "" {
+		desc += fmt.Sprintf(" (model: %s", info.Model)
+		if info.ModelSource != "" {
+			desc += ", " + info.ModelSource
+		}
+		desc += ")"
+	}
+	if info.ModelError != "" {
+		desc += "; " + info.ModelError
 	}
+	return desc
 }
 
 // GetProviderToken returns the appropriate token for a provider type
@@ -275,6 +531,13 @@ func GetProviderToken(providerType ProviderType, configToken string) string {
 		return configToken
 	}
 
+	// Then the config file's token, token_file or key_command
+	if fileCfg, _ := LoadConfig(); fileCfg != nil {
+		if token, err := fileCfg.ResolveToken(); err == nil && token != "" {
+			return token
+		}
+	}
+
 	// Provider-specific environment variables
 	switch providerType {
 	case ProviderOpenAI:
@@ -283,6 +546,8 @@ func GetProviderToken(providerType ProviderType, configToken string) string {
 		return os.Getenv("ANTHROPIC_API_KEY")
 	case ProviderMistral:
 		return os.Getenv("MISTRAL_API_KEY")
+	case ProviderCohere:
+		return os.Getenv("COHERE_API_KEY")
 	case ProviderOllama:
 		return "" // No token needed
 	case ProviderHTTP:
@@ -294,15 +559,9 @@ func GetProviderToken(providerType ProviderType, configToken string) string {
 
 // IsOllamaAvailable checks if Ollama is running locally
 func IsOllamaAvailable() bool {
-	endpoint := "http://localhost:11434/api/tags"
-	if host := os.Getenv("OLLAMA_HOST"); host != "" {
-		if !strings.HasPrefix(host, "http") {
-			host = "http://" + host
-		}
-		endpoint = host + "/api/tags"
-	}
+	endpoint := OllamaBaseURL("") + "/api/tags"
 
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := NewHTTPClient(2 * time.Second)
 	resp, err := client.Get(endpoint)
 	if err != nil {
 		return false
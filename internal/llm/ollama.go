@@ -0,0 +1,136 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Ollama model discovery and default model selection
+
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultOllamaBaseURL is the default local Ollama server
+const DefaultOllamaBaseURL = "http://localhost:11434"
+
+// ErrNoOllamaModels is returned when Ollama runs but has no models installed
+var ErrNoOllamaModels = errors.New("no Ollama models installed (run: ollama pull llama3.2)")
+
+// preferredOllamaFamilies are general models known to follow JSON instructions well
+var preferredOllamaFamilies = []string{"qwen2.5", "llama3", "mistral", "deepseek", "gemma2", "phi3"}
+
+// OllamaBaseURL returns the Ollama server URL from an endpoint or OLLAMA_HOST
+func OllamaBaseURL(endpoint string) string {
+	if endpoint != "" {
+		if idx := strings.Index(endpoint, "/api/"); idx >= 0 {
+			return endpoint[:idx]
+		}
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	if host := os.Getenv("OLLAMA_HOST"); host != "" {
+		if !strings.HasPrefix(host, "http") {
+			host = "http://" + host
+		}
+		return strings.TrimSuffix(host, "/")
+	}
+	return DefaultOllamaBaseURL
+}
+
+// ListOllamaModels queries /api/tags and returns the installed model names
+func ListOllamaModels(baseURL string) ([]string, error) {
+	client := NewHTTPClient(2 * time.Second)
+	resp, err := client.Get(baseURL + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("Ollama not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama /api/tags returned HTTP %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama model list: %w", err)
+	}
+
+	models := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		if m.Name != "" {
+			models = append(models, m.Name)
+		}
+	}
+	return models, nil
+}
+
+// SelectOllamaModel picks a default model, preferring instruct/code models.
+// Embedding-only models are never selected. Returns "" if none is usable.
+func SelectOllamaModel(models []string) string {
+	var candidates []string
+	for _, m := range models {
+		if !strings.Contains(strings.ToLower(m), "embed") {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return ollamaModelScore(candidates[i]) > ollamaModelScore(candidates[j])
+	})
+	return candidates[0]
+}
+
+// ollamaModelScore ranks a model name for plan generation
+func ollamaModelScore(model string) int {
+	name := strings.ToLower(model)
+	score := 0
+
+	if strings.Contains(name, "instruct") {
+		score += 3
+	}
+	if strings.Contains(name, "code") {
+		score += 2
+	}
+	for _, family := range preferredOllamaFamilies {
+		if strings.HasPrefix(name, family) {
+			score++
+			break
+		}
+	}
+	return score
+}
+
+// resolveOllamaModel fills config.Model from installed models when unset
+func resolveOllamaModel(config *ProviderConfig, info *ProviderSelectionInfo) {
+	if config.Model != "" {
+		info.Model = config.Model
+		info.ModelSource = "configured"
+		return
+	}
+
+	models, err := ListOllamaModels(OllamaBaseURL(config.Endpoint))
+	if err != nil {
+		// Server not reachable: leave the provider to report connection errors
+		return
+	}
+
+	model := SelectOllamaModel(models)
+	if model == "" {
+		info.ModelError = ErrNoOllamaModels.Error()
+		return
+	}
+
+	config.Model = model
+	info.Model = model
+	info.ModelSource = fmt.Sprintf("auto-picked from %d installed model(s)", len(models))
+}
@@ -5,14 +5,47 @@ package llm
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sony-level/readme-runner/internal/scanner"
 )
 
-// ClarityThreshold is the minimum score for README-first approach
+// ClarityThreshold is the default minimum score for README-first approach
 const ClarityThreshold = 0.6
 
+// ResolveClarityThreshold returns the README-first threshold: the CLI value
+// when cliSet, else clarity_threshold from the config file, else the default
+func ResolveClarityThreshold(cliValue float64, cliSet bool) (float64, error) {
+	if cliSet {
+		if err := validateClarityThreshold(cliValue); err != nil {
+			return 0, fmt.Errorf("--clarity-threshold %w", err)
+		}
+		return cliValue, nil
+	}
+
+	if fileCfg, _ := LoadConfig(); fileCfg != nil && fileCfg.ClarityThreshold != nil {
+		if err := validateClarityThreshold(*fileCfg.ClarityThreshold); err != nil {
+			return 0, fmt.Errorf("clarity_threshold in config file %w", err)
+		}
+		return *fileCfg.ClarityThreshold, nil
+	}
+
+	return ClarityThreshold, nil
+}
+
+func validateClarityThreshold(threshold float64) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("must be between 0 and 1, got %g", threshold)
+	}
+	return nil
+}
+
+// DefaultMaxPromptTokens is the estimated prompt size above which a hosted
+// provider needs confirmation
+const DefaultMaxPromptTokens = 32000
+
 // PromptBuilder constructs LLM prompts
 type PromptBuilder struct{}
 
@@ -69,7 +102,48 @@ func CalculateClarityScore(readme *scanner.ReadmeInfo) float64 {
 
 // ShouldUseReadme returns true if README should be the primary source
 func ShouldUseReadme(readme *scanner.ReadmeInfo) bool {
-	return CalculateClarityScore(readme) >= ClarityThreshold
+	return ShouldUseReadmeWithThreshold(readme, ClarityThreshold)
+}
+
+// ShouldUseReadmeWithThreshold is ShouldUseReadme with a custom threshold
+func ShouldUseReadmeWithThreshold(readme *scanner.ReadmeInfo, threshold float64) bool {
+	return readme != nil && CalculateClarityScore(readme) >= threshold
+}
+
+// Strategy chooses between the README and project-file signals as the
+// primary planning source
+type Strategy string
+
+const (
+	StrategyAuto   Strategy = "auto"   // decided by the clarity score
+	StrategyReadme Strategy = "readme" // always README-first
+	StrategyFiles  Strategy = "files"  // always project-file signals
+)
+
+// ParseStrategy parses a --strategy value ("" is auto)
+func ParseStrategy(value string) (Strategy, error) {
+	switch Strategy(strings.ToLower(strings.TrimSpace(value))) {
+	case "", StrategyAuto:
+		return StrategyAuto, nil
+	case StrategyReadme:
+		return StrategyReadme, nil
+	case StrategyFiles:
+		return StrategyFiles, nil
+	}
+	return "", fmt.Errorf("unknown strategy %q (supported: auto, readme, files)", value)
+}
+
+// ShouldUseReadmeWithStrategy applies a forced strategy, falling back to
+// the clarity threshold for auto. README-first is never chosen without a
+// README.
+func ShouldUseReadmeWithStrategy(readme *scanner.ReadmeInfo, threshold float64, strategy Strategy) bool {
+	switch strategy {
+	case StrategyReadme:
+		return readme != nil
+	case StrategyFiles:
+		return false
+	}
+	return ShouldUseReadmeWithThreshold(readme, threshold)
 }
 
 // BuildPlanPrompt creates the prompt for plan generation
@@ -84,7 +158,7 @@ func (b *PromptBuilder) BuildPlanPrompt(ctx *PlanContext) string {
 		sb.WriteString("## README Content (PRIMARY SOURCE)\n")
 		sb.WriteString("The README is clear and should be your primary guide for installation steps.\n\n")
 		sb.WriteString("```markdown\n")
-		sb.WriteString(b.truncateContent(ctx.ReadmeInfo.Content, 8000))
+		sb.WriteString(b.readmeExcerpt(ctx.ReadmeInfo, readmeBudget))
 		sb.WriteString("\n```\n\n")
 
 		// Include sections info
@@ -142,7 +216,7 @@ func (b *PromptBuilder) getOutputSchema() string {
 Return ONLY valid JSON matching this exact schema:
 {
   "version": "1",
-  "project_type": "docker|node|python|go|rust|mixed",
+  "project_type": "docker|node|python|go|rust|java|dotnet|ruby|php|elixir|cpp|kubernetes|mixed",
   "prerequisites": [
     {"name": "tool_name", "reason": "why needed", "min_version": "optional"}
   ],
@@ -152,14 +226,29 @@ Return ONLY valid JSON matching this exact schema:
       "cmd": "command to run",
       "cwd": ".",
       "risk": "low|medium|high|critical",
-      "requires_sudo": false
+      "requires_sudo": false,
+      "timeout": 0,
+      "depends_on": [],
+      "export_env": {}
     }
   ],
   "env": {},
   "ports": [],
-  "notes": ["any important notes"]
+  "notes": ["any important notes"],
+  "health_check": {"url": "http://localhost:PORT/", "expected_status": 200, "timeout": 30}
 }
 
+"health_check" is optional: include it only for web apps, pointing at a URL
+that responds once the app started by the "run" step is serving.
+"export_env" is optional: variables a step sets for the steps after it.
+"timeout" is optional: seconds a slow step needs (e.g. 900 when the README says
+the build takes about 10 minutes); 0 uses the default of 5 minutes, and every
+step is capped at 30 minutes.
+
+"depends_on" is optional: list the IDs of earlier steps a step needs. Once any
+step has depends_on, steps without it may run in parallel with earlier steps,
+so either leave it out everywhere or set it on every step that needs another.
+
 RISK LEVELS:
 - low: Safe read-only or local operations
 - medium: Modifies local files (npm install, pip install --user)
@@ -175,6 +264,91 @@ STEP ID CONVENTIONS:
 `
 }
 
+// EstimateTokens roughly estimates the token count of a prompt
+// (4 characters per token)
+func (b *PromptBuilder) EstimateTokens(prompt string) int {
+	return (utf8.RuneCountInString(prompt) + 3) / 4
+}
+
+// readmeBudget is the number of README characters included in the prompt
+const readmeBudget = 8000
+
+// sectionPriority orders the section kinds kept when the README is too long
+var sectionPriority = []string{scanner.SectionInstall, scanner.SectionQuickStart, scanner.SectionBuild, scanner.SectionUsage}
+
+// minSectionExcerpt is the smallest useful piece of a truncated section
+const minSectionExcerpt = 400
+
+// readmeExcerpt returns the README content to include in the prompt. A README
+// longer than maxLen keeps its installation, quick start, build and usage
+// sections (in that priority) and fills any remaining room with the
+// introduction, instead of cutting the content at maxLen.
+func (b *PromptBuilder) readmeExcerpt(readme *scanner.ReadmeInfo, maxLen int) string {
+	content := readme.Content
+	if len(content) <= maxLen {
+		return content
+	}
+
+	// Top-level sections of a relevant kind (a build subsection of an
+	// installation section is already covered by its parent)
+	var relevant []scanner.SectionRange
+	for _, section := range readme.SectionRanges {
+		if section.Kind == "" {
+			continue
+		}
+		if n := len(relevant); n > 0 && section.Start < relevant[n-1].End {
+			continue
+		}
+		relevant = append(relevant, section)
+	}
+	if len(relevant) == 0 {
+		return b.truncateContent(content, maxLen)
+	}
+
+	// Pick sections by priority within the budget
+	type excerpt struct {
+		start int
+		text  string
+	}
+	var picked []excerpt
+	remaining := maxLen
+	for _, kind := range sectionPriority {
+		for _, section := range relevant {
+			if section.Kind != kind || remaining < minSectionExcerpt {
+				continue
+			}
+			text := strings.TrimRight(readme.SectionContent(section), "\n")
+			if len(text) > remaining {
+				text = b.truncateContent(text, remaining)
+			}
+			picked = append(picked, excerpt{section.Start, text})
+			remaining -= len(text)
+		}
+	}
+
+	// The introduction (up to the first header after the title) names the project
+	introEnd := relevant[0].Start
+	for _, section := range readme.SectionRanges {
+		if section.Start > 0 && section.Start < introEnd {
+			introEnd = section.Start
+			break
+		}
+	}
+	if intro := strings.TrimSpace(content[:introEnd]); intro != "" && remaining >= minSectionExcerpt {
+		if len(intro) > remaining {
+			intro = b.truncateContent(intro, remaining)
+		}
+		picked = append(picked, excerpt{0, intro})
+	}
+
+	sort.Slice(picked, func(i, j int) bool { return picked[i].start < picked[j].start })
+	parts := make([]string, len(picked))
+	for i, p := range picked {
+		parts[i] = p.text
+	}
+	return strings.Join(parts, "\n\n... (other sections omitted)\n\n")
+}
+
 func (b *PromptBuilder) truncateContent(content string, maxLen int) string {
 	if len(content) <= maxLen {
 		return content
@@ -195,6 +369,10 @@ func (b *PromptBuilder) formatProfile(p *scanner.ProjectProfile) string {
 
 	sb.WriteString(fmt.Sprintf("- **Primary Stack**: %s\n", p.Stack))
 
+	if p.Framework != "" {
+		sb.WriteString(fmt.Sprintf("- **Framework**: %s (use its standard dev server command to run)\n", p.Framework))
+	}
+
 	if len(p.Languages) > 0 {
 		sb.WriteString(fmt.Sprintf("- **Languages**: %s\n", strings.Join(p.Languages, ", ")))
 	}
@@ -211,6 +389,11 @@ func (b *PromptBuilder) formatProfile(p *scanner.ProjectProfile) string {
 		sb.WriteString(fmt.Sprintf("- **Package Files**: %s\n", strings.Join(p.Packages, ", ")))
 	}
 
+	if len(p.GoModules) > 0 {
+		sb.WriteString(fmt.Sprintf("- **Go Workspace**: go.work uses modules %s (./... does not span modules: build each one with its directory as cwd)\n",
+			strings.Join(p.GoModules, ", ")))
+	}
+
 	if len(p.Signals) > 0 {
 		// Show first 10 signals
 		signals := p.Signals
@@ -5,6 +5,11 @@ package llm
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +19,15 @@ const (
 	MaxTimeout     = 300 * time.Second
 )
 
+// Retry defaults: one retry after a 500ms pause (2 attempts in total)
+const (
+	DefaultMaxRetries   = 1
+	DefaultRetryBackoff = 500 * time.Millisecond
+	NoRetries           = -1               // ProviderConfig.MaxRetries value that disables retries
+	MaxRetryDelay       = 60 * time.Second // longest pause between attempts, including Retry-After
+	MinRateLimitDelay   = 2 * time.Second  // pause after a 429 without Retry-After
+)
+
 // Provider errors
 var (
 	ErrUnknownProvider = errors.New("unknown provider type")
@@ -22,19 +36,130 @@ var (
 	ErrInvalidJSON     = errors.New("LLM returned invalid JSON")
 	ErrTimeout         = errors.New("LLM request timed out")
 	ErrEmptyResponse   = errors.New("LLM returned empty response")
+	ErrRateLimited     = errors.New("LLM provider rate limited the request")
 
 	// Deprecated
 	ErrCopilotNotFound = errors.New("GitHub Copilot is deprecated - use anthropic, openai, or mock")
 )
 
+// AuthScheme controls how the HTTP provider sends the token
+type AuthScheme string
+
+const (
+	// AuthBearer sends "Authorization: Bearer <token>" (default)
+	AuthBearer AuthScheme = "bearer"
+	// AuthRaw sends "Authorization: <token>"
+	AuthRaw AuthScheme = "raw"
+	// AuthNone sends no Authorization header (use Headers instead)
+	AuthNone AuthScheme = "none"
+)
+
+// ParseAuthScheme converts a config value into an AuthScheme
+func ParseAuthScheme(value string) (AuthScheme, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "bearer":
+		return AuthBearer, nil
+	case "raw", "token":
+		return AuthRaw, nil
+	case "none":
+		return AuthNone, nil
+	default:
+		return AuthBearer, fmt.Errorf("unknown auth scheme %q (supported: bearer, raw, none)", value)
+	}
+}
+
+// HTTPFormat is the request and response shape the HTTP provider speaks
+type HTTPFormat string
+
+const (
+	// FormatOpenAI sends a messages array starting with a system message (default)
+	FormatOpenAI HTTPFormat = "openai"
+	// FormatAnthropic sends a top-level system prompt and user messages, like
+	// the Anthropic Messages API
+	FormatAnthropic HTTPFormat = "anthropic"
+	// FormatOllamaGenerate sends a single prompt, like Ollama's /api/generate
+	FormatOllamaGenerate HTTPFormat = "ollama-generate"
+)
+
+// ParseHTTPFormat converts a config value into an HTTPFormat
+func ParseHTTPFormat(value string) (HTTPFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "openai":
+		return FormatOpenAI, nil
+	case "anthropic":
+		return FormatAnthropic, nil
+	case "ollama-generate", "ollama":
+		return FormatOllamaGenerate, nil
+	default:
+		return FormatOpenAI, fmt.Errorf("unknown HTTP format %q (supported: openai, anthropic, ollama-generate)", value)
+	}
+}
+
 // ProviderConfig holds configuration for LLM providers
 type ProviderConfig struct {
-	Type     ProviderType  // Provider type: anthropic, openai, mistral, ollama, http, mock
-	Endpoint string        // HTTP endpoint URL (for HTTP/Ollama provider)
-	Model    string        // Model name (optional)
-	Token    string        // Authentication token
-	Timeout  time.Duration // Request timeout
-	Verbose  bool          // Enable verbose output
+	Type       ProviderType      // Provider type: anthropic, openai, mistral, cohere, ollama, http, mock
+	Endpoint   string            // HTTP endpoint URL (for HTTP/Ollama provider)
+	Model      string            // Model name (optional)
+	Token      string            // Authentication token
+	Timeout    time.Duration     // Request timeout
+	Verbose    bool              // Enable verbose output
+	Headers    map[string]string // Extra request headers (HTTP provider)
+	AuthScheme AuthScheme        // How the token is sent (HTTP provider, default bearer)
+	Format     HTTPFormat        // Request body shape (HTTP provider, default openai)
+
+	MaxRetries   int           // Retries after the first attempt (0 = DefaultMaxRetries, NoRetries = none)
+	RetryBackoff time.Duration // Pause before the first retry, doubled for each retry (0 = default)
+
+	Out   io.Writer // Receives verbose messages such as retries (nil = os.Stderr)
+	Trace io.Writer // Receives raw requests and responses, credentials redacted (nil = off, -vvv)
+}
+
+// Logf prints a verbose message to Out
+func (c *ProviderConfig) Logf(format string, args ...any) {
+	out := c.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, format, args...)
+}
+
+// Attempts returns the total number of attempts per request
+func (c *ProviderConfig) Attempts() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 1
+	case c.MaxRetries == 0:
+		return 1 + DefaultMaxRetries
+	}
+	return 1 + c.MaxRetries
+}
+
+// RetryDelay returns the exponential backoff pause before retry n (1-based)
+func (c *ProviderConfig) RetryDelay(n int) time.Duration {
+	delay := c.RetryBackoff
+	if delay <= 0 {
+		delay = DefaultRetryBackoff
+	}
+	for i := 1; i < n && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	return delay
+}
+
+// ParseMaxRetries converts a max_retries setting, where 0 means no retries,
+// into a ProviderConfig.MaxRetries value
+func ParseMaxRetries(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid retry count %q (want a number >= 0)", value)
+	}
+	if n == 0 {
+		return NoRetries, nil
+	}
+	return n, nil
 }
 
 // Validate checks if the provider config is valid
@@ -44,7 +169,7 @@ func (c *ProviderConfig) Validate() error {
 		if c.Endpoint == "" {
 			return ErrMissingEndpoint
 		}
-	case ProviderOpenAI, ProviderAnthropic, ProviderMistral:
+	case ProviderOpenAI, ProviderAnthropic, ProviderMistral, ProviderCohere:
 		// Token validation happens in provider constructor
 	case ProviderOllama, ProviderMock:
 		// No validation required
@@ -74,6 +199,12 @@ func NewProvider(config *ProviderConfig) (Provider, error) {
 	return DefaultRegistry.Get(config), nil
 }
 
+// NewProviderWithInfo is NewProvider, recording fallbacks in info
+func NewProviderWithInfo(config *ProviderConfig, info *ProviderSelectionInfo) Provider {
+	DefaultRegistry.SetVerbose(config != nil && config.Verbose)
+	return DefaultRegistry.GetWithInfo(config, info)
+}
+
 // NewProviderWithFallback creates a provider that gracefully falls back to mock.
 // This is the recommended way to create providers for production use.
 func NewProviderWithFallback(config *ProviderConfig) Provider {
@@ -81,6 +212,27 @@ func NewProviderWithFallback(config *ProviderConfig) Provider {
 	return DefaultRegistry.Get(config)
 }
 
+// ParseHeaders parses a header list such as "X-Api-Key=abc, X-Team: infra".
+// Entries are comma-separated; each uses "=" or ":" between name and value.
+func ParseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		sep := strings.IndexAny(entry, "=:")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid header %q (expected Name=Value)", entry)
+		}
+
+		name := strings.TrimSpace(entry[:sep])
+		headers[name] = strings.TrimSpace(entry[sep+1:])
+	}
+	return headers, nil
+}
+
 // MaskToken returns a masked version of the token for logging
 func MaskToken(token string) string {
 	if token == "" {
@@ -11,8 +11,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"strings"
-	"time"
 
 	"github.com/sony-level/readme-runner/internal/llm"
 )
@@ -47,7 +45,8 @@ func NewAnthropicProvider(config *llm.ProviderConfig) (*AnthropicProvider, error
 	return &AnthropicProvider{
 		config: config,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: traceTransport(config),
 		},
 		builder: llm.NewPromptBuilder(),
 	}, nil
@@ -72,29 +71,13 @@ func (p *AnthropicProvider) Name() string {
 func (p *AnthropicProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
 	prompt := p.builder.BuildPlanPrompt(ctx)
 
-	var lastErr error
-	for attempt := 1; attempt <= 2; attempt++ {
-		plan, err := p.callAPI(prompt)
-		if err == nil {
-			return plan, nil
-		}
-		lastErr = err
-
-		if p.config.Verbose {
-			fmt.Printf("  [Anthropic] Attempt %d failed: %v\n", attempt, err)
-		}
-
-		if err == llm.ErrTimeout {
-			break
-		}
-		if strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "403") {
-			break
-		}
-
-		time.Sleep(500 * time.Millisecond)
+	plan, err := callWithRetries(p.config, "Anthropic", func() (*llm.RunPlan, error) {
+		return p.callAPI(prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic API failed: %w", err)
 	}
-
-	return nil, fmt.Errorf("Anthropic API failed: %w", lastErr)
+	return plan, nil
 }
 
 // AnthropicRequest is the request body for Anthropic API
@@ -166,7 +149,7 @@ func (p *AnthropicProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if llm.IsTimeout(err) {
 			return nil, llm.ErrTimeout
 		}
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -179,7 +162,7 @@ func (p *AnthropicProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, parseAnthropicError(resp.StatusCode, body)
+		return nil, withRetryAfter(resp, parseAnthropicError(resp.StatusCode, body))
 	}
 
 	return p.parseResponse(body)
@@ -0,0 +1,186 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Cohere API provider for plan generation
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+const (
+	CohereEndpoint     = "https://api.cohere.com/v1/chat"
+	DefaultCohereModel = "command-r"
+)
+
+// CohereProvider uses Cohere's chat API (Command-R models) to generate plans
+type CohereProvider struct {
+	config  *llm.ProviderConfig
+	client  *http.Client
+	builder *llm.PromptBuilder
+}
+
+// NewCohereProvider creates a new Cohere API provider
+func NewCohereProvider(config *llm.ProviderConfig) (*CohereProvider, error) {
+	token := getCohereToken(config)
+	if token == "" {
+		return nil, fmt.Errorf("Cohere API key not found (set COHERE_API_KEY or use --llm-token)")
+	}
+
+	config.Token = token
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = llm.DefaultTimeout
+	}
+
+	return &CohereProvider{
+		config: config,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: traceTransport(config),
+		},
+		builder: llm.NewPromptBuilder(),
+	}, nil
+}
+
+func getCohereToken(config *llm.ProviderConfig) string {
+	if config.Token != "" {
+		return config.Token
+	}
+	if token := os.Getenv("COHERE_API_KEY"); token != "" {
+		return token
+	}
+	return os.Getenv("RD_LLM_TOKEN")
+}
+
+// Name returns the provider name
+func (p *CohereProvider) Name() string {
+	return "cohere"
+}
+
+// GeneratePlan generates a RunPlan using Cohere API
+func (p *CohereProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
+	prompt := p.builder.BuildPlanPrompt(ctx)
+
+	plan, err := callWithRetries(p.config, "Cohere", func() (*llm.RunPlan, error) {
+		return p.callAPI(prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Cohere API failed: %w", err)
+	}
+	return plan, nil
+}
+
+// CohereRequest is the request body for Cohere's v1 chat API. The system
+// prompt goes in the preamble and the plan prompt is the user message.
+type CohereRequest struct {
+	Model       string  `json:"model"`
+	Message     string  `json:"message"`
+	Preamble    string  `json:"preamble,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+	MaxTokens   int     `json:"max_tokens,omitempty"`
+}
+
+// CohereResponse is the response from Cohere's v1 chat API
+type CohereResponse struct {
+	GenerationID string `json:"generation_id"`
+	Text         string `json:"text"`
+	FinishReason string `json:"finish_reason"`
+}
+
+func (p *CohereProvider) callAPI(prompt string) (*llm.RunPlan, error) {
+	model := p.config.Model
+	if model == "" {
+		model = DefaultCohereModel
+	}
+
+	reqBody := CohereRequest{
+		Model:       model,
+		Message:     prompt,
+		Preamble:    "You are an expert at analyzing software projects and generating installation/run plans. IMPORTANT: Respond with ONLY valid JSON, no markdown code blocks, no explanation text. Follow the exact schema provided.",
+		Temperature: 0.1,
+		MaxTokens:   2048,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	endpoint := CohereEndpoint
+	if p.config.Endpoint != "" {
+		endpoint = p.config.Endpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.config.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if llm.IsTimeout(err) {
+			return nil, llm.ErrTimeout
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, withRetryAfter(resp, parseCohereError(resp.StatusCode, body))
+	}
+
+	return p.parseResponse(body)
+}
+
+func parseCohereError(status int, body []byte) error {
+	var errResp struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Message != "" {
+		switch status {
+		case 401:
+			return fmt.Errorf("HTTP 401: invalid API key - check COHERE_API_KEY")
+		case 403:
+			return fmt.Errorf("HTTP 403: access forbidden - %s", errResp.Message)
+		case 429:
+			return fmt.Errorf("HTTP 429: rate limited - %s", errResp.Message)
+		default:
+			return fmt.Errorf("HTTP %d: %s", status, errResp.Message)
+		}
+	}
+	return fmt.Errorf("HTTP %d: %s", status, TruncateForError(string(body), 200))
+}
+
+func (p *CohereProvider) parseResponse(body []byte) (*llm.RunPlan, error) {
+	var resp CohereResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.Text == "" {
+		return nil, llm.ErrEmptyResponse
+	}
+
+	return ExtractPlanFromLLMContent(resp.Text)
+}
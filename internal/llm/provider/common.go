@@ -5,12 +5,108 @@ package provider
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sony-level/readme-runner/internal/llm"
 )
 
+// retryAfterError carries the delay a server asked for in Retry-After.
+// A 429 error matches llm.ErrRateLimited with errors.Is.
+type retryAfterError struct {
+	err         error
+	delay       time.Duration // 0 if the server sent no Retry-After
+	rateLimited bool
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+func (e *retryAfterError) Is(target error) bool {
+	return e.rateLimited && target == llm.ErrRateLimited
+}
+
+// withRetryAfter marks the error of a 429 response as rate limited and
+// attaches the Retry-After delay of a 429 or 503 response
+func withRetryAfter(resp *http.Response, err error) error {
+	delay := parseRetryAfter(resp.Header.Get("Retry-After"))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &retryAfterError{err: err, delay: delay, rateLimited: true}
+	case resp.StatusCode == http.StatusServiceUnavailable && delay > 0:
+		return &retryAfterError{err: err, delay: delay}
+	}
+	return err
+}
+
+// parseRetryAfter reads a Retry-After value in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// isAuthError reports whether an error is an authentication failure
+func isAuthError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "HTTP 401") || strings.Contains(msg, "HTTP 403")
+}
+
+// callWithRetries runs call up to config.Attempts() times, pausing with
+// exponential backoff (or the server's Retry-After) between attempts.
+// Timeouts and authentication errors are not retried; a rate-limited
+// request waits at least llm.MinRateLimitDelay.
+func callWithRetries(config *llm.ProviderConfig, label string, call func() (*llm.RunPlan, error)) (*llm.RunPlan, error) {
+	attempts := config.Attempts()
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		plan, err := call()
+		if err == nil {
+			return plan, nil
+		}
+		lastErr = err
+
+		last := err == llm.ErrTimeout || isAuthError(err) || attempt == attempts
+		rateLimited := errors.Is(err, llm.ErrRateLimited)
+
+		delay := config.RetryDelay(attempt)
+		var retryAfter *retryAfterError
+		if errors.As(err, &retryAfter) && retryAfter.delay > 0 {
+			delay = min(retryAfter.delay, llm.MaxRetryDelay)
+		} else if rateLimited {
+			delay = max(delay, llm.MinRateLimitDelay)
+		}
+
+		if config.Verbose {
+			if rateLimited && !last {
+				config.Logf("  [%s] Rate limited, retrying in %s (attempt %d of %d)\n", label, delay.Round(time.Millisecond), attempt+1, attempts)
+			} else {
+				config.Logf("  [%s] Attempt %d failed: %v\n", label, attempt, err)
+			}
+		}
+
+		if last {
+			break
+		}
+		time.Sleep(delay)
+	}
+
+	return nil, lastErr
+}
+
 // TruncateForError truncates a string for error messages
 func TruncateForError(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -33,7 +33,8 @@ func NewHTTPProvider(config *llm.ProviderConfig) *HTTPProvider {
 	return &HTTPProvider{
 		config: config,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: traceTransport(config),
 		},
 		builder: llm.NewPromptBuilder(),
 	}
@@ -48,26 +49,13 @@ func (p *HTTPProvider) Name() string {
 func (p *HTTPProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
 	prompt := p.builder.BuildPlanPrompt(ctx)
 
-	var lastErr error
-	for attempt := 1; attempt <= 2; attempt++ {
-		plan, err := p.callEndpoint(prompt)
-		if err == nil {
-			return plan, nil
-		}
-		lastErr = err
-
-		if p.config.Verbose {
-			fmt.Printf("  [HTTP] Attempt %d failed: %v\n", attempt, err)
-		}
-
-		if err == llm.ErrTimeout {
-			break
-		}
-
-		time.Sleep(500 * time.Millisecond)
+	plan, err := callWithRetries(p.config, "HTTP", func() (*llm.RunPlan, error) {
+		return p.callEndpoint(prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("HTTP LLM failed after retries: %w", err)
 	}
-
-	return nil, fmt.Errorf("HTTP LLM failed after retries: %w", lastErr)
+	return plan, nil
 }
 
 // HTTPRequest is the request body sent to the LLM endpoint
@@ -103,26 +91,69 @@ type HTTPResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
-func (p *HTTPProvider) callEndpoint(prompt string) (*llm.RunPlan, error) {
-	reqBody := HTTPRequest{
-		Model: p.config.Model,
-		Messages: []HTTPMessage{
-			{
-				Role:    "system",
-				Content: "You are an expert at analyzing software projects. Respond ONLY with valid JSON, no other text.",
+// OllamaGenerateRequest is the request body of Ollama's /api/generate
+type OllamaGenerateRequest struct {
+	Model   string         `json:"model,omitempty"`
+	System  string         `json:"system,omitempty"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Options *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaGenerateResponse is the response of Ollama's /api/generate
+type OllamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// httpSystemPrompt is the system instruction sent in every format
+const httpSystemPrompt = "You are an expert at analyzing software projects. Respond ONLY with valid JSON, no other text."
+
+// requestBody encodes the prompt in the configured format
+func (p *HTTPProvider) requestBody(prompt string) ([]byte, error) {
+	var reqBody any
+	switch p.config.Format {
+	case llm.FormatAnthropic:
+		reqBody = AnthropicRequest{
+			Model:       p.config.Model,
+			MaxTokens:   2000,
+			System:      httpSystemPrompt,
+			Messages:    []AnthropicMessage{{Role: "user", Content: prompt}},
+			Temperature: 0.1,
+		}
+	case llm.FormatOllamaGenerate:
+		reqBody = OllamaGenerateRequest{
+			Model:   p.config.Model,
+			System:  httpSystemPrompt,
+			Prompt:  prompt,
+			Stream:  false,
+			Options: &OllamaOptions{Temperature: 0.1, NumPredict: 2000},
+		}
+	default:
+		reqBody = HTTPRequest{
+			Model: p.config.Model,
+			Messages: []HTTPMessage{
+				{
+					Role:    "system",
+					Content: httpSystemPrompt,
+				},
+				{
+					Role:    "user",
+					Content: prompt,
+				},
 			},
-			{
-				Role:    "user",
-				Content: prompt,
+			Options: HTTPOptions{
+				Temperature: 0.1,
+				MaxTokens:   2000,
 			},
-		},
-		Options: HTTPOptions{
-			Temperature: 0.1,
-			MaxTokens:   2000,
-		},
+		}
 	}
+	return json.Marshal(reqBody)
+}
 
-	jsonBody, err := json.Marshal(reqBody)
+func (p *HTTPProvider) callEndpoint(prompt string) (*llm.RunPlan, error) {
+	jsonBody, err := p.requestBody(prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
@@ -136,13 +167,14 @@ func (p *HTTPProvider) callEndpoint(prompt string) (*llm.RunPlan, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if p.config.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	if p.config.Format == llm.FormatAnthropic {
+		req.Header.Set("anthropic-version", AnthropicAPIVersion)
 	}
+	p.applyHeaders(req)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if llm.IsTimeout(err) {
 			return nil, llm.ErrTimeout
 		}
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -155,13 +187,67 @@ func (p *HTTPProvider) callEndpoint(prompt string) (*llm.RunPlan, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, withRetryAfter(resp, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body)))
 	}
 
 	return p.parseResponse(body)
 }
 
+// applyHeaders sets the configured extra headers and Authorization header.
+// An explicit Authorization entry in Headers wins over AuthScheme.
+func (p *HTTPProvider) applyHeaders(req *http.Request) {
+	for name, value := range p.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if p.config.Token == "" || req.Header.Get("Authorization") != "" {
+		return
+	}
+
+	switch p.config.AuthScheme {
+	case llm.AuthNone:
+		// Token is expected to travel in a custom header
+	case llm.AuthRaw:
+		req.Header.Set("Authorization", p.config.Token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	}
+}
+
 func (p *HTTPProvider) parseResponse(body []byte) (*llm.RunPlan, error) {
+	switch p.config.Format {
+	case llm.FormatAnthropic:
+		var resp AnthropicResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("LLM error: %s", resp.Error.Message)
+		}
+		var text strings.Builder
+		for _, block := range resp.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		if text.Len() == 0 {
+			return nil, fmt.Errorf("%w: no text content in response", llm.ErrInvalidJSON)
+		}
+		return ExtractPlanFromLLMContent(text.String())
+	case llm.FormatOllamaGenerate:
+		var resp OllamaGenerateResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if resp.Error != "" {
+			return nil, fmt.Errorf("LLM error: %s", resp.Error)
+		}
+		if resp.Response == "" {
+			return nil, fmt.Errorf("%w: empty response", llm.ErrInvalidJSON)
+		}
+		return ExtractPlanFromLLMContent(resp.Response)
+	}
+
 	var httpResp HTTPResponse
 	if err := json.Unmarshal(body, &httpResp); err == nil {
 		if httpResp.Error != "" {
@@ -224,7 +310,7 @@ func extractJSONFromText(text string) string {
 
 // HTTPProviderHealthCheck checks if the endpoint is reachable
 func HTTPProviderHealthCheck(endpoint string, timeout time.Duration) error {
-	client := &http.Client{Timeout: timeout}
+	client := llm.NewHTTPClient(timeout)
 
 	resp, err := client.Head(endpoint)
 	if err != nil {
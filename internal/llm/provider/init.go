@@ -33,6 +33,10 @@ func RegisterProviders() {
 		return NewMistralProvider(config)
 	})
 
+	reg.Register(llm.ProviderCohere, func(config *llm.ProviderConfig) (llm.Provider, error) {
+		return NewCohereProvider(config)
+	})
+
 	reg.Register(llm.ProviderOllama, func(config *llm.ProviderConfig) (llm.Provider, error) {
 		return NewOllamaProvider(config)
 	})
@@ -11,8 +11,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"strings"
-	"time"
 
 	"github.com/sony-level/readme-runner/internal/llm"
 )
@@ -46,7 +44,8 @@ func NewMistralProvider(config *llm.ProviderConfig) (*MistralProvider, error) {
 	return &MistralProvider{
 		config: config,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: traceTransport(config),
 		},
 		builder: llm.NewPromptBuilder(),
 	}, nil
@@ -71,29 +70,13 @@ func (p *MistralProvider) Name() string {
 func (p *MistralProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
 	prompt := p.builder.BuildPlanPrompt(ctx)
 
-	var lastErr error
-	for attempt := 1; attempt <= 2; attempt++ {
-		plan, err := p.callAPI(prompt)
-		if err == nil {
-			return plan, nil
-		}
-		lastErr = err
-
-		if p.config.Verbose {
-			fmt.Printf("  [Mistral] Attempt %d failed: %v\n", attempt, err)
-		}
-
-		if err == llm.ErrTimeout {
-			break
-		}
-		if strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "403") {
-			break
-		}
-
-		time.Sleep(500 * time.Millisecond)
+	plan, err := callWithRetries(p.config, "Mistral", func() (*llm.RunPlan, error) {
+		return p.callAPI(prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Mistral API failed: %w", err)
 	}
-
-	return nil, fmt.Errorf("Mistral API failed: %w", lastErr)
+	return plan, nil
 }
 
 // MistralRequest is the request body for Mistral API
@@ -163,7 +146,7 @@ func (p *MistralProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if llm.IsTimeout(err) {
 			return nil, llm.ErrTimeout
 		}
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -176,7 +159,7 @@ func (p *MistralProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, parseMistralError(resp.StatusCode, body)
+		return nil, withRetryAfter(resp, parseMistralError(resp.StatusCode, body))
 	}
 
 	return p.parseResponse(body)
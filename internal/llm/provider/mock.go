@@ -4,7 +4,12 @@
 package provider
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/scanner"
 )
 
 // MockProvider returns fixed plans for testing
@@ -43,7 +48,58 @@ func (p *MockProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error)
 		stack = ctx.Profile.Stack
 	}
 
-	return p.defaultPlanForStack(stack, ctx), nil
+	plan := p.defaultPlanForStack(stack, ctx)
+	if ctx.Profile != nil && !ctx.UseReadme {
+		applyTaskfile(plan, ctx.Profile.Tasks)
+		applyProcfile(plan, ctx.Profile.Processes)
+	}
+	return plan, nil
+}
+
+// taskfileRunTasks are the tasks tried, in order, for the run step when
+// the Taskfile has no default task
+var taskfileRunTasks = []string{"run", "start", "dev", "serve"}
+
+// applyTaskfile replaces the stack's steps with the install, build and run
+// tasks of the project's Taskfile, when it defines a build or run task.
+// The stack's prerequisites and ports are kept.
+func applyTaskfile(plan *llm.RunPlan, tasks []string) {
+	var install, run string
+	for _, name := range []string{"install", "deps", "setup"} {
+		if containsItem(tasks, name) {
+			install = name
+			break
+		}
+	}
+	if containsItem(tasks, "default") {
+		run = "task"
+	} else {
+		for _, name := range taskfileRunTasks {
+			if containsItem(tasks, name) {
+				run = "task " + name
+				break
+			}
+		}
+	}
+	hasBuild := containsItem(tasks, "build")
+	if !hasBuild && run == "" {
+		return
+	}
+
+	var steps []llm.Step
+	if install != "" {
+		steps = append(steps, llm.Step{ID: "install", Cmd: "task " + install, Cwd: ".", Risk: llm.RiskMedium, Description: "Install dependencies (Taskfile)"})
+	}
+	if hasBuild {
+		steps = append(steps, llm.Step{ID: "build", Cmd: "task build", Cwd: ".", Risk: llm.RiskLow, Description: "Build the project (Taskfile)"})
+	}
+	if run != "" {
+		steps = append(steps, llm.Step{ID: "run", Cmd: run, Cwd: ".", Risk: llm.RiskLow, Description: "Run the project (Taskfile)"})
+	}
+
+	plan.Steps = steps
+	plan.Prerequisites = append(plan.Prerequisites, llm.Prerequisite{Name: "task", Reason: "Taskfile.yml runs the build (go-task)"})
+	plan.Notes = append(plan.Notes, "Steps use the Taskfile tasks: "+strings.Join(tasks, ", "))
 }
 
 func (p *MockProvider) defaultPlanForStack(stack string, ctx *llm.PlanContext) *llm.RunPlan {
@@ -58,6 +114,20 @@ func (p *MockProvider) defaultPlanForStack(stack string, ctx *llm.PlanContext) *
 		return p.goPlan(ctx)
 	case "rust":
 		return p.rustPlan(ctx)
+	case "java":
+		return p.javaPlan(ctx)
+	case "dotnet":
+		return p.dotnetPlan(ctx)
+	case "ruby":
+		return p.rubyPlan(ctx)
+	case "php":
+		return p.phpPlan(ctx)
+	case "elixir":
+		return p.elixirPlan(ctx)
+	case "cpp":
+		return p.cppPlan(ctx)
+	case "kubernetes":
+		return p.k8sPlan(ctx)
 	default:
 		return p.unknownPlan(ctx)
 	}
@@ -107,6 +177,10 @@ func (p *MockProvider) nodePlan(ctx *llm.PlanContext) *llm.RunPlan {
 	pkgManager := "npm"
 	installCmd := "npm install"
 
+	if ctx.Profile != nil && containsItem(ctx.Profile.Tools, "deno") && !containsItem(ctx.Profile.Packages, "package.json") {
+		return p.denoPlan(ctx)
+	}
+
 	if ctx.Profile != nil {
 		for _, tool := range ctx.Profile.Tools {
 			switch tool {
@@ -140,6 +214,18 @@ func (p *MockProvider) nodePlan(ctx *llm.PlanContext) *llm.RunPlan {
 		}
 	}
 
+	runCmd := pkgManager + " start"
+	if pkgManager == "bun" {
+		// Run the package.json script explicitly (Bun.serve defaults to 3000)
+		runCmd = "bun run start"
+	}
+	notes := []string{"Using " + pkgManager + " package manager"}
+	if ctx.Profile != nil && ctx.Profile.Framework == scanner.FrameworkNextJS {
+		// "next start" needs a production build; the dev server does not
+		runCmd = pkgManager + " run dev"
+		notes = append(notes, "Next.js project: starting the dev server")
+	}
+
 	return &llm.RunPlan{
 		Version:     "1",
 		ProjectType: "node",
@@ -149,11 +235,30 @@ func (p *MockProvider) nodePlan(ctx *llm.PlanContext) *llm.RunPlan {
 		},
 		Steps: []llm.Step{
 			{ID: "install", Cmd: installCmd, Cwd: ".", Risk: llm.RiskMedium},
-			{ID: "run", Cmd: pkgManager + " start", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "run", Cmd: runCmd, Cwd: ".", Risk: llm.RiskLow},
 		},
 		Env:   make(map[string]string),
 		Ports: []int{3000},
-		Notes: []string{"Using " + pkgManager + " package manager"},
+		Notes: notes,
+	}
+}
+
+// denoPlan runs the "start" task of deno.json; Deno.serve listens on 8000
+// by default
+func (p *MockProvider) denoPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "deno", Reason: "Deno runtime required (deno.json detected)"},
+		},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "deno install", Cwd: ".", Risk: llm.RiskMedium},
+			{ID: "run", Cmd: "deno task start", Cwd: ".", Risk: llm.RiskLow},
+		},
+		Env:   make(map[string]string),
+		Ports: []int{8000},
+		Notes: []string{"Deno project: dependencies are cached by deno install"},
 	}
 }
 
@@ -210,6 +315,11 @@ func (p *MockProvider) pythonPlan(ctx *llm.PlanContext) *llm.RunPlan {
 				if entryPoint == "" {
 					entryPoint = "wsgi"
 				}
+			case "asgi.py":
+				// ASGI application (Starlette/Django async)
+				if entryPoint == "" {
+					entryPoint = "asgi"
+				}
 			}
 		}
 	}
@@ -222,8 +332,38 @@ func (p *MockProvider) pythonPlan(ctx *llm.PlanContext) *llm.RunPlan {
 		Name: "python", Reason: "Python runtime required", MinVersion: "3.8",
 	})
 
-	// Determine run command based on entry point
+	framework := ""
+	asgiApp := ""
+	if ctx.Profile != nil {
+		framework = ctx.Profile.Framework
+		asgiApp = ctx.Profile.ASGIApp
+	}
+
+	// ASGI apps need an ASGI server; "python main.py" usually starts nothing
+	uvicornCmd := func(pythonBin, app string) string {
+		return pythonBin + " -m uvicorn " + app + " --host 0.0.0.0 --port 8000"
+	}
+
+	// Determine run command based on framework, then entry point
 	getRunCmd := func(pythonBin string) (string, bool) {
+		switch framework {
+		case scanner.FrameworkDjango:
+			return pythonBin + " manage.py runserver", true
+		case scanner.FrameworkFastAPI:
+			if asgiApp == "" {
+				asgiApp = "main:app"
+				if entryPoint == "app.py" {
+					asgiApp = "app:app"
+				}
+			}
+			return uvicornCmd(pythonBin, asgiApp), true
+		case scanner.FrameworkFlask:
+			return pythonBin + " -m flask run", true
+		}
+		if asgiApp != "" {
+			return uvicornCmd(pythonBin, asgiApp), true
+		}
+
 		switch {
 		case entryPoint == "manage.py runserver":
 			return pythonBin + " manage.py runserver", true
@@ -233,6 +373,8 @@ func (p *MockProvider) pythonPlan(ctx *llm.PlanContext) *llm.RunPlan {
 			return pythonBin + " .", true // Run as package
 		case entryPoint == "wsgi":
 			return pythonBin + " -m flask run", true
+		case entryPoint == "asgi":
+			return uvicornCmd(pythonBin, "asgi:application"), true
 		default:
 			return "", false // No entry point detected
 		}
@@ -297,7 +439,9 @@ func (p *MockProvider) pythonPlan(ctx *llm.PlanContext) *llm.RunPlan {
 	}
 
 	// If no entry point detected, add a helpful note
-	if entryPoint == "" {
+	if framework != "" {
+		notes = append(notes, "Detected framework: "+framework)
+	} else if entryPoint == "" {
 		notes = append(notes, "No entry point detected - check README for run instructions")
 	}
 
@@ -313,6 +457,11 @@ func (p *MockProvider) pythonPlan(ctx *llm.PlanContext) *llm.RunPlan {
 }
 
 func (p *MockProvider) goPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	if ctx.Profile != nil {
+		if plan := goWorkspacePlan(ctx.Profile.GoModules); plan != nil {
+			return plan
+		}
+	}
 	return &llm.RunPlan{
 		Version:     "1",
 		ProjectType: "go",
@@ -329,6 +478,42 @@ func (p *MockProvider) goPlan(ctx *llm.PlanContext) *llm.RunPlan {
 	}
 }
 
+// goWorkspacePlan builds each module of a go.work workspace in its own
+// directory: ./... only matches the packages of the module it is run in,
+// and the workspace root often has no go.mod at all. It returns nil without
+// modules inside the repository.
+func goWorkspacePlan(modules []string) *llm.RunPlan {
+	steps := make([]llm.Step, 0, len(modules))
+	for _, dir := range modules {
+		// Modules outside the repository cannot be a step's cwd
+		if dir == ".." || strings.HasPrefix(dir, "../") {
+			continue
+		}
+		id := "build"
+		if dir != "." {
+			id = "build-" + strings.ReplaceAll(dir, "/", "-")
+		}
+		steps = append(steps, llm.Step{ID: id, Cmd: "go build ./...", Cwd: dir, Risk: llm.RiskLow})
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "go", Reason: "Go compiler required (go.work needs Go 1.18+)", MinVersion: "1.21"},
+		},
+		Steps: steps,
+		Env:   make(map[string]string),
+		Ports: []int{},
+		Notes: []string{
+			"Go workspace (go.work) with modules: " + strings.Join(modules, ", "),
+			"Each module is built in its own directory; run a command with go run from the module that provides it",
+		},
+	}
+}
+
 func (p *MockProvider) rustPlan(ctx *llm.PlanContext) *llm.RunPlan {
 	return &llm.RunPlan{
 		Version:     "1",
@@ -347,6 +532,266 @@ func (p *MockProvider) rustPlan(ctx *llm.PlanContext) *llm.RunPlan {
 	}
 }
 
+func (p *MockProvider) javaPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	var hasGradle, hasGradlew, hasMvnw bool
+	if ctx.Profile != nil {
+		for _, signal := range ctx.Profile.Signals {
+			switch signal {
+			case "build.gradle", "build.gradle.kts":
+				hasGradle = true
+			case "gradlew":
+				hasGradlew = true
+			case "mvnw":
+				hasMvnw = true
+			}
+		}
+	}
+
+	prereqs := []llm.Prerequisite{
+		{Name: "java", Reason: "Java runtime and compiler required"},
+	}
+
+	// Prefer the project's wrapper: it pins the build tool version, so the
+	// system gradle/mvn is only required when no wrapper is checked in
+	var buildCmd string
+	var notes []string
+	switch {
+	case hasGradlew && (hasGradle || !hasMvnw):
+		buildCmd = "./gradlew build"
+		notes = append(notes, "Using the Gradle wrapper (gradlew)")
+	case hasMvnw:
+		buildCmd = "./mvnw package"
+		notes = append(notes, "Using the Maven wrapper (mvnw)")
+	case hasGradle:
+		buildCmd = "gradle build"
+		prereqs = append(prereqs, llm.Prerequisite{Name: "gradle", Reason: "Gradle build tool (no gradlew wrapper found)"})
+		notes = append(notes, "Gradle project without a wrapper")
+	default:
+		buildCmd = "mvn package"
+		prereqs = append(prereqs, llm.Prerequisite{Name: "maven", Reason: "Maven build tool (no mvnw wrapper found)"})
+		notes = append(notes, "Maven project without a wrapper")
+	}
+	notes = append(notes, "Built artifacts are in build/libs (Gradle) or target (Maven) - check README for run instructions")
+
+	return &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "java",
+		Prerequisites: prereqs,
+		Steps: []llm.Step{
+			{ID: "build", Cmd: buildCmd, Cwd: ".", Risk: llm.RiskMedium, Description: "Build the project"},
+		},
+		Env:   make(map[string]string),
+		Ports: []int{},
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) dotnetPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	notes := []string{".NET project using the dotnet CLI"}
+
+	if ctx.Profile != nil {
+		for _, pkg := range ctx.Profile.Packages {
+			if strings.HasSuffix(pkg, ".sln") {
+				notes = append(notes, "Solution file detected - use 'dotnet run --project <path>' if the startup project is not at the root")
+				break
+			}
+		}
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "dotnet",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "dotnet", Reason: ".NET SDK required"},
+		},
+		Steps: []llm.Step{
+			{ID: "restore", Cmd: "dotnet restore", Cwd: ".", Risk: llm.RiskMedium},
+			{ID: "build", Cmd: "dotnet build --no-restore", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "run", Cmd: "dotnet run --no-build", Cwd: ".", Risk: llm.RiskLow},
+		},
+		Env:   make(map[string]string),
+		Ports: []int{},
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) rubyPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	steps := []llm.Step{
+		{ID: "install", Cmd: "bundle install", Cwd: ".", Risk: llm.RiskMedium, Description: "Install gems from the Gemfile"},
+	}
+	notes := []string{"Ruby project using Bundler"}
+	ports := []int{}
+
+	if ctx.Profile != nil && ctx.Profile.Framework == scanner.FrameworkRails {
+		// db:setup creates the database, loads the schema and seeds it
+		steps = append(steps,
+			llm.Step{ID: "db-setup", Cmd: "bin/rails db:setup", Cwd: ".", Risk: llm.RiskMedium, Description: "Create, load and seed the database"},
+			llm.Step{ID: "run", Cmd: "bin/rails server", Cwd: ".", Risk: llm.RiskLow, Description: "Start the Rails server (Puma)"},
+		)
+		notes = append(notes, "Rails project: serving on http://localhost:3000",
+			"db:setup uses config/database.yml: the database server must be running")
+		ports = []int{3000}
+	} else {
+		notes = append(notes, "No Rails app found - check README for run instructions")
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "ruby",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "ruby", Reason: "Ruby runtime required"},
+			{Name: "bundler", Reason: "Bundler for Gemfile dependencies"},
+		},
+		Steps: steps,
+		Env:   make(map[string]string),
+		Ports: ports,
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) phpPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	isLaravel := false
+	if ctx.Profile != nil {
+		for _, signal := range ctx.Profile.Signals {
+			if signal == "artisan" {
+				isLaravel = true
+				break
+			}
+		}
+	}
+
+	runCmd := "php -S localhost:8000 -t public"
+	notes := []string{"PHP project using Composer", "Serving public/ with the built-in PHP server"}
+	if isLaravel {
+		runCmd = "php artisan serve"
+		notes = []string{"Laravel project (artisan detected)"}
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "php",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "php", Reason: "PHP runtime required"},
+			{Name: "composer", Reason: "Package manager for dependencies"},
+		},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "composer install", Cwd: ".", Risk: llm.RiskMedium},
+			{ID: "run", Cmd: runCmd, Cwd: ".", Risk: llm.RiskLow},
+		},
+		Env:   make(map[string]string),
+		Ports: []int{8000},
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) elixirPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	steps := []llm.Step{
+		{ID: "deps", Cmd: "mix deps.get", Cwd: ".", Risk: llm.RiskMedium, Description: "Fetch Mix dependencies"},
+	}
+	notes := []string{"Elixir project using Mix"}
+	ports := []int{}
+
+	if ctx.Profile != nil && ctx.Profile.Framework == scanner.FrameworkPhoenix {
+		steps = append(steps, llm.Step{
+			ID: "run", Cmd: "mix phx.server", Cwd: ".", Risk: llm.RiskLow, Description: "Start the Phoenix server",
+		})
+		notes = append(notes, "Phoenix project: serving on http://localhost:4000")
+		ports = []int{4000}
+	} else {
+		steps = append(steps, llm.Step{
+			ID: "build", Cmd: "mix compile", Cwd: ".", Risk: llm.RiskLow, Description: "Compile the project",
+		})
+		notes = append(notes, "No Phoenix dependency found - check README for run instructions")
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "elixir",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "elixir", Reason: "Elixir runtime required"},
+			{Name: "mix", Reason: "Mix build tool for dependencies"},
+		},
+		Steps: steps,
+		Env:   make(map[string]string),
+		Ports: ports,
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) cppPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	steps := []llm.Step{
+		{ID: "configure", Cmd: "cmake -B build", Cwd: ".", Risk: llm.RiskLow, Description: "Configure the CMake build in build/"},
+		{ID: "build", Cmd: "cmake --build build", Cwd: ".", Risk: llm.RiskLow, Description: "Compile the project"},
+	}
+	notes := []string{"C/C++ project using CMake"}
+
+	var executables []string
+	if ctx.Profile != nil {
+		executables = ctx.Profile.Executables
+	}
+	if len(executables) > 0 {
+		// Single-config generators (Makefiles, Ninja) put targets of the
+		// root CMakeLists.txt directly in the build directory
+		steps = append(steps, llm.Step{ID: "run", Cmd: "./build/" + executables[0], Cwd: ".", Risk: llm.RiskLow, Description: "Run the " + executables[0] + " executable"})
+		if len(executables) > 1 {
+			notes = append(notes, "Other executables in build/: "+strings.Join(executables[1:], ", "))
+		}
+	} else {
+		notes = append(notes, "No add_executable target in CMakeLists.txt - check README for run instructions")
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "cpp",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "cmake", Reason: "CMake build system required"},
+			{Name: "gcc", Reason: "C/C++ compiler required (clang works too)"},
+		},
+		Steps: steps,
+		Env:   make(map[string]string),
+		Ports: []int{},
+		Notes: notes,
+	}
+}
+
+func (p *MockProvider) k8sPlan(ctx *llm.PlanContext) *llm.RunPlan {
+	var manifests []string
+	if ctx.Profile != nil {
+		manifests = ctx.Profile.Manifests
+	}
+
+	var steps []llm.Step
+	for i, manifest := range manifests {
+		steps = append(steps, llm.Step{
+			ID:          fmt.Sprintf("apply-%d", i+1),
+			Cmd:         "kubectl apply -f " + manifest,
+			Cwd:         ".",
+			Risk:        llm.RiskHigh,
+			Description: "Apply " + manifest + " to the current cluster",
+		})
+	}
+	if len(steps) == 0 {
+		steps = []llm.Step{
+			{ID: "apply", Cmd: "kubectl apply -f .", Cwd: ".", Risk: llm.RiskHigh, Description: "Apply manifests to the current cluster"},
+		}
+	}
+
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "kubernetes",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "kubectl", Reason: "Kubernetes CLI required to apply manifests"},
+		},
+		Steps: steps,
+		Env:   make(map[string]string),
+		Ports: []int{},
+		Notes: []string{
+			"Kubernetes manifests are applied to your current kube-context",
+			"Check the target cluster first: kubectl config current-context",
+		},
+	}
+}
+
 func (p *MockProvider) unknownPlan(ctx *llm.PlanContext) *llm.RunPlan {
 	notes := []string{"Unknown project type - manual setup may be required"}
 
@@ -366,3 +811,76 @@ func (p *MockProvider) unknownPlan(ctx *llm.PlanContext) *llm.RunPlan {
 		Notes: notes,
 	}
 }
+
+// procfileDefaultPort is the PORT foreman gives the first web process
+const procfileDefaultPort = 5000
+
+// applyProcfile makes the Procfile's web process the run step, replacing
+// the stack's run step or following its other steps. Commands using $PORT
+// get it in the plan env.
+func applyProcfile(plan *llm.RunPlan, processes map[string]string) {
+	web, ok := processes["web"]
+	if !ok {
+		return
+	}
+
+	runStep := llm.Step{ID: "run", Cmd: web, Cwd: ".", Risk: llm.RiskLow, Description: "Start the Procfile web process"}
+	replaced := false
+	for i := range plan.Steps {
+		if plan.Steps[i].ID == "run" {
+			plan.Steps[i] = runStep
+			replaced = true
+		}
+	}
+	if !replaced {
+		plan.Steps = append(plan.Steps, runStep)
+	}
+
+	if strings.Contains(web, "$PORT") || strings.Contains(web, "${PORT") {
+		port := procfileDefaultPort
+		if len(plan.Ports) > 0 {
+			port = plan.Ports[0]
+		}
+		if plan.Env == nil {
+			plan.Env = make(map[string]string)
+		}
+		if _, set := plan.Env["PORT"]; !set {
+			plan.Env["PORT"] = fmt.Sprint(port)
+		}
+		if !containsPort(plan.Ports, port) {
+			plan.Ports = append(plan.Ports, port)
+		}
+	}
+
+	var others []string
+	for name := range processes {
+		if name != "web" {
+			others = append(others, name)
+		}
+	}
+	if len(others) > 0 {
+		sort.Strings(others)
+		plan.Notes = append(plan.Notes, fmt.Sprintf("The Procfile also declares %s: run all processes with foreman start or honcho start",
+			strings.Join(others, ", ")))
+	}
+}
+
+// containsPort checks if a port list contains a port
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// containsItem checks if a profile list contains an item
+func containsItem(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
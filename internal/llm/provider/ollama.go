@@ -42,7 +42,8 @@ func NewOllamaProvider(config *llm.ProviderConfig) (*OllamaProvider, error) {
 	return &OllamaProvider{
 		config: config,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: traceTransport(config),
 		},
 		builder: llm.NewPromptBuilder(),
 	}, nil
@@ -68,31 +69,29 @@ func (p *OllamaProvider) Name() string {
 
 // GeneratePlan generates a RunPlan using local Ollama
 func (p *OllamaProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
-	prompt := p.builder.BuildPlanPrompt(ctx)
-
-	var lastErr error
-	for attempt := 1; attempt <= 2; attempt++ {
-		plan, err := p.callAPI(prompt)
-		if err == nil {
-			return plan, nil
-		}
-		lastErr = err
-
-		if p.config.Verbose {
-			fmt.Printf("  [Ollama] Attempt %d failed: %v\n", attempt, err)
+	// Pick an installed model when none was configured
+	if p.config.Model == "" {
+		if models, err := llm.ListOllamaModels(llm.OllamaBaseURL(p.config.Endpoint)); err == nil {
+			model := llm.SelectOllamaModel(models)
+			if model == "" {
+				return nil, llm.ErrNoOllamaModels
+			}
+			p.config.Model = model
+			if p.config.Verbose {
+				p.config.Logf("  [Ollama] Using model %s\n", model)
+			}
 		}
+	}
 
-		if err == llm.ErrTimeout {
-			break
-		}
-		if strings.Contains(err.Error(), "connection refused") {
-			break
-		}
+	prompt := p.builder.BuildPlanPrompt(ctx)
 
-		time.Sleep(500 * time.Millisecond)
+	plan, err := callWithRetries(p.config, "Ollama", func() (*llm.RunPlan, error) {
+		return p.callAPI(prompt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Ollama failed: %w", err)
 	}
-
-	return nil, fmt.Errorf("Ollama failed: %w", lastErr)
+	return plan, nil
 }
 
 // OllamaRequest is the request body for Ollama API
@@ -169,7 +168,7 @@ func (p *OllamaProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if llm.IsTimeout(err) {
 			return nil, llm.ErrTimeout
 		}
 		return nil, fmt.Errorf("request failed (is Ollama running?): %w", err)
@@ -182,7 +181,7 @@ func (p *OllamaProvider) callAPI(prompt string) (*llm.RunPlan, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, TruncateForError(string(body), 200))
+		return nil, withRetryAfter(resp, fmt.Errorf("HTTP %d: %s", resp.StatusCode, TruncateForError(string(body), 200)))
 	}
 
 	return p.parseResponse(body)
@@ -216,7 +215,7 @@ func IsOllamaAvailable() bool {
 		endpoint = host + "/api/chat"
 	}
 
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := llm.NewHTTPClient(2 * time.Second)
 	tagsEndpoint := strings.Replace(endpoint, "/api/chat", "/api/tags", 1)
 	resp, err := client.Get(tagsEndpoint)
 	if err != nil {
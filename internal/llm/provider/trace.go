@@ -0,0 +1,119 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Raw request/response tracing for -vvv
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// redacted replaces credentials in traced headers and URLs
+const redacted = "[REDACTED]"
+
+// traceTransport returns the transport for a provider's HTTP client: the
+// shared llm.Transport, wrapped to dump each request and response to
+// config.Trace when it is set
+func traceTransport(config *llm.ProviderConfig) http.RoundTripper {
+	if config == nil || config.Trace == nil {
+		return llm.Transport()
+	}
+	return &tracingTransport{base: llm.Transport(), out: config.Trace}
+}
+
+// tracingTransport logs requests and responses around base
+type tracingTransport struct {
+	base http.RoundTripper
+	out  io.Writer
+}
+
+// RoundTrip dumps the request, sends it and dumps the response. Both bodies
+// are read in full and replaced so the caller still sees them.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	fmt.Fprintf(t.out, "  [trace] → %s %s\n", req.Method, redactURL(req.URL))
+	writeHeaders(t.out, req.Header)
+	fmt.Fprintf(t.out, "%s\n", reqBody)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(t.out, "  [trace] ← %v\n", err)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fmt.Fprintf(t.out, "  [trace] ← %s (%s)\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	writeHeaders(t.out, resp.Header)
+	fmt.Fprintf(t.out, "%s\n", respBody)
+	return resp, nil
+}
+
+// writeHeaders prints headers sorted by name, credentials redacted
+func writeHeaders(out io.Writer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if isCredential(name) {
+			value = redacted
+		}
+		fmt.Fprintf(out, "    %s: %s\n", name, value)
+	}
+}
+
+// redactURL hides credentials passed as query parameters
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isCredential(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	clone := *u
+	clone.RawQuery = query.Encode()
+	return clone.String()
+}
+
+// isCredential reports whether a header or parameter name carries a secret
+func isCredential(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range []string{"auth", "key", "token", "secret", "cookie"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
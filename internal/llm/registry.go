@@ -54,6 +54,13 @@ func (r *Registry) SetVerbose(v bool) {
 
 // Get creates a provider with graceful fallback to mock
 func (r *Registry) Get(config *ProviderConfig) Provider {
+	return r.GetWithInfo(config, nil)
+}
+
+// GetWithInfo is Get, recording any fallback to mock in info: at creation
+// (unknown or deprecated provider, missing key) or later, when the returned
+// provider fails to generate a plan. info may be nil.
+func (r *Registry) GetWithInfo(config *ProviderConfig, info *ProviderSelectionInfo) Provider {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -67,6 +74,7 @@ func (r *Registry) Get(config *ProviderConfig) Provider {
 		if r.verbose {
 			fmt.Printf("  [Registry] Provider '%s' is deprecated, using mock\n", config.Type)
 		}
+		info.recordFallback(string(config.Type), fmt.Errorf("provider %s is deprecated", config.Type))
 		return r.getMockProvider(config)
 	}
 
@@ -76,6 +84,7 @@ func (r *Registry) Get(config *ProviderConfig) Provider {
 		if r.verbose {
 			fmt.Printf("  [Registry] Unknown provider '%s', using mock\n", config.Type)
 		}
+		info.recordFallback(string(config.Type), fmt.Errorf("%w: %s", ErrUnknownProvider, config.Type))
 		return r.getMockProvider(config)
 	}
 
@@ -84,6 +93,7 @@ func (r *Registry) Get(config *ProviderConfig) Provider {
 		if r.verbose {
 			fmt.Printf("  [Registry] Failed to create provider '%s': %v, using mock\n", config.Type, err)
 		}
+		info.recordFallback(string(config.Type), err)
 		return r.getMockProvider(config)
 	}
 
@@ -93,6 +103,7 @@ func (r *Registry) Get(config *ProviderConfig) Provider {
 		Primary:  prov,
 		Fallback: mockProv,
 		Verbose:  config.Verbose,
+		Info:     info,
 	}
 }
 
@@ -143,6 +154,11 @@ type FallbackProvider struct {
 	Primary  Provider
 	Fallback Provider
 	Verbose  bool
+	Info     *ProviderSelectionInfo // Records a fallback, if set
+
+	// Err is the primary provider's error from the last GeneratePlan that
+	// fell back (nil if the primary succeeded)
+	Err error
 }
 
 // Name returns the primary provider name
@@ -153,9 +169,11 @@ func (p *FallbackProvider) Name() string {
 // GeneratePlan tries primary, falls back to mock on error
 func (p *FallbackProvider) GeneratePlan(ctx *PlanContext) (*RunPlan, error) {
 	plan, err := p.Primary.GeneratePlan(ctx)
+	p.Err = err
 	if err == nil {
 		return plan, nil
 	}
+	p.Info.recordFallback(p.Primary.Name(), err)
 
 	if p.Verbose {
 		fmt.Printf("  [%s] Failed: %v, falling back to mock\n", p.Primary.Name(), err)
@@ -176,6 +194,7 @@ func PrintCopilotDeprecationWarning() {
 	fmt.Println("  ║    • anthropic (recommended) - set ANTHROPIC_API_KEY         ║")
 	fmt.Println("  ║    • openai                  - set OPENAI_API_KEY            ║")
 	fmt.Println("  ║    • mistral                 - set MISTRAL_API_KEY           ║")
+	fmt.Println("  ║    • cohere                  - set COHERE_API_KEY            ║")
 	fmt.Println("  ║    • ollama                  - local, no key needed          ║")
 	fmt.Println("  ║    • mock                    - offline mode                  ║")
 	fmt.Println("  ║                                                              ║")
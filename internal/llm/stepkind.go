@@ -0,0 +1,146 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Step classification from step IDs and commands
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepKind is what a step does for the project: install dependencies,
+// build, test, start the app or set something up
+type StepKind string
+
+const (
+	StepKindInstall StepKind = "install"
+	StepKindBuild   StepKind = "build"
+	StepKindTest    StepKind = "test"
+	StepKindRun     StepKind = "run"
+	StepKindSetup   StepKind = "setup"
+	StepKindOther   StepKind = "other"
+)
+
+// stepKindRule classifies steps whose ID or command contains one of the
+// substrings (both lowercased)
+type stepKindRule struct {
+	kind StepKind
+	ids  []string
+	cmds []string
+}
+
+// stepKindRules are checked in order, so "npm run build" is a build step
+// rather than a run step
+var stepKindRules = []stepKindRule{
+	{
+		kind: StepKindInstall,
+		ids:  []string{"install", "deps"},
+		cmds: []string{
+			"npm install", "npm ci", "yarn install", "pnpm install",
+			"pip install", "poetry install", "go mod download", "cargo fetch",
+			"bundle install", "composer install",
+		},
+	},
+	{
+		kind: StepKindBuild,
+		ids:  []string{"build", "compile"},
+		cmds: []string{
+			"go build", "cargo build", "npm run build", "yarn build",
+			"make build", "docker build", "podman build",
+		},
+	},
+	{
+		kind: StepKindTest,
+		ids:  []string{"test"},
+		cmds: []string{"go test", "npm test", "pytest", "cargo test"},
+	},
+	{
+		kind: StepKindRun,
+		ids:  []string{"run", "start", "serve"},
+		cmds: []string{
+			"npm start", "npm run dev", "docker compose up", "docker-compose up",
+			"docker run", "podman compose up", "podman run", "./",
+		},
+	},
+	{
+		kind: StepKindSetup,
+		ids:  []string{"setup", "config", "init", "venv"},
+		cmds: []string{"venv"},
+	},
+}
+
+// ClassifyStep returns the kind of a step from its ID (without the
+// subproject of a monorepo plan) and command, or StepKindOther
+func ClassifyStep(step *Step) StepKind {
+	kind, _ := ExplainStepKind(step)
+	return kind
+}
+
+// ExplainStepKind is ClassifyStep with what matched, e.g. `ID contains
+// "install"` or `command contains "npm ci"` ("" for StepKindOther)
+func ExplainStepKind(step *Step) (StepKind, string) {
+	_, id := SplitStepID(step.ID)
+	id = strings.ToLower(id)
+	cmd := strings.ToLower(step.Cmd)
+
+	for _, rule := range stepKindRules {
+		if sub := firstContained(id, rule.ids); sub != "" {
+			return rule.kind, fmt.Sprintf("ID contains %q", sub)
+		}
+		if sub := firstContained(cmd, rule.cmds); sub != "" {
+			return rule.kind, fmt.Sprintf("command contains %q", sub)
+		}
+	}
+	return StepKindOther, ""
+}
+
+// firstContained returns the first of the substrings found in s, or ""
+func firstContained(s string, substrings []string) string {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return sub
+		}
+	}
+	return ""
+}
+
+// longRunningWords are command words of servers and watchers that keep
+// running until stopped ("npm start", "rails server", "compose up")
+var longRunningWords = map[string]bool{
+	"serve": true, "server": true, "runserver": true,
+	"start": true, "dev": true, "up": true,
+}
+
+// IsLongRunningStep reports whether a step probably starts a server that
+// does not exit on its own. Install, build, test and setup steps never are,
+// and "up" only counts without -d/--detach.
+func IsLongRunningStep(step *Step) bool {
+	switch ClassifyStep(step) {
+	case StepKindInstall, StepKindBuild, StepKindTest, StepKindSetup:
+		return false
+	}
+
+	fields := strings.Fields(strings.ToLower(step.Cmd))
+	detached := false
+	for _, field := range fields {
+		if field == "-d" || field == "--detach" {
+			detached = true
+		}
+	}
+
+	for _, field := range fields {
+		// "phx.server", "start:dev", "./bin/server"
+		parts := strings.FieldsFunc(field, func(r rune) bool {
+			return r == '.' || r == ':' || r == '/'
+		})
+		for _, part := range parts {
+			if !longRunningWords[part] {
+				continue
+			}
+			if part != "up" || !detached {
+				return true
+			}
+		}
+	}
+	return false
+}
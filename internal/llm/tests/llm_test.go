@@ -4,6 +4,10 @@
 package tests
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/sony-level/readme-runner/internal/llm"
@@ -80,6 +84,358 @@ func TestMockProviderNodeStack(t *testing.T) {
 	}
 }
 
+func TestMockProviderDotNetStack(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	ctx := &llm.PlanContext{
+		Profile: &scanner.ProjectProfile{
+			Stack:     "dotnet",
+			Languages: []string{"csharp"},
+			Tools:     []string{"dotnet"},
+			Packages:  []string{"App.csproj"},
+		},
+	}
+
+	plan, err := prov.GeneratePlan(ctx)
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+
+	if plan.ProjectType != "dotnet" {
+		t.Errorf("Expected project_type 'dotnet', got '%s'", plan.ProjectType)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+
+	for _, want := range []string{"dotnet restore", "dotnet build", "dotnet run"} {
+		found := false
+		for _, step := range plan.Steps {
+			if strings.HasPrefix(step.Cmd, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected a '%s' step", want)
+		}
+	}
+
+	if len(plan.Prerequisites) == 0 || plan.Prerequisites[0].Name != "dotnet" {
+		t.Errorf("Expected dotnet prerequisite, got %v", plan.Prerequisites)
+	}
+}
+
+func TestMockProviderPHPStack(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	ctx := &llm.PlanContext{
+		Profile: &scanner.ProjectProfile{
+			Stack:     "php",
+			Languages: []string{"php"},
+			Tools:     []string{"composer"},
+			Packages:  []string{"composer.json"},
+			Signals:   []string{"composer.json"},
+		},
+	}
+
+	plan, err := prov.GeneratePlan(ctx)
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if plan.ProjectType != "php" {
+		t.Errorf("Expected project_type 'php', got '%s'", plan.ProjectType)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+	if plan.Steps[0].Cmd != "composer install" {
+		t.Errorf("Expected 'composer install' first, got '%s'", plan.Steps[0].Cmd)
+	}
+	if run := plan.Steps[len(plan.Steps)-1].Cmd; run != "php -S localhost:8000 -t public" {
+		t.Errorf("Expected built-in server run step, got '%s'", run)
+	}
+
+	// Laravel projects are served with artisan
+	ctx.Profile.Signals = []string{"artisan", "composer.json"}
+	plan, _ = prov.GeneratePlan(ctx)
+	if run := plan.Steps[len(plan.Steps)-1].Cmd; run != "php artisan serve" {
+		t.Errorf("Expected 'php artisan serve' for Laravel, got '%s'", run)
+	}
+}
+
+func TestMockProviderJavaStack(t *testing.T) {
+	tests := []struct {
+		name        string
+		signals     []string
+		wantCmd     string
+		wantPrereqs []string
+	}{
+		{"gradle wrapper", []string{"build.gradle", "gradlew", "settings.gradle"}, "./gradlew build", []string{"java"}},
+		{"maven wrapper", []string{"mvnw", "pom.xml"}, "./mvnw package", []string{"java"}},
+		{"system gradle", []string{"build.gradle.kts"}, "gradle build", []string{"java", "gradle"}},
+		{"system maven", []string{"pom.xml"}, "mvn package", []string{"java", "maven"}},
+	}
+
+	prov := provider.NewMockProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := prov.GeneratePlan(&llm.PlanContext{
+				Profile: &scanner.ProjectProfile{Stack: "java", Signals: tt.signals},
+			})
+			if err != nil {
+				t.Fatalf("GeneratePlan failed: %v", err)
+			}
+			if plan.ProjectType != "java" {
+				t.Errorf("Expected project_type 'java', got '%s'", plan.ProjectType)
+			}
+			if err := plan.Validate(); err != nil {
+				t.Errorf("Plan should be valid: %v", err)
+			}
+			if plan.Steps[0].Cmd != tt.wantCmd {
+				t.Errorf("Expected %q, got %q", tt.wantCmd, plan.Steps[0].Cmd)
+			}
+			var prereqs []string
+			for _, prereq := range plan.Prerequisites {
+				prereqs = append(prereqs, prereq.Name)
+			}
+			if strings.Join(prereqs, ",") != strings.Join(tt.wantPrereqs, ",") {
+				t.Errorf("Prerequisites = %v, want %v", prereqs, tt.wantPrereqs)
+			}
+		})
+	}
+}
+
+func TestMockProviderElixirStack(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	ctx := &llm.PlanContext{
+		Profile: &scanner.ProjectProfile{
+			Stack:     "elixir",
+			Framework: scanner.FrameworkPhoenix,
+			Tools:     []string{"mix"},
+			Packages:  []string{"mix.exs", "mix.lock"},
+		},
+	}
+
+	plan, err := prov.GeneratePlan(ctx)
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if plan.ProjectType != "elixir" {
+		t.Errorf("Expected project_type 'elixir', got '%s'", plan.ProjectType)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+	if plan.Steps[0].Cmd != "mix deps.get" {
+		t.Errorf("Expected 'mix deps.get' first, got '%s'", plan.Steps[0].Cmd)
+	}
+	run := plan.Steps[len(plan.Steps)-1]
+	if run.ID != "run" || run.Cmd != "mix phx.server" {
+		t.Errorf("Expected run step 'mix phx.server', got %s: '%s'", run.ID, run.Cmd)
+	}
+	if len(plan.Ports) != 1 || plan.Ports[0] != 4000 {
+		t.Errorf("Ports = %v, want [4000]", plan.Ports)
+	}
+}
+
+func TestMockProviderTaskfile(t *testing.T) {
+	prov := provider.NewMockProvider()
+	profile := &scanner.ProjectProfile{
+		Stack: "go",
+		Tools: []string{"go", "task"},
+		Tasks: []string{"build", "default", "deps", "lint"},
+	}
+
+	plan, err := prov.GeneratePlan(&llm.PlanContext{Profile: profile})
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+	var cmds []string
+	for _, step := range plan.Steps {
+		cmds = append(cmds, step.Cmd)
+	}
+	if got := strings.Join(cmds, " | "); got != "task deps | task build | task" {
+		t.Errorf("Steps = %q, want task deps | task build | task", got)
+	}
+	hasTask := false
+	for _, prereq := range plan.Prerequisites {
+		hasTask = hasTask || prereq.Name == "task"
+	}
+	if !hasTask {
+		t.Errorf("Prerequisites = %v, missing task", plan.Prerequisites)
+	}
+
+	// A clear README wins over the Taskfile
+	plan, err = prov.GeneratePlan(&llm.PlanContext{Profile: profile, UseReadme: true})
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if plan.Steps[0].Cmd != "go build -o app ." {
+		t.Errorf("Expected the go plan with UseReadme, got '%s'", plan.Steps[0].Cmd)
+	}
+}
+
+func TestMockProviderProcfile(t *testing.T) {
+	prov := provider.NewMockProvider()
+	plan, err := prov.GeneratePlan(&llm.PlanContext{
+		Profile: &scanner.ProjectProfile{
+			Stack:    "python",
+			Tools:    []string{"pip"},
+			Packages: []string{"requirements.txt"},
+			Processes: map[string]string{
+				"web":     "gunicorn app:app --bind 0.0.0.0:$PORT",
+				"worker":  "celery -A app worker",
+				"release": "python manage.py migrate",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+
+	run := plan.Steps[len(plan.Steps)-1]
+	if run.ID != "run" || run.Cmd != "gunicorn app:app --bind 0.0.0.0:$PORT" {
+		t.Errorf("Expected the web process as run step, got %s: '%s'", run.ID, run.Cmd)
+	}
+	runSteps := 0
+	for _, step := range plan.Steps {
+		if step.ID == "run" {
+			runSteps++
+		}
+	}
+	if runSteps != 1 {
+		t.Errorf("Expected one run step, got %d", runSteps)
+	}
+	if plan.Env["PORT"] == "" {
+		t.Errorf("Env = %v, want PORT for $PORT", plan.Env)
+	}
+	found := false
+	for _, note := range plan.Notes {
+		found = found || strings.Contains(note, "release, worker")
+	}
+	if !found {
+		t.Errorf("Notes = %v, want a note about the other processes", plan.Notes)
+	}
+}
+
+func TestMockProviderFrameworkRunCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *scanner.ProjectProfile
+		wantRun string
+	}{
+		{
+			name:    "fastapi",
+			profile: &scanner.ProjectProfile{Stack: "python", Framework: scanner.FrameworkFastAPI, Packages: []string{"requirements.txt"}, Signals: []string{"main.py"}},
+			wantRun: ".venv/bin/python -m uvicorn main:app --host 0.0.0.0 --port 8000",
+		},
+		{
+			name:    "fastapi app object in api.py",
+			profile: &scanner.ProjectProfile{Stack: "python", Framework: scanner.FrameworkFastAPI, ASGIApp: "app:api", Packages: []string{"requirements.txt"}},
+			wantRun: ".venv/bin/python -m uvicorn app:api --host 0.0.0.0 --port 8000",
+		},
+		{
+			name:    "starlette without framework",
+			profile: &scanner.ProjectProfile{Stack: "python", ASGIApp: "main:app", Packages: []string{"requirements.txt"}, Signals: []string{"main.py"}},
+			wantRun: ".venv/bin/python -m uvicorn main:app --host 0.0.0.0 --port 8000",
+		},
+		{
+			name:    "asgi module",
+			profile: &scanner.ProjectProfile{Stack: "python", Packages: []string{"requirements.txt"}, Signals: []string{"asgi.py"}},
+			wantRun: ".venv/bin/python -m uvicorn asgi:application --host 0.0.0.0 --port 8000",
+		},
+		{
+			name:    "wsgi stays on flask",
+			profile: &scanner.ProjectProfile{Stack: "python", Packages: []string{"requirements.txt"}, Signals: []string{"wsgi.py"}},
+			wantRun: ".venv/bin/python -m flask run",
+		},
+		{
+			name:    "flask",
+			profile: &scanner.ProjectProfile{Stack: "python", Framework: scanner.FrameworkFlask, Packages: []string{"requirements.txt"}, Signals: []string{"app.py"}},
+			wantRun: ".venv/bin/python -m flask run",
+		},
+		{
+			name:    "django with poetry",
+			profile: &scanner.ProjectProfile{Stack: "python", Framework: scanner.FrameworkDjango, Tools: []string{"poetry"}, Packages: []string{"pyproject.toml"}},
+			wantRun: "poetry run python manage.py runserver",
+		},
+		{
+			name:    "nextjs",
+			profile: &scanner.ProjectProfile{Stack: "node", Framework: scanner.FrameworkNextJS, Tools: []string{"npm"}, Packages: []string{"package.json"}},
+			wantRun: "npm run dev",
+		},
+	}
+
+	prov := provider.NewMockProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := prov.GeneratePlan(&llm.PlanContext{Profile: tt.profile})
+			if err != nil {
+				t.Fatalf("GeneratePlan failed: %v", err)
+			}
+			run := plan.Steps[len(plan.Steps)-1]
+			if run.ID != "run" || run.Cmd != tt.wantRun {
+				t.Errorf("Run step = %s %q, want %q", run.ID, run.Cmd, tt.wantRun)
+			}
+			if strings.Contains(run.Cmd, "uvicorn") && (len(plan.Ports) == 0 || plan.Ports[0] != 8000) {
+				t.Errorf("ASGI plans should expose port 8000, got %v", plan.Ports)
+			}
+		})
+	}
+}
+
+func TestMockProviderKubernetesStack(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	ctx := &llm.PlanContext{
+		Profile: &scanner.ProjectProfile{
+			Stack:     "kubernetes",
+			Tools:     []string{"kubernetes"},
+			Manifests: []string{"k8s/deployment.yaml", "k8s/service.yaml"},
+		},
+	}
+
+	plan, err := prov.GeneratePlan(ctx)
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+	if plan.ProjectType != "kubernetes" {
+		t.Errorf("Expected project_type 'kubernetes', got '%s'", plan.ProjectType)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Errorf("Plan should be valid: %v", err)
+	}
+	if len(plan.Steps) != 2 {
+		t.Fatalf("Expected one apply step per manifest, got %d", len(plan.Steps))
+	}
+	if plan.Steps[0].Cmd != "kubectl apply -f k8s/deployment.yaml" {
+		t.Errorf("Unexpected apply command: %s", plan.Steps[0].Cmd)
+	}
+	for _, step := range plan.Steps {
+		if step.Risk != llm.RiskHigh {
+			t.Errorf("Step %s should be high risk, got %s", step.ID, step.Risk)
+		}
+	}
+	if plan.Prerequisites[0].Name != "kubectl" {
+		t.Errorf("Expected kubectl prerequisite, got %v", plan.Prerequisites)
+	}
+
+	// Without known manifests the whole directory is applied
+	ctx.Profile.Manifests = nil
+	plan, _ = prov.GeneratePlan(ctx)
+	if len(plan.Steps) != 1 || plan.Steps[0].Cmd != "kubectl apply -f ." {
+		t.Errorf("Expected a single 'kubectl apply -f .' step, got %v", plan.Steps)
+	}
+}
+
 func TestMockProviderDockerStack(t *testing.T) {
 	prov := provider.NewMockProvider()
 
@@ -303,3 +659,274 @@ func TestMaskToken(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigResolveToken(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "anthropic.key")
+	if err := os.WriteFile(tokenFile, []byte("sk-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := (&llm.Config{TokenFile: tokenFile}).ResolveToken()
+	if err != nil || token != "sk-from-file" {
+		t.Errorf("token_file: got %q, %v", token, err)
+	}
+
+	// The plaintext token wins over token_file
+	if token, _ := (&llm.Config{Token: "sk-plain", TokenFile: tokenFile}).ResolveToken(); token != "sk-plain" {
+		t.Errorf("expected plaintext token to win, got %q", token)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(tokenFile, 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := (&llm.Config{TokenFile: tokenFile}).ResolveToken()
+		if err == nil || !strings.Contains(err.Error(), "chmod 600") {
+			t.Errorf("expected a permissions error, got %v", err)
+		}
+		if err != nil && strings.Contains(err.Error(), "sk-from-file") {
+			t.Error("error must not contain the token")
+		}
+
+		token, err = (&llm.Config{KeyCommand: "echo sk-from-command"}).ResolveToken()
+		if err != nil || token != "sk-from-command" {
+			t.Errorf("key_command: got %q, %v", token, err)
+		}
+		if _, err := (&llm.Config{KeyCommand: "exit 3"}).ResolveToken(); err == nil {
+			t.Error("expected failing key_command to return an error")
+		}
+	}
+
+	if token, err := (&llm.Config{}).ResolveToken(); token != "" || err != nil {
+		t.Errorf("expected no token, got %q, %v", token, err)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	builder := llm.NewPromptBuilder()
+
+	if got := builder.EstimateTokens(""); got != 0 {
+		t.Errorf("EstimateTokens(\"\") = %d, want 0", got)
+	}
+	if got := builder.EstimateTokens(strings.Repeat("a", 4000)); got != 1000 {
+		t.Errorf("EstimateTokens(4000 chars) = %d, want 1000", got)
+	}
+	// Multi-byte characters count once
+	if got := builder.EstimateTokens("ソニーレベル"); got != 2 {
+		t.Errorf("EstimateTokens(6 runes) = %d, want 2", got)
+	}
+
+	if !llm.IsHostedProvider(llm.ProviderAnthropic) || llm.IsHostedProvider(llm.ProviderOllama) || llm.IsHostedProvider(llm.ProviderMock) {
+		t.Error("IsHostedProvider: only API providers are hosted")
+	}
+}
+
+func TestStepsAboveRisk(t *testing.T) {
+	if _, err := llm.ParseRiskLevel("extreme"); err == nil {
+		t.Error("expected an error for an unknown risk level")
+	}
+	max, err := llm.ParseRiskLevel("Medium")
+	if err != nil || max != llm.RiskMedium {
+		t.Fatalf("ParseRiskLevel(Medium) = %q, %v", max, err)
+	}
+
+	plan := &llm.RunPlan{Steps: []llm.Step{
+		{ID: "install", Risk: llm.RiskLow},
+		{ID: "build", Risk: llm.RiskMedium},
+		{ID: "deps", Risk: llm.RiskHigh},
+		{ID: "setup", Risk: llm.RiskCritical},
+	}}
+	above := plan.StepsAboveRisk(max)
+	if len(above) != 2 || above[0].ID != "deps" || above[1].ID != "setup" {
+		t.Errorf("expected deps and setup above medium, got %+v", above)
+	}
+	if len(plan.StepsAboveRisk(llm.RiskCritical)) != 0 {
+		t.Error("no step can exceed critical")
+	}
+}
+
+func TestMockProviderBunAndDenoPlans(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	tests := []struct {
+		name    string
+		profile *scanner.ProjectProfile
+		install string
+		run     string
+		port    int
+	}{
+		{"bun", &scanner.ProjectProfile{Stack: "node", Tools: []string{"bun", "npm"}, Packages: []string{"bun.lockb", "package.json"}}, "bun install", "bun run start", 3000},
+		{"deno", &scanner.ProjectProfile{Stack: "node", Tools: []string{"deno"}, Packages: []string{"deno.json"}}, "deno install", "deno task start", 8000},
+	}
+	for _, tt := range tests {
+		plan, err := prov.GeneratePlan(&llm.PlanContext{Profile: tt.profile})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := plan.Validate(); err != nil {
+			t.Errorf("%s: invalid plan: %v", tt.name, err)
+		}
+		if plan.Steps[0].Cmd != tt.install || plan.Steps[1].Cmd != tt.run {
+			t.Errorf("%s: unexpected steps %q, %q", tt.name, plan.Steps[0].Cmd, plan.Steps[1].Cmd)
+		}
+		if len(plan.Ports) != 1 || plan.Ports[0] != tt.port {
+			t.Errorf("%s: expected port %d, got %v", tt.name, tt.port, plan.Ports)
+		}
+	}
+}
+
+func TestMockProviderRubyPlans(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	rails, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{
+		Stack: "ruby", Framework: scanner.FrameworkRails, Tools: []string{"bundler"}, Packages: []string{"Gemfile"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rails.Validate(); err != nil {
+		t.Errorf("invalid plan: %v", err)
+	}
+	var cmds []string
+	for _, step := range rails.Steps {
+		cmds = append(cmds, step.Cmd)
+	}
+	want := []string{"bundle install", "bin/rails db:setup", "bin/rails server"}
+	if strings.Join(cmds, "|") != strings.Join(want, "|") {
+		t.Errorf("steps = %q, want %q", cmds, want)
+	}
+	if rails.Steps[1].Risk != llm.RiskMedium {
+		t.Errorf("db:setup risk = %s, want medium", rails.Steps[1].Risk)
+	}
+	if rails.Steps[2].ID != "run" {
+		t.Errorf("server step ID = %q, want run (stops on Puma readiness)", rails.Steps[2].ID)
+	}
+	if len(rails.Ports) != 1 || rails.Ports[0] != 3000 {
+		t.Errorf("expected port 3000, got %v", rails.Ports)
+	}
+
+	// A plain Gemfile project only installs its gems
+	gem, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{
+		Stack: "ruby", Tools: []string{"bundler"}, Packages: []string{"Gemfile"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gem.Steps) != 1 || gem.Steps[0].Cmd != "bundle install" {
+		t.Errorf("unexpected steps for a plain Ruby project: %+v", gem.Steps)
+	}
+}
+
+func TestMockProviderCMakePlan(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	runPlan, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{
+		Stack: "cpp", Tools: []string{"cmake"}, Executables: []string{"demo", "demo-bench"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlan.Validate(); err != nil {
+		t.Errorf("invalid plan: %v", err)
+	}
+	var cmds []string
+	for _, step := range runPlan.Steps {
+		cmds = append(cmds, step.Cmd)
+	}
+	want := []string{"cmake -B build", "cmake --build build", "./build/demo"}
+	if strings.Join(cmds, "|") != strings.Join(want, "|") {
+		t.Errorf("steps = %q, want %q", cmds, want)
+	}
+	var prereqs []string
+	for _, prereq := range runPlan.Prerequisites {
+		prereqs = append(prereqs, prereq.Name)
+	}
+	if strings.Join(prereqs, ",") != "cmake,gcc" {
+		t.Errorf("prerequisites = %v, want cmake and gcc", prereqs)
+	}
+
+	// Without an executable target there is nothing to run
+	lib, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "cpp", Tools: []string{"cmake"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lib.Steps) != 2 {
+		t.Errorf("unexpected steps for a CMake library: %+v", lib.Steps)
+	}
+}
+
+func TestMockProviderGoWorkspacePlan(t *testing.T) {
+	prov := provider.NewMockProvider()
+
+	runPlan, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{
+		Stack: "go", Tools: []string{"go"}, GoModules: []string{"api", "tools/cli", "../shared"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlan.Validate(); err != nil {
+		t.Errorf("invalid plan: %v", err)
+	}
+	var steps []string
+	for _, step := range runPlan.Steps {
+		steps = append(steps, step.ID+"@"+step.Cwd+": "+step.Cmd)
+	}
+	want := []string{"build-api@api: go build ./...", "build-tools-cli@tools/cli: go build ./..."}
+	if strings.Join(steps, "|") != strings.Join(want, "|") {
+		t.Errorf("steps = %q, want %q", steps, want)
+	}
+}
+
+func TestClassifyStep(t *testing.T) {
+	tests := []struct {
+		id   string
+		cmd  string
+		want llm.StepKind
+	}{
+		{"install", "npm ci", llm.StepKindInstall},
+		{"deps", "go mod download", llm.StepKindInstall},
+		{"gems", "bundle install", llm.StepKindInstall},
+		{"build", "npm run build", llm.StepKindBuild},
+		{"bundle", "npm run build", llm.StepKindBuild},
+		{"test", "go test ./...", llm.StepKindTest},
+		{"run", "npm start", llm.StepKindRun},
+		{"app", "docker compose up", llm.StepKindRun},
+		{"web/run", "npm start", llm.StepKindRun},
+		{"setup", "cp .env.example .env", llm.StepKindSetup},
+		{"env", "python -m venv .venv", llm.StepKindSetup},
+		{"lint", "golangci-lint", llm.StepKindOther},
+	}
+	for _, tt := range tests {
+		step := llm.Step{ID: tt.id, Cmd: tt.cmd}
+		if got := llm.ClassifyStep(&step); got != tt.want {
+			t.Errorf("ClassifyStep(%q, %q) = %s, want %s", tt.id, tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestIsLongRunningStep(t *testing.T) {
+	tests := []struct {
+		id   string
+		cmd  string
+		want bool
+	}{
+		{"run", "npm start", true},
+		{"dev", "npm run dev", true},
+		{"serve", "python manage.py runserver", true},
+		{"web", "bin/rails server", true},
+		{"app", "mix phx.server", true},
+		{"app", "docker compose up", true},
+		{"app", "docker compose up -d", false},
+		{"app", "docker compose up --detach", false},
+		{"install", "cd server && npm install", false},
+		{"build", "npm run build", false},
+		{"migrate", "python manage.py migrate", false},
+	}
+	for _, tt := range tests {
+		step := llm.Step{ID: tt.id, Cmd: tt.cmd}
+		if got := llm.IsLongRunningStep(&step); got != tt.want {
+			t.Errorf("IsLongRunningStep(%q, %q) = %v, want %v", tt.id, tt.cmd, got, tt.want)
+		}
+	}
+}
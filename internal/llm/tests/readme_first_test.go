@@ -5,6 +5,8 @@ package tests
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sony-level/readme-runner/internal/llm"
@@ -13,13 +15,14 @@ import (
 )
 
 // TestProviderAutoSelectionOrder verifies the provider auto-selection priority:
-// anthropic > openai > mistral > ollama > mock
+// anthropic > openai > mistral > cohere > ollama > mock
 func TestProviderAutoSelectionOrder(t *testing.T) {
 	// Save all env vars
 	envVars := []string{
 		"ANTHROPIC_API_KEY",
 		"OPENAI_API_KEY",
 		"MISTRAL_API_KEY",
+		"COHERE_API_KEY",
 		"RD_LLM_PROVIDER",
 		"RD_LLM_TOKEN",
 	}
@@ -102,6 +105,23 @@ func TestProviderAutoSelectionOrder(t *testing.T) {
 			expectedType: llm.ProviderOpenAI,
 			description:  "OpenAI should have higher priority than Mistral",
 		},
+		{
+			name: "cohere key only - selects cohere",
+			envVars: map[string]string{
+				"COHERE_API_KEY": "test-key",
+			},
+			expectedType: llm.ProviderCohere,
+			description:  "Should select Cohere when only Cohere key is available",
+		},
+		{
+			name: "mistral beats cohere",
+			envVars: map[string]string{
+				"MISTRAL_API_KEY": "test-key",
+				"COHERE_API_KEY":  "test-key",
+			},
+			expectedType: llm.ProviderMistral,
+			description:  "Mistral should have higher priority than Cohere",
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,6 +204,7 @@ func TestProviderPrecedence(t *testing.T) {
 			os.Unsetenv("ANTHROPIC_API_KEY")
 			os.Unsetenv("OPENAI_API_KEY")
 			os.Unsetenv("MISTRAL_API_KEY")
+			os.Unsetenv("COHERE_API_KEY")
 
 			// Set test env
 			if tt.envProvider != "" {
@@ -286,6 +307,97 @@ func TestReadmeFirstClarityThreshold(t *testing.T) {
 	}
 }
 
+// TestResolveClarityThreshold verifies CLI > config file > default precedence
+func TestResolveClarityThreshold(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if got, err := llm.ResolveClarityThreshold(0, false); err != nil || got != llm.ClarityThreshold {
+		t.Errorf("default: got %v, %v, want %v", got, err, llm.ClarityThreshold)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "readme-runner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "readme-runner", "config.yaml")
+	if err := os.WriteFile(configPath, []byte("clarity_threshold: 0.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := llm.ResolveClarityThreshold(0, false); err != nil || got != 0.5 {
+		t.Errorf("config file: got %v, %v, want 0.5", got, err)
+	}
+	if got, err := llm.ResolveClarityThreshold(0.8, true); err != nil || got != 0.8 {
+		t.Errorf("CLI: got %v, %v, want 0.8", got, err)
+	}
+	if _, err := llm.ResolveClarityThreshold(1.5, true); err == nil {
+		t.Error("expected an error for a threshold above 1")
+	}
+
+	// A README scoring 0.55 is used once the threshold is lowered
+	readme := &scanner.ReadmeInfo{Content: "x", HasInstall: true, HasUsage: true, CodeBlocks: 1}
+	score := llm.CalculateClarityScore(readme)
+	if llm.ShouldUseReadmeWithThreshold(readme, score+0.01) || !llm.ShouldUseReadmeWithThreshold(readme, score) {
+		t.Errorf("threshold not honored for score %.2f", score)
+	}
+	if llm.ShouldUseReadmeWithThreshold(nil, 0) {
+		t.Error("a missing README must never be used")
+	}
+}
+
+// TestStrategyOverridesClarityScore verifies --strategy readme|files|auto
+func TestStrategyOverridesClarityScore(t *testing.T) {
+	unclear := &scanner.ReadmeInfo{Content: "x"}
+	clear := &scanner.ReadmeInfo{Content: "x", HasInstall: true, HasUsage: true, CodeBlocks: 3, ShellCommands: 2}
+
+	tests := []struct {
+		strategy string
+		readme   *scanner.ReadmeInfo
+		want     bool
+	}{
+		{"auto", unclear, false},
+		{"", clear, true},
+		{"readme", unclear, true},
+		{"readme", nil, false},
+		{"files", clear, false},
+	}
+
+	for _, tt := range tests {
+		strategy, err := llm.ParseStrategy(tt.strategy)
+		if err != nil {
+			t.Fatalf("ParseStrategy(%q) error = %v", tt.strategy, err)
+		}
+		if got := llm.ShouldUseReadmeWithStrategy(tt.readme, llm.ClarityThreshold, strategy); got != tt.want {
+			t.Errorf("strategy %q: UseReadme = %v, want %v", tt.strategy, got, tt.want)
+		}
+	}
+
+	if _, err := llm.ParseStrategy("guess"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+// TestPromptKeepsInstallSectionOfLongReadme verifies that a long README
+// keeps its Installation section instead of being cut at the budget
+func TestPromptKeepsInstallSectionOfLongReadme(t *testing.T) {
+	content := "# Project\nA tool.\n\n## Background\n" + strings.Repeat("History of the project.\n", 600) +
+		"\n## Installation\n```bash\nnpm install\n```\n\n## Usage\n```bash\nnpm start\n```\n"
+	readme := &scanner.ReadmeInfo{Content: content, Sections: []string{"Project", "Background", "Installation", "Usage"}}
+	readme.SectionRanges = scanner.FindSections(content)
+
+	prompt := llm.NewPromptBuilder().BuildPlanPrompt(&llm.PlanContext{ReadmeInfo: readme, UseReadme: true})
+
+	for _, want := range []string{"npm install", "npm start", "A tool."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q", want)
+		}
+	}
+	if strings.Count(prompt, "History of the project.") > 10 {
+		t.Error("prompt should omit the long Background section")
+	}
+}
+
 // TestReadmeFirstInPlanContext verifies that PlanContext correctly sets UseReadme
 func TestReadmeFirstInPlanContext(t *testing.T) {
 	goodReadme := &scanner.ReadmeInfo{
@@ -465,3 +577,29 @@ func TestGetProviderSelectionDescription(t *testing.T) {
 		})
 	}
 }
+
+// TestOfflineModeForcesMock verifies --offline and RDR_OFFLINE override
+// available keys and explicit providers
+func TestOfflineModeForcesMock(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	t.Setenv("RD_LLM_PROVIDER", "")
+	t.Setenv(llm.OfflineEnvVar, "")
+
+	config, info := llm.ResolveProviderConfigWithOffline("anthropic", "", "", "", 0, false, true)
+	if config.Type != llm.ProviderMock {
+		t.Errorf("expected mock provider with --offline, got %s", config.Type)
+	}
+	if desc := llm.GetProviderSelectionDescription(info); !strings.Contains(desc, "forced offline mode") {
+		t.Errorf("unexpected description %q", desc)
+	}
+
+	t.Setenv(llm.OfflineEnvVar, "1")
+	if config, _ := llm.ResolveProviderConfigWithInfo("", "", "", "", 0, false); config.Type != llm.ProviderMock {
+		t.Errorf("expected mock provider with %s=1, got %s", llm.OfflineEnvVar, config.Type)
+	}
+
+	t.Setenv(llm.OfflineEnvVar, "0")
+	if config, _ := llm.ResolveProviderConfigWithInfo("", "", "", "", 0, false); config.Type != llm.ProviderAnthropic {
+		t.Errorf("expected anthropic with %s=0, got %s", llm.OfflineEnvVar, config.Type)
+	}
+}
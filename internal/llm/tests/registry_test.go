@@ -4,10 +4,14 @@
 package tests
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -71,6 +75,7 @@ func TestDefaultNoKeyUsesMock(t *testing.T) {
 		"ANTHROPIC_API_KEY",
 		"OPENAI_API_KEY",
 		"MISTRAL_API_KEY",
+		"COHERE_API_KEY",
 		"RD_LLM_TOKEN",
 		"RD_LLM_PROVIDER",
 	}
@@ -350,6 +355,7 @@ func TestSupportedProvidersList(t *testing.T) {
 		llm.ProviderAnthropic,
 		llm.ProviderOpenAI,
 		llm.ProviderMistral,
+		llm.ProviderCohere,
 		llm.ProviderOllama,
 		llm.ProviderHTTP,
 		llm.ProviderMock,
@@ -455,3 +461,511 @@ func (p *failingTestProvider) Name() string { return "failing" }
 func (p *failingTestProvider) GeneratePlan(ctx *llm.PlanContext) (*llm.RunPlan, error) {
 	return nil, llm.ErrTimeout
 }
+
+// TestHTTPProviderCustomHeaders verifies extra headers and auth schemes
+func TestHTTPProviderCustomHeaders(t *testing.T) {
+	planJSON := `{"version": "1", "project_type": "node", "prerequisites": [], "steps": [{"id": "run", "cmd": "npm start", "cwd": ".", "risk": "low"}], "env": {}, "ports": [], "notes": []}`
+
+	tests := []struct {
+		name       string
+		scheme     llm.AuthScheme
+		wantAuth   string
+		wantAPIKey string
+	}{
+		{"bearer", llm.AuthBearer, "Bearer secret-token", "key-123"},
+		{"raw", llm.AuthRaw, "secret-token", "key-123"},
+		{"none", llm.AuthNone, "", "key-123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != tt.wantAuth {
+					t.Errorf("Expected Authorization %q, got %q", tt.wantAuth, got)
+				}
+				if got := r.Header.Get("X-Api-Key"); got != tt.wantAPIKey {
+					t.Errorf("Expected X-Api-Key %q, got %q", tt.wantAPIKey, got)
+				}
+				json.NewEncoder(w).Encode(map[string]string{"content": planJSON})
+			}))
+			defer server.Close()
+
+			p := provider.NewHTTPProvider(&llm.ProviderConfig{
+				Type:       llm.ProviderHTTP,
+				Endpoint:   server.URL,
+				Token:      "secret-token",
+				Timeout:    5 * time.Second,
+				Headers:    map[string]string{"X-Api-Key": "key-123"},
+				AuthScheme: tt.scheme,
+			})
+
+			if _, err := p.GeneratePlan(&llm.PlanContext{OS: "linux"}); err != nil {
+				t.Fatalf("GeneratePlan failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestHTTPProviderFormats verifies the request and response shape of each format
+func TestHTTPProviderFormats(t *testing.T) {
+	planJSON := `{"version": "1", "project_type": "go", "prerequisites": [], "steps": [{"id": "build", "cmd": "go build ./...", "cwd": ".", "risk": "low"}], "env": {}, "ports": [], "notes": []}`
+
+	tests := []struct {
+		format   llm.HTTPFormat
+		check    func(t *testing.T, body map[string]any, r *http.Request)
+		response any
+	}{
+		{
+			format: llm.FormatOpenAI,
+			check: func(t *testing.T, body map[string]any, r *http.Request) {
+				messages, _ := body["messages"].([]any)
+				if len(messages) != 2 || messages[0].(map[string]any)["role"] != "system" {
+					t.Errorf("expected system and user messages, got %v", body["messages"])
+				}
+			},
+			response: map[string]any{"choices": []any{map[string]any{"message": map[string]string{"content": planJSON}}}},
+		},
+		{
+			format: llm.FormatAnthropic,
+			check: func(t *testing.T, body map[string]any, r *http.Request) {
+				messages, _ := body["messages"].([]any)
+				if body["system"] == "" || len(messages) != 1 || messages[0].(map[string]any)["role"] != "user" {
+					t.Errorf("expected a system prompt and one user message, got %v", body)
+				}
+				if body["max_tokens"] == nil {
+					t.Error("expected max_tokens, which the Messages API requires")
+				}
+				if r.Header.Get("anthropic-version") == "" {
+					t.Error("expected an anthropic-version header")
+				}
+			},
+			response: map[string]any{"content": []any{map[string]string{"type": "text", "text": planJSON}}},
+		},
+		{
+			format: llm.FormatOllamaGenerate,
+			check: func(t *testing.T, body map[string]any, r *http.Request) {
+				if prompt, _ := body["prompt"].(string); !strings.Contains(prompt, "linux") || body["messages"] != nil {
+					t.Errorf("expected a single prompt, got %v", body)
+				}
+				if body["stream"] != false {
+					t.Errorf("expected stream false, got %v", body["stream"])
+				}
+			},
+			response: map[string]any{"response": planJSON, "done": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid request body: %v", err)
+				}
+				tt.check(t, body, r)
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			p := provider.NewHTTPProvider(&llm.ProviderConfig{
+				Type:     llm.ProviderHTTP,
+				Endpoint: server.URL,
+				Timeout:  5 * time.Second,
+				Format:   tt.format,
+			})
+
+			plan, err := p.GeneratePlan(&llm.PlanContext{OS: "linux"})
+			if err != nil {
+				t.Fatalf("GeneratePlan failed: %v", err)
+			}
+			if plan.ProjectType != "go" {
+				t.Errorf("Expected project type go, got %s", plan.ProjectType)
+			}
+		})
+	}
+
+	if _, err := llm.ParseHTTPFormat("grpc"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	t.Setenv("RD_LLM_FORMAT", "anthropic")
+	if config := llm.ResolveProviderConfig("http", "http://localhost:9999", "", "", 0, false); config.Format != llm.FormatAnthropic {
+		t.Errorf("Expected format anthropic from env, got %q", config.Format)
+	}
+}
+
+// TestParseHeaders verifies RD_LLM_HEADERS parsing
+func TestParseHeaders(t *testing.T) {
+	headers, err := llm.ParseHeaders("X-Api-Key=abc, X-Team: infra")
+	if err != nil {
+		t.Fatalf("ParseHeaders failed: %v", err)
+	}
+	if headers["X-Api-Key"] != "abc" || headers["X-Team"] != "infra" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+
+	if _, err := llm.ParseHeaders("missing-separator"); err == nil {
+		t.Error("Expected error for header without value")
+	}
+}
+
+// TestHeadersFromEnv verifies RD_LLM_HEADERS and RD_LLM_AUTH_SCHEME resolution
+func TestHeadersFromEnv(t *testing.T) {
+	t.Setenv("RD_LLM_HEADERS", "X-Api-Key=from-env")
+	t.Setenv("RD_LLM_AUTH_SCHEME", "none")
+
+	config := llm.ResolveProviderConfig("http", "http://localhost:9999", "", "", 0, false)
+
+	if config.Headers["X-Api-Key"] != "from-env" {
+		t.Errorf("Expected header from env, got %v", config.Headers)
+	}
+	if config.AuthScheme != llm.AuthNone {
+		t.Errorf("Expected auth scheme none, got %q", config.AuthScheme)
+	}
+}
+
+// TestSelectOllamaModel verifies instruct/code models are preferred
+func TestSelectOllamaModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		models   []string
+		expected string
+	}{
+		{"prefers instruct", []string{"llama2:latest", "qwen2.5:7b-instruct"}, "qwen2.5:7b-instruct"},
+		{"prefers code", []string{"tinyllama:latest", "deepseek-coder:6.7b"}, "deepseek-coder:6.7b"},
+		{"skips embedding models", []string{"nomic-embed-text:latest", "phi3:mini"}, "phi3:mini"},
+		{"keeps order on tie", []string{"foo:latest", "bar:latest"}, "foo:latest"},
+		{"none usable", []string{"nomic-embed-text:latest"}, ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := llm.SelectOllamaModel(tt.models); got != tt.expected {
+				t.Errorf("SelectOllamaModel(%v) = %q, want %q", tt.models, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestOllamaModelAutoPick verifies model discovery via /api/tags during resolution
+func TestOllamaModelAutoPick(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"models": []map[string]string{
+				{"name": "llama3.2:latest"},
+				{"name": "qwen2.5-coder:7b-instruct"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("RD_LLM_MODEL", "")
+	config, info := llm.ResolveProviderConfigWithInfo("ollama", server.URL+"/api/chat", "", "", 0, false)
+
+	if config.Model != "qwen2.5-coder:7b-instruct" {
+		t.Errorf("Expected auto-picked coder model, got %q", config.Model)
+	}
+	desc := llm.GetProviderSelectionDescription(info)
+	if !strings.Contains(desc, "qwen2.5-coder:7b-instruct") || !strings.Contains(desc, "auto-picked") {
+		t.Errorf("Expected model in selection description, got %q", desc)
+	}
+}
+
+// TestOllamaNoModelsInstalled verifies a clear error when no models exist
+func TestOllamaNoModelsInstalled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+	}))
+	defer server.Close()
+
+	t.Setenv("RD_LLM_MODEL", "")
+	_, info := llm.ResolveProviderConfigWithInfo("ollama", server.URL+"/api/chat", "", "", 0, false)
+	if info.ModelError == "" {
+		t.Error("Expected ModelError when no models are installed")
+	}
+
+	p, err := provider.NewOllamaProvider(&llm.ProviderConfig{
+		Type:     llm.ProviderOllama,
+		Endpoint: server.URL + "/api/chat",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewOllamaProvider failed: %v", err)
+	}
+	if _, err := p.GeneratePlan(&llm.PlanContext{OS: "linux"}); err != llm.ErrNoOllamaModels {
+		t.Errorf("Expected ErrNoOllamaModels, got %v", err)
+	}
+}
+
+// TestCohereProviderRequest verifies the Cohere chat request shape and that
+// the response text is parsed as a plan
+func TestCohereProviderRequest(t *testing.T) {
+	var got struct {
+		Model    string `json:"model"`
+		Message  string `json:"message"`
+		Preamble string `json:"preamble"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer co-test" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"generation_id":"g1","finish_reason":"COMPLETE","text":"{\"version\":\"1\",\"project_type\":\"go\",\"steps\":[{\"id\":\"build\",\"cmd\":\"go build ./...\",\"cwd\":\".\",\"risk\":\"low\"}]}"}`)
+	}))
+	defer server.Close()
+
+	prov, err := provider.NewCohereProvider(&llm.ProviderConfig{
+		Type:     llm.ProviderCohere,
+		Endpoint: server.URL,
+		Token:    "co-test",
+		Timeout:  2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewCohereProvider failed: %v", err)
+	}
+
+	plan, err := prov.GeneratePlan(&llm.PlanContext{
+		Profile: &scanner.ProjectProfile{Stack: "go"},
+	})
+	if err != nil {
+		t.Fatalf("GeneratePlan failed: %v", err)
+	}
+
+	if got.Model != provider.DefaultCohereModel {
+		t.Errorf("expected default model %s, got %q", provider.DefaultCohereModel, got.Model)
+	}
+	if got.Message == "" || got.Preamble == "" {
+		t.Error("expected the prompt in message and the system prompt in preamble")
+	}
+	if plan.ProjectType != "go" || len(plan.Steps) != 1 || plan.Steps[0].Cmd != "go build ./..." {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+}
+
+// TestFallbackRecordsReason verifies that falling back to mock is recorded
+// in the selection info, both at creation and at plan generation
+func TestFallbackRecordsReason(t *testing.T) {
+	info := &llm.ProviderSelectionInfo{}
+	wrapper := &llm.FallbackProvider{
+		Primary:  &failingTestProvider{},
+		Fallback: provider.NewMockProvider(),
+		Info:     info,
+	}
+
+	if _, err := wrapper.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}}); err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if wrapper.Err != llm.ErrTimeout {
+		t.Errorf("expected primary error to be kept, got %v", wrapper.Err)
+	}
+	if !info.WasFallback || info.FallbackFrom != "failing" || info.FallbackError != llm.ErrTimeout.Error() {
+		t.Errorf("unexpected selection info: %+v", info)
+	}
+	if summary := info.FallbackSummary(); !strings.Contains(summary, "failing") || !strings.Contains(summary, "timed out") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if summary := info.FallbackSummary(); !info.TimedOut || !strings.Contains(summary, "--provider-timeout") {
+		t.Errorf("expected a timeout hint, got TimedOut = %v, summary %q", info.TimedOut, summary)
+	}
+
+	// A provider that cannot be created (no API key) falls back immediately
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("RD_LLM_TOKEN", "")
+	info = &llm.ProviderSelectionInfo{}
+	prov := llm.DefaultRegistry.GetWithInfo(&llm.ProviderConfig{Type: llm.ProviderAnthropic}, info)
+	if prov.Name() != "mock" {
+		t.Errorf("expected mock provider, got %s", prov.Name())
+	}
+	if !info.WasFallback || info.FallbackFrom != "anthropic" || !strings.Contains(info.FallbackError, "ANTHROPIC_API_KEY") {
+		t.Errorf("unexpected selection info: %+v", info)
+	}
+
+	// No fallback, no summary
+	if summary := (&llm.ProviderSelectionInfo{}).FallbackSummary(); summary != "" {
+		t.Errorf("expected empty summary, got %q", summary)
+	}
+}
+
+// TestProviderRetries verifies MaxRetries, backoff and Retry-After handling
+func TestProviderRetries(t *testing.T) {
+	const planJSON = `{"content":"{\"version\":\"1\",\"project_type\":\"go\",\"steps\":[{\"id\":\"build\",\"cmd\":\"go build ./...\",\"cwd\":\".\",\"risk\":\"low\"}]}"}`
+
+	// newServer fails the first failures requests with status
+	newServer := func(failures, status int, retryAfter string) (*httptest.Server, *int) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= failures {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(status)
+				return
+			}
+			fmt.Fprint(w, planJSON)
+		}))
+		return server, &calls
+	}
+	generate := func(url string, retries int, backoff time.Duration) error {
+		prov := provider.NewHTTPProvider(&llm.ProviderConfig{
+			Type:         llm.ProviderHTTP,
+			Endpoint:     url,
+			Timeout:      2 * time.Second,
+			MaxRetries:   retries,
+			RetryBackoff: backoff,
+		})
+		_, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}})
+		return err
+	}
+
+	server, calls := newServer(3, http.StatusInternalServerError, "")
+	if err := generate(server.URL, 3, time.Millisecond); err != nil || *calls != 4 {
+		t.Errorf("3 retries: err = %v, calls = %d, want success after 4 calls", err, *calls)
+	}
+	server.Close()
+
+	server, calls = newServer(1, http.StatusInternalServerError, "")
+	if err := generate(server.URL, llm.NoRetries, time.Millisecond); err == nil || *calls != 1 {
+		t.Errorf("no retries: err = %v, calls = %d, want failure after 1 call", err, *calls)
+	}
+	server.Close()
+
+	server, calls = newServer(1, http.StatusTooManyRequests, "1")
+	start := time.Now()
+	if err := generate(server.URL, 1, time.Millisecond); err != nil || *calls != 2 {
+		t.Errorf("429: err = %v, calls = %d, want success after 2 calls", err, *calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Retry-After: 1 not honored, retried after %s", elapsed)
+	}
+	server.Close()
+
+	// Verbose retry messages go to Out, not stdout
+	server, _ = newServer(1, http.StatusInternalServerError, "")
+	var out bytes.Buffer
+	prov := provider.NewHTTPProvider(&llm.ProviderConfig{
+		Type:         llm.ProviderHTTP,
+		Endpoint:     server.URL,
+		Timeout:      2 * time.Second,
+		RetryBackoff: time.Millisecond,
+		Verbose:      true,
+		Out:          &out,
+	})
+	if _, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}}); err != nil || !strings.Contains(out.String(), "Attempt 1 failed") {
+		t.Errorf("verbose retry: err = %v, out = %q, want the failed attempt in Out", err, out.String())
+	}
+	server.Close()
+
+	// Defaults and parsing
+	config := &llm.ProviderConfig{}
+	if config.Attempts() != 2 || config.RetryDelay(1) != llm.DefaultRetryBackoff || config.RetryDelay(2) != 2*llm.DefaultRetryBackoff {
+		t.Errorf("defaults: attempts = %d, delays = %s, %s", config.Attempts(), config.RetryDelay(1), config.RetryDelay(2))
+	}
+	if n, err := llm.ParseMaxRetries("0"); err != nil || n != llm.NoRetries {
+		t.Errorf("ParseMaxRetries(0) = %d, %v, want NoRetries", n, err)
+	}
+	if _, err := llm.ParseMaxRetries("-2"); err == nil {
+		t.Error("expected an error for a negative retry count")
+	}
+}
+
+// TestProviderTimeout verifies that a hung endpoint fails with ErrTimeout
+// and that a refused connection is not mistaken for one
+func TestProviderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	prov := provider.NewHTTPProvider(&llm.ProviderConfig{
+		Type:       llm.ProviderHTTP,
+		Endpoint:   server.URL,
+		Timeout:    100 * time.Millisecond,
+		MaxRetries: llm.NoRetries,
+	})
+	start := time.Now()
+	_, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}})
+	if !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("hung endpoint: err = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("hung endpoint failed after %s, want about the 100ms timeout", elapsed)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err = llm.NewHTTPClient(time.Second).Get(closed.URL)
+	if err == nil || llm.IsTimeout(err) {
+		t.Errorf("refused connection: err = %v, want a non-timeout error", err)
+	}
+}
+
+// TestRateLimitedFallback verifies that a persistent 429 is reported as
+// rate limiting rather than a generic failure
+func TestRateLimitedFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	primary := provider.NewHTTPProvider(&llm.ProviderConfig{
+		Type:       llm.ProviderHTTP,
+		Endpoint:   server.URL,
+		Timeout:    2 * time.Second,
+		MaxRetries: llm.NoRetries,
+	})
+	info := &llm.ProviderSelectionInfo{}
+	wrapper := &llm.FallbackProvider{Primary: primary, Fallback: provider.NewMockProvider(), Info: info}
+
+	if _, err := wrapper.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}}); err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if !errors.Is(wrapper.Err, llm.ErrRateLimited) {
+		t.Errorf("expected a rate-limit error, got %v", wrapper.Err)
+	}
+	if !info.RateLimited || !strings.Contains(info.FallbackSummary(), "rate limited") {
+		t.Errorf("unexpected selection info: %+v", info)
+	}
+}
+
+// TestProviderTraceRedactsCredentials verifies the -vvv request/response
+// dump and that the token never appears in it
+func TestProviderTraceRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Simulated failure", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var trace bytes.Buffer
+	config := &llm.ProviderConfig{
+		Type:       llm.ProviderHTTP,
+		Endpoint:   server.URL + "?api_key=secret-query",
+		Token:      "secret-token",
+		Timeout:    2 * time.Second,
+		MaxRetries: llm.NoRetries,
+		Trace:      &trace,
+	}
+
+	prov := provider.NewHTTPProvider(config)
+	if _, err := prov.GeneratePlan(&llm.PlanContext{Profile: &scanner.ProjectProfile{Stack: "go"}}); err == nil {
+		t.Fatal("expected the failing endpoint to return an error")
+	}
+
+	out := trace.String()
+	for _, want := range []string{"[trace] → POST", "[trace] ← 500", "Authorization: [REDACTED]", "Simulated failure"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-token") || strings.Contains(out, "secret-query") {
+		t.Errorf("trace leaks a credential:\n%s", out)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// HTTP transport shared by the LLM providers
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Transport timeouts and connection limits. A dead or unreachable endpoint
+// fails after DialTimeout or TLSHandshakeTimeout instead of the whole request
+// timeout. There is no response header timeout: the APIs only send their
+// headers once the plan is generated, which the request timeout bounds.
+const (
+	DialTimeout         = 10 * time.Second
+	TLSHandshakeTimeout = 10 * time.Second
+	KeepAlive           = 30 * time.Second
+	IdleConnTimeout     = 90 * time.Second
+	MaxIdleConns        = 16
+	MaxIdleConnsPerHost = 2
+	MaxConnsPerHost     = 4
+)
+
+// sharedTransport pools connections across providers and health checks
+var sharedTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   DialTimeout,
+		KeepAlive: KeepAlive,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	TLSHandshakeTimeout:   TLSHandshakeTimeout,
+	ExpectContinueTimeout: 1 * time.Second,
+	IdleConnTimeout:       IdleConnTimeout,
+	MaxIdleConns:          MaxIdleConns,
+	MaxIdleConnsPerHost:   MaxIdleConnsPerHost,
+	MaxConnsPerHost:       MaxConnsPerHost,
+}
+
+// Transport returns the transport shared by the providers' HTTP clients
+func Transport() http.RoundTripper {
+	return sharedTransport
+}
+
+// NewHTTPClient returns a client over the shared transport that gives up
+// on a request after timeout
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: sharedTransport}
+}
+
+// IsTimeout reports whether a request error is a timeout: the request's
+// deadline or timeout, or a dial or TLS handshake timeout
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
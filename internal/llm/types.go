@@ -3,7 +3,13 @@
 
 package llm
 
-import "github.com/sony-level/readme-runner/internal/scanner"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
 
 // RiskLevel represents command execution risk
 type RiskLevel string
@@ -15,6 +21,31 @@ const (
 	RiskCritical RiskLevel = "critical"
 )
 
+// Rank orders risk levels from low (1) to critical (4); unknown levels are 0
+func (r RiskLevel) Rank() int {
+	switch r {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	case RiskCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// ParseRiskLevel parses a risk level name (case-insensitive)
+func ParseRiskLevel(value string) (RiskLevel, error) {
+	level := RiskLevel(strings.ToLower(strings.TrimSpace(value)))
+	if level.Rank() == 0 {
+		return "", fmt.Errorf("unknown risk level %q (supported: low, medium, high, critical)", value)
+	}
+	return level, nil
+}
+
 // ProviderType identifies the LLM provider
 type ProviderType string
 
@@ -23,6 +54,7 @@ const (
 	ProviderOpenAI    ProviderType = "openai"
 	ProviderAnthropic ProviderType = "anthropic"
 	ProviderMistral   ProviderType = "mistral"
+	ProviderCohere    ProviderType = "cohere"
 	ProviderOllama    ProviderType = "ollama"
 	ProviderHTTP      ProviderType = "http"
 	ProviderMock      ProviderType = "mock"
@@ -36,11 +68,22 @@ var SupportedProviders = []ProviderType{
 	ProviderAnthropic,
 	ProviderOpenAI,
 	ProviderMistral,
+	ProviderCohere,
 	ProviderOllama,
 	ProviderHTTP,
 	ProviderMock,
 }
 
+// IsHostedProvider reports whether a provider sends prompts to a paid
+// third-party API
+func IsHostedProvider(p ProviderType) bool {
+	switch p {
+	case ProviderAnthropic, ProviderOpenAI, ProviderMistral, ProviderCohere:
+		return true
+	}
+	return false
+}
+
 // DeprecatedProviders lists providers that are no longer supported
 var DeprecatedProviders = []ProviderType{
 	ProviderCopilot,
@@ -50,7 +93,7 @@ var DeprecatedProviders = []ProviderType{
 const ValidPlanVersion = "1"
 
 // ValidProjectTypes are the allowed project types
-var ValidProjectTypes = []string{"docker", "node", "python", "go", "rust", "mixed"}
+var ValidProjectTypes = []string{"docker", "node", "python", "go", "rust", "java", "dotnet", "ruby", "php", "elixir", "cpp", "kubernetes", "mixed"}
 
 // Provider interface for LLM providers
 type Provider interface {
@@ -65,6 +108,7 @@ type PlanContext struct {
 	ReadmeInfo   *scanner.ReadmeInfo     // README metadata and content
 	Profile      *scanner.ProjectProfile // Detected project profile
 	ClarityScore float64                 // README clarity score (0.0-1.0)
+	Threshold    float64                 // Clarity threshold the score was compared to
 	UseReadme    bool                    // Whether to primarily use README
 	OS           string                  // Target OS (linux, darwin, windows)
 	Verbose      bool                    // Enable verbose output
@@ -72,31 +116,88 @@ type PlanContext struct {
 
 // RunPlan is the JSON v1 schema for execution plans
 type RunPlan struct {
-	Version       string            `json:"version"`
-	ProjectType   string            `json:"project_type"`
-	Prerequisites []Prerequisite    `json:"prerequisites"`
-	Steps         []Step            `json:"steps"`
-	Env           map[string]string `json:"env"`
-	Ports         []int             `json:"ports"`
-	Notes         []string          `json:"notes"`
+	Version       string            `json:"version" yaml:"version"`
+	ProjectType   string            `json:"project_type" yaml:"project_type"`
+	Prerequisites []Prerequisite    `json:"prerequisites" yaml:"prerequisites"`
+	Steps         []Step            `json:"steps" yaml:"steps"`
+	Env           map[string]string `json:"env" yaml:"env"`
+	Ports         []int             `json:"ports" yaml:"ports"`
+	Notes         []string          `json:"notes" yaml:"notes"`
+	HealthCheck   *HealthCheck      `json:"health_check,omitempty" yaml:"health_check,omitempty"`
+}
+
+// HealthCheck defines an HTTP endpoint polled after the run step
+type HealthCheck struct {
+	URL            string `json:"url" yaml:"url"`
+	ExpectedStatus int    `json:"expected_status,omitempty" yaml:"expected_status,omitempty"` // 0 = 200
+	Timeout        int    `json:"timeout,omitempty" yaml:"timeout,omitempty"`                 // seconds, 0 = default
 }
 
 // Prerequisite defines a required tool
 type Prerequisite struct {
-	Name       string `json:"name"`
-	Reason     string `json:"reason"`
-	MinVersion string `json:"min_version,omitempty"`
+	Name       string `json:"name" yaml:"name"`
+	Reason     string `json:"reason" yaml:"reason"`
+	MinVersion string `json:"min_version,omitempty" yaml:"min_version,omitempty"`
 }
 
 // Step defines an execution step
 type Step struct {
-	ID           string    `json:"id"`
-	Cmd          string    `json:"cmd"`
-	Cwd          string    `json:"cwd"`
-	Risk         RiskLevel `json:"risk"`
-	RequiresSudo bool      `json:"requires_sudo"`
-	Timeout      int       `json:"timeout,omitempty"`      // seconds, 0 = default
-	Description  string    `json:"description,omitempty"` // optional description
+	ID           string    `json:"id" yaml:"id"`
+	Cmd          string    `json:"cmd" yaml:"cmd"`
+	Cwd          string    `json:"cwd" yaml:"cwd"`
+	Risk         RiskLevel `json:"risk" yaml:"risk"`
+	RequiresSudo bool      `json:"requires_sudo" yaml:"requires_sudo"`
+	Timeout      int       `json:"timeout,omitempty" yaml:"timeout,omitempty"`         // seconds, 0 = default
+	Description  string    `json:"description,omitempty" yaml:"description,omitempty"` // optional description
+	DependsOn    []string  `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`   // step IDs that must complete first
+	Detach       bool      `json:"detach,omitempty" yaml:"detach,omitempty"`           // keep running in the background once up, like --detach
+
+	// ExportEnv is added to the environment of later steps once this step
+	// succeeds (values can also be written to $RDR_ENV at run time)
+	ExportEnv map[string]string `json:"export_env,omitempty" yaml:"export_env,omitempty"`
+}
+
+// SubprojectSeparator joins a subproject directory and a step ID in
+// monorepo plans, e.g. "frontend/install"
+const SubprojectSeparator = "/"
+
+// SubprojectStepID returns the ID of a subproject's step in a merged plan
+func SubprojectStepID(subproject, id string) string {
+	return subproject + SubprojectSeparator + id
+}
+
+// SplitStepID splits a merged step ID into its subproject and plain step ID.
+// The subproject is "" for steps of a single-project plan.
+func SplitStepID(id string) (subproject, stepID string) {
+	if i := strings.LastIndex(id, SubprojectSeparator); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// CwdEscapes reports whether a step cwd points outside the directory it is
+// relative to: an absolute path, or one that climbs above it with "..".
+// Only the path text is checked; symlinks are resolved by the runner.
+func CwdEscapes(cwd string) bool {
+	if filepath.IsAbs(cwd) || strings.HasPrefix(cwd, "/") || filepath.VolumeName(cwd) != "" {
+		return true
+	}
+	clean := filepath.ToSlash(filepath.Clean(cwd))
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// ValidEnvKey reports whether key is a valid environment variable name
+func ValidEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		letter := r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
 }
 
 // Validate checks if the RunPlan is valid
@@ -126,6 +227,16 @@ func (p *RunPlan) Validate() error {
 		}
 	}
 
+	if p.HealthCheck != nil {
+		url := p.HealthCheck.URL
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return &ValidationError{Field: "health_check", Message: "url must start with http:// or https://: " + url}
+		}
+		if p.HealthCheck.Timeout < 0 {
+			return &ValidationError{Field: "health_check", Message: "timeout must not be negative"}
+		}
+	}
+
 	return nil
 }
 
@@ -169,6 +280,29 @@ func (p *RunPlan) HasSudoSteps() bool {
 	return false
 }
 
+// HasDependencies returns true if any step declares depends_on. Such plans
+// form a dependency graph: a step without depends_on does not wait for the
+// steps before it.
+func (p *RunPlan) HasDependencies() bool {
+	for _, step := range p.Steps {
+		if len(step.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// StepsAboveRisk returns the steps whose risk exceeds max
+func (p *RunPlan) StepsAboveRisk(max RiskLevel) []Step {
+	var above []Step
+	for _, step := range p.Steps {
+		if step.Risk.Rank() > max.Rank() {
+			above = append(above, step)
+		}
+	}
+	return above
+}
+
 // GetHighRiskSteps returns steps with high or critical risk
 func (p *RunPlan) GetHighRiskSteps() []Step {
 	var highRisk []Step
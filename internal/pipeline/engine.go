@@ -0,0 +1,1136 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Engine composing fetch → scan → plan → validate → prereq → execute
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/fetcher"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/plan"
+	"github.com/sony-level/readme-runner/internal/prereq"
+	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/security"
+	"github.com/sony-level/readme-runner/internal/stacks"
+	"github.com/sony-level/readme-runner/internal/workspace"
+)
+
+// ErrInterrupted is returned by Run when ctx is cancelled (e.g. Ctrl+C)
+// before the run finished
+var ErrInterrupted = errors.New("interrupted")
+
+// Engine runs the rdr pipeline. The CLI is a thin wrapper around it; other
+// tools can embed it to plan and run projects without spawning rdr.
+type Engine struct{}
+
+// New creates an Engine
+func New() *Engine {
+	return &Engine{}
+}
+
+// run holds the state of one Engine.Run call
+type run struct {
+	ctx      context.Context
+	opts     Options
+	out      io.Writer
+	progress io.Writer
+	trace    io.Writer
+	report   *Report
+
+	ws           *workspace.Workspace
+	repoDir      string // project directory: the workspace copy, or the source with InPlace
+	engine       string // container engine
+	engineReason string
+	scanResult   *scanner.ScanResult
+	subprojects  []subproject
+	readmeFirst  bool // the root project's plan was generated README-first
+}
+
+// Run fetches, scans, plans, validates and (unless DryRun) executes the
+// project in opts.Input. The report is returned with the error of a failed
+// run as soon as a workspace was created; it is nil for invalid options.
+func (e *Engine) Run(ctx context.Context, opts Options) (report *Report, runErr error) {
+	if opts.MaxParallel < 1 {
+		opts.MaxParallel = 1
+	}
+	if opts.StepTimeout <= 0 {
+		opts.StepTimeout = exec.DefaultStepTimeout
+	}
+	if opts.Strategy == "" {
+		opts.Strategy = llm.StrategyAuto
+	}
+
+	r := &run{ctx: ctx, opts: opts, out: opts.Out, progress: opts.Progress, trace: opts.Trace}
+	if r.out == nil {
+		r.out = io.Discard
+	}
+	if r.progress == nil {
+		r.progress = r.out
+	}
+	if r.trace == nil {
+		r.trace = r.progress
+	}
+
+	// Container steps run with the image's sh, not a host shell
+	if !opts.DryRun && opts.Isolation == exec.IsolationNone {
+		if err := exec.CheckShell(opts.Shell); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	r.engine, r.engineReason, err = prereq.ResolveContainerEngine(opts.ContainerEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.openWorkspace(); err != nil {
+		return nil, err
+	}
+
+	// Ensure cleanup happens at the end
+	defer func() {
+		if cleanupErr := r.ws.Cleanup(); cleanupErr != nil {
+			r.noticef("Warning: cleanup failed: %v\n", cleanupErr)
+		}
+	}()
+
+	r.startMeta()
+
+	// Record the outcome before the workspace is cleaned up
+	defer func() {
+		r.report.Meta.Finish(runErr)
+		if err := r.ws.WriteMeta(r.report.Meta); err != nil {
+			r.noticef("Warning: %v\n", err)
+		}
+		if r.opts.Record != nil {
+			if err := r.opts.Record(r.report); err != nil {
+				r.noticef("Warning: %v\n", err)
+			}
+		}
+	}()
+
+	// The phase that was running reports the cancellation in its own
+	// words (fetch failed, execution failed, ...)
+	err = r.phases()
+	if err != nil && ctx.Err() != nil {
+		err = ErrInterrupted
+	}
+	return r.report, err
+}
+
+// phases runs the pipeline phases in order
+func (r *run) phases() error {
+	if r.opts.DryRun {
+		r.progressf("\n[DRY-RUN MODE] No commands will be executed.\n")
+	}
+
+	for _, phase := range []func() error{r.fetch, r.scan, r.plan, r.validate} {
+		if err := r.runPhase(phase); err != nil {
+			return err
+		}
+	}
+
+	// 'rdr plan --export' stops here and writes the plan instead of running it
+	if r.opts.Export != nil {
+		if r.opts.CheckPrereqs {
+			if err := r.runPhase(r.checkPrerequisites); err != nil {
+				return err
+			}
+		}
+		return r.opts.Export(r.report.Plan)
+	}
+
+	// 'rdr prepare' gets the host ready for the plan without running it
+	if r.opts.Prepare {
+		for _, phase := range []func() error{r.checkPrerequisites, r.installPrerequisites} {
+			if err := r.runPhase(phase); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	r.checkHostPorts()
+	for _, phase := range []func() error{r.checkPrerequisites, r.execute} {
+		if err := r.runPhase(phase); err != nil {
+			return err
+		}
+	}
+	r.postRun()
+	return nil
+}
+
+// runPhase runs a phase unless the run was cancelled before it started
+func (r *run) runPhase(phase func() error) error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	return phase()
+}
+
+// progressf prints phase headers and progress lines
+func (r *run) progressf(format string, args ...any) {
+	fmt.Fprintf(r.progress, format, args...)
+}
+
+// noticef prints warnings, prompts and summaries
+func (r *run) noticef(format string, args ...any) {
+	fmt.Fprintf(r.out, format, args...)
+}
+
+// excludeDirs returns the directories pruned from a scan rooted at subdir
+// ("" = repository root): the scanner defaults plus any --exclude entries,
+// with paths made relative to subdir
+func (r *run) excludeDirs(subdir string) []string {
+	dirs := append([]string{}, scanner.DefaultExcludeDirs...)
+	for _, dir := range scanner.NormalizeExcludeDirs(r.opts.ExcludeDirs) {
+		if subdir != "" && strings.Contains(dir, "/") {
+			rel, ok := strings.CutPrefix(dir, subdir+"/")
+			if !ok {
+				continue
+			}
+			dir = rel
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// repoPath returns the directory the project is scanned and run in
+func (r *run) repoPath() string {
+	if r.repoDir != "" {
+		return r.repoDir
+	}
+	return r.ws.RepoPath()
+}
+
+// verbose reports whether -v phase detail is shown
+func (r *run) verbose() bool {
+	return r.opts.Verbosity >= VerbosityDetail
+}
+
+// confirm asks a y/N question through Options.Confirm
+func (r *run) confirm(question string) bool {
+	return r.opts.Confirm != nil && r.opts.Confirm(question)
+}
+
+// openWorkspace creates a new workspace, or reopens the one being resumed
+func (r *run) openWorkspace() error {
+	// Workspaces live outside the project (OS temp dir unless overridden)
+	baseDir, err := workspace.ResolveBaseDir(r.opts.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+
+	// Incremental runs compare against the state of the previous run
+	wsConfig := &workspace.WorkspaceConfig{
+		BaseDir: baseDir,
+		Keep:    r.opts.Keep || (r.opts.Incremental && !r.opts.DryRun),
+	}
+
+	if r.opts.ResumeRunID != "" {
+		r.ws, err = workspace.Open(wsConfig, r.opts.ResumeRunID)
+		if err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+	} else {
+		r.ws, err = workspace.New(wsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+	}
+
+	// Display workspace info
+	if r.verbose() {
+		r.progressf("Workspace created:\n")
+		r.progressf("  Run ID:    %s\n", r.ws.RunID)
+		r.progressf("  Path:      %s\n", r.ws.Path)
+		r.progressf("  Repo:      %s\n", r.ws.RepoPath())
+		r.progressf("  Plan:      %s\n", r.ws.PlanPath())
+		r.progressf("  Logs:      %s\n", r.ws.LogsPath())
+		r.progressf("  Keep:      %v\n", r.ws.ShouldKeep())
+		r.progressf("\n")
+	}
+	return nil
+}
+
+// startMeta records what the workspace is for ('rdr workspaces' lists it)
+func (r *run) startMeta() {
+	ws := r.ws
+	r.progressf("Run ID: %s\n", ws.RunID)
+	r.progressf("Input: %s\n", r.opts.Input)
+
+	// Detect source type for display
+	sourceType := fetcher.DetectSourceType(r.opts.Input)
+	r.progressf("Source type: %s\n", sourceType)
+
+	source := r.opts.Input
+	if sourceType == fetcher.SourceTypeLocal {
+		if abs, err := filepath.Abs(r.opts.Input); err == nil {
+			source = abs
+		}
+	}
+	meta := &workspace.Meta{RunID: ws.RunID, Source: source, SourceType: sourceType}
+	if r.opts.ResumeRunID != "" {
+		if prev, err := workspace.ReadMeta(ws.MetaFile()); err == nil {
+			if prev.Source != "" && prev.Source != source {
+				r.noticef("  → ⚠ Run %s was created for %s, not %s\n", ws.RunID, prev.Source, source)
+			}
+			meta = prev
+		}
+	}
+	meta.DryRun = r.opts.DryRun
+	meta.StartedAt = time.Now()
+	meta.EndedAt, meta.Success, meta.Error = nil, nil, ""
+	if err := ws.WriteMeta(meta); err != nil {
+		r.noticef("Warning: %v\n", err)
+	}
+
+	r.report = &Report{Workspace: ws, Meta: meta}
+}
+
+// fetch is phase 1: copy or clone the project into the workspace
+func (r *run) fetch() error {
+	ws := r.ws
+	r.progressf("\n[1/7] Fetch / Workspace\n")
+	r.progressf("  → Workspace ready at %s\n", ws.Path)
+
+	if r.opts.ResumeRunID != "" && !r.opts.InPlace {
+		// Resumed runs continue in the project files left by the previous run
+		r.progressf("  → Resuming run %s: reusing project files in %s\n", ws.RunID, ws.RepoPath())
+		return nil
+	}
+
+	fetchConfig := &fetcher.FetchConfig{
+		Source:       r.opts.Input,
+		Destination:  ws.RepoPath(),
+		Verbose:      r.verbose(),
+		Progress:     r.progress,
+		ShallowClone: true, // Use shallow clone for efficiency
+		InPlace:      r.opts.InPlace,
+	}
+
+	r.progressf("  → Fetching project...\n")
+	fetchResult, err := fetcher.FetchWithContext(r.ctx, fetchConfig)
+	if err != nil {
+		return fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	if fetchResult.InPlace {
+		r.repoDir = fetchResult.Destination
+		r.report.Meta.InPlace = true
+		r.noticef("  → ⚠ --in-place: using %s directly, not a copy\n", r.repoDir)
+		if !r.opts.DryRun {
+			r.noticef("    Steps run in your source tree without a sandbox; what they change or delete there is not undone\n")
+		}
+	} else {
+		r.progressf("  → Fetched %d files (%d bytes) to %s\n",
+			fetchResult.FilesCopied, fetchResult.BytesCopied, fetchResult.Destination)
+	}
+	if fetchResult.IsGitRepo {
+		r.progressf("  → Source is a git repository\n")
+	}
+	return nil
+}
+
+// scan is phase 2: scan the project files and detect the stack
+func (r *run) scan() error {
+	verbose := r.verbose()
+	r.progressf("\n[2/7] Scan\n")
+	r.progressf("  → Scanning workspace for project files...\n")
+
+	scanConfig := &scanner.ScanConfig{
+		RootPath:    r.repoPath(),
+		MaxDepth:    3,
+		Verbose:     verbose,
+		ExcludeDirs: r.excludeDirs(""),
+	}
+
+	scanResult, err := scanner.ScanWithContext(r.ctx, scanConfig)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace: %w", err)
+	}
+	r.scanResult = scanResult
+	r.report.Profile = scanResult.Profile
+	if r.opts.AssumeStack != "" && scanResult.Profile != nil {
+		detected := scanResult.Profile.Stack
+		scanResult.Profile.AssumeStack(r.opts.AssumeStack)
+		r.noticef("  → Stack: %s (--assume-stack; detected %s)\n", r.opts.AssumeStack, detected)
+	}
+
+	r.progressf("  → Scanned %d files in %d directories (%v)\n",
+		scanResult.TotalFiles, scanResult.TotalDirs, scanResult.ScanDuration)
+	if verbose {
+		r.progressf("  → Excluding: %s\n", strings.Join(scanConfig.ExcludeDirs, ", "))
+		if len(scanResult.ExcludedDirs) > 0 {
+			r.progressf("  → Excluded directories: %s\n", strings.Join(scanResult.ExcludedDirs, ", "))
+		}
+	}
+	if scanResult.Profile != nil {
+		r.report.Meta.Stack = scanResult.Profile.Stack
+	}
+
+	// Display README info
+	if scanResult.ReadmeFile != nil {
+		r.progressf("  → README found: %s (%d bytes)\n",
+			scanResult.ReadmeFile.RelPath, scanResult.ReadmeFile.Size)
+
+		// Show README preview in verbose mode
+		if verbose && scanResult.ReadmeFile.Content != "" {
+			lines := strings.Split(scanResult.ReadmeFile.Content, "\n")
+			r.progressf("    Preview:\n")
+			previewLines := 0
+			for _, line := range lines {
+				if previewLines >= 5 { // Show first 5 non-empty lines
+					r.progressf("      ...\n")
+					break
+				}
+				trimmed := strings.TrimSpace(line)
+				if trimmed != "" {
+					// Truncate long lines
+					if len(trimmed) > 60 {
+						trimmed = trimmed[:57] + "..."
+					}
+					r.progressf("      %s\n", trimmed)
+					previewLines++
+				}
+			}
+
+			// Show truncation warning
+			if scanResult.ReadmeFile.Truncated {
+				r.progressf("    (Content truncated: was %d bytes)\n",
+					scanResult.ReadmeFile.OriginalSize)
+			}
+		}
+
+		if verbose {
+			r.progressf("    Sections: %d\n", len(scanResult.ReadmeFile.Sections))
+			r.progressf("    Code blocks: %d\n", scanResult.ReadmeFile.CodeBlocks)
+			r.progressf("    Shell commands: %d\n", scanResult.ReadmeFile.ShellCommands)
+			if scanResult.ReadmeFile.HasInstall {
+				r.progressf("    ✓ Has installation section\n")
+			}
+			if scanResult.ReadmeFile.HasUsage {
+				r.progressf("    ✓ Has usage section\n")
+			}
+			if scanResult.ReadmeFile.HasBuild {
+				r.progressf("    ✓ Has build section\n")
+			}
+			if scanResult.ReadmeFile.HasQuickStart {
+				r.progressf("    ✓ Has quick start section\n")
+			}
+		}
+	} else {
+		r.noticef("  → ⚠ No README found\n")
+	}
+
+	if scanResult.License != nil {
+		license := scanResult.License.SPDX
+		if license == "" {
+			license = "unrecognized"
+		}
+		r.progressf("  → License: %s (%s)\n", license, scanResult.License.RelPath)
+	}
+
+	// Display detected stacks (legacy method)
+	detectedStacks := scanResult.DetectedStacks()
+	if len(detectedStacks) > 0 {
+		r.progressf("  → Primary stack: %s\n", scanResult.PrimaryStack())
+		r.progressf("  → All stacks: %s\n", strings.Join(detectedStacks, ", "))
+	}
+
+	// Display ProjectProfile in verbose mode
+	if verbose && scanResult.Profile != nil {
+		profile := scanResult.Profile
+
+		r.progressf("  → Project Profile:\n")
+		r.progressf("    Root: %s\n", profile.Root)
+		r.progressf("    Primary stack: %s\n", profile.Stack)
+		if profile.Framework != "" {
+			r.progressf("    Framework: %s\n", profile.Framework)
+		}
+
+		if len(profile.Languages) > 0 {
+			r.progressf("    Languages: %s\n", strings.Join(profile.Languages, ", "))
+		}
+
+		if len(profile.Tools) > 0 {
+			r.progressf("    Tools: %s\n", strings.Join(profile.Tools, ", "))
+		}
+
+		if len(profile.Containers) > 0 {
+			r.progressf("    Containers: %s\n", strings.Join(profile.Containers, ", "))
+		}
+
+		if len(profile.Packages) > 0 {
+			r.progressf("    Package files: %s\n", strings.Join(profile.Packages, ", "))
+		}
+
+		if len(profile.Signals) > 0 {
+			maxSignals := 5
+			if len(profile.Signals) <= maxSignals {
+				r.progressf("    Key signals: %s\n", strings.Join(profile.Signals, ", "))
+			} else {
+				r.progressf("    Key signals: %s\n", strings.Join(profile.Signals[:maxSignals], ", "))
+				r.progressf("      ... and %d more\n", len(profile.Signals)-maxSignals)
+			}
+		}
+	}
+
+	// -vv dumps the whole profile, as the planner sees it
+	if r.opts.Verbosity >= VerbosityDebug && scanResult.Profile != nil {
+		if data, err := json.MarshalIndent(scanResult.Profile, "    ", "  "); err == nil {
+			r.progressf("  → Profile JSON:\n    %s\n", data)
+		}
+	}
+
+	// Run stack detection
+	if scanResult.Profile != nil {
+		aggregator := stacks.NewAggregator()
+		detection := stacks.AssumeStack(aggregator.Detect(scanResult.Profile), r.opts.AssumeStack)
+
+		r.progressf("  → Stack Detection:\n")
+		r.progressf("    Dominant: %s (confidence: %.2f)\n",
+			detection.Dominant.Name, detection.Dominant.Confidence)
+
+		if detection.IsMixed {
+			r.progressf("    Type: Mixed project\n")
+		}
+
+		if verbose {
+			r.progressf("    Explanation: %s\n", detection.Explanation)
+
+			if len(detection.Matches) > 1 {
+				r.progressf("    All detected stacks:\n")
+				for _, match := range detection.Matches {
+					r.progressf("      • %s (confidence: %.2f, priority: %d)\n",
+						match.Name, match.Confidence, match.Priority)
+					for _, reason := range match.Reasons {
+						r.progressf("        - %s\n", reason)
+					}
+				}
+			} else if len(detection.Matches) == 1 {
+				r.progressf("    Reasons:\n")
+				for _, reason := range detection.Dominant.Reasons {
+					r.progressf("      - %s\n", reason)
+				}
+			}
+		}
+	}
+
+	// Display project files in verbose mode
+	if verbose && len(scanResult.ProjectFiles) > 0 {
+		r.progressf("  → Project files:\n")
+		for fileType, paths := range scanResult.ProjectFiles {
+			r.progressf("    %s: %s\n", fileType, strings.Join(paths, ", "))
+		}
+	}
+
+	// In monorepo mode each subproject is scanned and planned on its own
+	if r.opts.Monorepo && r.opts.ResumeRunID == "" && r.opts.Plan == nil {
+		r.subprojects, err = r.scanSubprojects(r.repoPath())
+		if err != nil {
+			return err
+		}
+		if len(r.subprojects) == 0 {
+			r.noticef("  → ⚠ No subprojects with their own manifest found; planning the repository as one project\n")
+		} else {
+			r.progressf("  → Monorepo: %d subprojects\n", len(r.subprojects))
+			for _, sub := range r.subprojects {
+				r.progressf("    • %s: %s\n", sub.dir, sub.scan.Profile.Stack)
+			}
+			r.report.Meta.Stack = "mixed"
+		}
+	}
+	return nil
+}
+
+// plan is phase 3: generate the plan, reload it when resuming, or take the
+// one given in the options
+func (r *run) plan() error {
+	r.progressf("\n[3/7] Plan (AI)\n")
+
+	var runPlan *llm.RunPlan
+	var err error
+	meta := r.report.Meta
+	if r.opts.ResumeRunID != "" {
+		// Reuse the exact plan of the previous run so step IDs stay stable
+		runPlan, err = plan.LoadFile(r.ws.PlanFile())
+		if err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		r.progressf("  → Loaded saved plan from %s\n", r.ws.PlanFile())
+	} else if r.opts.Plan != nil {
+		runPlan = r.opts.Plan
+		r.progressf("  → Using the given plan instead of generating one\n")
+	} else if len(r.subprojects) > 0 {
+		runPlan, meta.Provider, err = r.generateMonorepoPlan(r.subprojects)
+		if err != nil {
+			return err
+		}
+	} else {
+		runPlan, meta.Provider, err = r.generateRunPlan(r.scanResult)
+		if err != nil {
+			return err
+		}
+	}
+	r.report.Plan = runPlan
+
+	r.progressf("  → Plan generated: %s project with %d steps\n",
+		runPlan.ProjectType, len(runPlan.Steps))
+	return nil
+}
+
+// validate is phase 4: validate, normalize and save the plan
+func (r *run) validate() error {
+	ws := r.ws
+	runPlan := r.report.Plan
+	r.progressf("\n[4/7] Validate / Normalize\n")
+
+	validator := plan.NewValidator()
+	validator.SetSuppressed(r.opts.Suppress)
+	validationResult := validator.Validate(runPlan)
+
+	if !validationResult.Valid {
+		r.noticef("  → ✗ Plan validation failed:\n")
+		for _, err := range validationResult.Errors {
+			r.noticef("      • %s\n", err)
+		}
+		return fmt.Errorf("plan validation failed")
+	}
+
+	r.progressf("  → ✓ Plan is valid\n")
+
+	if len(validationResult.Warnings) > 0 && r.verbose() {
+		r.progressf("  → Warnings:\n")
+		for _, warn := range validationResult.Warnings {
+			r.progressf("      • %s\n", warn)
+		}
+	}
+	if validationResult.Suppressed > 0 && r.verbose() {
+		r.progressf("  → %d warning(s) suppressed by --suppress\n", validationResult.Suppressed)
+	}
+
+	// Normalize plan (a resumed plan was already normalized and is reused as-is;
+	// subproject plans were normalized with their own profiles before merging)
+	if r.opts.ResumeRunID == "" {
+		llmPlan := runPlan
+		if err := plan.SaveFile(llmPlan, ws.LLMPlanFile()); err != nil {
+			r.noticef("Warning: %v\n", err)
+		}
+
+		if len(r.subprojects) == 0 {
+			normalizer := plan.NewNormalizer(r.scanResult.Profile)
+			normalizer.SetContainerEngine(r.engine)
+			runPlan = normalizer.Normalize(runPlan)
+		}
+
+		// Enhance plan with accurate risk levels
+		runPlan = validator.EnhancePlan(runPlan)
+
+		// A trailing "&" makes a step pass before its server is up, and the
+		// server is stopped with the step's process group. --suppress PLAN005
+		// silences the notice, not the offer to detach them.
+		if ids := plan.BackgroundSteps(runPlan); len(ids) > 0 {
+			if !validator.IsSuppressed(plan.CodeBackground) {
+				r.noticef("  → ⚠ %s end(s) with \"&\": the step would succeed at once and the command be stopped with it\n", strings.Join(ids, ", "))
+			}
+			if !r.opts.DryRun && (r.opts.Yes || r.confirm("\n  Drop the \"&\" and keep them running in the background once they are up? [y/N]: ")) {
+				runPlan = plan.DetachBackgroundSteps(runPlan)
+				r.progressf("  → Running %s as detached background step(s)\n", strings.Join(ids, ", "))
+			}
+		}
+
+		r.progressf("  → Plan normalized for %s\n", runtime.GOOS)
+		if r.engine != prereq.EngineDocker {
+			if r.engineReason != "" {
+				r.progressf("  → Container engine: %s (%s)\n", r.engine, r.engineReason)
+			} else {
+				r.progressf("  → Container engine: %s\n", r.engine)
+			}
+		}
+
+		// --verbose shows what normalization and risk enhancement changed
+		if r.verbose() {
+			if changes := plan.DiffPlans(llmPlan, runPlan); len(changes) > 0 {
+				r.progressf("  → Changes to the generated plan:\n%s", plan.FormatPlanDiff(changes, "      "))
+			} else {
+				r.progressf("  → Generated plan unchanged by normalization\n")
+			}
+		}
+
+		if r.opts.Edit != nil {
+			var err error
+			runPlan, validationResult, err = r.opts.Edit(runPlan, ws.PlanPath())
+			if err != nil {
+				return err
+			}
+			r.progressf("  → Plan edited: %d steps\n", len(runPlan.Steps))
+		}
+
+		// Save the plan so the run can be resumed with the same steps
+		if err := plan.SaveFile(runPlan, ws.PlanFile()); err != nil {
+			r.noticef("Warning: %v\n", err)
+		}
+	}
+	r.report.Plan = runPlan
+	r.report.Validation = validationResult
+
+	// Show risk summary
+	r.progressf("  → Risk summary: %s\n", exec.FormatRiskSummary(
+		validationResult.RiskReport.Low,
+		validationResult.RiskReport.Medium,
+		validationResult.RiskReport.High,
+		validationResult.RiskReport.Critical))
+
+	if r.opts.Explain {
+		var readme *scanner.ReadmeInfo
+		if r.readmeFirst {
+			readme = r.scanResult.ReadmeFile
+		}
+		r.noticef("  → Why each step (--explain):\n%s",
+			plan.FormatExplanations(validator.Explain(runPlan, readme), "      "))
+	}
+
+	if runPlan.HasSudoSteps() {
+		sudoCount := security.CountSudoSteps(runPlan)
+		r.noticef("  → ⚠ Plan contains %d step(s) requiring sudo\n", sudoCount)
+	}
+
+	for _, conflict := range plan.FindPortConflicts(runPlan) {
+		r.noticef("  → ⚠ %s\n", conflict)
+	}
+	return nil
+}
+
+// checkHostPorts warns about plan ports already bound on the host, which
+// make the run step fail late
+func (r *run) checkHostPorts() {
+	for _, port := range plan.PlanPorts(r.report.Plan) {
+		if !plan.PortInUse(port) {
+			continue
+		}
+		if free := plan.FreePortNear(port); free != 0 {
+			r.noticef("  → ⚠ Port %d is already in use on the host (nearest free port: %d, e.g. set PORT=%d in the plan env)\n", port, free, free)
+		} else {
+			r.noticef("  → ⚠ Port %d is already in use on the host\n", port)
+		}
+	}
+}
+
+// printExecutionSummary lists what the steps about to run will do outside
+// the project: sudo, system packages, downloads and ports
+func (r *run) printExecutionSummary(skipSteps map[string]bool) {
+	summary := security.NewPolicyChecker(nil).SummarizePlan(r.report.Plan, skipSteps)
+	ports := plan.PlanPorts(r.report.Plan)
+
+	where := "the workspace copy"
+	if r.repoDir != "" {
+		where = "your source tree (--in-place)"
+	}
+	r.noticef("\n  Execution summary:\n")
+	r.noticef("    • %d step(s) will run in %s\n", summary.Steps, where)
+	summaryLine := func(steps []string, what string) {
+		if len(steps) > 0 {
+			r.noticef("    • %d step(s) %s: %s\n", len(steps), what, strings.Join(steps, ", "))
+		}
+	}
+	summaryLine(summary.SudoSteps, "run with sudo")
+	summaryLine(summary.PackageManagerSteps, "use the system package manager")
+	summaryLine(summary.NetworkSteps, "download code or packages")
+	summaryLine(summary.RemoteScriptSteps, "pipe a downloaded script into a shell")
+	if len(ports) > 0 {
+		names := make([]string, len(ports))
+		for i, port := range ports {
+			names[i] = fmt.Sprint(port)
+		}
+		r.noticef("    • Ports to be opened: %s\n", strings.Join(names, ", "))
+	}
+	if len(summary.SudoSteps)+len(summary.PackageManagerSteps)+len(summary.NetworkSteps)+len(summary.RemoteScriptSteps)+len(ports) == 0 {
+		r.noticef("    • No sudo, system package, network or port changes detected\n")
+	}
+}
+
+// createEnvFile copies the project's .env template to a missing .env, after
+// asking (with --yes, always, noting that placeholder values are in use)
+func (r *run) createEnvFile() {
+	if r.scanResult == nil || r.scanResult.EnvExample == nil {
+		return
+	}
+	envExample := r.scanResult.EnvExample
+	envPath := filepath.Join(r.repoPath(), ".env")
+	if _, err := os.Stat(envPath); err == nil {
+		return
+	}
+
+	r.noticef("\n  ⚠ No .env file, but %s lists %d variable(s):\n", envExample.RelPath, len(envExample.Vars))
+	for _, name := range envExample.Vars {
+		if containsString(envExample.Empty, name) {
+			r.noticef("      • %s (empty, fill it in)\n", name)
+		} else {
+			r.noticef("      • %s\n", name)
+		}
+	}
+	if !r.opts.Yes && !r.confirm(fmt.Sprintf("\n  Copy %s to .env? [y/N]: ", envExample.RelPath)) {
+		r.progressf("  → Continuing without .env\n")
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.repoPath(), envExample.RelPath))
+	if err == nil {
+		err = os.WriteFile(envPath, data, 0600)
+	}
+	if err != nil {
+		r.noticef("  → ⚠ Could not create .env: %v\n", err)
+		return
+	}
+	r.noticef("  → Created .env from %s\n", envExample.RelPath)
+	if r.opts.Yes {
+		r.report.Plan.Notes = append(r.report.Plan.Notes,
+			fmt.Sprintf(".env was copied from %s: its placeholder values are in use, edit .env to set real ones", envExample.RelPath))
+	}
+}
+
+// checkPrerequisites is phase 5: check the tools the plan needs
+func (r *run) checkPrerequisites() error {
+	runPlan := r.report.Plan
+	r.progressf("\n[5/7] Prerequisites\n")
+
+	checker := prereq.NewChecker()
+	checkSummary := checker.CheckPrerequisites(runPlan.Prerequisites)
+	r.report.Prerequisites = checkSummary
+
+	if checkSummary.Ready() {
+		r.progressf("  → ✓ All %d prerequisites available\n", len(runPlan.Prerequisites))
+	} else {
+		if !checkSummary.AllFound {
+			r.noticef("  → ✗ Missing prerequisites:\n")
+		}
+		for _, missing := range checkSummary.MissingTools {
+			r.noticef("      • %s\n", missing)
+			guide := checker.GetInstallGuide(missing)
+			if guide != "" && r.verbose() {
+				lines := strings.Split(guide, "\n")
+				for _, line := range lines[:min(3, len(lines))] {
+					r.noticef("        %s\n", line)
+				}
+			}
+		}
+
+		// Installed but not usable, e.g. the Docker daemon is not running
+		for _, result := range checkSummary.Results {
+			if result.Unreachable {
+				r.noticef("  → ✗ %s: %v\n", result.Name, result.Error)
+			}
+			if result.Outdated {
+				r.noticef("  → ✗ %s %s found, %s required\n", result.Name, result.Version, result.MinVersion)
+			}
+		}
+
+		if r.opts.CheckPrereqs {
+			return fmt.Errorf("prerequisites not met: %s", strings.Join(checkSummary.Problems(), ", "))
+		}
+		// 'rdr prepare' installs what is missing next
+		if !r.opts.DryRun && !r.opts.Yes && !r.opts.Prepare {
+			if !r.confirm("\n  Continue anyway? [y/N]: ") {
+				return fmt.Errorf("aborted: prerequisites not available")
+			}
+		}
+	}
+
+	// Show found tools in verbose mode
+	if r.verbose() {
+		for _, result := range checkSummary.Results {
+			if result.Found && !result.Unreachable {
+				version := result.Version
+				if version == "" {
+					version = "version unknown"
+				}
+				// The path shows which binary won when a shim shadows another install
+				if result.Path != "" {
+					r.progressf("  → ✓ %s: %s (%s)\n", result.Name, version, result.Path)
+				} else {
+					r.progressf("  → ✓ %s: %s\n", result.Name, version)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// execute is phase 6: show the plan in a dry run, otherwise run it
+func (r *run) execute() error {
+	ws := r.ws
+	runPlan := r.report.Plan
+	opts := r.opts
+	r.progressf("\n[6/7] Execute\n")
+
+	// On resume, skip the leading steps that completed and are unchanged;
+	// an incremental run skips unchanged install and build steps
+	var skipSteps map[string]bool
+	var prevState *exec.ExecutionState
+	if opts.ResumeRunID != "" {
+		var err error
+		prevState, err = exec.LoadExecutionState(ws.StateFile())
+		if err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		var planChanged bool
+		skipSteps, planChanged = prevState.ResumableSteps(runPlan)
+		if prevState == nil {
+			r.noticef("  → ⚠ No execution state found for %s; starting from the first step\n", ws.RunID)
+		} else if planChanged {
+			r.noticef("  → ⚠ Plan changed since the previous run; resuming from the first changed step\n")
+		}
+		r.progressf("  → Resuming: %d of %d step(s) already completed\n", len(skipSteps), len(runPlan.Steps))
+	} else if opts.Incremental {
+		skipSteps, prevState = r.incrementalSkips(runPlan)
+	}
+
+	// Steps above --max-risk (steps completed in a previous run are not re-run)
+	var aboveRisk []llm.Step
+	if opts.MaxRisk != "" {
+		for _, step := range runPlan.StepsAboveRisk(opts.MaxRisk) {
+			if !skipSteps[step.ID] {
+				aboveRisk = append(aboveRisk, step)
+			}
+		}
+	}
+	if len(aboveRisk) > 0 {
+		r.noticef("\n  ⚠ %d step(s) above --max-risk %s:\n", len(aboveRisk), opts.MaxRisk)
+		for _, step := range aboveRisk {
+			r.noticef("      • %s [%s] $ %s\n", step.ID, step.Risk, step.Cmd)
+		}
+	}
+
+	if opts.DryRun && opts.ListSteps {
+		r.noticef("\n  Steps (dry-run, nothing will be executed):\n%s", exec.FormatStepList(runPlan, skipSteps))
+		return nil
+	}
+	if opts.DryRun {
+		// --verbose adds each step's resolved cwd and the env overrides
+		r.noticef("%s", exec.DryRunDisplayWithOptions(runPlan, r.repoPath(), &exec.DryRunOptions{
+			Sandbox:  opts.Sandbox,
+			Detailed: r.verbose(),
+			Shell:    opts.Shell,
+		}))
+		if opts.Isolation == exec.IsolationDocker {
+			r.noticef("\nIsolation: steps would run in a docker container (image: %s)\n", r.containerImage())
+		}
+		return nil
+	}
+
+	// Risk ceiling: explicit confirmation, never auto-accepted
+	if len(aboveRisk) > 0 {
+		if opts.Yes {
+			return fmt.Errorf("aborted: %d step(s) exceed --max-risk %s (not auto-accepted with --yes)", len(aboveRisk), opts.MaxRisk)
+		}
+		if !r.confirm(fmt.Sprintf("\n  Run steps above %s risk? [y/N]: ", opts.MaxRisk)) {
+			return fmt.Errorf("aborted: steps above --max-risk %s not confirmed", opts.MaxRisk)
+		}
+	}
+
+	// Consent gate: what the plan will change, with one confirmation for
+	// the whole plan (--list-steps adds the step list)
+	if opts.ListSteps {
+		r.noticef("\n  Steps to run:\n%s", exec.FormatStepList(runPlan, skipSteps))
+	}
+	r.printExecutionSummary(skipSteps)
+	if !opts.Yes {
+		question := "\n  Proceed? [y/N]: "
+		if opts.ListSteps {
+			question = fmt.Sprintf("\n  Run these %d step(s)? [y/N]: ", len(runPlan.Steps)-len(skipSteps))
+		}
+		if !r.confirm(question) {
+			return fmt.Errorf("aborted: plan not confirmed")
+		}
+	}
+
+	r.createEnvFile()
+
+	// Track step progress for display
+	totalSteps := len(runPlan.Steps)
+	currentStep := len(skipSteps)
+	currentGroup := ""
+
+	// Persist completed steps so a failed run can be resumed
+	state := exec.NewExecutionState(ws.RunID, runPlan)
+	for i := range runPlan.Steps {
+		step := &runPlan.Steps[i]
+		if !skipSteps[step.ID] {
+			continue
+		}
+		if at, ok := prevState.CompletedAt(runPlan, step); ok {
+			state.MarkCompletedAt(runPlan, step, at)
+		} else {
+			state.MarkCompleted(runPlan, step)
+		}
+	}
+	saveState := func() {
+		if err := state.Save(ws.StateFile()); err != nil {
+			r.noticef("Warning: %v\n", err)
+		}
+	}
+
+	runnerConfig := &exec.RunnerConfig{
+		Mode:            exec.ModeExecute,
+		WorkingDir:      r.repoPath(),
+		AutoYes:         opts.Yes,
+		AllowSudo:       opts.AllowSudo,
+		Verbose:         r.verbose(),
+		ShowEnv:         opts.Verbosity >= VerbosityTrace,
+		StepTimeout:     opts.StepTimeout,
+		GlobalTimeout:   opts.GlobalTimeout,
+		Isolation:       opts.Isolation,
+		Shell:           opts.Shell,
+		Sandbox:         opts.Sandbox,
+		SkipSteps:       skipSteps,
+		MaxParallel:     opts.MaxParallel,
+		MaxRetriesTotal: opts.MaxRetries,
+		Detach:          opts.Detach,
+		LeaveRunning:    opts.LeaveRunning,
+		LogDir:          ws.LogsPath(),
+		Output:          r.out,
+		OnStepStart: func(step *llm.Step) {
+			currentStep++
+			// Group monorepo steps under their subproject
+			if group, _ := llm.SplitStepID(step.ID); group != "" && group != currentGroup {
+				currentGroup = group
+				r.progressf("\n  ▸ %s\n", group)
+			}
+			// Show step number and description/ID
+			stepDesc := step.ID
+			if step.Description != "" {
+				stepDesc = step.Description
+			}
+			r.progressf("\n  → Step %d/%d: %s\n", currentStep, totalSteps, stepDesc)
+			r.progressf("    $ %s\n", step.Cmd)
+			if step.Cwd != "" && step.Cwd != "." {
+				r.progressf("    (in %s)\n", step.Cwd)
+			}
+			if step.RequiresSudo {
+				r.noticef("    ⚠ Requires sudo\n")
+			}
+		},
+		OnStepComplete: func(step *llm.Step, result *exec.StepResult) {
+			r.progressf("    %s\n", exec.FormatStepResult(result))
+			if result.Success && !result.Skipped {
+				state.MarkCompleted(runPlan, step)
+				saveState()
+			}
+		},
+	}
+
+	var execResult *exec.ExecutionResult
+	if opts.Isolation == exec.IsolationDocker {
+		runnerConfig.ContainerImage = opts.ContainerImage
+		containerRunner, err := exec.NewContainerRunner(runnerConfig)
+		if err != nil {
+			return fmt.Errorf("cannot isolate execution: %w", err)
+		}
+		if opts.SudoPrompt != nil {
+			containerRunner.SetSudoPrompt(opts.SudoPrompt)
+		}
+		if opts.FailurePrompt != nil {
+			containerRunner.SetFailurePrompt(opts.FailurePrompt)
+		}
+
+		r.progressf("  → Isolation: docker (image: %s)\n", r.containerImage())
+		if runPlan.HasSudoSteps() {
+			r.noticef("  → ⚠ Sudo steps will be rejected inside the container\n")
+		}
+
+		execResult = containerRunner.ExecuteWithContext(r.ctx, runPlan)
+	} else {
+		runner := exec.NewRunner(runnerConfig)
+		if opts.SudoPrompt != nil {
+			runner.SetSudoPrompt(opts.SudoPrompt)
+		}
+		if opts.FailurePrompt != nil {
+			runner.SetFailurePrompt(opts.FailurePrompt)
+		}
+
+		execResult = runner.ExecuteWithContext(r.ctx, runPlan)
+	}
+	r.report.Execution = execResult
+
+	// Record steps that succeeded after a retry or auto-recovery
+	state.Record(runPlan, execResult)
+	saveState()
+
+	// Show execution summary
+	r.noticef("%s", exec.FormatExecutionResult(execResult))
+
+	// Processes left running and detached compose services need the
+	// directory they run in (compose file, bind mounts, build context)
+	if len(execResult.Processes)+len(execResult.Services) > 0 && r.repoDir == "" && !ws.ShouldKeep() {
+		ws.SetKeep(true)
+		r.noticef("\n  Workspace kept at %s: processes or services started there are still running\n", ws.Path)
+	}
+
+	if !execResult.Success {
+		// Keep the workspace so the run can pick up where it stopped
+		ws.SetKeep(true)
+		r.noticef("\n  Workspace kept at %s\n", ws.Path)
+		r.noticef("  To resume from the first incomplete step, run:\n")
+		if opts.WorkspaceDir != "" {
+			r.noticef("    rdr %s --dry-run=false --workspace-dir %s --resume %s\n", opts.Input, opts.WorkspaceDir, ws.RunID)
+		} else {
+			r.noticef("    rdr %s --dry-run=false --resume %s\n", opts.Input, ws.RunID)
+		}
+		return fmt.Errorf("execution failed")
+	}
+	return nil
+}
+
+// postRun is phase 7: ports, notes and what happens to the workspace
+func (r *run) postRun() {
+	runPlan := r.report.Plan
+	r.progressf("\n[7/7] Post-run / Cleanup\n")
+
+	if len(runPlan.Ports) > 0 {
+		r.noticef("  → Exposed ports: %v\n", runPlan.Ports)
+	}
+
+	if len(runPlan.Notes) > 0 {
+		r.progressf("  → Notes:\n")
+		for _, note := range runPlan.Notes {
+			r.progressf("      • %s\n", note)
+		}
+	}
+
+	if r.opts.Keep {
+		r.progressf("  → Workspace preserved: %s\n", r.ws.Path)
+	} else {
+		r.progressf("  → Workspace will be cleaned up\n")
+	}
+
+	if r.opts.DryRun {
+		r.progressf("\n  To execute this plan, run again without --dry-run:\n")
+		if r.opts.Monorepo {
+			r.progressf("    rdr %s --monorepo --dry-run=false\n", r.opts.Input)
+		} else {
+			r.progressf("    rdr %s --dry-run=false\n", r.opts.Input)
+		}
+	}
+}
+
+// containerImage returns the image --isolate docker will use for the plan
+func (r *run) containerImage() string {
+	if r.opts.ContainerImage != "" {
+		return r.opts.ContainerImage
+	}
+	return exec.ContainerImageFor(r.report.Plan.ProjectType)
+}
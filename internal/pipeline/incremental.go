@@ -0,0 +1,155 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Skipping unchanged install and build steps of in-place runs (--incremental)
+
+package pipeline
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/workspace"
+)
+
+// errChanged stops the walk of newerFile at the first changed file
+var errChanged = errors.New("changed")
+
+// incrementalSkips returns the install and build steps that the last run of
+// the same source completed and whose inputs have not changed since: the
+// package manifests and lockfiles for install steps, any project file for
+// build steps. Skipping stops at the first install or build step that has
+// to run, since the steps after it build on what it produces. The previous
+// run's state is returned with the skips so their completion times carry over.
+func (r *run) incrementalSkips(runPlan *llm.RunPlan) (map[string]bool, *exec.ExecutionState) {
+	prevRunID, prevState := r.lastInPlaceRun()
+	if prevState == nil {
+		r.progressf("  → Incremental: no previous run of %s; running every step\n", r.report.Meta.Source)
+		return nil, nil
+	}
+
+	skip := make(map[string]bool)
+	reason := ""
+	for i := range runPlan.Steps {
+		step := &runPlan.Steps[i]
+		kind := llm.ClassifyStep(step)
+		if kind != llm.StepKindInstall && kind != llm.StepKindBuild {
+			continue
+		}
+		completedAt, ok := prevState.CompletedAt(runPlan, step)
+		if !ok {
+			reason = "step " + step.ID + " did not complete in that run or changed"
+			break
+		}
+		if changed := r.changedInput(step, kind, completedAt); changed != "" {
+			reason = changed + " changed since step " + step.ID + " ran"
+			break
+		}
+		skip[step.ID] = true
+	}
+
+	r.progressf("  → Incremental: %d install/build step(s) unchanged since run %s\n", len(skip), prevRunID)
+	if reason != "" {
+		r.progressf("    Running from there on: %s\n", reason)
+	}
+	return skip, prevState
+}
+
+// lastInPlaceRun returns the most recently started other in-place run of
+// the source with recorded execution state. Run IDs only have minute
+// precision, so the runs are compared by their metadata's start time.
+func (r *run) lastInPlaceRun() (string, *exec.ExecutionState) {
+	infos, err := workspace.List(r.ws.BaseDir)
+	if err != nil {
+		return "", nil
+	}
+	var lastID string
+	var lastState *exec.ExecutionState
+	var lastStart time.Time
+	for _, info := range infos {
+		meta := info.Meta
+		if info.RunID == r.ws.RunID || meta == nil || !meta.InPlace || meta.DryRun || meta.Source != r.report.Meta.Source {
+			continue
+		}
+		if lastState != nil && !meta.StartedAt.After(lastStart) {
+			continue
+		}
+		ws := &workspace.Workspace{RunID: info.RunID, Path: info.Path, BaseDir: r.ws.BaseDir}
+		if state, err := exec.LoadExecutionState(ws.StateFile()); err == nil && state != nil {
+			lastID, lastState, lastStart = info.RunID, state, meta.StartedAt
+		}
+	}
+	return lastID, lastState
+}
+
+// changedInput returns the path (relative to the project) of an input of
+// the step modified after since, or "" if its inputs are unchanged
+func (r *run) changedInput(step *llm.Step, kind llm.StepKind, since time.Time) string {
+	dir := filepath.Join(r.repoPath(), step.Cwd)
+
+	if kind == llm.StepKindBuild {
+		return r.relPath(newerFile(dir, since))
+	}
+
+	// Install steps only depend on the manifests and lockfiles
+	found := false
+	if r.scanResult != nil && r.scanResult.Profile != nil {
+		for _, name := range r.scanResult.Profile.Packages {
+			info, err := os.Stat(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			found = true
+			if info.ModTime().After(since) {
+				return r.relPath(filepath.Join(dir, name))
+			}
+		}
+	}
+	if !found {
+		return "no package manifest in " + r.relPath(dir)
+	}
+	return ""
+}
+
+// relPath returns path relative to the project directory ("" stays "")
+func (r *run) relPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(r.repoPath(), path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// newerFile returns a file under dir modified after since, or "". The
+// directories the scanner skips (dependencies, build output, VCS and
+// hidden directories) are not inputs.
+func newerFile(dir string, since time.Time) string {
+	var newer string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != dir && scanner.ShouldSkipDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err == nil && info.ModTime().After(since) {
+			newer = path
+			return errChanged
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChanged) {
+		return dir
+	}
+	return newer
+}
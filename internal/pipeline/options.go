@@ -0,0 +1,131 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Options and report of an embedded pipeline run
+
+package pipeline
+
+import (
+	"io"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/plan"
+	"github.com/sony-level/readme-runner/internal/prereq"
+	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/workspace"
+)
+
+// Verbosity levels (-v, -vv, -vvv); each level includes the ones below
+const (
+	VerbosityDetail = 1 // phase detail: README analysis, stacks, diffs, tool versions
+	VerbosityDebug  = 2 // the prompt sent to the provider and the project profile as JSON
+	VerbosityTrace  = 3 // raw LLM requests/responses and each step's env overrides
+)
+
+// Options configures one run of the pipeline. The fields mirror the rdr
+// flags; DefaultOptions returns the values the CLI starts from.
+type Options struct {
+	Input        string // local path or git URL
+	WorkspaceDir string // base directory for workspaces ("" = OS temp dir)
+	Keep         bool   // keep the workspace after the run
+	ResumeRunID  string // reuse the plan and state of a previous run
+	InPlace      bool   // scan and run a local Input where it is instead of a workspace copy
+	Incremental  bool   // with InPlace, skip install/build steps whose inputs are unchanged since the last run
+	DryRun       bool
+	Verbosity    int      // 0 = normal, VerbosityDetail..VerbosityTrace for -v..-vvv
+	Yes          bool     // auto-accept prompts (except security-critical)
+	ListSteps    bool     // confirm the whole plan once before executing
+	Explain      bool     // print why each step is in the plan and why it has its risk
+	Monorepo     bool     // plan each subproject from its own scan
+	ExcludeDirs  []string // pruned from scans on top of scanner.DefaultExcludeDirs
+
+	// Planning
+	LLMProvider      string // provider name ("" = auto-select)
+	LLMEndpoint      string
+	LLMModel         string
+	LLMToken         string
+	LLMTimeout       time.Duration // per provider request (0 = RD_LLM_TIMEOUT, config file or llm.DefaultTimeout)
+	Offline          bool
+	Provider         llm.Provider // used instead of the resolved provider when set
+	Plan             *llm.RunPlan // executed instead of a generated plan when set (validated like one)
+	ClarityThreshold float64
+	Strategy         llm.Strategy
+	MaxPromptTokens  int // 0 = no limit
+
+	// Execution
+	MaxRisk         llm.RiskLevel // "" = no ceiling
+	AllowSudo       bool
+	StepTimeout     time.Duration
+	GlobalTimeout   time.Duration // 0 = no limit
+	MaxParallel     int
+	MaxRetries      int      // retries allowed across all steps before the run stops (0 = no limit)
+	Detach          bool     // keep long-running steps in the background until the last step is done
+	LeaveRunning    bool     // leave servers and step processes running after rdr exits
+	Suppress        []string // validation warning codes to silence (plan.ParseWarningCodes)
+	AssumeStack     string   // primary stack to plan for instead of the detected one ("" = detect)
+	Isolation       exec.IsolationMode
+	Shell           exec.Shell
+	ContainerImage  string // --isolate docker image ("" = per project type)
+	ContainerEngine string // "" = auto-detect
+	Sandbox         *exec.SandboxConfig
+
+	// Out receives warnings, prompts and summaries (nil = io.Discard).
+	// Progress receives phase headers and progress lines (nil = Out).
+	// Trace receives the raw LLM requests and responses at VerbosityTrace
+	// (nil = Progress).
+	Out      io.Writer
+	Progress io.Writer
+	Trace    io.Writer
+
+	// Confirm asks a y/N question; nil answers no, so a run that needs
+	// confirmation aborts unless Yes is set
+	Confirm func(question string) bool
+	// SudoPrompt and FailurePrompt replace the runner's default prompts
+	SudoPrompt    exec.SudoPromptFunc
+	FailurePrompt exec.FailurePromptFunc
+	// Edit lets the plan be reviewed before it is saved. It returns the
+	// edited plan and its validation result.
+	Edit func(runPlan *llm.RunPlan, dir string) (*llm.RunPlan, *plan.ValidationResult, error)
+	// Export, when set, receives the validated plan and the run stops there
+	// instead of checking prerequisites and executing
+	Export func(runPlan *llm.RunPlan) error
+	// CheckPrereqs checks the plan's prerequisites before Export, and fails
+	// the run instead of prompting when one is missing, unusable or too old
+	CheckPrereqs bool
+	// Prepare stops after the prerequisites instead of executing the plan
+	// and, with AutoInstall and not DryRun, installs the missing tools with
+	// the host package manager ('rdr prepare')
+	Prepare     bool
+	AutoInstall bool
+	// Record, when set, receives the final report of every run that got a
+	// workspace, before the workspace is cleaned up (e.g. to bundle its
+	// plan and logs). Its error is printed as a warning.
+	Record func(report *Report) error
+}
+
+// DefaultOptions returns the options of a plain 'rdr <input>' run
+func DefaultOptions(input string) Options {
+	return Options{
+		Input:            input,
+		DryRun:           true,
+		ClarityThreshold: llm.ClarityThreshold,
+		Strategy:         llm.StrategyAuto,
+		StepTimeout:      exec.DefaultStepTimeout,
+		MaxParallel:      1,
+	}
+}
+
+// Report is the outcome of a run. Run returns it, possibly partial, with
+// any error once the workspace exists.
+type Report struct {
+	Workspace  *workspace.Workspace
+	Meta       *workspace.Meta
+	Profile    *scanner.ProjectProfile    // nil if scanning failed
+	Prompts    map[string]string          // prompt built for the provider per project dir ("." for the root)
+	Selection  *llm.ProviderSelectionInfo // how the provider was chosen; nil for Options.Provider and --plan
+	Plan       *llm.RunPlan               // nil if planning failed
+	Validation *plan.ValidationResult     // nil if planning failed
+	Execution  *exec.ExecutionResult      // nil for dry runs and exports
+
+	Prerequisites *prereq.CheckSummary // nil until prerequisites are checked
+}
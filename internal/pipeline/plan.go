@@ -0,0 +1,326 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Plan generation for single projects and monorepos
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	llmprovider "github.com/sony-level/readme-runner/internal/llm/provider"
+	"github.com/sony-level/readme-runner/internal/plan"
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
+
+// generateRunPlan asks the LLM provider for a plan, README-first, and falls
+// back to the mock provider on any failure. It also returns the name of the
+// provider that produced the plan.
+func (r *run) generateRunPlan(scanResult *scanner.ScanResult) (*llm.RunPlan, string, error) {
+	opts := r.opts
+	verbose := r.verbose()
+
+	// Build LLM context with README-first approach
+	clarityScore := llm.CalculateClarityScore(scanResult.ReadmeFile)
+	useReadme := llm.ShouldUseReadmeWithStrategy(scanResult.ReadmeFile, opts.ClarityThreshold, opts.Strategy)
+
+	planCtx := &llm.PlanContext{
+		ReadmeInfo:   scanResult.ReadmeFile,
+		Profile:      scanResult.Profile,
+		ClarityScore: clarityScore,
+		Threshold:    opts.ClarityThreshold,
+		UseReadme:    useReadme,
+		OS:           runtime.GOOS,
+		Verbose:      verbose,
+	}
+	if len(r.subprojects) == 0 {
+		r.readmeFirst = useReadme
+	}
+	prompt := llm.NewPromptBuilder().BuildPlanPrompt(planCtx)
+	r.recordPrompt(scanResult, prompt)
+
+	// Display README-first analysis
+	r.progressf("  → README clarity score: %.2f (threshold: %.2f)\n", clarityScore, opts.ClarityThreshold)
+	if useReadme {
+		if opts.Strategy == llm.StrategyReadme {
+			r.progressf("  → Strategy: README-first (forced by --strategy readme)\n")
+		} else {
+			r.progressf("  → Strategy: README-first (clear instructions detected)\n")
+		}
+	} else {
+		if scanResult.ReadmeFile == nil {
+			if opts.Strategy == llm.StrategyReadme {
+				r.noticef("  → ⚠ --strategy readme ignored: no README found\n")
+			}
+			r.progressf("  → Strategy: Project-file signals (no README found)\n")
+		} else if opts.Strategy == llm.StrategyFiles {
+			r.progressf("  → Strategy: Project-file signals (forced by --strategy files)\n")
+		} else {
+			r.progressf("  → Strategy: Project-file signals (README unclear, score below threshold)\n")
+		}
+	}
+
+	if verbose && scanResult.ReadmeFile != nil {
+		// Show README analysis breakdown
+		r.progressf("    README analysis:\n")
+		if scanResult.ReadmeFile.HasInstall {
+			r.progressf("      ✓ Installation section found\n")
+		}
+		if scanResult.ReadmeFile.HasUsage {
+			r.progressf("      ✓ Usage section found\n")
+		}
+		if scanResult.ReadmeFile.HasBuild {
+			r.progressf("      ✓ Build section found\n")
+		}
+		if scanResult.ReadmeFile.HasQuickStart {
+			r.progressf("      ✓ Quick start section found\n")
+		}
+		r.progressf("      Code blocks: %d, Shell commands: %d\n",
+			scanResult.ReadmeFile.CodeBlocks, scanResult.ReadmeFile.ShellCommands)
+	}
+
+	// A provider set by the embedding tool is used as-is
+	if opts.Provider != nil {
+		r.progressf("  → LLM provider: %s\n", opts.Provider.Name())
+		r.progressf("  → Generating installation plan...\n")
+		runPlan, err := r.generatePlan(opts.Provider, planCtx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate plan: %w", err)
+		}
+		return runPlan, opts.Provider.Name(), nil
+	}
+
+	// Create LLM provider (auto-selects based on available API keys)
+	provider, selectionInfo := r.createLLMProvider()
+	r.progressf("  → LLM provider: %s\n", provider.Name())
+	if selectionInfo.Model != "" {
+		r.report.Meta.Model = selectionInfo.Model
+	}
+	r.report.Selection = selectionInfo
+
+	// A provider that already fell back to the mock sends nothing
+	hosted := selectionInfo.Provider
+	if selectionInfo.WasFallback {
+		hosted = llm.ProviderMock
+	}
+	if err := r.checkPromptSize(prompt, hosted); err != nil {
+		return nil, "", err
+	}
+
+	// Generate plan using README-first approach
+	r.progressf("  → Generating installation plan...\n")
+	runPlan, err := r.generatePlan(provider, planCtx)
+	if err != nil && r.ctx.Err() != nil {
+		return nil, "", err
+	}
+	if err != nil {
+		// Graceful fallback to mock provider on any failure (network, auth, JSON parse, etc.)
+		r.noticef("  → ⚠ LLM provider failed: %v\n", err)
+		r.progressf("  → Falling back to mock provider (using project file signals)...\n")
+		if verbose {
+			r.progressf("    Fallback reason: provider error, continuing with offline analysis\n")
+		}
+		selectionInfo.WasFallback = true
+		selectionInfo.FallbackFrom = provider.Name()
+		selectionInfo.FallbackError = err.Error()
+		selectionInfo.TimedOut = errors.Is(err, llm.ErrTimeout)
+		mockProvider := llmprovider.NewMockProvider()
+		runPlan, err = mockProvider.GeneratePlan(planCtx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate plan: %w", err)
+		}
+		r.progressf("  → Plan generated using mock provider (offline mode)\n")
+		return runPlan, mockProvider.Name() + " (fallback from " + provider.Name() + ")", nil
+	}
+
+	// The provider may have replaced itself with the mock (bad key, network
+	// error, invalid JSON): say so even without --verbose
+	if summary := selectionInfo.FallbackSummary(); summary != "" {
+		r.noticef("  → ⚠ LLM fallback: %s\n", summary)
+		return runPlan, "mock (fallback from " + selectionInfo.FallbackFrom + ")", nil
+	}
+
+	return runPlan, provider.Name(), nil
+}
+
+// generatePlan calls the provider, returning early when the run is
+// cancelled. Providers take no context, so an abandoned request finishes in
+// the background.
+func (r *run) generatePlan(provider llm.Provider, planCtx *llm.PlanContext) (*llm.RunPlan, error) {
+	type result struct {
+		plan *llm.RunPlan
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		runPlan, err := provider.GeneratePlan(planCtx)
+		done <- result{runPlan, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.plan, res.err
+	case <-r.ctx.Done():
+		return nil, r.ctx.Err()
+	}
+}
+
+// checkPromptSize prints the estimated prompt size in verbose mode and asks
+// before sending a prompt over MaxPromptTokens to a hosted provider
+func (r *run) checkPromptSize(prompt string, providerType llm.ProviderType) error {
+	tokens := llm.NewPromptBuilder().EstimateTokens(prompt)
+	if r.verbose() {
+		r.progressf("  → Prompt size: %d chars (~%d tokens)\n", len(prompt), tokens)
+	}
+	if r.opts.Verbosity >= VerbosityDebug {
+		r.progressf("  → Prompt:\n%s\n", indentLines(prompt, "      "))
+	}
+
+	limit := r.opts.MaxPromptTokens
+	if limit == 0 || tokens <= limit || !llm.IsHostedProvider(providerType) {
+		return nil
+	}
+	r.noticef("  → ⚠ Prompt is ~%d tokens, over --max-prompt-tokens %d\n", tokens, limit)
+	if r.opts.Yes {
+		return nil
+	}
+	if !r.confirm(fmt.Sprintf("\n  Send it to %s anyway? [y/N]: ", providerType)) {
+		return fmt.Errorf("aborted: prompt exceeds --max-prompt-tokens (use --offline, --strategy files or a higher limit)")
+	}
+	return nil
+}
+
+// recordPrompt keeps the prompt built for a project in the report, keyed
+// by its directory in the repository ("." for the root)
+func (r *run) recordPrompt(scanResult *scanner.ScanResult, prompt string) {
+	dir := "."
+	if rel, err := filepath.Rel(r.repoPath(), scanResult.RootPath); err == nil {
+		dir = filepath.ToSlash(rel)
+	}
+	if r.report.Prompts == nil {
+		r.report.Prompts = make(map[string]string)
+	}
+	r.report.Prompts[dir] = prompt
+}
+
+// createLLMProvider creates the LLM provider from the options.
+// Uses config resolution with precedence: CLI > ENV > config file > defaults (auto-select).
+// Auto-selection order: anthropic > openai > mistral > cohere > ollama > mock
+// The provider is a FallbackProvider that never fails; the returned
+// selection info records why it fell back. Selection details are logged in
+// verbose mode.
+func (r *run) createLLMProvider() (llm.Provider, *llm.ProviderSelectionInfo) {
+	opts := r.opts
+
+	// Resolve config with proper precedence and get selection info
+	config, selectionInfo := llm.ResolveProviderConfigWithOffline(
+		opts.LLMProvider,
+		opts.LLMEndpoint,
+		opts.LLMModel,
+		opts.LLMToken,
+		opts.LLMTimeout,
+		r.verbose(),
+		opts.Offline, // --offline/--no-llm
+	)
+
+	// Log provider selection in verbose mode
+	if r.verbose() {
+		r.progressf("  → Provider selection: %s\n", llm.GetProviderSelectionDescription(selectionInfo))
+		r.progressf("  → Provider timeout: %s per request\n", config.Timeout)
+	} else if selectionInfo.ModelError != "" {
+		r.noticef("  → ⚠ %s\n", selectionInfo.ModelError)
+	}
+	if selectionInfo.TokenError != "" {
+		r.noticef("  → ⚠ Config token not loaded: %s\n", selectionInfo.TokenError)
+	}
+
+	// Retries are progress; -vvv dumps the raw requests and responses
+	// (credentials redacted)
+	config.Out = r.progress
+	if opts.Verbosity >= VerbosityTrace {
+		config.Trace = r.trace
+	}
+
+	return llm.NewProviderWithInfo(config, selectionInfo), selectionInfo
+}
+
+// subproject is a monorepo directory scanned as a project of its own
+type subproject struct {
+	dir  string
+	scan *scanner.ScanResult
+}
+
+// scanSubprojects scans every top-level subdirectory that has its own
+// project manifest. It returns nil if the repository has none.
+func (r *run) scanSubprojects(repoPath string) ([]subproject, error) {
+	dirs, err := scanner.FindSubprojects(repoPath, r.excludeDirs("")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var subprojects []subproject
+	for _, dir := range dirs {
+		result, err := scanner.ScanWithContext(r.ctx, &scanner.ScanConfig{
+			RootPath:    filepath.Join(repoPath, dir),
+			MaxDepth:    3,
+			Verbose:     r.verbose(),
+			ExcludeDirs: r.excludeDirs(dir),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subproject %s: %w", dir, err)
+		}
+		subprojects = append(subprojects, subproject{dir: dir, scan: result})
+	}
+	return subprojects, nil
+}
+
+// generateMonorepoPlan generates and normalizes a plan for each subproject
+// from its own scan, then merges them into one plan run from the root.
+// It also returns the provider name(s) that produced the plans.
+func (r *run) generateMonorepoPlan(subprojects []subproject) (*llm.RunPlan, string, error) {
+	var subplans []plan.Subplan
+	var providers []string
+
+	for _, sub := range subprojects {
+		r.progressf("  ▸ %s\n", sub.dir)
+		subPlan, providerName, err := r.generateRunPlan(sub.scan)
+		if err != nil {
+			return nil, "", fmt.Errorf("subproject %s: %w", sub.dir, err)
+		}
+
+		normalizer := plan.NewNormalizer(sub.scan.Profile)
+		normalizer.SetContainerEngine(r.engine)
+		subPlan = normalizer.Normalize(subPlan)
+
+		r.progressf("    %s plan with %d steps\n", subPlan.ProjectType, len(subPlan.Steps))
+		subplans = append(subplans, plan.Subplan{Dir: sub.dir, Plan: subPlan})
+
+		if !containsString(providers, providerName) {
+			providers = append(providers, providerName)
+		}
+	}
+
+	return plan.MergeSubplans(subplans), strings.Join(providers, ", "), nil
+}
+
+// indentLines prefixes every line of s with indent
+func indentLines(s, indent string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// containsString checks if a slice contains a string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
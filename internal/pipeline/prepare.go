@@ -0,0 +1,127 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Installing missing prerequisites without running the plan (rdr prepare)
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/exec"
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/prereq"
+)
+
+// installPrerequisites is phase 6 of 'rdr prepare': install the missing
+// tools with the host package manager, then check the prerequisites again.
+// Without AutoInstall (a dry run) it only shows what it would run. Tools
+// that are outdated, unusable or have no package are left to the user.
+func (r *run) installPrerequisites() error {
+	summary := r.report.Prerequisites
+	r.progressf("\n[6/7] Install prerequisites\n")
+	if summary.Ready() {
+		r.progressf("  → Nothing to install\n")
+		return nil
+	}
+
+	checker := prereq.NewChecker()
+	manager := prereq.DetectPackageManager()
+	installPlan := &llm.RunPlan{Version: "1", ProjectType: r.report.Plan.ProjectType}
+	var manual []string
+	for _, name := range summary.MissingTools {
+		cmd, sudo, ok := checker.InstallCommand(name, manager)
+		if !ok {
+			manual = append(manual, name)
+			continue
+		}
+		risk := llm.RiskMedium
+		if sudo {
+			risk = llm.RiskHigh
+		}
+		installPlan.Steps = append(installPlan.Steps, llm.Step{
+			ID:           "install-" + name,
+			Cmd:          cmd,
+			Cwd:          ".",
+			Risk:         risk,
+			RequiresSudo: sudo,
+			Description:  fmt.Sprintf("Install %s with %s", name, manager),
+		})
+	}
+	// apt-get only finds packages once its lists are refreshed
+	if cmd, sudo, ok := prereq.RefreshCommand(manager); ok && len(installPlan.Steps) > 0 {
+		refresh := llm.Step{
+			ID:           "refresh-packages",
+			Cmd:          cmd,
+			Cwd:          ".",
+			Risk:         llm.RiskMedium,
+			RequiresSudo: sudo,
+			Description:  fmt.Sprintf("Refresh the %s package lists", manager),
+		}
+		if sudo {
+			refresh.Risk = llm.RiskHigh
+		}
+		installPlan.Steps = append([]llm.Step{refresh}, installPlan.Steps...)
+	}
+	for _, result := range summary.Results {
+		if result.Unreachable || result.Outdated {
+			manual = append(manual, result.Name)
+		}
+	}
+
+	for _, name := range manual {
+		r.noticef("  → ✗ %s must be installed or fixed by hand:\n", name)
+		for _, line := range strings.Split(checker.GetInstallGuide(name), "\n") {
+			r.noticef("        %s\n", line)
+		}
+	}
+	if len(installPlan.Steps) == 0 {
+		return fmt.Errorf("prerequisites not met: %s", strings.Join(summary.Problems(), ", "))
+	}
+
+	if r.opts.DryRun || !r.opts.AutoInstall {
+		r.noticef("\n  With --auto-install, rdr would run:\n")
+		for _, step := range installPlan.Steps {
+			r.noticef("    $ %s\n", step.Cmd)
+		}
+		return fmt.Errorf("prerequisites not met: %s", strings.Join(summary.Problems(), ", "))
+	}
+
+	r.noticef("\n  Tools to install with %s:\n%s", manager, exec.FormatStepList(installPlan, nil))
+	if !r.opts.Yes && !r.confirm(fmt.Sprintf("\n  Install %d tool(s)? [y/N]: ", len(installPlan.Steps))) {
+		return fmt.Errorf("aborted: installation not confirmed")
+	}
+
+	runner := exec.NewRunner(&exec.RunnerConfig{
+		Mode:        exec.ModeExecute,
+		WorkingDir:  r.repoPath(),
+		AutoYes:     r.opts.Yes,
+		AllowSudo:   r.opts.AllowSudo,
+		Verbose:     r.verbose(),
+		StepTimeout: r.opts.StepTimeout,
+		Shell:       r.opts.Shell,
+		Output:      r.out,
+		OnStepStart: func(step *llm.Step) {
+			r.progressf("\n  → %s\n", step.Description)
+			r.progressf("    $ %s\n", step.Cmd)
+		},
+		OnStepComplete: func(step *llm.Step, result *exec.StepResult) {
+			r.progressf("    %s\n", exec.FormatStepResult(result))
+		},
+	})
+	if r.opts.SudoPrompt != nil {
+		runner.SetSudoPrompt(r.opts.SudoPrompt)
+	}
+	if r.opts.FailurePrompt != nil {
+		runner.SetFailurePrompt(r.opts.FailurePrompt)
+	}
+	runner.ExecuteWithContext(r.ctx, installPlan)
+
+	// A failed install shows up as a tool that is still missing
+	summary = checker.CheckPrerequisites(r.report.Plan.Prerequisites)
+	r.report.Prerequisites = summary
+	if !summary.Ready() {
+		return fmt.Errorf("prerequisites not met: %s", strings.Join(summary.Problems(), ", "))
+	}
+	r.noticef("\n  → ✓ Ready: all %d prerequisites available\n", len(summary.Results))
+	return nil
+}
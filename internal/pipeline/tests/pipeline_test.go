@@ -0,0 +1,593 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// End-to-end tests for the embeddable pipeline
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/llm/provider"
+	"github.com/sony-level/readme-runner/internal/pipeline"
+)
+
+// goProject writes a minimal Go project and returns its directory
+func goProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/demo\n\ngo 1.21\n",
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"README.md": "# Demo\n\n## Installation\n\n```bash\ngo build ./...\n```\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func echoPlan() *llm.RunPlan {
+	return &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps: []llm.Step{
+			{ID: "hello", Cmd: "echo hello", Cwd: "."},
+			{ID: "world", Cmd: "echo world", Cwd: "."},
+		},
+	}
+}
+
+func TestEngineDryRun(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+	var out bytes.Buffer
+	opts.Out = &out
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	if report.Plan == nil || len(report.Plan.Steps) == 0 {
+		t.Fatal("expected a plan with steps")
+	}
+	if report.Execution != nil {
+		t.Error("dry run should not execute the plan")
+	}
+	if report.Meta.Success == nil || !*report.Meta.Success {
+		t.Error("expected the run to be recorded as successful")
+	}
+	if report.Meta.Stack != "go" {
+		t.Errorf("Meta.Stack = %q, want go", report.Meta.Stack)
+	}
+	if !strings.Contains(out.String(), "[6/7] Execute") {
+		t.Errorf("expected phase headers in the output, got:\n%s", out.String())
+	}
+	if report.Selection == nil || report.Selection.Provider != llm.ProviderMock || report.Selection.Source != "offline" {
+		t.Errorf("Selection = %+v, want the offline mock provider", report.Selection)
+	}
+}
+
+func TestEngineExecutesPlan(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(echoPlan())
+	opts.DryRun = false
+	opts.Yes = true
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Execution == nil || !report.Execution.Success {
+		t.Fatal("expected a successful execution")
+	}
+	if report.Execution.Completed != 2 {
+		t.Errorf("Completed = %d, want 2", report.Execution.Completed)
+	}
+	if _, err := os.Stat(report.Workspace.Path); !os.IsNotExist(err) {
+		t.Error("expected the workspace to be cleaned up")
+	}
+}
+
+func TestEngineGivenPlan(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+	opts.Plan = echoPlan()
+	opts.DryRun = false
+	opts.Yes = true
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Execution == nil || report.Execution.Completed != 2 {
+		t.Fatalf("expected the given plan's 2 steps to run, got %+v", report.Execution)
+	}
+
+	// A given plan is validated like a generated one
+	opts.Plan = &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps:       []llm.Step{{ID: "danger", Cmd: "rm -rf /", Cwd: "."}},
+	}
+	report, err = pipeline.New().Run(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "plan validation failed") {
+		t.Fatalf("Run() error = %v, want plan validation failed", err)
+	}
+	if report.Execution != nil {
+		t.Error("an invalid plan should not be executed")
+	}
+}
+
+func TestEngineRecord(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+
+	var recorded *pipeline.Report
+	opts.Record = func(report *pipeline.Report) error {
+		// The workspace still exists while the hook runs
+		if _, err := os.Stat(report.Workspace.PlanFile()); err != nil {
+			t.Errorf("plan file missing when recording: %v", err)
+		}
+		recorded = report
+		return nil
+	}
+
+	if _, err := pipeline.New().Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if recorded == nil {
+		t.Fatal("Record was not called")
+	}
+	if recorded.Profile == nil || recorded.Profile.Stack != "go" {
+		t.Errorf("Profile = %+v, want the go profile", recorded.Profile)
+	}
+	if !strings.Contains(recorded.Prompts["."], "## Project Profile") {
+		t.Errorf("Prompts = %v, want the root project's prompt", recorded.Prompts)
+	}
+	if recorded.Meta.Success == nil || !*recorded.Meta.Success {
+		t.Error("expected the outcome to be recorded before the hook runs")
+	}
+}
+
+func TestEngineInPlace(t *testing.T) {
+	dir := goProject(t)
+	opts := pipeline.DefaultOptions(dir)
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps:       []llm.Step{{ID: "touch", Cmd: "touch built.txt", Cwd: "."}},
+	})
+	opts.DryRun = false
+	opts.Yes = true
+	opts.InPlace = true
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !report.Meta.InPlace {
+		t.Error("Meta.InPlace = false, want true")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "built.txt")); err != nil {
+		t.Errorf("expected the step to run in the source directory: %v", err)
+	}
+}
+
+func TestEngineIncremental(t *testing.T) {
+	dir := goProject(t)
+	workspaceDir := t.TempDir()
+	// The steps log to a hidden directory, which is not a build input
+	logStep := func(id string) llm.Step {
+		return llm.Step{ID: id, Cmd: "mkdir -p .out && echo " + id + " >> .out/steps.log", Cwd: "."}
+	}
+	run := func() string {
+		t.Helper()
+		opts := pipeline.DefaultOptions(dir)
+		opts.WorkspaceDir = workspaceDir
+		opts.Provider = provider.NewMockProviderWithPlan(&llm.RunPlan{
+			Version:     "1",
+			ProjectType: "go",
+			Steps:       []llm.Step{logStep("install"), logStep("build"), logStep("run")},
+		})
+		opts.DryRun = false
+		opts.Yes = true
+		opts.InPlace = true
+		opts.Incremental = true
+		if _, err := pipeline.New().Run(context.Background(), opts); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, ".out", "steps.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(filepath.Join(dir, ".out", "steps.log"))
+		return strings.Join(strings.Fields(string(data)), " ")
+	}
+	if got := run(); got != "install build run" {
+		t.Errorf("first run ran %q, want every step", got)
+	}
+	if got := run(); got != "run" {
+		t.Errorf("unchanged run ran %q, want only the run step", got)
+	}
+
+	// A changed manifest re-runs install and everything after it
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(dir, "go.mod"), now, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(); got != "install build run" {
+		t.Errorf("run after a go.mod change ran %q, want every step", got)
+	}
+
+	// A changed source file only re-runs the build
+	now = time.Now()
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), now, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(); got != "build run" {
+		t.Errorf("run after a main.go change ran %q, want build and run", got)
+	}
+}
+
+func TestEngineAbortsWithoutConfirmation(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(echoPlan())
+	opts.DryRun = false
+	opts.ListSteps = true
+
+	var asked []string
+	opts.Confirm = func(question string) bool {
+		asked = append(asked, question)
+		return false
+	}
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "plan not confirmed") {
+		t.Fatalf("Run() error = %v, want plan not confirmed", err)
+	}
+	if len(asked) != 1 {
+		t.Errorf("expected one confirmation, got %d", len(asked))
+	}
+	if report == nil || report.Execution != nil {
+		t.Error("expected a report without execution")
+	}
+	if report != nil && (report.Meta.Success == nil || *report.Meta.Success) {
+		t.Error("expected the run to be recorded as failed")
+	}
+}
+
+func TestEngineExecutionSummary(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps: []llm.Step{
+			{ID: "deps", Cmd: "go mod download", Cwd: "."},
+			{ID: "tools", Cmd: "brew install jq", Cwd: "."},
+			{ID: "run", Cmd: "echo serving", Cwd: "."},
+		},
+		Ports: []int{8080},
+	})
+	opts.DryRun = false
+	var out bytes.Buffer
+	opts.Out = &out
+
+	var asked []string
+	opts.Confirm = func(question string) bool {
+		asked = append(asked, question)
+		return false
+	}
+
+	_, err := pipeline.New().Run(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "plan not confirmed") {
+		t.Fatalf("Run() error = %v, want plan not confirmed", err)
+	}
+	if len(asked) != 1 || !strings.Contains(asked[0], "Proceed?") {
+		t.Errorf("asked = %q, want one Proceed? question", asked)
+	}
+	for _, want := range []string{
+		"3 step(s) will run",
+		"1 step(s) use the system package manager: tools",
+		"1 step(s) download code or packages: deps",
+		"Ports to be opened: 8080",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEngineExport(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(echoPlan())
+
+	var exported *llm.RunPlan
+	opts.Export = func(runPlan *llm.RunPlan) error {
+		exported = runPlan
+		return nil
+	}
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if exported == nil || exported != report.Plan {
+		t.Error("expected the validated plan to be exported")
+	}
+}
+
+func TestEngineExportCheckPrereqs(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	runPlan := echoPlan()
+	runPlan.Prerequisites = []llm.Prerequisite{{Name: "rdr-no-such-tool", Reason: "test"}}
+	opts.Provider = provider.NewMockProviderWithPlan(runPlan)
+	opts.CheckPrereqs = true
+
+	exported := false
+	opts.Export = func(*llm.RunPlan) error {
+		exported = true
+		return nil
+	}
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "rdr-no-such-tool missing") {
+		t.Fatalf("Run() error = %v, want unmet prerequisites", err)
+	}
+	if exported {
+		t.Error("plan exported despite a missing prerequisite")
+	}
+	if report.Prerequisites == nil || len(report.Prerequisites.MissingTools) != 1 {
+		t.Fatalf("Prerequisites = %+v, want one missing tool", report.Prerequisites)
+	}
+}
+
+func TestEnginePrepareInstallsMissingTools(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake apt-get is a shell script")
+	}
+	if _, err := osexec.LookPath("php"); err == nil {
+		t.Skip("php is installed")
+	}
+	// Like on a fresh machine, apt-get only finds php once its lists are
+	// updated; it "installs" php into the fake bin dir. sudo just runs the
+	// command.
+	bin := t.TempDir()
+	php := filepath.Join(bin, "php")
+	lists := filepath.Join(bin, "lists")
+	scripts := map[string]string{
+		"apt-get": `if [ "$1" = update ]; then touch ` + lists + `; exit 0; fi
+[ -f ` + lists + ` ] || { echo "E: Unable to locate package php-cli" >&2; exit 100; }
+printf '#!/bin/sh\necho "PHP 8.3.6 (cli)"\n' > ` + php + ` && chmod +x ` + php,
+		"sudo": `exec "$@"`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	runPlan := echoPlan()
+	runPlan.Prerequisites = []llm.Prerequisite{{Name: "php", Reason: "test", MinVersion: "8.1"}}
+	opts.Provider = provider.NewMockProviderWithPlan(runPlan)
+	opts.Prepare = true
+	opts.AutoInstall = true
+	opts.DryRun = false
+	opts.Yes = true
+	opts.AllowSudo = true
+	var out bytes.Buffer
+	opts.Out = &out
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	if report.Execution != nil {
+		t.Error("prepare executed the plan")
+	}
+	if !report.Prerequisites.Ready() {
+		t.Errorf("Prerequisites not ready after install: %v", report.Prerequisites.Problems())
+	}
+	update := strings.Index(out.String(), "apt-get update")
+	install := strings.Index(out.String(), "apt-get install -y php-cli")
+	if update < 0 || install < 0 || update > install {
+		t.Errorf("want apt-get update before apt-get install -y php-cli:\n%s", out.String())
+	}
+}
+
+func TestEngineInterruptedBeforeFetch(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := pipeline.New().Run(ctx, opts)
+	if !errors.Is(err, pipeline.ErrInterrupted) {
+		t.Fatalf("Run() error = %v, want ErrInterrupted", err)
+	}
+	if report.Meta.Error != pipeline.ErrInterrupted.Error() {
+		t.Errorf("Meta.Error = %q, want %q", report.Meta.Error, pipeline.ErrInterrupted)
+	}
+	if _, err := os.Stat(report.Workspace.Path); !os.IsNotExist(err) {
+		t.Error("expected the workspace to be cleaned up")
+	}
+}
+
+func TestEngineInterruptedDuringExecution(t *testing.T) {
+	opts := pipeline.DefaultOptions(goProject(t))
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps:       []llm.Step{{ID: "wait", Cmd: "sleep 5", Cwd: "."}},
+	})
+	opts.DryRun = false
+	opts.Yes = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	report, err := pipeline.New().Run(ctx, opts)
+	if !errors.Is(err, pipeline.ErrInterrupted) {
+		t.Fatalf("Run() error = %v, want ErrInterrupted", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Run() took %v after the interrupt", elapsed)
+	}
+	// An interrupted execution keeps its workspace so it can be resumed
+	if !report.Workspace.ShouldKeep() {
+		t.Error("expected the workspace to be kept for --resume")
+	}
+}
+
+func TestEngineCreatesEnvFromExample(t *testing.T) {
+	dir := goProject(t)
+	if err := os.WriteFile(filepath.Join(dir, ".env.example"), []byte("PORT=8080\nSECRET_KEY=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := pipeline.DefaultOptions(dir)
+	opts.WorkspaceDir = t.TempDir()
+	opts.Provider = provider.NewMockProviderWithPlan(&llm.RunPlan{
+		Version:     "1",
+		ProjectType: "go",
+		Steps:       []llm.Step{{ID: "env", Cmd: "grep -q PORT=8080 .env", Cwd: "."}},
+	})
+	opts.DryRun = false
+	opts.Yes = true
+	var out bytes.Buffer
+	opts.Out = &out
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "SECRET_KEY (empty, fill it in)") {
+		t.Errorf("expected the variables to be listed, got:\n%s", out.String())
+	}
+	found := false
+	for _, note := range report.Plan.Notes {
+		if strings.Contains(note, "placeholder values") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Notes = %v, want a placeholder values note", report.Plan.Notes)
+	}
+}
+
+func TestEngineAssumeStack(t *testing.T) {
+	// A Go CLI whose package.json only runs docs tooling is detected as node
+	dir := goProject(t)
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "docs", "private": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := pipeline.DefaultOptions(dir)
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+	opts.AssumeStack = "go"
+	var out bytes.Buffer
+	opts.Out = &out
+
+	report, err := pipeline.New().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	if report.Meta.Stack != "go" {
+		t.Errorf("Meta.Stack = %q, want go", report.Meta.Stack)
+	}
+	if report.Plan == nil || report.Plan.ProjectType != "go" {
+		t.Fatalf("expected a go plan, got %+v", report.Plan)
+	}
+	for _, want := range []string{"--assume-stack; detected node", "Dominant: go"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEngineBackgroundStepNotice(t *testing.T) {
+	run := func(suppress []string) string {
+		opts := pipeline.DefaultOptions(goProject(t))
+		opts.WorkspaceDir = t.TempDir()
+		opts.Offline = true
+		opts.Plan = &llm.RunPlan{
+			Version:     "1",
+			ProjectType: "go",
+			Steps:       []llm.Step{{ID: "serve", Cmd: "sleep 5 &", Cwd: "."}},
+		}
+		opts.Suppress = suppress
+		var out bytes.Buffer
+		opts.Out = &out
+		if _, err := pipeline.New().Run(context.Background(), opts); err != nil {
+			t.Fatalf("Run() error = %v\n%s", err, out.String())
+		}
+		return out.String()
+	}
+
+	if out := run(nil); !strings.Contains(out, `serve end(s) with "&"`) {
+		t.Errorf("expected the background notice:\n%s", out)
+	}
+	if out := run([]string{"PLAN005"}); strings.Contains(out, `end(s) with "&"`) {
+		t.Errorf("--suppress PLAN005 should silence the background notice:\n%s", out)
+	}
+}
+
+func TestEngineCIDockerfileDoesNotDominateGo(t *testing.T) {
+	// The on-disk counterpart of the stacks tests' ciDockerfileGoProfile: a
+	// Go service with a Dockerfile used only for CI
+	dir := goProject(t)
+	files := map[string]string{
+		"go.sum":           "",
+		"Makefile":         "build:\n\tgo build ./...\n",
+		"Dockerfile":       "FROM golang:1.21\nRUN go test ./...\n",
+		"cmd/demo/main.go": "package main\n\nfunc main() {}\n",
+		"internal/x/x.go":  "package x\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := pipeline.DefaultOptions(dir)
+	opts.WorkspaceDir = t.TempDir()
+	opts.Offline = true
+	var out bytes.Buffer
+	opts.Out = &out
+	if _, err := pipeline.New().Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Dominant: go (") {
+		t.Errorf("want Go as the dominant stack:\n%s", out.String())
+	}
+}
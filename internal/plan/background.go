@@ -0,0 +1,56 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Steps backgrounded with a trailing "&"
+
+package plan
+
+import (
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// HasTrailingBackground reports whether cmd ends with the shell's "&"
+// background operator (but not "&&" or a ">&" redirection)
+func HasTrailingBackground(cmd string) bool {
+	rest, ok := strings.CutSuffix(strings.TrimSpace(cmd), "&")
+	if !ok {
+		return false
+	}
+	rest = strings.TrimRight(rest, " \t")
+	if rest == "" {
+		return false
+	}
+	switch rest[len(rest)-1] {
+	case '&', '>', '<', '|', '\\':
+		return false
+	}
+	return true
+}
+
+// BackgroundSteps returns the IDs of the steps ending with "&"
+func BackgroundSteps(runPlan *llm.RunPlan) []string {
+	var ids []string
+	for _, step := range runPlan.Steps {
+		if HasTrailingBackground(step.Cmd) {
+			ids = append(ids, step.ID)
+		}
+	}
+	return ids
+}
+
+// DetachBackgroundSteps returns a copy of the plan where steps ending with
+// "&" run in the foreground until they are up and are then kept in the
+// background (Step.Detach), instead of returning at once and being
+// stopped with their process group
+func DetachBackgroundSteps(runPlan *llm.RunPlan) *llm.RunPlan {
+	detached := *runPlan
+	detached.Steps = make([]llm.Step, len(runPlan.Steps))
+	for i, step := range runPlan.Steps {
+		if HasTrailingBackground(step.Cmd) {
+			step.Cmd = strings.TrimRight(strings.TrimSuffix(strings.TrimSpace(step.Cmd), "&"), " \t")
+			step.Detach = true
+		}
+		detached.Steps[i] = step
+	}
+	return &detached
+}
@@ -0,0 +1,176 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Differences between the LLM plan and the normalized plan
+
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// PlanChange is one difference between two versions of a plan. Step is ""
+// for plan-level changes (prerequisites, env, ports, notes).
+type PlanChange struct {
+	Step   string
+	Field  string
+	Before string // "" when the field or step was added
+	After  string // "" when the field or step was removed
+}
+
+func (c PlanChange) String() string {
+	scope := "plan"
+	if c.Step != "" {
+		scope = "step " + c.Step
+	}
+	switch {
+	case c.Field == "step" && c.Before == "":
+		return fmt.Sprintf("%s added: %s", scope, c.After)
+	case c.Field == "step" && c.After == "":
+		return fmt.Sprintf("%s removed: %s", scope, c.Before)
+	case c.Before == "":
+		return fmt.Sprintf("%s: %s added %s", scope, c.Field, c.After)
+	case c.After == "":
+		return fmt.Sprintf("%s: %s removed %s", scope, c.Field, c.Before)
+	}
+	return fmt.Sprintf("%s: %s %s → %s", scope, c.Field, c.Before, c.After)
+}
+
+// DiffPlans lists what changed from before to after. Steps are matched by
+// ID: a step only in before is reported as removed, one only in after as
+// added, and for the others each changed field is reported.
+func DiffPlans(before, after *llm.RunPlan) []PlanChange {
+	var changes []PlanChange
+
+	beforeSteps := make(map[string]*llm.Step, len(before.Steps))
+	for i := range before.Steps {
+		beforeSteps[before.Steps[i].ID] = &before.Steps[i]
+	}
+	afterIDs := make(map[string]bool, len(after.Steps))
+	for i := range after.Steps {
+		step := &after.Steps[i]
+		afterIDs[step.ID] = true
+		old, ok := beforeSteps[step.ID]
+		if !ok {
+			changes = append(changes, PlanChange{Step: step.ID, Field: "step", After: strconv.Quote(step.Cmd)})
+			continue
+		}
+		changes = append(changes, diffStep(old, step)...)
+	}
+	for _, step := range before.Steps {
+		if !afterIDs[step.ID] {
+			changes = append(changes, PlanChange{Step: step.ID, Field: "step", Before: strconv.Quote(step.Cmd)})
+		}
+	}
+
+	changes = append(changes, diffSets("prerequisite", prerequisiteNames(before), prerequisiteNames(after))...)
+	changes = append(changes, diffMap("", "env", before.Env, after.Env)...)
+	changes = append(changes, diffSets("port", portNames(before.Ports), portNames(after.Ports))...)
+	changes = append(changes, diffSets("note", before.Notes, after.Notes)...)
+	return changes
+}
+
+// diffStep compares the fields of two versions of a step
+func diffStep(before, after *llm.Step) []PlanChange {
+	var changes []PlanChange
+	field := func(name, old, updated string) {
+		if old != updated {
+			changes = append(changes, PlanChange{Step: after.ID, Field: name, Before: old, After: updated})
+		}
+	}
+
+	field("cmd", strconv.Quote(before.Cmd), strconv.Quote(after.Cmd))
+	field("cwd", strconv.Quote(before.Cwd), strconv.Quote(after.Cwd))
+	field("risk", string(before.Risk), string(after.Risk))
+	field("requires_sudo", strconv.FormatBool(before.RequiresSudo), strconv.FormatBool(after.RequiresSudo))
+	field("timeout", strconv.Itoa(before.Timeout), strconv.Itoa(after.Timeout))
+	field("detach", strconv.FormatBool(before.Detach), strconv.FormatBool(after.Detach))
+	field("depends_on", "["+strings.Join(before.DependsOn, ", ")+"]", "["+strings.Join(after.DependsOn, ", ")+"]")
+	field("description", strconv.Quote(before.Description), strconv.Quote(after.Description))
+	changes = append(changes, diffMap(after.ID, "export_env", before.ExportEnv, after.ExportEnv)...)
+	return changes
+}
+
+// diffMap reports added, removed and changed keys, sorted by key
+func diffMap(step, field string, before, after map[string]string) []PlanChange {
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []PlanChange
+	for _, k := range sorted {
+		old, inBefore := before[k]
+		updated, inAfter := after[k]
+		switch {
+		case !inBefore:
+			changes = append(changes, PlanChange{Step: step, Field: field, After: k + "=" + strconv.Quote(updated)})
+		case !inAfter:
+			changes = append(changes, PlanChange{Step: step, Field: field, Before: k + "=" + strconv.Quote(old)})
+		case old != updated:
+			changes = append(changes, PlanChange{Step: step, Field: field + " " + k, Before: strconv.Quote(old), After: strconv.Quote(updated)})
+		}
+	}
+	return changes
+}
+
+// diffSets reports plan-level values added or removed, in plan order
+func diffSets(field string, before, after []string) []PlanChange {
+	inBefore := make(map[string]bool, len(before))
+	for _, v := range before {
+		inBefore[v] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, v := range after {
+		inAfter[v] = true
+	}
+
+	var changes []PlanChange
+	for _, v := range after {
+		if !inBefore[v] {
+			changes = append(changes, PlanChange{Field: field, After: v})
+		}
+	}
+	for _, v := range before {
+		if !inAfter[v] {
+			changes = append(changes, PlanChange{Field: field, Before: v})
+		}
+	}
+	return changes
+}
+
+func prerequisiteNames(runPlan *llm.RunPlan) []string {
+	names := make([]string, len(runPlan.Prerequisites))
+	for i, prereq := range runPlan.Prerequisites {
+		names[i] = prereq.Name
+	}
+	return names
+}
+
+func portNames(ports []int) []string {
+	names := make([]string, len(ports))
+	for i, port := range ports {
+		names[i] = strconv.Itoa(port)
+	}
+	return names
+}
+
+// FormatPlanDiff renders plan changes one per line with the given indent
+func FormatPlanDiff(changes []PlanChange, indent string) string {
+	var sb strings.Builder
+	for _, change := range changes {
+		sb.WriteString(indent + "• " + change.String() + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Build context note for Docker plans without a .dockerignore
+
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
+
+// imageBuildPattern matches commands that send a build context to the
+// engine: docker/podman build, buildx build and compose build/up
+var imageBuildPattern = regexp.MustCompile(`\b(docker|podman)\s+(buildx\s+)?build\b|\b(docker|podman)[\s-]compose\b.*\b(build|up)\b`)
+
+// dockerContextNote returns a note for a plan that builds an image from a
+// Dockerfile while installed dependencies or build output sit in the
+// project root and no .dockerignore keeps them out of the build context,
+// or "" if that is not the case
+func dockerContextNote(plan *llm.RunPlan, profile *scanner.ProjectProfile) string {
+	if profile == nil || len(profile.DependencyDirs) == 0 {
+		return ""
+	}
+	if !hasProfileSignal(profile, scanner.FileTypeDockerfile) || hasProfileSignal(profile, scanner.FileTypeDockerignore) {
+		return ""
+	}
+	if !buildsImage(plan) {
+		return ""
+	}
+	dirs := strings.Join(profile.DependencyDirs, ", ")
+	return fmt.Sprintf("No .dockerignore: %s is sent to Docker as part of the build context, which makes image builds slow; add a .dockerignore listing %s",
+		dirs, dirs)
+}
+
+// buildsImage reports whether a step of the plan builds a container image
+func buildsImage(plan *llm.RunPlan) bool {
+	for _, step := range plan.Steps {
+		if imageBuildPattern.MatchString(step.Cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProfileSignal reports whether the profile has a project file signal
+func hasProfileSignal(profile *scanner.ProjectProfile, name string) bool {
+	for _, signal := range profile.Signals {
+		if strings.EqualFold(signal, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Editable plan text for reviewing a plan in $EDITOR
+
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// ErrEditAborted is returned by ParseEdited when the edited plan is empty
+var ErrEditAborted = errors.New("edited plan is empty")
+
+// commentPrefix starts a line that is ignored in edited plans
+const commentPrefix = "//"
+
+// EditHeader returns the comment block written above a plan being edited,
+// listing the problems found in the previous attempt
+func EditHeader(problems []string) string {
+	var sb strings.Builder
+	sb.WriteString("// Edit the plan below: reorder, change or remove steps, then save and close.\n")
+	sb.WriteString("// Lines starting with // are ignored. Save an empty file to abort.\n")
+	if len(problems) > 0 {
+		sb.WriteString("//\n// The edited plan is invalid:\n")
+		for _, problem := range problems {
+			sb.WriteString("//   - " + strings.ReplaceAll(problem, "\n", " ") + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// StripComments removes the lines starting with // from an edited plan
+func StripComments(data []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), commentPrefix) {
+			continue
+		}
+		out.WriteString(line)
+	}
+	return bytes.TrimLeft(out.Bytes(), "\r\n")
+}
+
+// ParseEdited decodes an edited plan with its comments stripped. Unknown
+// fields are rejected so a mistyped key is reported instead of dropped.
+func ParseEdited(data []byte) (*llm.RunPlan, error) {
+	body := StripComments(data)
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrEditAborted
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	var runPlan llm.RunPlan
+	if err := decoder.Decode(&runPlan); err != nil {
+		return nil, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+	return &runPlan, nil
+}
@@ -0,0 +1,85 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Per-step justification of a plan (--explain)
+
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
+
+// StepExplanation says why a step is in the plan and why it has its risk
+type StepExplanation struct {
+	StepID       string
+	Cmd          string
+	Kind         llm.StepKind
+	KindReason   string                // what classified the step ("" for other)
+	Risk         llm.RiskLevel         // the step's risk in the plan
+	DetectedRisk llm.RiskLevel         // what the security analyzer found
+	Categories   []string              // the analyzer's detections, e.g. "sudo"
+	Source       *scanner.ReadmeSource // README line of the command, nil if not from the README
+}
+
+// Explain justifies each step of a plan. readme is the README a README-first
+// plan was built from; pass nil for other plans.
+func (v *Validator) Explain(runPlan *llm.RunPlan, readme *scanner.ReadmeInfo) []StepExplanation {
+	explanations := make([]StepExplanation, 0, len(runPlan.Steps))
+	for i := range runPlan.Steps {
+		step := &runPlan.Steps[i]
+		analysis := v.policyChecker.AnalyzeCommand(step.Cmd)
+		kind, reason := llm.ExplainStepKind(step)
+
+		explanation := StepExplanation{
+			StepID:       step.ID,
+			Cmd:          step.Cmd,
+			Kind:         kind,
+			KindReason:   reason,
+			Risk:         step.Risk,
+			DetectedRisk: analysis.Risk,
+			Categories:   analysis.Categories,
+		}
+		if readme != nil {
+			if source, ok := readme.FindCommand(step.Cmd); ok {
+				explanation.Source = &source
+			}
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+// FormatExplanations returns the explanations as an indented, numbered list
+func FormatExplanations(explanations []StepExplanation, indent string) string {
+	var sb strings.Builder
+	for i, e := range explanations {
+		sb.WriteString(fmt.Sprintf("%s%d. %s: %s\n", indent, i+1, e.StepID, e.Cmd))
+
+		kind := string(e.Kind)
+		if e.KindReason != "" {
+			kind += " (" + e.KindReason + ")"
+		}
+		sb.WriteString(fmt.Sprintf("%s   kind:   %s\n", indent, kind))
+
+		detected := "nothing risky detected"
+		if len(e.Categories) > 0 {
+			detected = "detected " + strings.Join(e.Categories, ", ")
+		}
+		risk := fmt.Sprintf("%s (%s)", e.Risk, detected)
+		if e.Risk.Rank() > e.DetectedRisk.Rank() {
+			risk = fmt.Sprintf("%s (declared by the plan; %s, which alone is %s)", e.Risk, detected, e.DetectedRisk)
+		}
+		sb.WriteString(fmt.Sprintf("%s   risk:   %s\n", indent, risk))
+
+		if e.Source != nil {
+			source := fmt.Sprintf("README line %d", e.Source.Line)
+			if e.Source.Section != "" {
+				source += fmt.Sprintf(", section %q", e.Source.Section)
+			}
+			sb.WriteString(fmt.Sprintf("%s   source: %s\n", indent, source))
+		}
+	}
+	return sb.String()
+}
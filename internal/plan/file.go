@@ -0,0 +1,100 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Reading and writing RunPlan files
+
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAMLFile reports whether a plan path has a .yaml or .yml extension
+func IsYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// SaveFile writes a plan as YAML for a .yaml/.yml path, otherwise as
+// indented JSON
+func SaveFile(runPlan *llm.RunPlan, path string) error {
+	var data []byte
+	var err error
+	if IsYAMLFile(path) {
+		data, err = MarshalYAML(runPlan)
+	} else {
+		data, err = json.MarshalIndent(runPlan, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// MarshalYAML encodes a plan as YAML with two-space indentation
+func MarshalYAML(runPlan *llm.RunPlan) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(runPlan); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadFile reads a plan file: YAML for a .yaml/.yml path, otherwise JSON.
+// The plan is decoded but not validated.
+func LoadFile(path string) (*llm.RunPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	return decode(data, IsYAMLFile(path), path)
+}
+
+// Read decodes a plan from r, such as a plan piped on stdin: JSON when it
+// starts with "{", otherwise YAML. The plan is decoded but not validated.
+func Read(r io.Reader) (*llm.RunPlan, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("failed to read plan: no input")
+	}
+	return decode(trimmed, trimmed[0] != '{', "stdin")
+}
+
+// decode unmarshals a plan; source names it in errors
+func decode(data []byte, isYAML bool, source string) (*llm.RunPlan, error) {
+	var runPlan llm.RunPlan
+	if isYAML {
+		if err := yaml.Unmarshal(data, &runPlan); err != nil {
+			return nil, fmt.Errorf("invalid plan YAML in %s: %w", source, err)
+		}
+		return &runPlan, nil
+	}
+
+	if err := json.Unmarshal(data, &runPlan); err != nil {
+		return nil, fmt.Errorf("invalid plan JSON in %s: %w", source, err)
+	}
+	return &runPlan, nil
+}
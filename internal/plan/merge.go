@@ -0,0 +1,108 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Merging per-subproject plans into one monorepo plan
+
+package plan
+
+import (
+	"path"
+	"sort"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// Subplan is the plan generated for one subproject of a monorepo
+type Subplan struct {
+	Dir  string       // Subproject directory, relative to the repository root
+	Plan *llm.RunPlan // Plan generated from the subproject's own scan
+}
+
+// MergeSubplans combines subproject plans into a single "mixed" plan that
+// runs from the repository root. Steps keep their subproject order, get IDs
+// of the form "<dir>/<id>" and have their cwd rebased onto the subproject
+// directory; depends_on links each step to its own subproject only, so
+// subprojects can run in parallel. Prerequisites and ports are deduplicated; on conflicting env
+// values the first subproject wins.
+func MergeSubplans(subplans []Subplan) *llm.RunPlan {
+	merged := &llm.RunPlan{
+		Version:       llm.ValidPlanVersion,
+		ProjectType:   "mixed",
+		Prerequisites: []llm.Prerequisite{},
+		Steps:         []llm.Step{},
+		Env:           make(map[string]string),
+		Ports:         []int{},
+		Notes:         []string{},
+	}
+
+	seenPrereqs := make(map[string]bool)
+	seenPorts := make(map[int]bool)
+
+	for _, sub := range subplans {
+		if sub.Plan == nil {
+			continue
+		}
+
+		for _, prereq := range sub.Plan.Prerequisites {
+			if !seenPrereqs[prereq.Name] {
+				seenPrereqs[prereq.Name] = true
+				merged.Prerequisites = append(merged.Prerequisites, prereq)
+			}
+		}
+
+		// Subprojects are independent: each step only waits for its own
+		// subproject's steps (in order, unless the subplan has a graph)
+		hasGraph := sub.Plan.HasDependencies()
+		prevID := ""
+		for _, step := range sub.Plan.Steps {
+			var dependsOn []string
+			if hasGraph {
+				for _, dep := range step.DependsOn {
+					dependsOn = append(dependsOn, llm.SubprojectStepID(sub.Dir, dep))
+				}
+			} else if prevID != "" {
+				dependsOn = []string{prevID}
+			}
+			step.ID = llm.SubprojectStepID(sub.Dir, step.ID)
+			step.Cwd = path.Join(sub.Dir, step.Cwd)
+			step.DependsOn = dependsOn
+			prevID = step.ID
+			merged.Steps = append(merged.Steps, step)
+		}
+
+		for _, key := range sortedKeys(sub.Plan.Env) {
+			value := sub.Plan.Env[key]
+			if existing, ok := merged.Env[key]; ok && existing != value {
+				merged.Notes = append(merged.Notes,
+					"["+sub.Dir+"] env "+key+" differs from another subproject; using "+existing)
+				continue
+			}
+			merged.Env[key] = value
+		}
+
+		for _, port := range sub.Plan.Ports {
+			if !seenPorts[port] {
+				seenPorts[port] = true
+				merged.Ports = append(merged.Ports, port)
+			}
+		}
+
+		for _, note := range sub.Plan.Notes {
+			merged.Notes = append(merged.Notes, "["+sub.Dir+"] "+note)
+		}
+
+		if merged.HealthCheck == nil && sub.Plan.HealthCheck != nil {
+			merged.HealthCheck = sub.Plan.HealthCheck
+		}
+	}
+
+	return merged
+}
+
+// sortedKeys returns the keys of an env map in a stable order
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
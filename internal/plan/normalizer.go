@@ -4,10 +4,14 @@
 package plan
 
 import (
+	"fmt"
+	"regexp"
 	"runtime"
 	"strings"
 
+	"github.com/sony-level/readme-runner/internal/exec"
 	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/prereq"
 	"github.com/sony-level/readme-runner/internal/scanner"
 )
 
@@ -15,6 +19,7 @@ import (
 type Normalizer struct {
 	os      string
 	profile *scanner.ProjectProfile
+	engine  string // container engine docker commands run with
 }
 
 // NewNormalizer creates a new plan normalizer
@@ -33,6 +38,12 @@ func NewNormalizerWithOS(os string, profile *scanner.ProjectProfile) *Normalizer
 	}
 }
 
+// SetContainerEngine makes the normalizer rewrite docker invocations for
+// another engine (e.g. "podman"). Docker is the default.
+func (n *Normalizer) SetContainerEngine(engine string) {
+	n.engine = engine
+}
+
 // Normalize adjusts the plan for the current environment
 func (n *Normalizer) Normalize(plan *llm.RunPlan) *llm.RunPlan {
 	normalized := *plan
@@ -41,9 +52,19 @@ func (n *Normalizer) Normalize(plan *llm.RunPlan) *llm.RunPlan {
 	for i, step := range plan.Steps {
 		normalized.Steps[i] = n.normalizeStep(step)
 	}
+	uniqueStepIDs(normalized.Steps)
 
 	// Normalize prerequisites
 	normalized.Prerequisites = n.normalizePrerequisites(plan.Prerequisites)
+	normalized.Prerequisites = n.addStepPrerequisites(normalized.Prerequisites, normalized.Steps)
+
+	if n.usesPodman() && usesDockerCLI(plan) {
+		normalized.Notes = append(append([]string{}, plan.Notes...),
+			"docker commands rewritten to podman (podman compose needs podman-compose or docker-compose installed)")
+	}
+	if note := dockerContextNote(&normalized, n.profile); note != "" {
+		normalized.Notes = append(append([]string{}, normalized.Notes...), note)
+	}
 
 	return &normalized
 }
@@ -57,6 +78,7 @@ func (n *Normalizer) normalizeStep(step llm.Step) llm.Step {
 
 	// Normalize Docker commands
 	normalized.Cmd = n.normalizeDockerCommand(normalized.Cmd)
+	normalized.Cmd = n.detachComposeUp(normalized)
 
 	// Normalize paths for OS
 	normalized.Cmd = n.normalizePathSeparators(normalized.Cmd)
@@ -67,9 +89,56 @@ func (n *Normalizer) normalizeStep(step llm.Step) llm.Step {
 		normalized.Cwd = "."
 	}
 
+	normalized.Timeout = clampStepTimeout(step.Timeout)
+
 	return normalized
 }
 
+// uniqueStepIDs renames repeated step IDs to "<id>_2", "<id>_3", ... so
+// resume state and depends_on never mix up two steps. The validator rejects
+// duplicates; this only guards plans that reach execution without it.
+// depends_on keeps naming the first step with the ID.
+func uniqueStepIDs(steps []llm.Step) {
+	seen := make(map[string]bool, len(steps))
+	for i := range steps {
+		seen[steps[i].ID] = true
+	}
+	used := make(map[string]bool, len(steps))
+	for i := range steps {
+		id := steps[i].ID
+		if used[id] {
+			for n := 2; ; n++ {
+				candidate := fmt.Sprintf("%s_%d", id, n)
+				if !seen[candidate] && !used[candidate] {
+					id = candidate
+					break
+				}
+			}
+			steps[i].ID = id
+		}
+		used[id] = true
+	}
+}
+
+// minStepTimeout is the shortest timeout (seconds) kept from a plan: lower
+// values are usually minutes written as seconds
+const minStepTimeout = 30
+
+// clampStepTimeout keeps a step's timeout (seconds) between minStepTimeout
+// and exec.MaxStepTimeout. 0 (and any negative value) means the default.
+func clampStepTimeout(timeout int) int {
+	maxTimeout := int(exec.MaxStepTimeout.Seconds())
+	switch {
+	case timeout <= 0:
+		return 0
+	case timeout < minStepTimeout:
+		return minStepTimeout
+	case timeout > maxTimeout:
+		return maxTimeout
+	}
+	return timeout
+}
+
 // normalizePackageCommand adjusts package manager commands based on lockfiles
 func (n *Normalizer) normalizePackageCommand(cmd string) string {
 	if n.profile == nil {
@@ -114,20 +183,52 @@ func (n *Normalizer) normalizePackageCommand(cmd string) string {
 func (n *Normalizer) normalizeDockerCommand(cmd string) string {
 	// Prefer "docker compose" over "docker-compose"
 	if strings.HasPrefix(cmd, "docker-compose ") {
-		return strings.Replace(cmd, "docker-compose ", "docker compose ", 1)
+		cmd = strings.Replace(cmd, "docker-compose ", "docker compose ", 1)
 	}
 
-	// Add --build flag to docker compose up if not present for dev usage
-	if strings.HasPrefix(cmd, "docker compose up") && !strings.Contains(cmd, "--build") {
-		// Only for run steps, not explicit build steps
-		if !strings.Contains(cmd, "-d") {
-			// Interactive mode - might want to rebuild
-		}
+	// Podman is CLI-compatible: docker build/run/compose -> podman ...
+	if n.usesPodman() {
+		cmd = dockerInvocation.ReplaceAllString(cmd, "${1}podman${2}")
 	}
 
 	return cmd
 }
 
+// detachComposeUp makes a foreground compose up in the run step start the
+// services in the background. Docker waits for them to be running (and
+// healthy, when they define a healthcheck); podman compose has no --wait.
+func (n *Normalizer) detachComposeUp(step llm.Step) string {
+	if _, id := llm.SplitStepID(step.ID); id != "run" {
+		return step.Cmd
+	}
+	if !exec.IsComposeUp(step.Cmd) || exec.IsDetachedComposeUp(step.Cmd) {
+		return step.Cmd
+	}
+	if strings.HasPrefix(step.Cmd, "podman ") {
+		return step.Cmd + " -d"
+	}
+	return step.Cmd + " -d --wait"
+}
+
+// dockerInvocation matches docker used as a command: at the start, after
+// a shell separator or after sudo
+var dockerInvocation = regexp.MustCompile(`(^|[;&|(]\s*|\bsudo\s+)docker(\s|$)`)
+
+// usesPodman reports whether docker commands are rewritten for podman
+func (n *Normalizer) usesPodman() bool {
+	return n.engine == prereq.EnginePodman
+}
+
+// usesDockerCLI reports whether any step invokes docker
+func usesDockerCLI(plan *llm.RunPlan) bool {
+	for _, step := range plan.Steps {
+		if dockerInvocation.MatchString(step.Cmd) || strings.HasPrefix(step.Cmd, "docker-compose ") {
+			return true
+		}
+	}
+	return false
+}
+
 // normalizePathSeparators adjusts path separators for the current OS
 func (n *Normalizer) normalizePathSeparators(path string) string {
 	if n.os == "windows" {
@@ -147,6 +248,10 @@ func (n *Normalizer) normalizePrerequisites(prereqs []llm.Prerequisite) []llm.Pr
 			prereq.Name = "docker"
 			prereq.Reason = "Docker (with compose plugin) required"
 		}
+		if prereq.Name == "docker" && n.usesPodman() {
+			prereq.Name = "podman"
+			prereq.Reason = "Podman (Docker-compatible engine) required"
+		}
 
 		// Deduplicate
 		exists := false
@@ -164,6 +269,42 @@ func (n *Normalizer) normalizePrerequisites(prereqs []llm.Prerequisite) []llm.Pr
 	return normalized
 }
 
+// stepTools maps CLIs that plans often invoke without listing them as
+// prerequisites to the reason reported when one is added
+var stepTools = map[string]string{
+	"kubectl":   "Kubernetes CLI used by plan steps",
+	"helm":      "Helm used by plan steps to install charts",
+	"terraform": "Terraform used by plan steps",
+}
+
+// addStepPrerequisites adds a prerequisite for each cluster/infrastructure
+// CLI a step runs, so its availability is checked before execution
+func (n *Normalizer) addStepPrerequisites(prereqs []llm.Prerequisite, steps []llm.Step) []llm.Prerequisite {
+	listed := make(map[string]bool, len(prereqs))
+	for _, p := range prereqs {
+		listed[p.Name] = true
+	}
+
+	for _, step := range steps {
+		fields := strings.Fields(step.Cmd)
+		if len(fields) == 0 {
+			continue
+		}
+		tool := fields[0]
+		if tool == "sudo" && len(fields) > 1 {
+			tool = fields[1]
+		}
+		reason, ok := stepTools[tool]
+		if !ok || listed[tool] {
+			continue
+		}
+		listed[tool] = true
+		prereqs = append(prereqs, llm.Prerequisite{Name: tool, Reason: reason})
+	}
+
+	return prereqs
+}
+
 // hasPackageFile checks if a package file exists in the profile
 func (n *Normalizer) hasPackageFile(filename string) bool {
 	if n.profile == nil {
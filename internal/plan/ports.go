@@ -0,0 +1,133 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Detection of ports bound by plan steps and conflicts between them
+
+package plan
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// portPatterns match the port a command listens on. The first submatch is
+// the port; for docker -p/--publish it is the host side of the mapping.
+// A bare "-p <port>" is not matched: clients such as psql and redis-cli
+// take the port they connect to that way, and docker publishes it on a
+// random host port.
+var portPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:^|\s)(?:-p|--publish)[ =](?:[\d.]+:)?(\d+):\d+`),
+	regexp.MustCompile(`(?:^|\s)--port[ =](\d+)\b`),
+	regexp.MustCompile(`(?:^|\s)PORT=(\d+)\b`),
+	regexp.MustCompile(`\brunserver\s+(?:[\w.]+:)?(\d+)\b`),
+	regexp.MustCompile(`\bhttp\.server\s+(\d+)\b`),
+	regexp.MustCompile(`(?:^|\s)(?:-b|--bind)[ =][\w.]*:(\d+)\b`),
+}
+
+// maxPortSearch bounds how far FreePortNear looks past a busy port
+const maxPortSearch = 100
+
+// PortConflict is a port bound by more than one step
+type PortConflict struct {
+	Port    int
+	StepIDs []string
+}
+
+func (c PortConflict) String() string {
+	return fmt.Sprintf("Port %d is bound by more than one step: %s", c.Port, strings.Join(c.StepIDs, ", "))
+}
+
+// StepPorts returns the ports a command listens on, in order of appearance
+func StepPorts(cmd string) []int {
+	type match struct{ offset, port int }
+	var matches []match
+	for _, pattern := range portPatterns {
+		for _, loc := range pattern.FindAllStringSubmatchIndex(cmd, -1) {
+			port, err := strconv.Atoi(cmd[loc[2]:loc[3]])
+			if err != nil || port < 1 || port > 65535 {
+				continue
+			}
+			matches = append(matches, match{loc[2], port})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].offset < matches[j].offset })
+
+	var ports []int
+	seen := make(map[int]bool)
+	for _, m := range matches {
+		if !seen[m.port] {
+			seen[m.port] = true
+			ports = append(ports, m.port)
+		}
+	}
+	return ports
+}
+
+// FindPortConflicts returns the ports bound by two or more steps, sorted by port
+func FindPortConflicts(plan *llm.RunPlan) []PortConflict {
+	stepsByPort := make(map[int][]string)
+	for _, step := range plan.Steps {
+		for _, port := range StepPorts(step.Cmd) {
+			stepsByPort[port] = append(stepsByPort[port], step.ID)
+		}
+	}
+
+	var conflicts []PortConflict
+	for port, ids := range stepsByPort {
+		if len(ids) > 1 {
+			conflicts = append(conflicts, PortConflict{Port: port, StepIDs: ids})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Port < conflicts[j].Port })
+	return conflicts
+}
+
+// PlanPorts returns the ports declared in the plan and bound by its steps,
+// sorted and deduplicated
+func PlanPorts(plan *llm.RunPlan) []int {
+	seen := make(map[int]bool)
+	var ports []int
+	add := func(port int) {
+		if port > 0 && port <= 65535 && !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	for _, port := range plan.Ports {
+		add(port)
+	}
+	for _, step := range plan.Steps {
+		for _, port := range StepPorts(step.Cmd) {
+			add(port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// PortInUse reports whether a TCP port is already bound on the host. A port
+// that cannot be bound for lack of privileges is not reported.
+func PortInUse(port int) bool {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return strings.Contains(err.Error(), "address already in use") ||
+			strings.Contains(err.Error(), "Only one usage of each socket address")
+	}
+	listener.Close()
+	return false
+}
+
+// FreePortNear returns the first port after port that is free on the host,
+// or 0 if none is found nearby
+func FreePortNear(port int) int {
+	for candidate := port + 1; candidate <= port+maxPortSearch && candidate <= 65535; candidate++ {
+		if !PortInUse(candidate) {
+			return candidate
+		}
+	}
+	return 0
+}
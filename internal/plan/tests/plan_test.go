@@ -4,11 +4,17 @@
 package tests
 
 import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sony-level/readme-runner/internal/llm"
 	"github.com/sony-level/readme-runner/internal/plan"
 	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/security"
 )
 
 func TestValidatorValidPlan(t *testing.T) {
@@ -52,7 +58,7 @@ func TestValidatorSudoDetection(t *testing.T) {
 	// Should warn about sudo mismatch
 	hasWarning := false
 	for _, warn := range result.Warnings {
-		if warn != "" {
+		if warn.Message != "" {
 			hasWarning = true
 			break
 		}
@@ -62,6 +68,33 @@ func TestValidatorSudoDetection(t *testing.T) {
 	}
 }
 
+func TestValidatorWarnsLongRunningStep(t *testing.T) {
+	validator := plan.NewValidator()
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "python",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "pip install -r requirements.txt", Cwd: "."},
+			{ID: "serve", Cmd: "python manage.py runserver", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: "."},
+		},
+	}
+
+	result := validator.Validate(runPlan)
+
+	var warnings []string
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, "long-running") {
+			warnings = append(warnings, warning.Message)
+		}
+	}
+	// The conventional run step is expected to block
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Step serve") {
+		t.Errorf("expected one long-running warning for serve, got %v", warnings)
+	}
+}
+
 func TestValidatorEnhancePlan(t *testing.T) {
 	validator := plan.NewValidator()
 
@@ -176,6 +209,30 @@ func TestNormalizerDockerCompose(t *testing.T) {
 	}
 }
 
+func TestNormalizerDetachesComposeRunStep(t *testing.T) {
+	tests := []struct {
+		engine string
+		step   llm.Step
+		want   string
+	}{
+		{"", llm.Step{ID: "run", Cmd: "docker compose up"}, "docker compose up -d --wait"},
+		{"", llm.Step{ID: "run", Cmd: "docker-compose -f compose.dev.yml up --build"}, "docker compose -f compose.dev.yml up --build -d --wait"},
+		{"", llm.Step{ID: "api/run", Cmd: "docker compose up"}, "docker compose up -d --wait"},
+		{"", llm.Step{ID: "run", Cmd: "docker compose up -d"}, "docker compose up -d"},
+		{"", llm.Step{ID: "up", Cmd: "docker compose up"}, "docker compose up"},
+		{"", llm.Step{ID: "run", Cmd: "docker compose build && docker compose up"}, "docker compose build && docker compose up"},
+		{"podman", llm.Step{ID: "run", Cmd: "docker compose up"}, "podman compose up -d"},
+	}
+	for _, tt := range tests {
+		normalizer := plan.NewNormalizer(nil)
+		normalizer.SetContainerEngine(tt.engine)
+		normalized := normalizer.Normalize(&llm.RunPlan{Version: "1", ProjectType: "docker", Steps: []llm.Step{tt.step}})
+		if got := normalized.Steps[0].Cmd; got != tt.want {
+			t.Errorf("%s %q: Cmd = %q, want %q", tt.engine, tt.step.Cmd, got, tt.want)
+		}
+	}
+}
+
 func TestNormalizerPrerequisitesDedupe(t *testing.T) {
 	normalizer := plan.NewNormalizer(nil)
 
@@ -197,6 +254,136 @@ func TestNormalizerPrerequisitesDedupe(t *testing.T) {
 	}
 }
 
+func TestNormalizerAddsStepPrerequisites(t *testing.T) {
+	normalizer := plan.NewNormalizer(nil)
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "kubernetes",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "kubectl", Reason: "Kubernetes CLI required"},
+		},
+		Steps: []llm.Step{
+			{ID: "init", Cmd: "terraform init", Cwd: "infra"},
+			{ID: "deploy", Cmd: "helm upgrade --install web ./chart", Cwd: "."},
+			{ID: "check", Cmd: "kubectl get pods", Cwd: "."},
+		},
+	}
+
+	normalized := normalizer.Normalize(runPlan)
+
+	names := make([]string, 0, len(normalized.Prerequisites))
+	for _, p := range normalized.Prerequisites {
+		names = append(names, p.Name)
+	}
+	want := []string{"kubectl", "terraform", "helm"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Prerequisites = %v, want %v", names, want)
+	}
+}
+
+func TestNormalizerClampsStepTimeout(t *testing.T) {
+	normalizer := plan.NewNormalizer(nil)
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "default", Cmd: "npm ci", Cwd: "."},
+			{ID: "negative", Cmd: "npm ci", Cwd: ".", Timeout: -5},
+			{ID: "minutes", Cmd: "npm run build", Cwd: ".", Timeout: 10},
+			{ID: "build", Cmd: "npm run build", Cwd: ".", Timeout: 900},
+			{ID: "huge", Cmd: "npm run build", Cwd: ".", Timeout: 86400},
+		},
+	}
+
+	normalized := normalizer.Normalize(runPlan)
+
+	want := []int{0, 0, 30, 900, 1800}
+	for i, step := range normalized.Steps {
+		if step.Timeout != want[i] {
+			t.Errorf("step %s: Timeout = %d, want %d", step.ID, step.Timeout, want[i])
+		}
+	}
+}
+
+func TestNormalizerPodmanRewrite(t *testing.T) {
+	normalizer := plan.NewNormalizer(nil)
+	normalizer.SetContainerEngine("podman")
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "docker",
+		Prerequisites: []llm.Prerequisite{
+			{Name: "docker", Reason: "Docker required"},
+			{Name: "docker-compose", Reason: "Compose required"},
+		},
+		Steps: []llm.Step{
+			{ID: "build", Cmd: "docker build -t app .", Cwd: "."},
+			{ID: "up", Cmd: "docker-compose up", Cwd: "."},
+			{ID: "chain", Cmd: "cd web && sudo docker run my-docker-image", Cwd: "."},
+			{ID: "other", Cmd: "echo docker", Cwd: "."},
+		},
+	}
+
+	normalized := normalizer.Normalize(runPlan)
+
+	want := []string{
+		"podman build -t app .",
+		"podman compose up",
+		"cd web && sudo podman run my-docker-image",
+		"echo docker",
+	}
+	for i, cmd := range want {
+		if normalized.Steps[i].Cmd != cmd {
+			t.Errorf("Steps[%d].Cmd = %q, want %q", i, normalized.Steps[i].Cmd, cmd)
+		}
+	}
+
+	if len(normalized.Prerequisites) != 1 || normalized.Prerequisites[0].Name != "podman" {
+		t.Errorf("Prerequisites = %v, want [podman]", normalized.Prerequisites)
+	}
+	if len(normalized.Notes) != 1 || len(runPlan.Notes) != 0 {
+		t.Errorf("Notes = %v, want one rewrite note (original plan untouched)", normalized.Notes)
+	}
+}
+
+func TestNormalizerDockerContextNote(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "docker",
+		Steps:       []llm.Step{{ID: "build", Cmd: "docker build -t app .", Cwd: "."}},
+	}
+	profile := &scanner.ProjectProfile{
+		Signals:        []string{"Dockerfile", "package.json"},
+		DependencyDirs: []string{"node_modules"},
+	}
+
+	normalized := plan.NewNormalizer(profile).Normalize(runPlan)
+	if len(normalized.Notes) != 1 || !strings.Contains(normalized.Notes[0], "add a .dockerignore listing node_modules") {
+		t.Errorf("Notes = %v, want the .dockerignore note", normalized.Notes)
+	}
+
+	// A .dockerignore, no dependency directories or no image build: no note
+	withIgnore := *profile
+	withIgnore.Signals = append([]string{".dockerignore"}, profile.Signals...)
+	noDeps := *profile
+	noDeps.DependencyDirs = nil
+	for name, p := range map[string]*scanner.ProjectProfile{".dockerignore": &withIgnore, "no dependency dirs": &noDeps} {
+		if notes := plan.NewNormalizer(p).Normalize(runPlan).Notes; len(notes) != 0 {
+			t.Errorf("%s: Notes = %v, want none", name, notes)
+		}
+	}
+	runOnly := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "docker",
+		Steps:       []llm.Step{{ID: "run", Cmd: "docker run -p 8080:8080 nginx", Cwd: "."}},
+	}
+	if notes := plan.NewNormalizer(profile).Normalize(runOnly).Notes; len(notes) != 0 {
+		t.Errorf("docker run: Notes = %v, want none", notes)
+	}
+}
+
 func TestNormalizerSuggestDocker(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -241,3 +428,586 @@ func TestNormalizerSuggestDocker(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeSubplans(t *testing.T) {
+	frontend := &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "node",
+		Prerequisites: []llm.Prerequisite{{Name: "node"}, {Name: "npm"}},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm install", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: "."},
+		},
+		Env:   map[string]string{"NODE_ENV": "development"},
+		Ports: []int{3000},
+		Notes: []string{"Using npm package manager"},
+	}
+	backend := &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "go",
+		Prerequisites: []llm.Prerequisite{{Name: "go"}, {Name: "node"}},
+		Steps: []llm.Step{
+			{ID: "build", Cmd: "go build ./...", Cwd: "cmd/server"},
+		},
+		Env:   map[string]string{"NODE_ENV": "production"},
+		Ports: []int{8080, 3000},
+	}
+
+	merged := plan.MergeSubplans([]plan.Subplan{
+		{Dir: "frontend", Plan: frontend},
+		{Dir: "backend", Plan: backend},
+	})
+
+	if merged.ProjectType != "mixed" {
+		t.Errorf("ProjectType = %q, want mixed", merged.ProjectType)
+	}
+	if err := merged.Validate(); err != nil {
+		t.Errorf("merged plan should be valid: %v", err)
+	}
+
+	wantSteps := []struct{ id, cwd string }{
+		{"frontend/install", "frontend"},
+		{"frontend/run", "frontend"},
+		{"backend/build", "backend/cmd/server"},
+	}
+	if len(merged.Steps) != len(wantSteps) {
+		t.Fatalf("got %d steps, want %d", len(merged.Steps), len(wantSteps))
+	}
+	for i, want := range wantSteps {
+		if merged.Steps[i].ID != want.id || merged.Steps[i].Cwd != want.cwd {
+			t.Errorf("step %d = %s (cwd %s), want %s (cwd %s)",
+				i, merged.Steps[i].ID, merged.Steps[i].Cwd, want.id, want.cwd)
+		}
+	}
+
+	if deps := merged.Steps[1].DependsOn; len(deps) != 1 || deps[0] != "frontend/install" {
+		t.Errorf("frontend/run DependsOn = %v, want [frontend/install]", deps)
+	}
+	if deps := merged.Steps[2].DependsOn; len(deps) != 0 {
+		t.Errorf("backend/build DependsOn = %v, want none (independent subproject)", deps)
+	}
+
+	if len(merged.Prerequisites) != 3 {
+		t.Errorf("Prerequisites = %v, want node, npm, go", merged.Prerequisites)
+	}
+	if len(merged.Ports) != 2 {
+		t.Errorf("Ports = %v, want [3000 8080]", merged.Ports)
+	}
+	if merged.Env["NODE_ENV"] != "development" {
+		t.Errorf("NODE_ENV = %q, first subproject should win", merged.Env["NODE_ENV"])
+	}
+	if merged.Notes[0] != "[frontend] Using npm package manager" {
+		t.Errorf("Notes = %v, want notes prefixed with the subproject", merged.Notes)
+	}
+
+	if sub, id := llm.SplitStepID("backend/build"); sub != "backend" || id != "build" {
+		t.Errorf("SplitStepID = %q, %q", sub, id)
+	}
+}
+
+func TestValidatorDependsOn(t *testing.T) {
+	validator := plan.NewValidator()
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm install", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: ".", DependsOn: []string{"install"}},
+		},
+	}
+	if result := validator.Validate(runPlan); !result.Valid {
+		t.Errorf("expected valid plan, got errors: %v", result.Errors)
+	}
+
+	// Forward references and unknown IDs are rejected (no cycles possible)
+	runPlan.Steps[0].DependsOn = []string{"run"}
+	result := validator.Validate(runPlan)
+	if result.Valid {
+		t.Fatal("expected forward depends_on to be rejected")
+	}
+	if !strings.Contains(strings.Join(result.Errors, "\n"), "must name an earlier step") {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+}
+
+func TestDuplicateStepIDs(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "run", Cmd: "npm install", Cwd: "."},
+			{ID: "run_2", Cmd: "npm run build", Cwd: "."},
+			{ID: "run", Cmd: "npm start", Cwd: "."},
+			{ID: "run", Cmd: "npm run worker", Cwd: "."},
+		},
+	}
+
+	result := plan.NewValidator().Validate(runPlan)
+	if result.Valid {
+		t.Fatal("expected duplicate step IDs to be rejected")
+	}
+	if !strings.Contains(strings.Join(result.Errors, "\n"), `Step 3: duplicate step ID "run"`) {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+
+	// The normalizer renames repeats without clashing with existing IDs
+	normalized := plan.NewNormalizer(nil).Normalize(runPlan)
+	want := []string{"run", "run_2", "run_3", "run_4"}
+	for i, step := range normalized.Steps {
+		if step.ID != want[i] {
+			t.Errorf("step %d: ID = %s, want %s", i+1, step.ID, want[i])
+		}
+	}
+	if runPlan.Steps[2].ID != "run" {
+		t.Error("Normalize() modified the input plan")
+	}
+	if result := plan.NewValidator().Validate(normalized); !result.Valid {
+		t.Errorf("normalized plan invalid: %v", result.Errors)
+	}
+}
+
+func TestValidatorCwdContainment(t *testing.T) {
+	validator := plan.NewValidator()
+
+	tests := []struct {
+		cwd   string
+		valid bool
+	}{
+		{".", true},
+		{"frontend", true},
+		{"frontend/../backend", true},
+		{"..", false},
+		{"../other", false},
+		{"frontend/../../other", false},
+		{"/etc", false},
+	}
+
+	for _, tt := range tests {
+		runPlan := &llm.RunPlan{
+			Version:     "1",
+			ProjectType: "node",
+			Steps: []llm.Step{
+				{ID: "install", Cmd: "npm install", Cwd: tt.cwd},
+			},
+		}
+		result := validator.Validate(runPlan)
+		if result.Valid != tt.valid {
+			t.Errorf("cwd %q: expected valid=%v, got errors: %v", tt.cwd, tt.valid, result.Errors)
+		}
+		if !tt.valid && !strings.Contains(strings.Join(result.Errors, "\n"), "outside the project directory") {
+			t.Errorf("cwd %q: unexpected errors: %v", tt.cwd, result.Errors)
+		}
+	}
+}
+
+func TestStepPorts(t *testing.T) {
+	tests := []struct {
+		cmd   string
+		ports []int
+	}{
+		{"docker run -p 8080:80 -p 127.0.0.1:5432:5432 app", []int{8080, 5432}},
+		{"npm run dev -- --port 3000", []int{3000}},
+		{"PORT=4000 npm start", []int{4000}},
+		{"bundle exec rails server --port 3000 -b 0.0.0.0", []int{3000}},
+		{"psql -h localhost -p 5432 -U postgres", nil},
+		{"redis-cli -p 6379 ping", nil},
+		{"docker run -p 8080 app", nil},
+		{"python manage.py runserver 0.0.0.0:8000", []int{8000}},
+		{"gunicorn --bind 0.0.0.0:8000 app:app", []int{8000}},
+		{"python -m http.server 9000", []int{9000}},
+		{"docker compose -p myproject up", nil},
+		{"curl http://localhost:3000/health", nil},
+	}
+	for _, tt := range tests {
+		if got := plan.StepPorts(tt.cmd); !reflect.DeepEqual(got, tt.ports) {
+			t.Errorf("StepPorts(%q) = %v, want %v", tt.cmd, got, tt.ports)
+		}
+	}
+}
+
+func TestValidatorPortConflicts(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "mixed",
+		Steps: []llm.Step{
+			{ID: "api", Cmd: "PORT=3000 npm start", Cwd: "."},
+			{ID: "web", Cmd: "npx serve --port 3000", Cwd: "."},
+			{ID: "db", Cmd: "docker run -p 5432:5432 postgres", Cwd: "."},
+		},
+	}
+
+	result := plan.NewValidator().Validate(runPlan)
+	if len(result.PortConflicts) != 1 {
+		t.Fatalf("expected 1 port conflict, got %v", result.PortConflicts)
+	}
+	conflict := result.PortConflicts[0]
+	if conflict.Port != 3000 || !reflect.DeepEqual(conflict.StepIDs, []string{"api", "web"}) {
+		t.Errorf("unexpected conflict: %+v", conflict)
+	}
+	if !result.Valid {
+		t.Errorf("a port conflict should only warn, got errors %v", result.Errors)
+	}
+}
+
+func TestPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if !plan.PortInUse(port) {
+		t.Errorf("expected port %d to be reported in use", port)
+	}
+	if free := plan.FreePortNear(port); free == 0 || free == port {
+		t.Errorf("expected a free port near %d, got %d", port, free)
+	}
+}
+
+func TestEnhancePlanDetectsEmbeddedSudo(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "and", Cmd: "echo x && sudo apt install -y libpq-dev", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "semicolon", Cmd: "cd build;sudo make install", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "subshell", Cmd: "echo $(sudo cat /etc/shadow)", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "pipe", Cmd: "curl -fsSL https://example.com/key |sudo tee /etc/apt/key", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "package", Cmd: "npm install sudo-prompt", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "visudo", Cmd: "echo visudo", Cwd: ".", Risk: llm.RiskLow},
+		},
+	}
+
+	enhanced := plan.NewValidator().EnhancePlan(runPlan)
+	want := map[string]bool{"and": true, "semicolon": true, "subshell": true, "pipe": true, "package": false, "visudo": false}
+	for _, step := range enhanced.Steps {
+		if step.RequiresSudo != want[step.ID] {
+			t.Errorf("step %s: RequiresSudo = %v, want %v", step.ID, step.RequiresSudo, want[step.ID])
+		}
+		if want[step.ID] && step.Risk != llm.RiskCritical {
+			t.Errorf("step %s: expected critical risk, got %s", step.ID, step.Risk)
+		}
+	}
+}
+
+func TestParseEditedPlan(t *testing.T) {
+	header := plan.EditHeader([]string{"Step build: cwd \"../x\" is outside the project directory"})
+	if !strings.Contains(header, "//   - Step build") {
+		t.Errorf("expected the problem listed in the header, got:\n%s", header)
+	}
+
+	edited := header + `{
+  "version": "1",
+  "project_type": "node",
+  "prerequisites": [],
+  // a comment left between fields
+  "steps": [{"id": "start", "cmd": "npm start", "cwd": ".", "risk": "low", "requires_sudo": false}]
+}
+`
+	runPlan, err := plan.ParseEdited([]byte(edited))
+	if err != nil {
+		t.Fatalf("ParseEdited: %v", err)
+	}
+	if len(runPlan.Steps) != 1 || runPlan.Steps[0].Cmd != "npm start" {
+		t.Errorf("unexpected plan: %+v", runPlan)
+	}
+
+	if _, err := plan.ParseEdited([]byte(header)); err != plan.ErrEditAborted {
+		t.Errorf("expected ErrEditAborted for an empty plan, got %v", err)
+	}
+	if _, err := plan.ParseEdited([]byte(`{"version": "1", "stpes": []}`)); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestDiffPlans(t *testing.T) {
+	before := &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "node",
+		Prerequisites: []llm.Prerequisite{{Name: "node"}},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm install", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "lint", Cmd: "npm run lint", Cwd: "."},
+		},
+	}
+	after := &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "node",
+		Prerequisites: []llm.Prerequisite{{Name: "node"}, {Name: "npm"}},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm ci", Cwd: ".", Risk: llm.RiskMedium},
+			{ID: "start", Cmd: "npm start", Cwd: "."},
+		},
+		Env: map[string]string{"NODE_ENV": "development"},
+	}
+
+	got := plan.FormatPlanDiff(plan.DiffPlans(before, after), "")
+	for _, want := range []string{
+		`• step install: cmd "npm install" → "npm ci"`,
+		`• step install: risk low → medium`,
+		`• step start added: "npm start"`,
+		`• step lint removed: "npm run lint"`,
+		`• plan: prerequisite added npm`,
+		`• plan: env added NODE_ENV="development"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in diff:\n%s", want, got)
+		}
+	}
+	if changes := plan.DiffPlans(after, after); len(changes) != 0 {
+		t.Errorf("expected no changes between identical plans, got %v", changes)
+	}
+}
+
+func TestPlanFileYAMLRoundTrip(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:       "1",
+		ProjectType:   "python",
+		Prerequisites: []llm.Prerequisite{{Name: "python", Reason: "Runtime", MinVersion: "3.11"}},
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "pip install -r requirements.txt", Cwd: ".", Risk: llm.RiskMedium},
+			{ID: "run", Cmd: "python app.py", Cwd: ".", Risk: llm.RiskLow, DependsOn: []string{"install"}, Timeout: 60},
+		},
+		Env:         map[string]string{"FLASK_ENV": "development"},
+		Ports:       []int{5000},
+		Notes:       []string{},
+		HealthCheck: &llm.HealthCheck{URL: "http://localhost:5000/"},
+	}
+
+	path := filepath.Join(t.TempDir(), "run-plan.yaml")
+	if err := plan.SaveFile(runPlan, path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "project_type: python") || !strings.Contains(string(data), "requires_sudo: false") {
+		t.Errorf("expected snake_case YAML keys, got:\n%s", data)
+	}
+
+	loaded, err := plan.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, runPlan) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", loaded, runPlan)
+	}
+
+	hand := filepath.Join(t.TempDir(), "plan.yml")
+	os.WriteFile(hand, []byte("version: \"1\"\nproject_type: go\nsteps:\n  - id: build\n    cmd: go build ./...\n    cwd: .\n"), 0644)
+	handPlan, err := plan.LoadFile(hand)
+	if err != nil {
+		t.Fatalf("LoadFile(.yml): %v", err)
+	}
+	if len(handPlan.Steps) != 1 || handPlan.Steps[0].Cmd != "go build ./..." {
+		t.Errorf("unexpected hand-written plan: %+v", handPlan)
+	}
+}
+
+func TestPlanRead(t *testing.T) {
+	jsonPlan, err := plan.Read(strings.NewReader(`  {"version": "1", "project_type": "go", "steps": [{"id": "build", "cmd": "go build ./...", "cwd": "."}]}`))
+	if err != nil {
+		t.Fatalf("Read(JSON): %v", err)
+	}
+	yamlPlan, err := plan.Read(strings.NewReader("version: \"1\"\nproject_type: go\nsteps:\n  - id: build\n    cmd: go build ./...\n    cwd: .\n"))
+	if err != nil {
+		t.Fatalf("Read(YAML): %v", err)
+	}
+	if !reflect.DeepEqual(jsonPlan, yamlPlan) {
+		t.Errorf("JSON and YAML plans differ:\n%+v\n%+v", jsonPlan, yamlPlan)
+	}
+
+	if _, err := plan.Read(strings.NewReader("\n")); err == nil || !strings.Contains(err.Error(), "no input") {
+		t.Errorf("Read(empty) error = %v, want no input", err)
+	}
+	if _, err := plan.Read(strings.NewReader(`{"steps": [`)); err == nil || !strings.Contains(err.Error(), "invalid plan JSON in stdin") {
+		t.Errorf("Read(truncated) error = %v, want invalid JSON", err)
+	}
+}
+
+func TestValidatorExplain(t *testing.T) {
+	readme := &scanner.ReadmeInfo{
+		Content: "# Demo\n\n## Installation\n\n### From source\n\n```bash\n$ npm install\n```\n\n## Usage\n\n```bash\nnpm start\n```\n",
+	}
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "install", Cmd: "npm install", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "deps", Cmd: "sudo apt-get install -y libpq-dev", Cwd: ".", Risk: llm.RiskCritical},
+			{ID: "serve", Cmd: "npm start && echo started", Cwd: ".", Risk: llm.RiskMedium},
+		},
+	}
+
+	explanations := plan.NewValidator().Explain(runPlan, readme)
+	if len(explanations) != 3 {
+		t.Fatalf("got %d explanations, want 3", len(explanations))
+	}
+
+	install := explanations[0]
+	if install.Kind != llm.StepKindInstall || install.KindReason != `ID contains "install"` {
+		t.Errorf("install kind = %s (%s)", install.Kind, install.KindReason)
+	}
+	if install.Source == nil || install.Source.Line != 8 || install.Source.Section != "From source" {
+		t.Errorf("install source = %+v, want line 8 in From source", install.Source)
+	}
+
+	deps := explanations[1]
+	if !containsString(deps.Categories, "sudo") || !containsString(deps.Categories, "system package manager") {
+		t.Errorf("deps categories = %v, want sudo and system package manager", deps.Categories)
+	}
+	if deps.Source != nil {
+		t.Errorf("deps source = %+v, want none", deps.Source)
+	}
+
+	serve := explanations[2]
+	if serve.Kind != llm.StepKindRun || serve.KindReason != `ID contains "serve"` {
+		t.Errorf("serve kind = %s (%s)", serve.Kind, serve.KindReason)
+	}
+	if serve.Source == nil || serve.Source.Section != "Usage" {
+		t.Errorf("serve source = %+v, want the Usage section", serve.Source)
+	}
+
+	out := plan.FormatExplanations(explanations, "")
+	for _, want := range []string{
+		`source: README line 8, section "From source"`,
+		"risk:   critical (detected sudo, system package manager)",
+		"risk:   medium (declared by the plan; nothing risky detected, which alone is low)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatExplanations() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// warningCodes returns the codes of the warnings, in order
+func warningCodes(warnings []security.Warning) []string {
+	codes := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		codes = append(codes, warning.Code)
+	}
+	return codes
+}
+
+func TestValidatorWarningCodes(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "deps", Cmd: "sudo apt-get install -y libpq-dev", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "run", Cmd: "npm start", Cwd: ".", Risk: llm.RiskLow},
+		},
+	}
+
+	result := plan.NewValidator().Validate(runPlan)
+	codes := warningCodes(result.Warnings)
+	for _, want := range []string{"SEC002", "SEC003", "SEC004", "PLAN003"} {
+		if !containsString(codes, want) {
+			t.Errorf("warning codes %v missing %s", codes, want)
+		}
+	}
+	for _, warning := range result.Warnings {
+		if _, ok := plan.WarningCodes[warning.Code]; !ok {
+			t.Errorf("warning %q has an undocumented code", warning)
+		}
+		if warning.Code == security.CodeSudo && warning.Severity != security.SeverityHigh {
+			t.Errorf("sudo warning severity = %s, want high", warning.Severity)
+		}
+	}
+	if got := result.Warnings[0].String(); !strings.HasPrefix(got, "[SEC003] Step 1 (deps): ") {
+		t.Errorf("String() = %q, want the code and step prefix", got)
+	}
+}
+
+func TestDetachBackgroundSteps(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want bool
+	}{
+		{"npm start &", true},
+		{"npm start&", true},
+		{"python -m http.server > server.log 2>&1 &  ", true},
+		{"npm ci && npm start", false},
+		{"npm start", false},
+		{"echo \\&", false},
+		{"&", false},
+	}
+	for _, tt := range tests {
+		if got := plan.HasTrailingBackground(tt.cmd); got != tt.want {
+			t.Errorf("HasTrailingBackground(%q) = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "api", Cmd: "npm run api &", Cwd: ".", Risk: llm.RiskLow},
+			{ID: "test", Cmd: "npm test", Cwd: ".", Risk: llm.RiskLow},
+		},
+	}
+	result := plan.NewValidator().Validate(runPlan)
+	if codes := warningCodes(result.Warnings); !containsString(codes, plan.CodeBackground) {
+		t.Errorf("warning codes %v missing %s", codes, plan.CodeBackground)
+	}
+	if ids := plan.BackgroundSteps(runPlan); len(ids) != 1 || ids[0] != "api" {
+		t.Errorf("BackgroundSteps() = %v, want [api]", ids)
+	}
+
+	detached := plan.DetachBackgroundSteps(runPlan)
+	if detached.Steps[0].Cmd != "npm run api" || !detached.Steps[0].Detach {
+		t.Errorf("api step = %+v, want the & dropped and detach set", detached.Steps[0])
+	}
+	if detached.Steps[1].Detach {
+		t.Error("test step should not be detached")
+	}
+	if runPlan.Steps[0].Cmd != "npm run api &" {
+		t.Error("DetachBackgroundSteps() modified the original plan")
+	}
+	if codes := warningCodes(plan.NewValidator().Validate(detached).Warnings); containsString(codes, plan.CodeBackground) {
+		t.Errorf("detached plan still warns about &: %v", codes)
+	}
+}
+
+func TestValidatorSuppressesWarningCodes(t *testing.T) {
+	runPlan := &llm.RunPlan{
+		Version:     "1",
+		ProjectType: "node",
+		Steps: []llm.Step{
+			{ID: "deps", Cmd: "brew install postgresql", Cwd: ".", Risk: llm.RiskLow},
+		},
+	}
+
+	codes, err := plan.ParseWarningCodes([]string{"sec004", " PLAN003 "})
+	if err != nil {
+		t.Fatalf("ParseWarningCodes() error = %v", err)
+	}
+	validator := plan.NewValidator()
+	validator.SetSuppressed(codes)
+	result := validator.Validate(runPlan)
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want all suppressed", result.Warnings)
+	}
+	if result.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", result.Suppressed)
+	}
+
+	// A retired code is still accepted
+	if _, err := plan.ParseWarningCodes([]string{"PLAN001"}); err != nil {
+		t.Errorf("ParseWarningCodes(PLAN001) error = %v, want retired code accepted", err)
+	}
+	if _, err := plan.ParseWarningCodes([]string{"SEC999"}); err == nil || !strings.Contains(err.Error(), "SEC001") {
+		t.Errorf("ParseWarningCodes(SEC999) error = %v, want one listing the known codes", err)
+	}
+}
+
+// containsString reports whether slice contains item
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 // Validator validates and enhances RunPlan security
 type Validator struct {
 	policyChecker *security.PolicyChecker
+	suppressed    map[string]bool // warning codes left out of results
 }
 
 // NewValidator creates a new plan validator
@@ -30,12 +31,29 @@ func NewValidatorWithPolicy(policy *security.PolicyConfig) *Validator {
 	}
 }
 
+// SetSuppressed silences warnings with the given codes (see ParseWarningCodes)
+func (v *Validator) SetSuppressed(codes []string) {
+	v.suppressed = make(map[string]bool)
+	for _, code := range codes {
+		v.suppressed[strings.ToUpper(code)] = true
+	}
+}
+
+// IsSuppressed reports whether warnings with code are silenced
+func (v *Validator) IsSuppressed(code string) bool {
+	return v.suppressed[strings.ToUpper(code)]
+}
+
 // ValidationResult contains the results of plan validation
 type ValidationResult struct {
 	Valid      bool
 	Errors     []string
-	Warnings   []string
+	Warnings   []security.Warning
+	Suppressed int // Warnings left out by SetSuppressed
 	RiskReport RiskReport
+	// PortConflicts lists ports bound by more than one step. They are
+	// reported separately since they are shown even without --verbose.
+	PortConflicts []PortConflict
 }
 
 // RiskReport summarizes risk levels in the plan
@@ -52,7 +70,7 @@ func (v *Validator) Validate(plan *llm.RunPlan) *ValidationResult {
 	result := &ValidationResult{
 		Valid:    true,
 		Errors:   []string{},
-		Warnings: []string{},
+		Warnings: []security.Warning{},
 	}
 
 	// Schema validation
@@ -81,39 +99,87 @@ func (v *Validator) Validate(plan *llm.RunPlan) *ValidationResult {
 
 	// Additional validation rules
 	v.validateStepIDs(plan, result)
+	v.validateDependencies(plan, result)
 	v.validatePaths(plan, result)
 	v.validateEnvVars(plan, result)
+	v.validatePorts(plan, result)
+	v.validateLongRunning(plan, result)
+	v.validateBackground(plan, result)
 
+	result.Warnings, result.Suppressed = filterWarnings(result.Warnings, v.suppressed)
 	return result
 }
 
-// validateStepIDs ensures step IDs are unique
+// validateLongRunning flags servers outside the conventional "run" step:
+// they may not terminate unless they print a known readiness line
+func (v *Validator) validateLongRunning(plan *llm.RunPlan, result *ValidationResult) {
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		if _, id := llm.SplitStepID(step.ID); strings.EqualFold(id, "run") || step.Detach || !llm.IsLongRunningStep(step) {
+			continue
+		}
+		result.Warnings = append(result.Warnings, security.NewWarning(CodeLongRunning, security.SeverityWarning,
+			"Step %s looks like a long-running server (%s): it may not terminate unless it prints a readiness line, use --detach to keep it in the background", step.ID, step.Cmd))
+	}
+}
+
+// validateBackground flags commands ending with "&": the shell returns at
+// once, so the step passes before the server is up, and the server is
+// stopped with the step's process group
+func (v *Validator) validateBackground(plan *llm.RunPlan, result *ValidationResult) {
+	for _, id := range BackgroundSteps(plan) {
+		result.Warnings = append(result.Warnings, security.NewWarning(CodeBackground, security.SeverityWarning,
+			"Step %s ends with \"&\": it succeeds before the command is up and the command is stopped with the step; set \"detach\": true instead to wait for readiness and keep it running", id))
+	}
+}
+
+// validateStepIDs ensures step IDs are unique: resume state, depends_on
+// and recovery steps all refer to steps by ID
 func (v *Validator) validateStepIDs(plan *llm.RunPlan, result *ValidationResult) {
 	seen := make(map[string]bool)
-	for _, step := range plan.Steps {
+	for i, step := range plan.Steps {
 		if seen[step.ID] {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("Duplicate step ID: %s", step.ID))
+			result.Valid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Step %d: duplicate step ID %q", i+1, step.ID))
 		}
 		seen[step.ID] = true
 	}
 }
 
-// validatePaths checks for path traversal attempts
+// validateDependencies ensures depends_on only names steps defined earlier
+// in the plan, which also rules out cycles
+func (v *Validator) validateDependencies(plan *llm.RunPlan, result *ValidationResult) {
+	defined := make(map[string]bool)
+	for _, step := range plan.Steps {
+		for _, dep := range step.DependsOn {
+			if !defined[dep] {
+				result.Valid = false
+				result.Errors = append(result.Errors,
+					fmt.Sprintf("Step %s: depends_on %q must name an earlier step", step.ID, dep))
+			}
+		}
+		defined[step.ID] = true
+	}
+}
+
+// validatePaths rejects a cwd outside the project directory and flags
+// commands referencing absolute paths
 func (v *Validator) validatePaths(plan *llm.RunPlan, result *ValidationResult) {
 	for _, step := range plan.Steps {
-		// Check cwd for path traversal
-		if strings.Contains(step.Cwd, "..") {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("Step %s: cwd contains path traversal (..)", step.ID))
+		// A cwd must stay inside the project directory
+		if llm.CwdEscapes(step.Cwd) {
+			result.Valid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Step %s: cwd %q is outside the project directory", step.ID, step.Cwd))
 		}
 
 		// Check command for absolute paths outside workspace
 		if strings.Contains(step.Cmd, "/home/") ||
 			strings.Contains(step.Cmd, "/root/") ||
 			strings.Contains(step.Cmd, "/tmp/") {
-			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("Step %s: command references absolute paths", step.ID))
+			result.Warnings = append(result.Warnings, security.NewWarning(CodeAbsolutePath, security.SeverityInfo,
+				"Step %s: command references absolute paths", step.ID))
 		}
 	}
 }
@@ -125,14 +191,24 @@ func (v *Validator) validateEnvVars(plan *llm.RunPlan, result *ValidationResult)
 		"apikey", "private", "credential", "auth",
 	}
 
+	for _, step := range plan.Steps {
+		for key := range step.ExportEnv {
+			if !llm.ValidEnvKey(key) {
+				result.Valid = false
+				result.Errors = append(result.Errors,
+					fmt.Sprintf("Step %s: export_env key %q is not a valid variable name", step.ID, key))
+			}
+		}
+	}
+
 	for key, value := range plan.Env {
 		lowerKey := strings.ToLower(key)
 		for _, pattern := range sensitivePatterns {
 			if strings.Contains(lowerKey, pattern) {
 				// Check if value looks like a placeholder vs actual secret
 				if !isPlaceholder(value) {
-					result.Warnings = append(result.Warnings,
-						fmt.Sprintf("Environment variable '%s' may contain sensitive data", key))
+					result.Warnings = append(result.Warnings, security.NewWarning(security.CodeSensitiveEnv, security.SeverityWarning,
+						"Environment variable '%s' may contain sensitive data", key))
 				}
 				break
 			}
@@ -140,6 +216,11 @@ func (v *Validator) validateEnvVars(plan *llm.RunPlan, result *ValidationResult)
 	}
 }
 
+// validatePorts warns when two steps would listen on the same port
+func (v *Validator) validatePorts(plan *llm.RunPlan, result *ValidationResult) {
+	result.PortConflicts = FindPortConflicts(plan)
+}
+
 // isPlaceholder checks if a value looks like a placeholder
 func isPlaceholder(value string) bool {
 	placeholderPatterns := []string{
@@ -173,7 +254,7 @@ func (v *Validator) EnhancePlan(plan *llm.RunPlan) *llm.RunPlan {
 		analysis := v.policyChecker.AnalyzeCommand(step.Cmd)
 
 		// Update risk if analysis shows higher risk
-		if riskLevel(analysis.Risk) > riskLevel(step.Risk) {
+		if analysis.Risk.Rank() > step.Risk.Rank() {
 			enhanced.Steps[i].Risk = analysis.Risk
 		}
 
@@ -186,22 +267,6 @@ func (v *Validator) EnhancePlan(plan *llm.RunPlan) *llm.RunPlan {
 	return &enhanced
 }
 
-// riskLevel converts a RiskLevel to an integer for comparison
-func riskLevel(r llm.RiskLevel) int {
-	switch r {
-	case llm.RiskLow:
-		return 1
-	case llm.RiskMedium:
-		return 2
-	case llm.RiskHigh:
-		return 3
-	case llm.RiskCritical:
-		return 4
-	default:
-		return 0
-	}
-}
-
 // FormatValidationResult returns a human-readable validation result
 func FormatValidationResult(result *ValidationResult) string {
 	var sb strings.Builder
@@ -225,6 +290,9 @@ func FormatValidationResult(result *ValidationResult) string {
 			sb.WriteString(fmt.Sprintf("  • %s\n", warn))
 		}
 	}
+	if result.Suppressed > 0 {
+		sb.WriteString(fmt.Sprintf("  (%d suppressed)\n", result.Suppressed))
+	}
 
 	// Risk summary
 	sb.WriteString("\nRisk Summary:\n")
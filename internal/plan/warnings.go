@@ -0,0 +1,76 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Warning codes of the plan validator and --suppress handling
+
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/security"
+)
+
+// Plan warning codes (PLAN003, risk divergence, comes from the policy checker)
+const (
+	CodeDuplicateStepID = "PLAN001" // Retired: duplicate step IDs are a validation error
+	CodeLongRunning     = "PLAN002" // Server outside the run step
+	CodeAbsolutePath    = "PLAN004" // Command references absolute paths
+	CodeBackground      = "PLAN005" // Command ends with a background "&"
+)
+
+// WarningCodes describes every code a validation warning can carry
+var WarningCodes = map[string]string{
+	security.CodeRemoteScript:    "remote script piped into a shell",
+	security.CodeSudoMismatch:    "command uses sudo but requires_sudo is false",
+	security.CodeSudo:            "command requires sudo",
+	security.CodePackageManager:  "system package manager command",
+	security.CodeClusterMutation: "modifies cluster resources",
+	security.CodeDatabase:        "modifies the application database",
+	security.CodeSystemDirs:      "accesses system directories",
+	security.CodeSensitiveEnv:    "environment variable may contain sensitive data",
+	CodeLongRunning:              "long-running server outside the run step",
+	security.CodeRiskDivergence:  "detected risk differs from the declared risk",
+	CodeAbsolutePath:             "command references absolute paths",
+	CodeBackground:               "command ends with a background &",
+}
+
+// RetiredWarningCodes are codes no warning carries anymore. --suppress still
+// accepts them, matching nothing, so existing scripts keep working.
+var RetiredWarningCodes = map[string]string{
+	CodeDuplicateStepID: "duplicate step ID (now a validation error)",
+}
+
+// ParseWarningCodes upper-cases --suppress codes and rejects unknown ones
+func ParseWarningCodes(codes []string) ([]string, error) {
+	var parsed []string
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		_, known := WarningCodes[code]
+		if _, retired := RetiredWarningCodes[code]; !known && !retired {
+			known := make([]string, 0, len(WarningCodes))
+			for c := range WarningCodes {
+				known = append(known, c)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown warning code %q (known: %s)", code, strings.Join(known, ", "))
+		}
+		parsed = append(parsed, code)
+	}
+	return parsed, nil
+}
+
+// filterWarnings drops the warnings whose code is suppressed and returns
+// the others with the number dropped
+func filterWarnings(warnings []security.Warning, suppressed map[string]bool) ([]security.Warning, int) {
+	kept := make([]security.Warning, 0, len(warnings))
+	for _, warning := range warnings {
+		if !suppressed[warning.Code] {
+			kept = append(kept, warning)
+		}
+	}
+	return kept, len(warnings) - len(kept)
+}
@@ -4,21 +4,77 @@
 package prereq
 
 import (
+	"context"
+	"errors"
 	"os/exec"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sony-level/readme-runner/internal/llm"
 )
 
+// HealthCheckTimeout bounds a tool health check such as "docker info"
+const HealthCheckTimeout = 5 * time.Second
+
+// VersionTimeout bounds a version command such as "node --version", so a
+// tool that waits for input cannot stall the check
+const VersionTimeout = 5 * time.Second
+
+// versionPattern matches a dotted version number such as 1.21.5 or v20.11.0
+var versionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
 // Checker verifies tool existence
 type Checker struct {
 	tools map[string]*Tool
 }
 
-// NewChecker creates a new prerequisite checker
+// NewChecker creates a prerequisite checker for the built-in tools and the
+// tools: entries of the config file. Like the other config file settings,
+// a file that fails to load is ignored.
 func NewChecker() *Checker {
+	tools := DefaultTools()
+	if fileCfg, _ := llm.LoadConfig(); fileCfg != nil {
+		MergeTools(tools, fileCfg.Tools)
+	}
 	return &Checker{
-		tools: DefaultTools(),
+		tools: tools,
+	}
+}
+
+// MergeTools adds config file tool definitions to tools. An entry for a
+// known tool only replaces the fields it sets; entries without a name are
+// skipped.
+func MergeTools(tools map[string]*Tool, custom []llm.ToolConfig) {
+	for _, cfg := range custom {
+		name := strings.TrimSpace(cfg.Name)
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+
+		tool, ok := tools[key]
+		if !ok {
+			tool = &Tool{
+				Name:         name,
+				Command:      name,
+				Category:     "custom",
+				InstallGuide: "Install " + name + " and make sure it is on your PATH",
+			}
+			tools[key] = tool
+		}
+		if cfg.Command != "" {
+			tool.Command = cfg.Command
+		}
+		if cfg.VersionCmd != "" {
+			tool.VersionCmd = cfg.VersionCmd
+		}
+		if len(cfg.Alternatives) > 0 {
+			tool.Alternatives = cfg.Alternatives
+		}
+		if cfg.InstallGuide != "" {
+			tool.InstallGuide = cfg.InstallGuide
+		}
 	}
 }
 
@@ -29,12 +85,17 @@ func NewCheckerWithTools(tools map[string]*Tool) *Checker {
 	}
 }
 
-// CheckPrerequisites verifies all required tools from a plan
+// CheckPrerequisites verifies all required tools from a plan and their
+// minimum versions. A version that cannot be compared is not outdated.
 func (c *Checker) CheckPrerequisites(prereqs []llm.Prerequisite) *CheckSummary {
 	summary := NewCheckSummary()
 
 	for _, prereq := range prereqs {
 		result := c.CheckTool(prereq.Name)
+		result.MinVersion = prereq.MinVersion
+		if meets, known := result.MeetsMinVersion(); known && !meets {
+			result.Outdated = true
+		}
 		summary.AddResult(result)
 	}
 
@@ -61,6 +122,7 @@ func (c *Checker) CheckTool(name string) CheckResult {
 		result.Found = true
 		result.Path = c.whichCommand(tool.Command)
 		result.Version = c.getVersion(tool.VersionCmd)
+		c.checkHealth(tool, &result)
 		return result
 	}
 
@@ -74,11 +136,13 @@ func (c *Checker) CheckTool(name string) CheckResult {
 				if c.subcommandWorks(parts[0], parts[1:]) {
 					result.Found = true
 					result.Path = c.whichCommand(parts[0])
+					result.Version = c.getVersion(alternativeVersionCmd(tool, alt))
 					return result
 				}
 			} else {
 				result.Found = true
 				result.Path = c.whichCommand(alt)
+				result.Version = c.getVersion(alternativeVersionCmd(tool, alt))
 				return result
 			}
 		}
@@ -106,7 +170,9 @@ func (c *Checker) GetAllTools() map[string]*Tool {
 	return c.tools
 }
 
-// commandExists checks if a command exists in PATH
+// commandExists checks if a command exists in PATH. exec.LookPath also
+// resolves Windows executables (node.exe, npm.cmd) through PATHEXT, so no
+// shell builtin such as "command -v" is needed.
 func (c *Checker) commandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
@@ -121,47 +187,93 @@ func (c *Checker) whichCommand(cmd string) string {
 	return path
 }
 
-// getVersion executes a version command and returns the output
+// getVersion runs a version command and parses its output. Both stdout and
+// stderr are read because some tools (java -version, older pythons) print
+// their version to stderr.
 func (c *Checker) getVersion(versionCmd string) string {
-	if versionCmd == "" {
+	parts := strings.Fields(versionCmd)
+	if len(parts) == 0 {
 		return ""
 	}
 
-	parts := strings.Fields(versionCmd)
-	if len(parts) == 0 {
+	ctx, cancel := context.WithTimeout(context.Background(), VersionTimeout)
+	defer cancel()
+
+	// A non-zero exit still counts when the tool printed something
+	out, _ := exec.CommandContext(ctx, parts[0], parts[1:]...).CombinedOutput()
+	if ctx.Err() != nil {
 		return ""
 	}
+	return ParseVersion(string(out))
+}
 
-	cmd := exec.Command(parts[0], parts[1:]...)
-	out, err := cmd.Output()
-	if err != nil {
+// ParseVersion extracts the version number from a version command's output
+// ("go version go1.21.5 linux/amd64" gives "1.21.5"). Output without a
+// dotted number gives its first non-empty line, and empty output gives "".
+func ParseVersion(output string) string {
+	var first string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if match := versionPattern.FindString(line); match != "" {
+			return match
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return first
+}
+
+// alternativeVersionCmd is the tool's version command run through an
+// alternative ("docker-compose --version" becomes "docker compose --version")
+func alternativeVersionCmd(tool *Tool, alt string) string {
+	parts := strings.Fields(tool.VersionCmd)
+	if len(parts) == 0 || parts[0] != tool.Command {
 		return ""
 	}
+	return alt + " " + strings.Join(parts[1:], " ")
+}
 
-	// Return first line of output, trimmed
-	output := strings.TrimSpace(string(out))
-	if idx := strings.Index(output, "\n"); idx > 0 {
-		output = output[:idx]
+// checkHealth runs the tool's health command, marking the result
+// unreachable if it fails or times out
+func (c *Checker) checkHealth(tool *Tool, result *CheckResult) {
+	parts := strings.Fields(tool.HealthCmd)
+	if len(parts) == 0 {
+		return
 	}
 
-	return output
+	ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout)
+	defer cancel()
+
+	if err := exec.CommandContext(ctx, parts[0], parts[1:]...).Run(); err != nil {
+		result.Unreachable = true
+		hint := tool.HealthHint
+		if hint == "" {
+			hint = tool.Name + " installed but '" + tool.HealthCmd + "' failed"
+		}
+		if ctx.Err() != nil {
+			hint += " (timed out after " + HealthCheckTimeout.String() + ")"
+		}
+		result.Error = errors.New(hint)
+	}
 }
 
 // subcommandWorks checks if a subcommand works (e.g., "docker compose")
 func (c *Checker) subcommandWorks(mainCmd string, args []string) bool {
 	// Add --version or --help to check if subcommand exists
-	testArgs := append(args, "--version")
-	cmd := exec.Command(mainCmd, testArgs...)
-	err := cmd.Run()
-	if err == nil {
-		return true
+	for _, flag := range []string{"--version", "--help"} {
+		ctx, cancel := context.WithTimeout(context.Background(), VersionTimeout)
+		testArgs := append(append([]string{}, args...), flag)
+		err := exec.CommandContext(ctx, mainCmd, testArgs...).Run()
+		cancel()
+		if err == nil {
+			return true
+		}
 	}
-
-	// Try with --help
-	testArgs = append(args, "--help")
-	cmd = exec.Command(mainCmd, testArgs...)
-	err = cmd.Run()
-	return err == nil
+	return false
 }
 
 // CheckMultiple checks multiple tools and returns a summary
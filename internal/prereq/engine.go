@@ -0,0 +1,38 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Container engine selection (Docker or Podman)
+
+package prereq
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Supported container engines
+const (
+	EngineDocker = "docker"
+	EnginePodman = "podman"
+)
+
+// ResolveContainerEngine returns the engine for a --container-engine value.
+// "" and "auto" select docker when installed, otherwise podman when only
+// podman is installed. The reason describes an automatic choice.
+func ResolveContainerEngine(value string) (engine, reason string, err error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case EngineDocker:
+		return EngineDocker, "", nil
+	case EnginePodman:
+		return EnginePodman, "", nil
+	case "", "auto":
+		if _, err := exec.LookPath("docker"); err == nil {
+			return EngineDocker, "", nil
+		}
+		if _, err := exec.LookPath("podman"); err == nil {
+			return EnginePodman, "docker not found, podman detected", nil
+		}
+		return EngineDocker, "", nil
+	default:
+		return "", "", fmt.Errorf("unknown container engine %q (supported: auto, docker, podman)", value)
+	}
+}
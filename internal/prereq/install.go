@@ -0,0 +1,98 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Installing missing tools with the host package manager
+
+package prereq
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Package managers rdr prepare can install tools with
+const (
+	PackageManagerBrew   = "brew"
+	PackageManagerApt    = "apt"
+	PackageManagerDnf    = "dnf"
+	PackageManagerWinget = "winget"
+)
+
+// packageManagerCommands is the command each package manager is found by
+var packageManagerCommands = map[string]string{
+	PackageManagerBrew:   "brew",
+	PackageManagerApt:    "apt-get",
+	PackageManagerDnf:    "dnf",
+	PackageManagerWinget: "winget",
+}
+
+// DetectPackageManager returns the package manager to install tools with
+// on this host: brew on macOS, winget on Windows, apt or dnf on Linux
+// (then brew), or "" if none is installed
+func DetectPackageManager() string {
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{PackageManagerBrew}
+	case "windows":
+		candidates = []string{PackageManagerWinget}
+	default:
+		candidates = []string{PackageManagerApt, PackageManagerDnf, PackageManagerBrew}
+	}
+	for _, manager := range candidates {
+		if _, err := exec.LookPath(packageManagerCommands[manager]); err == nil {
+			return manager
+		}
+	}
+	return ""
+}
+
+// InstallCommand returns the command that installs a tool with a package
+// manager and whether it needs sudo. ok is false for unknown tools and
+// tools without a package for that manager, which are installed by hand
+// (see GetInstallGuide).
+func (c *Checker) InstallCommand(name, manager string) (cmd string, sudo bool, ok bool) {
+	tool := c.GetTool(name)
+	if tool == nil {
+		return "", false, false
+	}
+	pkg := tool.Packages[manager]
+	if pkg == "" {
+		return "", false, false
+	}
+
+	switch manager {
+	case PackageManagerBrew:
+		return "brew install " + pkg, false, true
+	case PackageManagerWinget:
+		return "winget install -e --id " + pkg, false, true
+	case PackageManagerApt:
+		cmd = "apt-get install -y " + pkg
+	case PackageManagerDnf:
+		cmd = "dnf install -y " + pkg
+	default:
+		return "", false, false
+	}
+	cmd, sudo = asRoot(cmd)
+	return cmd, sudo, true
+}
+
+// RefreshCommand returns the command that refreshes a package manager's
+// package lists before installing, and whether it needs sudo. ok is false
+// for package managers that refresh on their own (brew, dnf, winget).
+// apt-get on a fresh machine has no lists and finds no package without it.
+func RefreshCommand(manager string) (cmd string, sudo bool, ok bool) {
+	if manager != PackageManagerApt {
+		return "", false, false
+	}
+	cmd, sudo = asRoot("apt-get update")
+	return cmd, sudo, true
+}
+
+// asRoot prefixes a system package manager command with sudo, unless rdr
+// already runs as root
+func asRoot(cmd string) (string, bool) {
+	if os.Geteuid() == 0 {
+		return cmd, false
+	}
+	return "sudo " + cmd, true
+}
@@ -0,0 +1,319 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Prerequisite checker tests
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+	"github.com/sony-level/readme-runner/internal/prereq"
+)
+
+// writeTool puts an executable shell script named name in dir
+func writeTool(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakePath returns a directory that is the only entry on PATH
+func fakePath(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func TestCheckToolFindsToolOnPath(t *testing.T) {
+	dir := fakePath(t)
+	writeTool(t, dir, "faketool", `echo "faketool version 1.4.2 (build abc)"`)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"faketool": {Name: "faketool", Command: "faketool", VersionCmd: "faketool --version"},
+	})
+	result := checker.CheckTool("faketool")
+
+	if !result.Found {
+		t.Fatal("CheckTool() Found = false, want true")
+	}
+	if result.Path != filepath.Join(dir, "faketool") {
+		t.Errorf("Path = %q, want %q", result.Path, filepath.Join(dir, "faketool"))
+	}
+	if result.Version != "1.4.2" {
+		t.Errorf("Version = %q, want 1.4.2", result.Version)
+	}
+}
+
+func TestCheckToolReadsVersionFromStderr(t *testing.T) {
+	dir := fakePath(t)
+	// Like "java -version": version on stderr, nothing on stdout
+	writeTool(t, dir, "fakejava", `echo 'openjdk version "21.0.2" 2024-01-16' >&2`)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"fakejava": {Name: "fakejava", Command: "fakejava", VersionCmd: "fakejava -version"},
+	})
+	result := checker.CheckTool("fakejava")
+
+	if !result.Found {
+		t.Fatal("CheckTool() Found = false, want true")
+	}
+	if result.Version != "21.0.2" {
+		t.Errorf("Version = %q, want 21.0.2", result.Version)
+	}
+}
+
+func TestCheckToolFindsAlternative(t *testing.T) {
+	dir := fakePath(t)
+	writeTool(t, dir, "go-faketask", `echo "Task version: v3.35.1"`)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"faketask": {
+			Name:         "faketask",
+			Command:      "faketask",
+			VersionCmd:   "faketask --version",
+			Alternatives: []string{"go-faketask"},
+		},
+	})
+	result := checker.CheckTool("faketask")
+
+	if !result.Found {
+		t.Fatal("CheckTool() Found = false, want true")
+	}
+	if result.Path != filepath.Join(dir, "go-faketask") {
+		t.Errorf("Path = %q, want the alternative", result.Path)
+	}
+	if result.Version != "3.35.1" {
+		t.Errorf("Version = %q, want 3.35.1", result.Version)
+	}
+}
+
+func TestCheckToolMissing(t *testing.T) {
+	fakePath(t)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"faketool": {Name: "faketool", Command: "faketool", VersionCmd: "faketool --version"},
+	})
+	result := checker.CheckTool("faketool")
+
+	if result.Found {
+		t.Errorf("CheckTool() Found = true for a tool not on PATH")
+	}
+	if result.Version != "" {
+		t.Errorf("Version = %q, want empty", result.Version)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"go version go1.21.5 linux/amd64\n", "1.21.5"},
+		{"v20.11.0\n", "20.11.0"},
+		{"git version 2.43.0\n", "2.43.0"},
+		{"\nPython 3.12.1\n", "3.12.1"},
+		{"rustc 1.75.0 (82e1608df 2023-12-21)", "1.75.0"},
+		{"development build\n", "development build"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := prereq.ParseVersion(tt.output); got != tt.want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPrerequisitesReportsPath(t *testing.T) {
+	dir := fakePath(t)
+	writeTool(t, dir, "faketool", `echo "faketool 0.9.0"`)
+	writeTool(t, dir, "unlisted", `exit 0`)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"faketool": {Name: "faketool", Command: "faketool", VersionCmd: "faketool --version"},
+	})
+	summary := checker.CheckPrerequisites([]llm.Prerequisite{
+		{Name: "faketool", Reason: "build"},
+		{Name: "unlisted", Reason: "run"},
+	})
+
+	if !summary.AllFound {
+		t.Fatalf("AllFound = false, missing %v", summary.MissingTools)
+	}
+	for _, result := range summary.Results {
+		want := filepath.Join(dir, result.Name)
+		if result.Path != want {
+			t.Errorf("%s: Path = %q, want %q", result.Name, result.Path, want)
+		}
+	}
+}
+
+func TestNewCheckerMergesConfigTools(t *testing.T) {
+	dir := fakePath(t)
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	writeTool(t, dir, "ourcli", `echo "ourcli 2.3.0"`)
+
+	config := `tools:
+  - name: ourcli
+    version_cmd: ourcli version
+    install_guide: "See https://wiki.example.com/ourcli"
+  - name: node
+    install_guide: "Use the company node mirror"
+`
+	if err := os.MkdirAll(filepath.Join(dir, "readme-runner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "readme-runner", "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := prereq.NewChecker()
+
+	result := checker.CheckTool("ourcli")
+	if !result.Found || result.Version != "2.3.0" {
+		t.Errorf("CheckTool(ourcli) = found %v, version %q; want found, 2.3.0", result.Found, result.Version)
+	}
+	if guide := checker.GetInstallGuide("ourcli"); guide != "See https://wiki.example.com/ourcli" {
+		t.Errorf("ourcli install guide = %q", guide)
+	}
+
+	// Overriding a built-in tool keeps the fields the entry leaves out
+	node := checker.GetTool("node")
+	if node == nil || node.InstallGuide != "Use the company node mirror" {
+		t.Fatalf("node install guide not overridden: %+v", node)
+	}
+	if node.Command != "node" || node.VersionCmd != "node --version" {
+		t.Errorf("node command = %q, version_cmd = %q; want the built-in ones", node.Command, node.VersionCmd)
+	}
+}
+
+func TestMergeToolsSkipsUnnamedEntries(t *testing.T) {
+	tools := map[string]*prereq.Tool{}
+	prereq.MergeTools(tools, []llm.ToolConfig{{Command: "nameless"}, {Name: "OurCLI"}})
+
+	if len(tools) != 1 {
+		t.Fatalf("got %d tools, want 1", len(tools))
+	}
+	tool := tools["ourcli"]
+	if tool == nil || tool.Command != "OurCLI" || tool.InstallGuide == "" {
+		t.Errorf("ourcli = %+v, want command defaulting to the name and an install guide", tool)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"1.21.5", "1.21", 1, true},
+		{"1.19", "1.21", -1, true},
+		{"1.21", "1.21.0", 0, true},
+		{"v20.11.0", "18", 1, true},
+		{"3.10", "3.8", 1, true},
+		{"development build", "1.0", 0, false},
+		{"1.2", "", 0, false},
+		{"20.11.0", ">=18", 1, true},
+		{"1.21", ">1.21", 0, true},
+		{"3.10", "=3.10", 0, true},
+		{"18.2.0", "^18", 1, true},
+		{"1.19", "~1.21", -1, true},
+		{"20.11.0", ">= v20.11", 0, true},
+		{"17.9", "18+", -1, true},
+		{"1.2", ">=", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := prereq.CompareVersions(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("CompareVersions(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckPrerequisitesMinVersion(t *testing.T) {
+	dir := fakePath(t)
+	writeTool(t, dir, "fakego", `echo "go version go1.19.3 linux/amd64"`)
+	writeTool(t, dir, "fakenode", `echo "v20.11.0"`)
+	writeTool(t, dir, "fakedev", `echo "development build"`)
+
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"fakego":   {Name: "fakego", Command: "fakego", VersionCmd: "fakego version"},
+		"fakenode": {Name: "fakenode", Command: "fakenode", VersionCmd: "fakenode --version"},
+		"fakedev":  {Name: "fakedev", Command: "fakedev", VersionCmd: "fakedev --version"},
+	})
+	summary := checker.CheckPrerequisites([]llm.Prerequisite{
+		{Name: "fakego", Reason: "build", MinVersion: "1.21"},
+		{Name: "fakenode", Reason: "run", MinVersion: "18"},
+		{Name: "fakedev", Reason: "run", MinVersion: "2"},
+	})
+
+	if summary.Ready() {
+		t.Fatal("Ready() = true with fakego 1.19.3 below 1.21")
+	}
+	if len(summary.OutdatedTools) != 1 || summary.OutdatedTools[0] != "fakego" {
+		t.Errorf("OutdatedTools = %v, want [fakego]", summary.OutdatedTools)
+	}
+	if problems := summary.Problems(); len(problems) != 1 || problems[0] != "fakego 1.19.3 < 1.21" {
+		t.Errorf("Problems() = %v, want [fakego 1.19.3 < 1.21]", problems)
+	}
+
+	for _, result := range summary.Results {
+		meets, known := result.MeetsMinVersion()
+		switch result.Name {
+		case "fakego":
+			if !known || meets {
+				t.Errorf("fakego: MeetsMinVersion() = %v, %v, want false, true", meets, known)
+			}
+		case "fakenode":
+			if !known || !meets || result.Outdated {
+				t.Errorf("fakenode: MeetsMinVersion() = %v, %v, Outdated = %v, want true, true, false", meets, known, result.Outdated)
+			}
+		case "fakedev":
+			// An unparseable version is unknown, not outdated
+			if known || result.Outdated {
+				t.Errorf("fakedev: known = %v, Outdated = %v, want false, false", known, result.Outdated)
+			}
+		}
+	}
+}
+
+func TestInstallCommand(t *testing.T) {
+	checker := prereq.NewCheckerWithTools(map[string]*prereq.Tool{
+		"faketool": {Name: "faketool", Command: "faketool", Packages: map[string]string{
+			prereq.PackageManagerBrew: "fake",
+			prereq.PackageManagerApt:  "fake-tool",
+		}},
+	})
+
+	if cmd, sudo, ok := checker.InstallCommand("faketool", prereq.PackageManagerBrew); !ok || sudo || cmd != "brew install fake" {
+		t.Errorf("brew: InstallCommand() = %q, %v, %v, want \"brew install fake\", false, true", cmd, sudo, ok)
+	}
+
+	// apt needs root: through sudo unless rdr already runs as root
+	cmd, sudo, ok := checker.InstallCommand("faketool", prereq.PackageManagerApt)
+	wantSudo := os.Geteuid() != 0
+	want := "apt-get install -y fake-tool"
+	if wantSudo {
+		want = "sudo " + want
+	}
+	if !ok || sudo != wantSudo || cmd != want {
+		t.Errorf("apt: InstallCommand() = %q, %v, %v, want %q, %v, true", cmd, sudo, ok, want, wantSudo)
+	}
+
+	if _, _, ok := checker.InstallCommand("faketool", prereq.PackageManagerDnf); ok {
+		t.Error("dnf: InstallCommand() ok = true without a dnf package")
+	}
+	if _, _, ok := checker.InstallCommand("unknown", prereq.PackageManagerBrew); ok {
+		t.Error("unknown tool: InstallCommand() ok = true")
+	}
+}
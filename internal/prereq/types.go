@@ -11,6 +11,10 @@ type Tool struct {
 	Alternatives []string // Alternative command names
 	InstallGuide string   // Installation instructions
 	Category     string   // Category (runtime, build, container, etc.)
+	HealthCmd    string   // Command that must succeed for the tool to be usable (optional)
+	HealthHint   string   // Reported when HealthCmd fails
+
+	Packages map[string]string // Package per package manager, for rdr prepare --auto-install
 }
 
 // DefaultTools returns the list of supported tools
@@ -21,6 +25,7 @@ func DefaultTools() map[string]*Tool {
 			Command:    "git",
 			VersionCmd: "git --version",
 			Category:   "vcs",
+			Packages:   map[string]string{PackageManagerBrew: "git", PackageManagerApt: "git", PackageManagerDnf: "git", PackageManagerWinget: "Git.Git"},
 			InstallGuide: `Install git:
   macOS:   brew install git
   Ubuntu:  sudo apt install git
@@ -32,11 +37,28 @@ func DefaultTools() map[string]*Tool {
 			Command:    "docker",
 			VersionCmd: "docker --version",
 			Category:   "container",
+			HealthCmd:  "docker info",
+			HealthHint: "docker installed but daemon not reachable (is Docker running?)",
 			InstallGuide: `Install Docker:
   macOS:   brew install --cask docker
   Ubuntu:  https://docs.docker.com/engine/install/ubuntu/
   Fedora:  https://docs.docker.com/engine/install/fedora/
   Windows: https://docs.docker.com/desktop/install/windows-install/`,
+		},
+		"podman": {
+			Name:       "podman",
+			Command:    "podman",
+			VersionCmd: "podman --version",
+			Category:   "container",
+			Packages:   map[string]string{PackageManagerBrew: "podman", PackageManagerApt: "podman", PackageManagerDnf: "podman", PackageManagerWinget: "RedHat.Podman"},
+			HealthCmd:  "podman info",
+			HealthHint: "podman installed but not usable (on macOS/Windows, is the podman machine started?)",
+			InstallGuide: `Install Podman:
+  macOS:   brew install podman && podman machine init && podman machine start
+  Ubuntu:  sudo apt install podman
+  Fedora:  sudo dnf install podman
+  Windows: winget install RedHat.Podman
+  All:     https://podman.io/docs/installation`,
 		},
 		"docker-compose": {
 			Name:         "docker-compose",
@@ -55,6 +77,7 @@ For standalone installation:
 			VersionCmd:   "node --version",
 			Alternatives: []string{"nodejs"},
 			Category:     "runtime",
+			Packages:     map[string]string{PackageManagerBrew: "node", PackageManagerApt: "nodejs", PackageManagerDnf: "nodejs", PackageManagerWinget: "OpenJS.NodeJS.LTS"},
 			InstallGuide: `Install Node.js:
   macOS:   brew install node
   Ubuntu:  sudo apt install nodejs npm
@@ -69,6 +92,7 @@ For standalone installation:
 			Command:    "npm",
 			VersionCmd: "npm --version",
 			Category:   "package",
+			Packages:   map[string]string{PackageManagerBrew: "node", PackageManagerApt: "npm", PackageManagerDnf: "npm", PackageManagerWinget: "OpenJS.NodeJS.LTS"},
 			InstallGuide: `npm is included with Node.js.
 Install Node.js to get npm.`,
 		},
@@ -100,6 +124,16 @@ Install Node.js to get npm.`,
 			InstallGuide: `Install Bun:
   macOS/Linux: curl -fsSL https://bun.sh/install | bash
   Windows:     powershell -c "irm bun.sh/install.ps1 | iex"`,
+		},
+		"deno": {
+			Name:       "deno",
+			Command:    "deno",
+			VersionCmd: "deno --version",
+			Category:   "runtime",
+			InstallGuide: `Install Deno:
+  macOS/Linux: curl -fsSL https://deno.land/install.sh | sh
+  macOS:       brew install deno
+  Windows:     powershell -c "irm https://deno.land/install.ps1 | iex"`,
 		},
 		"python": {
 			Name:         "python",
@@ -107,6 +141,7 @@ Install Node.js to get npm.`,
 			VersionCmd:   "python3 --version",
 			Alternatives: []string{"python"},
 			Category:     "runtime",
+			Packages:     map[string]string{PackageManagerBrew: "python", PackageManagerApt: "python3", PackageManagerDnf: "python3", PackageManagerWinget: "Python.Python.3.12"},
 			InstallGuide: `Install Python:
   macOS:   brew install python
   Ubuntu:  sudo apt install python3 python3-pip python3-venv
@@ -121,6 +156,7 @@ Install Node.js to get npm.`,
 			VersionCmd:   "pip3 --version",
 			Alternatives: []string{"pip"},
 			Category:     "package",
+			Packages:     map[string]string{PackageManagerApt: "python3-pip", PackageManagerDnf: "python3-pip"},
 			InstallGuide: `pip is included with Python 3.4+.
 If missing:
   python3 -m ensurepip --upgrade`,
@@ -148,6 +184,7 @@ If missing:
 			Command:    "go",
 			VersionCmd: "go version",
 			Category:   "runtime",
+			Packages:   map[string]string{PackageManagerBrew: "go", PackageManagerApt: "golang-go", PackageManagerDnf: "golang", PackageManagerWinget: "GoLang.Go"},
 			InstallGuide: `Install Go:
   macOS:   brew install go
   Ubuntu:  sudo apt install golang-go
@@ -159,6 +196,7 @@ If missing:
 			Command:    "cargo",
 			VersionCmd: "cargo --version",
 			Category:   "build",
+			Packages:   map[string]string{PackageManagerBrew: "rust", PackageManagerApt: "cargo", PackageManagerDnf: "cargo"},
 			InstallGuide: `Install Rust/Cargo:
   All:     curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh`,
 		},
@@ -175,18 +213,192 @@ If missing:
 			Command:    "rustup",
 			VersionCmd: "rustup --version",
 			Category:   "build",
+			Packages:   map[string]string{PackageManagerBrew: "rustup", PackageManagerWinget: "Rustlang.Rustup"},
 			InstallGuide: `Install rustup:
   All:     curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh`,
+		},
+		"dotnet": {
+			Name:       "dotnet",
+			Command:    "dotnet",
+			VersionCmd: "dotnet --version",
+			Category:   "runtime",
+			InstallGuide: `Install the .NET SDK:
+  macOS:   brew install --cask dotnet-sdk
+  Ubuntu:  sudo apt install dotnet-sdk-8.0
+  Windows: winget install Microsoft.DotNet.SDK.8
+  All:     https://dotnet.microsoft.com/download`,
+		},
+		"ruby": {
+			Name:       "ruby",
+			Command:    "ruby",
+			VersionCmd: "ruby --version",
+			Category:   "runtime",
+			Packages:   map[string]string{PackageManagerBrew: "ruby", PackageManagerApt: "ruby-full", PackageManagerDnf: "ruby", PackageManagerWinget: "RubyInstallerTeam.Ruby.3.2"},
+			InstallGuide: `Install Ruby:
+  macOS:   brew install ruby (or rbenv install)
+  Ubuntu:  sudo apt install ruby-full
+  Fedora:  sudo dnf install ruby ruby-devel
+  Windows: https://rubyinstaller.org/`,
+		},
+		"bundler": {
+			Name:       "bundler",
+			Command:    "bundle",
+			VersionCmd: "bundle --version",
+			Category:   "package",
+			InstallGuide: `Install Bundler (ships with Ruby 2.6+):
+  All: gem install bundler`,
+		},
+		"php": {
+			Name:       "php",
+			Command:    "php",
+			VersionCmd: "php --version",
+			Category:   "runtime",
+			Packages:   map[string]string{PackageManagerBrew: "php", PackageManagerApt: "php-cli", PackageManagerDnf: "php-cli"},
+			InstallGuide: `Install PHP:
+  macOS:   brew install php
+  Ubuntu:  sudo apt install php-cli
+  Fedora:  sudo dnf install php-cli
+  Windows: https://windows.php.net/download/`,
+		},
+		"composer": {
+			Name:       "composer",
+			Command:    "composer",
+			VersionCmd: "composer --version",
+			Category:   "package",
+			InstallGuide: `Install Composer:
+  macOS:   brew install composer
+  Ubuntu:  sudo apt install composer
+  All:     https://getcomposer.org/download/`,
+		},
+		"elixir": {
+			Name:       "elixir",
+			Command:    "elixir",
+			VersionCmd: "elixir --version",
+			Category:   "runtime",
+			InstallGuide: `Install Elixir:
+  macOS:   brew install elixir
+  Ubuntu:  sudo apt install elixir
+  Fedora:  sudo dnf install elixir
+  Windows: https://elixir-lang.org/install.html#windows`,
+		},
+		"mix": {
+			Name:       "mix",
+			Command:    "mix",
+			VersionCmd: "mix --version",
+			Category:   "package",
+			InstallGuide: `Mix is installed with Elixir:
+  macOS:   brew install elixir
+  Ubuntu:  sudo apt install elixir
+  Fedora:  sudo dnf install elixir`,
+		},
+		"kubectl": {
+			Name:       "kubectl",
+			Command:    "kubectl",
+			VersionCmd: "kubectl version --client",
+			Category:   "container",
+			Packages:   map[string]string{PackageManagerBrew: "kubectl", PackageManagerWinget: "Kubernetes.kubectl"},
+			InstallGuide: `Install kubectl:
+  macOS:   brew install kubectl
+  Ubuntu:  sudo snap install kubectl --classic
+  Fedora:  sudo dnf install kubectl
+  Windows: winget install Kubernetes.kubectl
+  All:     https://kubernetes.io/docs/tasks/tools/`,
+		},
+		"helm": {
+			Name:       "helm",
+			Command:    "helm",
+			VersionCmd: "helm version --short",
+			Category:   "container",
+			Packages:   map[string]string{PackageManagerBrew: "helm", PackageManagerWinget: "Helm.Helm"},
+			InstallGuide: `Install Helm:
+  macOS:   brew install helm
+  Ubuntu:  sudo snap install helm --classic
+  Fedora:  sudo dnf install helm
+  Windows: winget install Helm.Helm
+  All:     https://helm.sh/docs/intro/install/`,
+		},
+		"terraform": {
+			Name:       "terraform",
+			Command:    "terraform",
+			VersionCmd: "terraform version",
+			Category:   "build",
+			InstallGuide: `Install Terraform:
+  macOS:   brew tap hashicorp/tap && brew install hashicorp/tap/terraform
+  Ubuntu:  sudo apt install terraform (after adding the HashiCorp apt repository)
+  Fedora:  sudo dnf install terraform (after adding the HashiCorp dnf repository)
+  Windows: winget install Hashicorp.Terraform
+  All:     https://developer.hashicorp.com/terraform/install`,
 		},
 		"make": {
 			Name:       "make",
 			Command:    "make",
 			VersionCmd: "make --version",
 			Category:   "build",
+			Packages:   map[string]string{PackageManagerBrew: "make", PackageManagerApt: "make", PackageManagerDnf: "make"},
 			InstallGuide: `Install Make:
   macOS:   xcode-select --install
   Ubuntu:  sudo apt install build-essential
   Fedora:  sudo dnf install make`,
+		},
+		"cmake": {
+			Name:       "cmake",
+			Command:    "cmake",
+			VersionCmd: "cmake --version",
+			Category:   "build",
+			Packages:   map[string]string{PackageManagerBrew: "cmake", PackageManagerApt: "cmake", PackageManagerDnf: "cmake", PackageManagerWinget: "Kitware.CMake"},
+			InstallGuide: `Install CMake:
+  macOS:   brew install cmake
+  Ubuntu:  sudo apt install cmake
+  Fedora:  sudo dnf install cmake
+  Windows: winget install Kitware.CMake
+  All:     https://cmake.org/download/`,
+		},
+		"gcc": {
+			Name:         "gcc",
+			Command:      "gcc",
+			VersionCmd:   "gcc --version",
+			Alternatives: []string{"cc", "clang"},
+			Category:     "build",
+			Packages:     map[string]string{PackageManagerBrew: "gcc", PackageManagerApt: "gcc", PackageManagerDnf: "gcc"},
+			InstallGuide: `Install GCC (C and C++ compilers):
+  macOS:   xcode-select --install (provides clang as gcc)
+  Ubuntu:  sudo apt install build-essential
+  Fedora:  sudo dnf install gcc gcc-c++
+  Windows: winget install MSYS2.MSYS2, then pacman -S mingw-w64-ucrt-x86_64-gcc`,
+		},
+		"clang": {
+			Name:       "clang",
+			Command:    "clang",
+			VersionCmd: "clang --version",
+			Category:   "build",
+			InstallGuide: `Install Clang (C and C++ compilers):
+  macOS:   xcode-select --install
+  Ubuntu:  sudo apt install clang
+  Fedora:  sudo dnf install clang
+  Windows: winget install LLVM.LLVM`,
+		},
+		"task": {
+			Name:         "task",
+			Command:      "task",
+			VersionCmd:   "task --version",
+			Alternatives: []string{"go-task"},
+			Category:     "build",
+			InstallGuide: `Install Task (go-task):
+  macOS:   brew install go-task
+  Ubuntu:  sudo snap install task --classic
+  Fedora:  sudo dnf install go-task
+  Windows: winget install Task.Task
+  All:     go install github.com/go-task/task/v3/cmd/task@latest`,
+		},
+		"foreman": {
+			Name:         "foreman",
+			Command:      "foreman",
+			VersionCmd:   "foreman --version",
+			Alternatives: []string{"honcho"},
+			Category:     "runtime",
+			InstallGuide: `Install a Procfile runner:
+  All:     gem install foreman
+  Python:  pip install honcho`,
 		},
 		"java": {
 			Name:         "java",
@@ -194,6 +406,7 @@ If missing:
 			VersionCmd:   "java --version",
 			Alternatives: []string{"java"},
 			Category:     "runtime",
+			Packages:     map[string]string{PackageManagerBrew: "openjdk", PackageManagerApt: "default-jdk", PackageManagerDnf: "java-21-openjdk-devel", PackageManagerWinget: "Microsoft.OpenJDK.21"},
 			InstallGuide: `Install Java:
   macOS:   brew install openjdk
   Ubuntu:  sudo apt install default-jdk
@@ -204,6 +417,7 @@ If missing:
 			Command:    "mvn",
 			VersionCmd: "mvn --version",
 			Category:   "build",
+			Packages:   map[string]string{PackageManagerBrew: "maven", PackageManagerApt: "maven", PackageManagerDnf: "maven"},
 			InstallGuide: `Install Maven:
   macOS:   brew install maven
   Ubuntu:  sudo apt install maven
@@ -227,8 +441,27 @@ type CheckResult struct {
 	Name    string // Tool name
 	Found   bool   // Whether tool was found
 	Version string // Detected version (if found)
-	Path    string // Path to tool (if found)
+	Path    string // Resolved binary via exec.LookPath (if found)
 	Error   error  // Error during check (if any)
+
+	Unreachable bool // Found, but the health check failed (e.g. daemon down)
+
+	MinVersion string // Version the plan requires ("" for any)
+	Outdated   bool   // Found, but Version is older than MinVersion
+}
+
+// MeetsMinVersion reports whether the detected version satisfies
+// MinVersion. known is false without a MinVersion or when the version
+// could not be compared (not found, or not a dotted number).
+func (r *CheckResult) MeetsMinVersion() (meets, known bool) {
+	if r.MinVersion == "" || !r.Found {
+		return false, false
+	}
+	cmp, ok := CompareVersions(r.Version, r.MinVersion)
+	if !ok {
+		return false, false
+	}
+	return cmp >= 0, true
 }
 
 // CheckSummary contains results for all checks
@@ -236,6 +469,9 @@ type CheckSummary struct {
 	Results      []CheckResult // Individual results
 	AllFound     bool          // Whether all tools were found
 	MissingTools []string      // List of missing tool names
+
+	UnreachableTools []string // Installed tools whose health check failed
+	OutdatedTools    []string // Installed tools older than their MinVersion
 }
 
 // NewCheckSummary creates a new check summary
@@ -244,6 +480,9 @@ func NewCheckSummary() *CheckSummary {
 		Results:      []CheckResult{},
 		AllFound:     true,
 		MissingTools: []string{},
+
+		UnreachableTools: []string{},
+		OutdatedTools:    []string{},
 	}
 }
 
@@ -253,5 +492,33 @@ func (s *CheckSummary) AddResult(result CheckResult) {
 	if !result.Found {
 		s.AllFound = false
 		s.MissingTools = append(s.MissingTools, result.Name)
+	} else if result.Unreachable {
+		s.UnreachableTools = append(s.UnreachableTools, result.Name)
+	}
+	if result.Outdated {
+		s.OutdatedTools = append(s.OutdatedTools, result.Name)
+	}
+}
+
+// Problems lists the missing, unreachable and outdated tools, in that order
+func (s *CheckSummary) Problems() []string {
+	var problems []string
+	for _, name := range s.MissingTools {
+		problems = append(problems, name+" missing")
+	}
+	for _, name := range s.UnreachableTools {
+		problems = append(problems, name+" unreachable")
 	}
+	for _, result := range s.Results {
+		if result.Outdated {
+			problems = append(problems, result.Name+" "+result.Version+" < "+result.MinVersion)
+		}
+	}
+	return problems
+}
+
+// Ready reports whether every tool was found, passed its health check and
+// meets its minimum version
+func (s *CheckSummary) Ready() bool {
+	return s.AllFound && len(s.UnreachableTools) == 0 && len(s.OutdatedTools) == 0
 }
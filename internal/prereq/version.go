@@ -0,0 +1,58 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Version comparison for MinVersion checks
+
+package prereq
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two dotted versions such as 1.21.5 and 1.21
+// numerically, part by part; missing parts count as 0 ("1.21" equals
+// "1.21.0"). It returns -1, 0 or 1, and ok is false if either is not a
+// dotted number (see ParseVersion).
+func CompareVersions(a, b string) (cmp int, ok bool) {
+	partsA, okA := versionParts(a)
+	partsB, okB := versionParts(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// versionParts splits a version into its numbers. Constraint prefixes
+// plans write min_version with (">=", ">", "=", "^", "~"), a leading "v"
+// and a trailing "+" ("18+") are ignored.
+func versionParts(version string) ([]int, bool) {
+	version = strings.TrimLeft(strings.TrimSpace(version), ">=^~ ")
+	version = strings.TrimSuffix(strings.TrimPrefix(version, "v"), "+")
+	if version == "" {
+		return nil, false
+	}
+	fields := strings.Split(version, ".")
+	parts := make([]int, 0, len(fields))
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
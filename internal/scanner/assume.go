@@ -0,0 +1,39 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Overriding the detected primary stack (--assume-stack)
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stacks are the primary stacks a profile can have, in detection order
+var Stacks = []string{"docker", "node", "go", "rust", "python", "java", "dotnet", "ruby", "php", "elixir", "cpp", "kubernetes"}
+
+// ParseStack validates a stack name given with --assume-stack
+func ParseStack(name string) (string, error) {
+	stack := strings.ToLower(strings.TrimSpace(name))
+	if stack == "" {
+		return "", nil
+	}
+	if !containsString(Stacks, stack) {
+		return "", fmt.Errorf("unknown stack %q (supported: %s)", name, strings.Join(Stacks, ", "))
+	}
+	return stack, nil
+}
+
+// AssumeStack replaces the detected primary stack, for projects whose
+// stray manifest (a package.json for docs tooling in a Go CLI) misleads
+// detection. A framework of another language stack is dropped so the
+// planner does not mix the two; other signals are kept.
+func (p *ProjectProfile) AssumeStack(stack string) {
+	if stack == "" || stack == p.Stack {
+		return
+	}
+	if p.Framework != "" && stack != "docker" && !containsString(frameworksByStack[stack], p.Framework) {
+		p.Framework = ""
+		p.ASGIApp = ""
+	}
+	p.Stack = stack
+}
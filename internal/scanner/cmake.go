@@ -0,0 +1,86 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// CMakeLists.txt parsing
+
+package scanner
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// cmakeCommentPattern matches "# ..." line comments
+	cmakeCommentPattern = regexp.MustCompile(`#[^\n]*`)
+	// cmakeQuotedPattern matches quoted arguments such as DESCRIPTION strings
+	cmakeQuotedPattern = regexp.MustCompile(`"[^"]*"`)
+	// cmakeProjectPattern matches the arguments of project(...)
+	cmakeProjectPattern = regexp.MustCompile(`(?i)\bproject\s*\(([^)]*)\)`)
+	// cmakeExecutablePattern matches the arguments of add_executable(...)
+	cmakeExecutablePattern = regexp.MustCompile(`(?i)\badd_executable\s*\(([^)]*)\)`)
+)
+
+// cmakeProject is what the root CMakeLists.txt says about the build
+type cmakeProject struct {
+	Languages   []string // "c" and/or "cpp", from project()
+	Executables []string // add_executable targets, in file order
+}
+
+// detectCMakeProject parses the root-level CMakeLists.txt, or returns nil
+// if there is none
+func detectCMakeProject(result *ScanResult) *cmakeProject {
+	for _, relPath := range result.ProjectFiles[FileTypeCMakeLists] {
+		if strings.Contains(relPath, string(os.PathSeparator)) {
+			continue
+		}
+		return parseCMakeLists(readRootFile(result.RootPath, relPath))
+	}
+	return nil
+}
+
+// parseCMakeLists returns the languages and executable targets of a
+// CMakeLists.txt. Without a LANGUAGES list, project() enables C and C++.
+func parseCMakeLists(content string) *cmakeProject {
+	content = cmakeCommentPattern.ReplaceAllString(content, "")
+	content = cmakeQuotedPattern.ReplaceAllString(content, "")
+	project := &cmakeProject{}
+
+	if match := cmakeProjectPattern.FindStringSubmatch(content); match != nil {
+		args := strings.Fields(match[1])
+		hasC, hasCXX, none := false, false, false
+		for _, arg := range args[min(1, len(args)):] {
+			switch arg {
+			case "C":
+				hasC = true
+			case "CXX":
+				hasCXX = true
+			case "NONE":
+				none = true
+			}
+		}
+		if !hasC && !hasCXX && !none {
+			hasC, hasCXX = true, true
+		}
+		if hasC {
+			project.Languages = append(project.Languages, "c")
+		}
+		if hasCXX {
+			project.Languages = append(project.Languages, "cpp")
+		}
+	}
+
+	for _, match := range cmakeExecutablePattern.FindAllStringSubmatch(content, -1) {
+		args := strings.Fields(match[1])
+		if len(args) == 0 || strings.Contains(args[0], "${") {
+			continue
+		}
+		// Imported and alias targets are not built by the project
+		if len(args) > 1 && (args[1] == "IMPORTED" || args[1] == "ALIAS") {
+			continue
+		}
+		if !containsString(project.Executables, args[0]) {
+			project.Executables = append(project.Executables, args[0])
+		}
+	}
+	return project
+}
@@ -6,6 +6,7 @@ package scanner
 import (
 	"bufio"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -31,6 +32,51 @@ var skipDirs = map[string]bool{
 	".nuxt":        true,
 	"coverage":     true,
 	".cache":       true,
+	".terraform":   true,
+}
+
+// dependencyDirs are skipped directories that hold installed dependencies
+// or build output, which a Docker build context should leave out
+var dependencyDirs = map[string]bool{
+	"node_modules": true,
+	"target":       true,
+	".venv":        true,
+	"venv":         true,
+}
+
+// DefaultExcludeDirs are pruned unless ScanConfig.ExcludeDirs is set: test
+// fixtures and third-party code carry manifests of projects that are not
+// the one being run
+var DefaultExcludeDirs = []string{"testdata", "fixtures", "third_party", "bower_components"}
+
+// NormalizeExcludeDirs cleans --exclude values: "./vendor/" becomes
+// "vendor", backslashes become slashes and empty entries are dropped
+func NormalizeExcludeDirs(dirs []string) []string {
+	var cleaned []string
+	for _, dir := range dirs {
+		dir = strings.ReplaceAll(strings.TrimSpace(dir), "\\", "/")
+		dir = strings.Trim(strings.TrimPrefix(dir, "./"), "/")
+		if dir != "" && dir != "." {
+			cleaned = append(cleaned, dir)
+		}
+	}
+	return cleaned
+}
+
+// isExcludedDir reports whether a directory matches an ExcludeDirs entry
+func isExcludedDir(relPath string, excludes []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	name := path.Base(relPath)
+	for _, exclude := range excludes {
+		if strings.Contains(exclude, "/") {
+			if relPath == exclude {
+				return true
+			}
+		} else if name == exclude {
+			return true
+		}
+	}
+	return false
 }
 
 // detectFileType returns the file type constant for a given filename
@@ -41,6 +87,8 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypeDockerfile
 	case "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml":
 		return FileTypeCompose
+	case ".dockerignore":
+		return FileTypeDockerignore
 	}
 
 	// Node.js files
@@ -55,6 +103,8 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypePnpmLock
 	case "bun.lockb":
 		return FileTypeBunLock
+	case "deno.json", "deno.jsonc":
+		return FileTypeDenoJSON
 	}
 
 	// Python files
@@ -92,6 +142,8 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypeGoMod
 	case "go.sum":
 		return FileTypeGoSum
+	case "go.work":
+		return FileTypeGoWork
 	}
 
 	// Rust files
@@ -112,6 +164,10 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypeGradleKts
 	case "settings.gradle", "settings.gradle.kts":
 		return FileTypeSettingsGradle
+	case "gradlew":
+		return FileTypeGradleWrapper
+	case "mvnw":
+		return FileTypeMavenWrapper
 	}
 
 	// Make/Build files
@@ -120,6 +176,13 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypeMakefile
 	case "cmakelists.txt":
 		return FileTypeCMakeLists
+	case "taskfile.yml", "taskfile.yaml":
+		return FileTypeTaskfile
+	}
+
+	// Heroku-style Procfile (the name is case-sensitive)
+	if name == "Procfile" {
+		return FileTypeProcfile
 	}
 
 	// Ruby files
@@ -128,8 +191,19 @@ func detectFileType(name, nameLower, fullPath string) string {
 	}
 
 	// PHP files
-	if nameLower == "composer.json" {
+	switch nameLower {
+	case "composer.json":
 		return FileTypeComposerJSON
+	case "artisan":
+		return FileTypeArtisan
+	}
+
+	// Elixir files
+	switch nameLower {
+	case "mix.exs":
+		return FileTypeMixExs
+	case "mix.lock":
+		return FileTypeMixLock
 	}
 
 	// .NET files (check by extension)
@@ -141,6 +215,13 @@ func detectFileType(name, nameLower, fullPath string) string {
 		return FileTypeFSProj
 	case ".sln":
 		return FileTypeSolution
+	case ".tf":
+		return FileTypeTerraform
+	}
+
+	// Helm charts (checked before the generic manifest check)
+	if nameLower == "chart.yaml" {
+		return FileTypeHelmChart
 	}
 
 	// Check for Kubernetes manifests (YAML files with k8s content)
@@ -0,0 +1,77 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Detection of .env templates (.env.example, .env.sample)
+
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EnvExampleInfo describes the root-level .env template of a project
+type EnvExampleInfo struct {
+	Path    string   `json:"-"`     // Absolute path to the template
+	RelPath string   `json:"path"`  // Relative path from root
+	Vars    []string `json:"vars"`  // Variable names, in file order
+	Empty   []string `json:"empty"` // Variables without a value, which must be filled in
+}
+
+// envVarPattern matches "NAME=value" lines, optionally prefixed with export
+var envVarPattern = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// isEnvExampleFile checks if filename is a .env template
+func isEnvExampleFile(name string) bool {
+	switch strings.ToLower(name) {
+	case ".env.example", ".env.sample", ".env.template", ".env.dist":
+		return true
+	}
+	return false
+}
+
+// ParseEnvExample reads the variable names of a .env template
+func ParseEnvExample(path, relPath string) (*EnvExampleInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := &EnvExampleInfo{Path: path, RelPath: relPath, Vars: []string{}, Empty: []string{}}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		match := envVarPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		info.Vars = append(info.Vars, match[1])
+
+		if envValue(match[2]) == "" {
+			info.Empty = append(info.Empty, match[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// envValue returns a template value without quotes or inline comment
+func envValue(raw string) string {
+	if raw != "" && (raw[0] == '"' || raw[0] == '\'') {
+		if end := strings.IndexByte(raw[1:], raw[0]); end >= 0 {
+			return raw[1 : end+1]
+		}
+		return raw[1:]
+	}
+	// Unquoted values end at an inline comment
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw)
+}
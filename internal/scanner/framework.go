@@ -0,0 +1,173 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Web framework detection from root-level project files
+
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported frameworks
+const (
+	FrameworkDjango  = "django"
+	FrameworkFlask   = "flask"
+	FrameworkFastAPI = "fastapi"
+	FrameworkRails   = "rails"
+	FrameworkNextJS  = "nextjs"
+	FrameworkPhoenix = "phoenix"
+)
+
+// maxManifestSize bounds how much of a dependency file is read
+const maxManifestSize = 256 * 1024
+
+// frameworksByStack lists frameworks checked for each stack, in order
+var frameworksByStack = map[string][]string{
+	"python": {FrameworkDjango, FrameworkFastAPI, FrameworkFlask},
+	"ruby":   {FrameworkRails},
+	"node":   {FrameworkNextJS},
+	"elixir": {FrameworkPhoenix},
+}
+
+// frameworkOrder is used when the primary stack has no framework match
+// (e.g. a Dockerfile made "docker" the primary stack)
+var frameworkOrder = []string{FrameworkNextJS, FrameworkDjango, FrameworkFastAPI, FrameworkFlask, FrameworkRails, FrameworkPhoenix}
+
+// pythonDependencyPattern matches a requirements.txt, Pipfile or
+// pyproject.toml entry for the package (not packages such as flask-cors)
+var pythonDependencyPattern = map[string]*regexp.Regexp{
+	FrameworkDjango:  pythonDependencyRegexp("django"),
+	FrameworkFastAPI: pythonDependencyRegexp("fastapi"),
+	FrameworkFlask:   pythonDependencyRegexp("flask"),
+}
+
+func pythonDependencyRegexp(pkg string) *regexp.Regexp {
+	return regexp.MustCompile(`(?mi)(^|["'])\s*` + pkg + `\s*([<>=!~\[;,"']|$)`)
+}
+
+var railsGemPattern = regexp.MustCompile(`(?m)^\s*gem\s+["']rails["']`)
+
+// phoenixDepPattern matches a {:phoenix, ...} dependency in mix.exs
+var phoenixDepPattern = regexp.MustCompile(`\{\s*:phoenix\s*,`)
+
+// asgiAppPattern matches "app = FastAPI(" (optionally annotated) in Python code
+var asgiAppPattern = regexp.MustCompile(`(?m)^(\w+)\s*(?::[^=\n]+)?=\s*(FastAPI|Starlette)\(`)
+
+// asgiEntryFiles are the root-level modules searched for an ASGI app
+var asgiEntryFiles = []string{"main.py", "app.py", "asgi.py"}
+
+// detectASGIApp finds an ASGI application object in the root entry modules
+// and returns its uvicorn import string (e.g. "main:app") and class name
+func detectASGIApp(root string) (app, class string) {
+	for _, name := range asgiEntryFiles {
+		match := asgiAppPattern.FindStringSubmatch(readRootFile(root, name))
+		if match != nil {
+			return strings.TrimSuffix(name, ".py") + ":" + match[1], match[2]
+		}
+	}
+	return "", ""
+}
+
+// detectFramework returns the web framework of the project, or "" if none.
+// Only root-level files are considered.
+func detectFramework(result *ScanResult, profile *ProjectProfile) string {
+	checked := make(map[string]bool)
+	order := append(append([]string{}, frameworksByStack[profile.Stack]...), frameworkOrder...)
+
+	for _, framework := range order {
+		if checked[framework] {
+			continue
+		}
+		checked[framework] = true
+		if hasFramework(result, framework) {
+			return framework
+		}
+	}
+	return ""
+}
+
+// hasFramework checks the signals of a single framework
+func hasFramework(result *ScanResult, framework string) bool {
+	switch framework {
+	case FrameworkDjango:
+		return rootFileExists(result.RootPath, "manage.py") || pythonDependsOn(result, framework)
+	case FrameworkFastAPI:
+		if _, class := detectASGIApp(result.RootPath); class == "FastAPI" {
+			return true
+		}
+		return pythonDependsOn(result, framework)
+	case FrameworkFlask:
+		return pythonDependsOn(result, framework)
+	case FrameworkRails:
+		if rootFileExists(result.RootPath, filepath.Join("bin", "rails")) ||
+			rootFileExists(result.RootPath, filepath.Join("config", "application.rb")) {
+			return true
+		}
+		return railsGemPattern.MatchString(readRootFile(result.RootPath, "Gemfile"))
+	case FrameworkNextJS:
+		for _, name := range []string{"next.config.js", "next.config.mjs", "next.config.ts"} {
+			if rootFileExists(result.RootPath, name) {
+				return true
+			}
+		}
+		return packageJSONDependsOn(readRootFile(result.RootPath, "package.json"), "next")
+	case FrameworkPhoenix:
+		return phoenixDepPattern.MatchString(readRootFile(result.RootPath, "mix.exs"))
+	}
+	return false
+}
+
+// pythonDependsOn checks root-level Python dependency files for a package
+func pythonDependsOn(result *ScanResult, framework string) bool {
+	pattern := pythonDependencyPattern[framework]
+	for _, name := range []string{"requirements.txt", "pyproject.toml", "Pipfile"} {
+		content := readRootFile(result.RootPath, name)
+		if content == "" {
+			continue
+		}
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageJSONDependsOn checks dependencies and devDependencies
+func packageJSONDependsOn(content, pkg string) bool {
+	if content == "" {
+		return false
+	}
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return false
+	}
+	_, inDeps := manifest.Dependencies[pkg]
+	_, inDevDeps := manifest.DevDependencies[pkg]
+	return inDeps || inDevDeps
+}
+
+// rootFileExists reports whether a file exists relative to the project root
+func rootFileExists(root, relPath string) bool {
+	info, err := os.Stat(filepath.Join(root, relPath))
+	return err == nil && !info.IsDir()
+}
+
+// readRootFile returns a root-level file's content ("" if missing or too large)
+func readRootFile(root, name string) string {
+	path := filepath.Join(root, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() > maxManifestSize {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// go.work parsing
+
+package scanner
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// detectGoModules returns the modules used by the root-level go.work, or
+// nil if there is none
+func detectGoModules(result *ScanResult) []string {
+	for _, relPath := range result.ProjectFiles[FileTypeGoWork] {
+		if strings.Contains(relPath, string(os.PathSeparator)) {
+			continue
+		}
+		return parseGoWork(readRootFile(result.RootPath, relPath))
+	}
+	return nil
+}
+
+// parseGoWork returns the directories of the use directives of a go.work,
+// cleaned and slash-separated ("." for the workspace root), in file order
+func parseGoWork(content string) []string {
+	var modules []string
+	add := func(dir string) {
+		dir = strings.Trim(dir, "\"`")
+		if dir == "" {
+			return
+		}
+		dir = path.Clean(strings.ReplaceAll(dir, "\\", "/"))
+		if !containsString(modules, dir) {
+			modules = append(modules, dir)
+		}
+	}
+
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		if inBlock {
+			if line == ")" {
+				inBlock = false
+			} else {
+				add(line)
+			}
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(line, "use")
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '(') {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if rest == "(" {
+			inBlock = true
+			continue
+		}
+		add(rest)
+	}
+	return modules
+}
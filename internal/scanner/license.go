@@ -0,0 +1,101 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// License file detection with a lightweight SPDX heuristic
+
+package scanner
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// LicenseInfo describes the project's root-level license file
+type LicenseInfo struct {
+	Path    string `json:"-"`       // Absolute path to the license file
+	RelPath string `json:"path"`    // Relative path from root
+	SPDX    string `json:"spdx_id"` // SPDX identifier ("" if not recognized)
+}
+
+// licenseHeadSize is how much of a license file the heuristic reads
+const licenseHeadSize = 4096
+
+var spdxTagPattern = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+()\- ]+)`)
+
+// licenseRule maps text that must all appear in a license to its SPDX ID
+type licenseRule struct {
+	spdx   string
+	phrase []string
+}
+
+// licenseRules are checked in order: more specific texts come first
+// (e.g. the AGPL and LGPL before the GPL, BSD-3 before BSD-2)
+var licenseRules = []licenseRule{
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"Unlicense", []string{"free and unencumbered software released into the public domain"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"MIT", []string{"mit license"}},
+}
+
+// isLicenseFile checks if filename matches a common license file name
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, base := range []string{"license", "licence", "copying", "unlicense"} {
+		if name == base || name == base+".md" || name == base+".txt" || name == base+".rst" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLicense reads the start of a license file and identifies it
+func ParseLicense(path, relPath string) (*LicenseInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	head, err := io.ReadAll(io.LimitReader(file, licenseHeadSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LicenseInfo{
+		Path:    path,
+		RelPath: relPath,
+		SPDX:    identifyLicense(string(head)),
+	}, nil
+}
+
+// identifyLicense returns the SPDX identifier for license text, or ""
+func identifyLicense(text string) string {
+	if match := spdxTagPattern.FindStringSubmatch(text); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+
+	// Normalize whitespace so phrases match across wrapped lines
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, rule := range licenseRules {
+		matched := true
+		for _, phrase := range rule.phrase {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule.spdx
+		}
+	}
+	return ""
+}
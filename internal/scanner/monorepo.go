@@ -0,0 +1,77 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Subproject discovery for monorepos
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestTypes are the file types that make a directory a project of its own
+var manifestTypes = map[string]bool{
+	FileTypePackageJSON:  true,
+	FileTypeGoMod:        true,
+	FileTypeGoWork:       true,
+	FileTypeCargoToml:    true,
+	FileTypePyProject:    true,
+	FileTypeRequirements: true,
+	FileTypeSetupPy:      true,
+	FileTypePipfile:      true,
+	FileTypePomXML:       true,
+	FileTypeBuildGradle:  true,
+	FileTypeGradleKts:    true,
+	FileTypeCSProj:       true,
+	FileTypeFSProj:       true,
+	FileTypeSolution:     true,
+	FileTypeGemfile:      true,
+	FileTypeComposerJSON: true,
+	FileTypeMixExs:       true,
+	FileTypeDockerfile:   true,
+	FileTypeCompose:      true,
+}
+
+// FindSubprojects returns the top-level subdirectories of root that have a
+// project manifest of their own (e.g. frontend/package.json), sorted by name.
+// Directories matching excludeDirs (see ScanConfig.ExcludeDirs) are skipped.
+func FindSubprojects(root string, excludeDirs ...string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", root, err)
+	}
+
+	var subprojects []string
+	for _, entry := range entries {
+		if !entry.IsDir() || ShouldSkipDir(entry.Name()) || isExcludedDir(entry.Name(), excludeDirs) {
+			continue
+		}
+		if hasManifest(filepath.Join(root, entry.Name())) {
+			subprojects = append(subprojects, entry.Name())
+		}
+	}
+
+	sort.Strings(subprojects)
+	return subprojects, nil
+}
+
+// hasManifest checks the files directly inside dir for a project manifest
+func hasManifest(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		fileType := detectFileType(name, strings.ToLower(name), filepath.Join(dir, name))
+		if manifestTypes[fileType] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Heroku-style Procfile parsing
+
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// procfileLinePattern matches "<process>: <command>" lines
+var procfileLinePattern = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*:\s*(.+)$`)
+
+// detectProcesses returns the processes of the root-level Procfile by
+// name, or nil if there is none
+func detectProcesses(result *ScanResult) map[string]string {
+	for _, relPath := range result.ProjectFiles[FileTypeProcfile] {
+		if strings.Contains(relPath, string(os.PathSeparator)) {
+			continue
+		}
+		return parseProcfile(readRootFile(result.RootPath, relPath))
+	}
+	return nil
+}
+
+// parseProcfile returns the process commands of a Procfile by name
+func parseProcfile(content string) map[string]string {
+	processes := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if match := procfileLinePattern.FindStringSubmatch(line); match != nil {
+			processes[match[1]] = strings.TrimSpace(match[2])
+		}
+	}
+	if len(processes) == 0 {
+		return nil
+	}
+	return processes
+}
@@ -5,6 +5,7 @@ package scanner
 
 import (
 	"bufio"
+	"bytes"
 	"os"
 	"regexp"
 	"strings"
@@ -14,7 +15,6 @@ var (
 	// Regex patterns for section detection
 	sectionHeaderRegex = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
 	codeBlockRegex     = regexp.MustCompile("^```")
-	shellBlockRegex    = regexp.MustCompile("^```(bash|sh|shell|console|zsh|powershell|cmd)")
 )
 
 // MaxReadmeSize is the maximum README content to load (512KB)
@@ -23,7 +23,46 @@ const MaxReadmeSize = 512 * 1024
 // PreviewLines is the number of lines to show for truncated content
 const PreviewLines = 50
 
-// ParseReadme analyzes a README file and extracts metadata
+// utf8BOM is the byte-order mark some Windows editors write at file start
+const utf8BOM = "\uFEFF"
+
+// NormalizeText strips a leading UTF-8 BOM and converts CRLF and lone CR
+// line endings to LF
+func NormalizeText(content string) string {
+	content = strings.TrimPrefix(content, utf8BOM)
+	if !strings.Contains(content, "\r") {
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
+// scanAnyLines is bufio.ScanLines that also splits on CRLF and lone CR
+func scanAnyLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the buffer may be the first half of a CRLF
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ParseReadme analyzes a README file and extracts metadata.
+// Line endings and a leading BOM are normalized before analysis.
 func ParseReadme(path, relPath string) (*ReadmeInfo, error) {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -48,31 +87,40 @@ func ParseReadme(path, relPath string) (*ReadmeInfo, error) {
 	if info.Size() <= MaxReadmeSize {
 		content, err := os.ReadFile(path)
 		if err == nil {
-			readme.Content = string(content)
+			readme.Content = NormalizeText(string(content))
 		}
 	} else {
 		// Load truncated content for large files
 		readme.Truncated = true
 		content, err := loadTruncatedContent(path, MaxReadmeSize)
 		if err == nil {
-			readme.Content = content
+			readme.Content = NormalizeText(content)
 		}
 	}
 
 	// Parse the file line by line
 	scanner := bufio.NewScanner(file)
+	scanner.Split(scanAnyLines)
 	inCodeBlock := false
+	blockLang := ""
+	var blockLines []string
+	firstLine := true
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		if firstLine {
+			line = strings.TrimPrefix(line, utf8BOM)
+			firstLine = false
+		}
 
 		// Detect code blocks
 		if codeBlockRegex.MatchString(line) {
 			if !inCodeBlock {
 				readme.CodeBlocks++
-				if shellBlockRegex.MatchString(line) {
-					readme.ShellCommands++
-				}
+				blockLang = strings.TrimSpace(strings.TrimLeft(line, "`"))
+				blockLines = blockLines[:0]
+			} else if isShellBlock(blockLang, blockLines) {
+				readme.ShellCommands++
 			}
 			inCodeBlock = !inCodeBlock
 			continue
@@ -80,6 +128,7 @@ func ParseReadme(path, relPath string) (*ReadmeInfo, error) {
 
 		// Skip content inside code blocks for section detection
 		if inCodeBlock {
+			blockLines = append(blockLines, line)
 			continue
 		}
 
@@ -97,6 +146,12 @@ func ParseReadme(path, relPath string) (*ReadmeInfo, error) {
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
+	readme.SectionRanges = FindSections(readme.Content)
+
+	// An unterminated block runs to the end of the file
+	if inCodeBlock && isShellBlock(blockLang, blockLines) {
+		readme.ShellCommands++
+	}
 
 	return readme, nil
 }
@@ -125,69 +180,198 @@ func loadTruncatedContent(path string, maxBytes int64) (string, error) {
 	return result, nil
 }
 
+// Section kinds recognized from header titles
+const (
+	SectionInstall    = "install"
+	SectionUsage      = "usage"
+	SectionBuild      = "build"
+	SectionQuickStart = "quickstart"
+)
+
+// sectionKindPatterns maps section kinds to title substrings
+var sectionKindPatterns = []struct {
+	kind     string
+	patterns []string
+}{
+	{SectionInstall, []string{"install", "setup", "getting started", "prerequisites", "requirements"}},
+	{SectionUsage, []string{"usage", "how to use", "example", "demo", "tutorial"}},
+	{SectionBuild, []string{"build", "compile", "development", "contributing", "running"}},
+	{SectionQuickStart, []string{"quick start", "quickstart", "tldr", "tl;dr", "quick"}},
+}
+
+// sectionKinds returns every kind whose patterns match a lowercase title
+func sectionKinds(lowerTitle string) []string {
+	var kinds []string
+	for _, entry := range sectionKindPatterns {
+		for _, pattern := range entry.patterns {
+			if strings.Contains(lowerTitle, pattern) {
+				kinds = append(kinds, entry.kind)
+				break
+			}
+		}
+	}
+	return kinds
+}
+
 // checkSectionType identifies the type of section based on title
 func checkSectionType(lowerTitle string, readme *ReadmeInfo) {
-	// Installation patterns
-	installPatterns := []string{"install", "setup", "getting started", "prerequisites", "requirements"}
-	for _, pattern := range installPatterns {
-		if strings.Contains(lowerTitle, pattern) {
+	for _, kind := range sectionKinds(lowerTitle) {
+		switch kind {
+		case SectionInstall:
 			readme.HasInstall = true
-			break
+		case SectionUsage:
+			readme.HasUsage = true
+		case SectionBuild:
+			readme.HasBuild = true
+		case SectionQuickStart:
+			readme.HasQuickStart = true
 		}
 	}
+}
 
-	// Usage patterns
-	usagePatterns := []string{"usage", "how to use", "example", "demo", "tutorial"}
-	for _, pattern := range usagePatterns {
-		if strings.Contains(lowerTitle, pattern) {
-			readme.HasUsage = true
-			break
+// FindSections locates the ATX sections of normalized README content.
+// A section runs from its header line to the next header of the same or a
+// higher level; headers inside code blocks are ignored.
+func FindSections(content string) []SectionRange {
+	var sections []SectionRange
+	var open []int // indexes of sections not yet closed, outermost first
+	inCodeBlock := false
+	offset := 0
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		line = strings.TrimSuffix(line, "\n")
+
+		if codeBlockRegex.MatchString(line) {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		matches := sectionHeaderRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		for len(open) > 0 && sections[open[len(open)-1]].Level >= level {
+			sections[open[len(open)-1]].End = start
+			open = open[:len(open)-1]
+		}
+
+		title := strings.TrimSpace(matches[1])
+		kind := ""
+		if kinds := sectionKinds(strings.ToLower(title)); len(kinds) > 0 {
+			kind = kinds[0]
 		}
+		sections = append(sections, SectionRange{Title: title, Level: level, Kind: kind, Start: start, End: len(content)})
+		open = append(open, len(sections)-1)
 	}
 
-	// Build patterns
-	buildPatterns := []string{"build", "compile", "development", "contributing", "running"}
-	for _, pattern := range buildPatterns {
-		if strings.Contains(lowerTitle, pattern) {
-			readme.HasBuild = true
-			break
+	return sections
+}
+
+// ReadmeSource is where a command appears in a README
+type ReadmeSource struct {
+	Line    int    // 1-based line in ReadmeInfo.Content
+	Section string // title of the innermost section, "" before the first header
+}
+
+// FindCommand locates a command in the README: the first line of a shell
+// code block that is the command (or, for "a && b", its first part), with
+// any "$ " prompt dropped. Mentions in prose and longer commands that merely
+// contain it do not count. ok is false when it is not there, as for
+// commands the normalizer rewrote.
+func (r *ReadmeInfo) FindCommand(cmd string) (source ReadmeSource, ok bool) {
+	candidates := []string{strings.TrimSpace(cmd)}
+	if first, _, found := strings.Cut(cmd, "&&"); found {
+		candidates = append(candidates, strings.TrimSpace(first))
+	}
+
+	blocks := ExtractCodeBlocks(r.Content)
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		line := findShellLine(blocks, candidate)
+		if line == 0 {
+			continue
+		}
+		source.Line = line
+		offset := lineOffset(r.Content, line)
+
+		sections := r.SectionRanges
+		if sections == nil {
+			sections = FindSections(r.Content)
+		}
+		for _, section := range sections {
+			// Later sections that still contain the offset are nested deeper
+			if section.Start <= offset && offset < section.End {
+				source.Section = section.Title
+			}
 		}
+		return source, true
 	}
+	return source, false
+}
 
-	// Quick start patterns
-	quickStartPatterns := []string{"quick start", "quickstart", "tldr", "tl;dr", "quick"}
-	for _, pattern := range quickStartPatterns {
-		if strings.Contains(lowerTitle, pattern) {
-			readme.HasQuickStart = true
+// findShellLine returns the 1-based README line of the first shell block
+// line that is cmd, or 0
+func findShellLine(blocks []CodeBlock, cmd string) int {
+	for _, block := range blocks {
+		if !block.IsShell {
+			continue
+		}
+		for i, line := range block.Lines {
+			line = strings.TrimPrefix(strings.TrimSpace(line), "$ ")
+			if strings.TrimSpace(line) == cmd {
+				return block.StartLine + i
+			}
+		}
+	}
+	return 0
+}
+
+// lineOffset returns the byte offset of a 1-based line in content
+func lineOffset(content string, line int) int {
+	offset := 0
+	for n := 1; n < line; n++ {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next < 0 {
 			break
 		}
+		offset += next + 1
 	}
+	return offset
 }
 
 // ExtractCodeBlocks extracts all code blocks from README content
 func ExtractCodeBlocks(content string) []CodeBlock {
 	var blocks []CodeBlock
-	lines := strings.Split(content, "\n")
+	lines := strings.Split(NormalizeText(content), "\n")
 
 	var currentBlock *CodeBlock
 	inBlock := false
 
-	for _, line := range lines {
+	for i, line := range lines {
 		if codeBlockRegex.MatchString(line) {
 			if !inBlock {
 				// Start of code block
-				lang := strings.TrimPrefix(line, "```")
+				lang := strings.TrimLeft(line, "`")
 				lang = strings.TrimSpace(lang)
 				currentBlock = &CodeBlock{
-					Language: lang,
-					IsShell:  isShellLanguage(lang),
-					Lines:    []string{},
+					Language:  lang,
+					Lines:     []string{},
+					StartLine: i + 2,
 				}
 				inBlock = true
 			} else {
 				// End of code block
 				if currentBlock != nil {
 					currentBlock.Content = strings.Join(currentBlock.Lines, "\n")
+					currentBlock.IsShell = isShellBlock(currentBlock.Language, currentBlock.Lines)
 					blocks = append(blocks, *currentBlock)
 				}
 				currentBlock = nil
@@ -203,47 +387,162 @@ func ExtractCodeBlocks(content string) []CodeBlock {
 
 // CodeBlock represents a code block extracted from README
 type CodeBlock struct {
-	Language string   // Language identifier (bash, go, python, etc.)
-	IsShell  bool     // Whether this is a shell command block
-	Content  string   // Full content of the block
-	Lines    []string // Individual lines
+	Language  string   // Language identifier (bash, go, python, etc.)
+	IsShell   bool     // Whether this is a shell command block
+	Content   string   // Full content of the block
+	Lines     []string // Individual lines
+	StartLine int      // 1-based line of Lines[0] in the README
+}
+
+// shellLanguages are the fence info strings of shell and terminal blocks
+var shellLanguages = map[string]bool{
+	"bash": true, "sh": true, "shell": true, "zsh": true, "fish": true, "ksh": true,
+	"console": true, "terminal": true, "sh-session": true, "shell-session": true,
+	"shellsession": true, "bash-session": true, "shellscript": true,
+	"powershell": true, "pwsh": true, "ps1": true, "posh": true,
+	"cmd": true, "bat": true, "batch": true, "dos": true,
 }
 
-// isShellLanguage checks if the language is a shell language
+// shellCommandWords are commands that mark an untagged block as shell
+var shellCommandWords = map[string]bool{
+	"apt": true, "apt-get": true, "brew": true, "bun": true, "bundle": true,
+	"cargo": true, "cd": true, "chmod": true, "composer": true, "cp": true,
+	"curl": true, "deno": true, "docker": true, "docker-compose": true,
+	"dotnet": true, "export": true, "gem": true, "git": true, "go": true,
+	"gradle": true, "helm": true, "java": true, "kubectl": true, "make": true,
+	"mix": true, "mkdir": true, "mvn": true, "node": true, "npm": true,
+	"npx": true, "php": true, "pip": true, "pip3": true, "pipenv": true,
+	"pnpm": true, "poetry": true, "python": true, "python3": true,
+	"rails": true, "rustup": true, "source": true, "sudo": true, "uv": true,
+	"wget": true, "yarn": true,
+}
+
+// isShellLanguage checks if the language is a shell language. Only the
+// first word of the info string is considered ("bash title=x", "{.sh}").
 func isShellLanguage(lang string) bool {
-	lang = strings.ToLower(lang)
-	shellLangs := []string{"bash", "sh", "shell", "console", "zsh", "powershell", "cmd", "terminal"}
-	for _, shellLang := range shellLangs {
-		if lang == shellLang {
-			return true
+	fields := strings.Fields(strings.ToLower(lang))
+	if len(fields) == 0 {
+		return false
+	}
+	return shellLanguages[strings.Trim(fields[0], "{}.")]
+}
+
+// isShellBlock reports whether a code block holds shell commands. A tagged
+// block is judged by its language; an untagged block is shell when at least
+// half of its non-comment lines start with "$ " or a known command.
+func isShellBlock(lang string, lines []string) bool {
+	if strings.TrimSpace(lang) != "" {
+		return isShellLanguage(lang)
+	}
+
+	total, commands := 0, 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		total++
+		if looksLikeCommand(line) {
+			commands++
 		}
 	}
-	return false
+	return commands > 0 && commands*2 >= total
 }
 
-// GetShellCommands extracts shell commands from README content
+// looksLikeCommand checks a single line for a prompt or a known command
+func looksLikeCommand(line string) bool {
+	if strings.HasPrefix(line, "$ ") {
+		return true
+	}
+	word := strings.Fields(line)[0]
+	if strings.HasPrefix(word, "./") {
+		return true
+	}
+	return shellCommandWords[word]
+}
+
+// GetShellCommands extracts shell commands from README content.
+// Backslash continuations and lines ending in &&, || or | are joined into
+// one command, and heredoc bodies are skipped (the command that opens the
+// heredoc is kept).
 func GetShellCommands(content string) []string {
 	blocks := ExtractCodeBlocks(content)
 	var commands []string
 
 	for _, block := range blocks {
 		if block.IsShell {
-			// Split into individual commands
-			for _, line := range block.Lines {
-				line = strings.TrimSpace(line)
-				// Skip empty lines and comments
-				if line == "" || strings.HasPrefix(line, "#") {
-					continue
-				}
-				// Remove common prompt prefixes
-				line = strings.TrimPrefix(line, "$ ")
-				line = strings.TrimPrefix(line, "> ")
-				if line != "" {
-					commands = append(commands, line)
-				}
+			commands = append(commands, shellBlockCommands(block.Lines)...)
+		}
+	}
+
+	return commands
+}
+
+// heredocRegex matches a heredoc operator and captures its delimiter. The
+// << must start a word and be followed by a delimiter, which leaves out
+// here-strings (<<<) and shifts in arithmetic ($((a<<b))).
+var heredocRegex = regexp.MustCompile(`(?:^|[\s;&|(])<<(-?)\s*['"]?([A-Za-z_][A-Za-z0-9_]*)['"]?`)
+
+// shellBlockCommands splits the lines of a shell code block into commands
+func shellBlockCommands(lines []string) []string {
+	var commands []string
+	var pending strings.Builder
+	heredocEnd := ""
+	heredocTabs := false
+
+	for _, raw := range lines {
+		// Skip the heredoc body up to its delimiter line
+		if heredocEnd != "" {
+			end := raw
+			if heredocTabs {
+				end = strings.TrimLeft(end, "\t")
+			}
+			if strings.TrimRight(end, " ") == heredocEnd {
+				heredocEnd = ""
 			}
+			continue
+		}
+
+		line := strings.TrimSpace(raw)
+		continuing := pending.Len() > 0
+		// Skip empty lines and comments (an empty line also ends a continuation)
+		if line == "" || (!continuing && strings.HasPrefix(line, "#")) {
+			if continuing {
+				commands = append(commands, strings.TrimSpace(pending.String()))
+				pending.Reset()
+			}
+			continue
+		}
+		// Remove common prompt prefixes ("> " is also the continuation prompt)
+		line = strings.TrimPrefix(line, "$ ")
+		line = strings.TrimPrefix(line, "> ")
+
+		if match := heredocRegex.FindStringSubmatch(line); match != nil {
+			heredocTabs = match[1] == "-"
+			heredocEnd = match[2]
 		}
+
+		// Backslash continuation, or a chain operator at the end of the line
+		if heredocEnd == "" {
+			if strings.HasSuffix(line, "\\") {
+				pending.WriteString(strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " ")
+				continue
+			}
+			if strings.HasSuffix(line, "&&") || strings.HasSuffix(line, "||") || strings.HasSuffix(line, "|") {
+				pending.WriteString(line + " ")
+				continue
+			}
+		}
+
+		pending.WriteString(line)
+		if command := strings.TrimSpace(pending.String()); command != "" {
+			commands = append(commands, command)
+		}
+		pending.Reset()
 	}
 
+	if command := strings.TrimSpace(pending.String()); command != "" {
+		commands = append(commands, command)
+	}
 	return commands
 }
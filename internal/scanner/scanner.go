@@ -4,6 +4,7 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +14,12 @@ import (
 
 // Scan performs a file system scan of the workspace
 func Scan(config *ScanConfig) (*ScanResult, error) {
+	return ScanWithContext(context.Background(), config)
+}
+
+// ScanWithContext scans the workspace, stopping when ctx is cancelled or
+// its deadline passes
+func ScanWithContext(ctx context.Context, config *ScanConfig) (*ScanResult, error) {
 	if config == nil {
 		return nil, fmt.Errorf("scan config cannot be nil")
 	}
@@ -35,6 +42,11 @@ func Scan(config *ScanConfig) (*ScanResult, error) {
 		config.MaxDepth = 3
 	}
 
+	excludes := DefaultExcludeDirs
+	if config.ExcludeDirs != nil {
+		excludes = NormalizeExcludeDirs(config.ExcludeDirs)
+	}
+
 	startTime := time.Now()
 
 	result := &ScanResult{
@@ -49,6 +61,9 @@ func Scan(config *ScanConfig) (*ScanResult, error) {
 			result.Errors = append(result.Errors, err)
 			return nil
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Calculate depth
 		relPath, _ := filepath.Rel(config.RootPath, path)
@@ -68,6 +83,14 @@ func Scan(config *ScanConfig) (*ScanResult, error) {
 			}
 
 			if ShouldSkipDir(d.Name()) {
+				if depth == 1 && dependencyDirs[d.Name()] {
+					result.DependencyDirs = append(result.DependencyDirs, d.Name())
+				}
+				return filepath.SkipDir
+			}
+
+			if isExcludedDir(relPath, excludes) {
+				result.ExcludedDirs = append(result.ExcludedDirs, filepath.ToSlash(relPath))
 				return filepath.SkipDir
 			}
 
@@ -122,6 +145,32 @@ func detectProjectFile(path, relPath string, result *ScanResult) {
 		return
 	}
 
+	// Detect the root-level license file
+	if isLicenseFile(nameLower) {
+		if result.License == nil && !strings.Contains(relPath, string(os.PathSeparator)) {
+			license, err := ParseLicense(path, relPath)
+			if err == nil {
+				result.License = license
+			} else {
+				result.Errors = append(result.Errors, fmt.Errorf("failed to read license: %w", err))
+			}
+		}
+		return
+	}
+
+	// Detect the root-level .env template
+	if isEnvExampleFile(nameLower) {
+		if result.EnvExample == nil && !strings.Contains(relPath, string(os.PathSeparator)) {
+			envExample, err := ParseEnvExample(path, relPath)
+			if err == nil {
+				result.EnvExample = envExample
+			} else {
+				result.Errors = append(result.Errors, fmt.Errorf("failed to read %s: %w", relPath, err))
+			}
+		}
+		return
+	}
+
 	// Detect project files
 	fileType := detectFileType(name, nameLower, path)
 	if fileType != "" {
@@ -25,6 +25,9 @@ func DetectSignals(result *ScanResult) *ProjectProfile {
 		for _, file := range files {
 			baseName := filepath.Base(file)
 			profile.Signals = append(profile.Signals, baseName)
+			if fileType == FileTypeK8sManifest {
+				profile.Manifests = append(profile.Manifests, filepath.ToSlash(file))
+			}
 
 			detectToolsFromFile(fileType, baseName, profile)
 		}
@@ -32,16 +35,33 @@ func DetectSignals(result *ScanResult) *ProjectProfile {
 
 	// Detect languages from project files
 	profile.Languages = detectLanguages(result.ProjectFiles)
+	if cmake := detectCMakeProject(result); cmake != nil {
+		profile.Languages = append(profile.Languages, cmake.Languages...)
+		profile.Executables = cmake.Executables
+	}
 
 	// Determine primary stack
 	profile.Stack = determinePrimaryStack(profile)
 
+	// Detect the web framework for framework-specific run commands
+	profile.Framework = detectFramework(result, profile)
+	profile.ASGIApp, _ = detectASGIApp(result.RootPath)
+
+	if result.License != nil {
+		profile.License = result.License.SPDX
+	}
+	profile.Tasks = detectTasks(result)
+	profile.Processes = detectProcesses(result)
+	profile.DependencyDirs = result.DependencyDirs
+	profile.GoModules = detectGoModules(result)
+
 	// Sort and deduplicate all slices
 	profile.Languages = uniqueSortedStrings(profile.Languages)
 	profile.Tools = uniqueSortedStrings(profile.Tools)
 	profile.Signals = uniqueSortedStrings(profile.Signals)
 	profile.Containers = uniqueSortedStrings(profile.Containers)
 	profile.Packages = uniqueSortedStrings(profile.Packages)
+	profile.Manifests = uniqueSortedStrings(profile.Manifests)
 
 	return profile
 }
@@ -66,6 +86,11 @@ func detectToolsFromFile(fileType, baseName string, profile *ProjectProfile) {
 		profile.Tools = append(profile.Tools, "bun")
 		profile.Packages = append(profile.Packages, baseName)
 
+	// Deno
+	case FileTypeDenoJSON:
+		profile.Tools = append(profile.Tools, "deno")
+		profile.Packages = append(profile.Packages, baseName)
+
 	// Python
 	case FileTypePyProject:
 		profile.Tools = append(profile.Tools, "poetry")
@@ -89,6 +114,9 @@ func detectToolsFromFile(fileType, baseName string, profile *ProjectProfile) {
 		profile.Packages = append(profile.Packages, baseName)
 	case FileTypeGoSum:
 		profile.Packages = append(profile.Packages, baseName)
+	case FileTypeGoWork:
+		profile.Tools = append(profile.Tools, "go")
+		profile.Packages = append(profile.Packages, baseName)
 
 	// Rust
 	case FileTypeCargoToml:
@@ -114,6 +142,10 @@ func detectToolsFromFile(fileType, baseName string, profile *ProjectProfile) {
 		profile.Packages = append(profile.Packages, baseName)
 	case FileTypeSettingsGradle:
 		profile.Packages = append(profile.Packages, baseName)
+	case FileTypeGradleWrapper:
+		profile.Tools = append(profile.Tools, "gradle")
+	case FileTypeMavenWrapper:
+		profile.Tools = append(profile.Tools, "maven")
 
 	// Docker
 	case FileTypeDockerfile:
@@ -127,12 +159,21 @@ func detectToolsFromFile(fileType, baseName string, profile *ProjectProfile) {
 	case FileTypeK8sManifest:
 		profile.Tools = append(profile.Tools, "kubernetes")
 		profile.Containers = append(profile.Containers, baseName)
+	case FileTypeHelmChart:
+		profile.Tools = append(profile.Tools, "helm")
+		profile.Containers = append(profile.Containers, baseName)
+
+	// Terraform
+	case FileTypeTerraform:
+		profile.Tools = append(profile.Tools, "terraform")
 
 	// Make/Build
 	case FileTypeMakefile:
 		profile.Tools = append(profile.Tools, "make")
 	case FileTypeCMakeLists:
 		profile.Tools = append(profile.Tools, "cmake")
+	case FileTypeTaskfile:
+		profile.Tools = append(profile.Tools, "task")
 
 	// Ruby
 	case FileTypeGemfile:
@@ -143,6 +184,13 @@ func detectToolsFromFile(fileType, baseName string, profile *ProjectProfile) {
 	case FileTypeComposerJSON:
 		profile.Tools = append(profile.Tools, "composer")
 		profile.Packages = append(profile.Packages, baseName)
+
+	// Elixir
+	case FileTypeMixExs:
+		profile.Tools = append(profile.Tools, "mix")
+		profile.Packages = append(profile.Packages, baseName)
+	case FileTypeMixLock:
+		profile.Packages = append(profile.Packages, baseName)
 	}
 }
 
@@ -154,9 +202,15 @@ func detectLanguages(files map[string][]string) []string {
 	if _, ok := files[FileTypePackageJSON]; ok {
 		languages["javascript"] = true
 	}
+	if _, ok := files[FileTypeDenoJSON]; ok {
+		languages["typescript"] = true
+	}
 	if _, ok := files[FileTypeGoMod]; ok {
 		languages["go"] = true
 	}
+	if _, ok := files[FileTypeGoWork]; ok {
+		languages["go"] = true
+	}
 	if _, ok := files[FileTypeCargoToml]; ok {
 		languages["rust"] = true
 	}
@@ -193,6 +247,9 @@ func detectLanguages(files map[string][]string) []string {
 	if _, ok := files[FileTypeComposerJSON]; ok {
 		languages["php"] = true
 	}
+	if _, ok := files[FileTypeMixExs]; ok {
+		languages["elixir"] = true
+	}
 
 	return mapKeysToSlice(languages)
 }
@@ -207,7 +264,8 @@ func determinePrimaryStack(profile *ProjectProfile) string {
 	if containsString(profile.Tools, "npm") ||
 		containsString(profile.Tools, "yarn") ||
 		containsString(profile.Tools, "pnpm") ||
-		containsString(profile.Tools, "bun") {
+		containsString(profile.Tools, "bun") ||
+		containsString(profile.Tools, "deno") {
 		return "node"
 	}
 	if containsString(profile.Tools, "go") {
@@ -234,6 +292,12 @@ func determinePrimaryStack(profile *ProjectProfile) string {
 	if containsString(profile.Tools, "composer") {
 		return "php"
 	}
+	if containsString(profile.Tools, "mix") {
+		return "elixir"
+	}
+	if containsString(profile.Tools, "cmake") {
+		return "cpp"
+	}
 	if containsString(profile.Tools, "kubernetes") {
 		return "kubernetes"
 	}
@@ -0,0 +1,60 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// go-task Taskfile parsing
+
+package scanner
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// taskfile is the part of a Taskfile.yml read for planning
+type taskfile struct {
+	Tasks map[string]yaml.Node `yaml:"tasks"`
+}
+
+// detectTasks returns the sorted task names of the root-level Taskfile, or
+// nil if there is none or it cannot be parsed. Internal tasks and tasks
+// from included Taskfiles ("docs:build") are left out.
+func detectTasks(result *ScanResult) []string {
+	for _, relPath := range result.ProjectFiles[FileTypeTaskfile] {
+		if strings.Contains(relPath, string(os.PathSeparator)) {
+			continue
+		}
+		return parseTaskNames([]byte(readRootFile(result.RootPath, relPath)))
+	}
+	return nil
+}
+
+// parseTaskNames returns the sorted names of the public tasks in a Taskfile
+func parseTaskNames(data []byte) []string {
+	var parsed taskfile
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil
+	}
+
+	var names []string
+	for name, node := range parsed.Tasks {
+		if strings.Contains(name, ":") || isInternalTask(&node) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isInternalTask reports whether a task has "internal: true", which hides
+// it from the task command line
+func isInternalTask(node *yaml.Node) bool {
+	var fields struct {
+		Internal bool `yaml:"internal"`
+	}
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	return node.Decode(&fields) == nil && fields.Internal
+}
@@ -0,0 +1,70 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Golden test pinning the JSON form of ScanResult
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
+
+// update rewrites the golden files: go test ./internal/scanner/tests -update
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestScanResultJSONGolden(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"README.md":          "# Demo\n\n## Installation\n\n```bash\ngo build ./...\n```\n\n## Usage\n\nRun `./demo`.\n",
+		"LICENSE":            "MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\n",
+		"go.mod":             "module example.com/demo\n\ngo 1.21\n",
+		"main.go":            "package main\n\nfunc main() {}\n",
+		"Dockerfile":         "FROM golang:1.21\n",
+		"testdata/input.txt": "fixture\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: root, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	// Pin the fields that vary between runs
+	result.ScanDuration = 42 * time.Millisecond
+	result.Errors = []error{errors.New("permission denied: secret/")}
+
+	got, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	got = bytes.ReplaceAll(got, []byte(root), []byte("/project"))
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "scan_result.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ScanResult JSON changed; if that is intended, run with -update and review the diff\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
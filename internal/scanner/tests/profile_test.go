@@ -434,6 +434,344 @@ func TestDetectSignals_UniqueValues(t *testing.T) {
 	}
 }
 
+func TestProjectProfile_Framework(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"django manage.py", map[string]string{"manage.py": "", "requirements.txt": "Django>=4.2"}, scanner.FrameworkDjango},
+		{"fastapi requirements", map[string]string{"requirements.txt": "fastapi==0.110\nuvicorn", "main.py": ""}, scanner.FrameworkFastAPI},
+		{"flask pyproject", map[string]string{"pyproject.toml": "[project]\ndependencies = [\"flask>=3\"]"}, scanner.FrameworkFlask},
+		{"fastapi app without manifest", map[string]string{"main.py": "from fastapi import FastAPI\n\napp = FastAPI()\n"}, scanner.FrameworkFastAPI},
+		{"flask extension only", map[string]string{"requirements.txt": "flask-cors\nrequests"}, ""},
+		{"rails gemfile", map[string]string{"Gemfile": "source 'https://rubygems.org'\ngem 'rails', '~> 7.1'"}, scanner.FrameworkRails},
+		{"rails bin", map[string]string{"Gemfile": "", "bin/rails": "#!/usr/bin/env ruby"}, scanner.FrameworkRails},
+		{"rails config", map[string]string{"Gemfile": "", "config/application.rb": "module Demo\nend\n"}, scanner.FrameworkRails},
+		{"nextjs config", map[string]string{"package.json": "{}", "next.config.mjs": "export default {}"}, scanner.FrameworkNextJS},
+		{"nextjs dependency", map[string]string{"package.json": `{"dependencies": {"next": "14.0.0", "react": "18"}}`}, scanner.FrameworkNextJS},
+		{"plain node", map[string]string{"package.json": `{"dependencies": {"express": "4"}}`}, ""},
+		{"phoenix mix.exs", map[string]string{"mix.exs": "defp deps do\n  [\n    {:phoenix, \"~> 1.7\"},\n    {:jason, \"~> 1.2\"}\n  ]\nend"}, scanner.FrameworkPhoenix},
+		{"plain mix project", map[string]string{"mix.exs": "defp deps do\n  [{:phoenix_html, \"~> 4.0\"}]\nend"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(name)), 0755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+				createFile(t, tmpDir, name, content)
+			}
+
+			result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			if result.Profile.Framework != tt.want {
+				t.Errorf("Framework = %q, want %q", result.Profile.Framework, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectProfile_KubernetesManifests(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "k8s"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	createFile(t, tmpDir, "k8s/deployment.yaml", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+	createFile(t, tmpDir, "config.yaml", "debug: true\n")
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	profile := result.Profile
+	if profile.Stack != "kubernetes" {
+		t.Errorf("Stack = %s, want kubernetes", profile.Stack)
+	}
+	if len(profile.Manifests) != 1 || profile.Manifests[0] != "k8s/deployment.yaml" {
+		t.Errorf("Manifests = %v, want [k8s/deployment.yaml]", profile.Manifests)
+	}
+}
+
+func TestProjectProfile_HelmAndTerraform(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "chart"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	createFile(t, tmpDir, "chart/Chart.yaml", "apiVersion: v2\nname: web\nversion: 0.1.0\n")
+	createFile(t, tmpDir, "main.tf", "terraform {}\n")
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if !result.HasProjectFile(scanner.FileTypeHelmChart) {
+		t.Error("Chart.yaml not detected")
+	}
+	if !result.HasProjectFile(scanner.FileTypeTerraform) {
+		t.Error("main.tf not detected")
+	}
+
+	tools := make(map[string]bool)
+	for _, tool := range result.Profile.Tools {
+		tools[tool] = true
+	}
+	for _, want := range []string{"helm", "terraform"} {
+		if !tools[want] {
+			t.Errorf("Tools = %v, missing %s", result.Profile.Tools, want)
+		}
+	}
+}
+
+func TestProjectProfile_Taskfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "go.mod", "module example.com/demo\n\ngo 1.21\n")
+	createFile(t, tmpDir, "Taskfile.yml", `version: '3'
+includes:
+  docs: ./docs
+tasks:
+  default:
+    cmds: [task: run]
+  build:
+    cmds: [go build ./...]
+  run: go run .
+  generate:
+    internal: true
+    cmds: [go generate ./...]
+`)
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !result.HasProjectFile(scanner.FileTypeTaskfile) {
+		t.Error("Taskfile.yml not detected")
+	}
+	if got := strings.Join(result.Profile.Tasks, ","); got != "build,default,run" {
+		t.Errorf("Tasks = %v, want [build default run]", result.Profile.Tasks)
+	}
+	found := false
+	for _, tool := range result.Profile.Tools {
+		found = found || tool == "task"
+	}
+	if !found {
+		t.Errorf("Tools = %v, missing task", result.Profile.Tools)
+	}
+}
+
+func TestProjectProfile_Procfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "requirements.txt", "gunicorn\n")
+	createFile(t, tmpDir, "Procfile", "# processes\nweb: gunicorn app:app --bind 0.0.0.0:$PORT\nworker:  celery -A app worker\n\nnot a process\n")
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !result.HasProjectFile(scanner.FileTypeProcfile) {
+		t.Error("Procfile not detected")
+	}
+	processes := result.Profile.Processes
+	if len(processes) != 2 {
+		t.Fatalf("Processes = %v, want web and worker", processes)
+	}
+	if processes["web"] != "gunicorn app:app --bind 0.0.0.0:$PORT" {
+		t.Errorf("web = %q", processes["web"])
+	}
+	if processes["worker"] != "celery -A app worker" {
+		t.Errorf("worker = %q", processes["worker"])
+	}
+}
+
+func TestProjectProfile_CMake(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "CMakeLists.txt", `cmake_minimum_required(VERSION 3.16)
+project(demo VERSION 1.0 DESCRIPTION "A C tool" LANGUAGES C)
+
+add_executable(demo src/main.c)
+# add_executable(old src/old.c)
+add_executable(
+  demo-bench bench/main.c
+)
+add_executable(zlib::tool IMPORTED)
+`)
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Profile.Stack != "cpp" {
+		t.Errorf("Stack = %q, want cpp", result.Profile.Stack)
+	}
+	if len(result.Profile.Languages) != 1 || result.Profile.Languages[0] != "c" {
+		t.Errorf("Languages = %v, want [c]", result.Profile.Languages)
+	}
+	if got := strings.Join(result.Profile.Executables, ","); got != "demo,demo-bench" {
+		t.Errorf("Executables = %v, want demo and demo-bench", result.Profile.Executables)
+	}
+
+	// project() without LANGUAGES enables C and C++
+	tmpDir = t.TempDir()
+	createFile(t, tmpDir, "CMakeLists.txt", "project(app)\nadd_library(core core.cpp)\n")
+	result, err = scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if got := strings.Join(result.Profile.Languages, ","); got != "c,cpp" {
+		t.Errorf("Languages = %v, want c and cpp", result.Profile.Languages)
+	}
+	if len(result.Profile.Executables) != 0 {
+		t.Errorf("Executables = %v, want none", result.Profile.Executables)
+	}
+}
+
+func TestProjectProfile_JavaWrappers(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "build.gradle", "plugins { id 'java' }\n")
+	createFile(t, tmpDir, "gradlew", "#!/bin/sh\n")
+	createFile(t, tmpDir, "mvnw", "#!/bin/sh\n")
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if !result.HasProjectFile(scanner.FileTypeGradleWrapper) {
+		t.Error("gradlew not detected")
+	}
+	if !result.HasProjectFile(scanner.FileTypeMavenWrapper) {
+		t.Error("mvnw not detected")
+	}
+	for _, want := range []string{"gradlew", "mvnw"} {
+		if !containsString(result.Profile.Signals, want) {
+			t.Errorf("Signals = %v, missing %s", result.Profile.Signals, want)
+		}
+	}
+	if result.Profile.Stack != "java" {
+		t.Errorf("Stack = %q, want java", result.Profile.Stack)
+	}
+}
+
+func TestProjectProfile_ASGIApp(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantApp  string
+		wantFram string
+	}{
+		{"fastapi main", "main.py", "from fastapi import FastAPI\napi: FastAPI = FastAPI(title=\"x\")\n", "main:api", scanner.FrameworkFastAPI},
+		{"starlette app", "app.py", "from starlette.applications import Starlette\napp = Starlette(routes=[])\n", "app:app", ""},
+		{"plain script", "main.py", "print('hello')\n", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			createFile(t, tmpDir, tt.file, tt.content)
+
+			result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			if result.Profile.ASGIApp != tt.wantApp {
+				t.Errorf("ASGIApp = %q, want %q", result.Profile.ASGIApp, tt.wantApp)
+			}
+			if result.Profile.Framework != tt.wantFram {
+				t.Errorf("Framework = %q, want %q", result.Profile.Framework, tt.wantFram)
+			}
+		})
+	}
+}
+
+func TestProjectProfile_License(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantSPDX string
+	}{
+		{"mit", "LICENSE", "MIT License\n\nCopyright (c) 2026 Example\n\nPermission is hereby granted, free of charge,\nto any person obtaining a copy\n", "MIT"},
+		{"apache", "LICENSE.txt", "                                 Apache License\n                           Version 2.0, January 2004\n", "Apache-2.0"},
+		{"gpl-3", "COPYING", "                    GNU GENERAL PUBLIC LICENSE\n                       Version 3, 29 June 2007\n", "GPL-3.0"},
+		{"lgpl wrapped", "COPYING.md", "GNU LESSER GENERAL\nPUBLIC LICENSE Version 2.1, February 1999\n", "LGPL-2.1"},
+		{"bsd-3", "LICENSE.md", "Redistribution and use in source and binary forms, with or without\nmodification, are permitted...\n3. Neither the name of the copyright holder\n", "BSD-3-Clause"},
+		{"spdx tag", "LICENCE", "SPDX-License-Identifier: MPL-2.0\n", "MPL-2.0"},
+		{"unknown", "LICENSE", "All rights reserved.\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			createFile(t, tmpDir, tt.file, tt.content)
+
+			result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			if result.License == nil {
+				t.Fatal("License not detected")
+			}
+			if result.License.RelPath != tt.file {
+				t.Errorf("License.RelPath = %q, want %q", result.License.RelPath, tt.file)
+			}
+			if result.License.SPDX != tt.wantSPDX {
+				t.Errorf("License.SPDX = %q, want %q", result.License.SPDX, tt.wantSPDX)
+			}
+			if result.Profile.License != tt.wantSPDX {
+				t.Errorf("Profile.License = %q, want %q", result.Profile.License, tt.wantSPDX)
+			}
+		})
+	}
+}
+
+func TestProjectProfile_NestedLicenseIgnored(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "vendor", "lib"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	createFile(t, tmpDir, "vendor/lib/LICENSE", "MIT License\n")
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.License != nil {
+		t.Errorf("License = %+v, want nil for a nested license file", result.License)
+	}
+}
+
+func TestFindSubprojects(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"frontend", "backend", "docs", "node_modules/pkg", ".github"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	createFile(t, tmpDir, "frontend/package.json", "{}")
+	createFile(t, tmpDir, "backend/go.mod", "module example.com/backend\n")
+	createFile(t, tmpDir, "docs/index.md", "# Docs\n")
+	createFile(t, tmpDir, "node_modules/package.json", "{}")
+	createFile(t, tmpDir, ".github/package.json", "{}")
+
+	subprojects, err := scanner.FindSubprojects(tmpDir)
+	if err != nil {
+		t.Fatalf("FindSubprojects() error = %v", err)
+	}
+	if strings.Join(subprojects, ",") != "backend,frontend" {
+		t.Errorf("FindSubprojects() = %v, want [backend frontend]", subprojects)
+	}
+}
+
 // Helper functions
 
 func createFile(t *testing.T, dir, name, content string) {
@@ -452,3 +790,96 @@ func containsString(slice []string, item string) bool {
 	}
 	return false
 }
+
+func TestProjectProfile_AssumeStack(t *testing.T) {
+	if stack, err := scanner.ParseStack(" Go "); err != nil || stack != "go" {
+		t.Errorf("ParseStack(Go) = %q, %v; want go", stack, err)
+	}
+	if _, err := scanner.ParseStack("cobol"); err == nil || !strings.Contains(err.Error(), "supported:") {
+		t.Errorf("ParseStack(cobol) error = %v, want one listing the supported stacks", err)
+	}
+
+	profile := &scanner.ProjectProfile{Stack: "node", Framework: scanner.FrameworkNextJS, Tools: []string{"go", "npm"}}
+	profile.AssumeStack("go")
+	if profile.Stack != "go" {
+		t.Errorf("Stack = %q, want go", profile.Stack)
+	}
+	if profile.Framework != "" {
+		t.Errorf("Framework = %q, want the node framework dropped", profile.Framework)
+	}
+	if !containsString(profile.Tools, "npm") {
+		t.Errorf("Tools = %v, want the detected tools kept", profile.Tools)
+	}
+
+	// A container stack keeps the app's framework
+	profile = &scanner.ProjectProfile{Stack: "python", Framework: scanner.FrameworkDjango}
+	profile.AssumeStack("docker")
+	if profile.Framework != scanner.FrameworkDjango {
+		t.Errorf("Framework = %q, want django kept for docker", profile.Framework)
+	}
+}
+
+func TestProjectProfile_DependencyDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "Dockerfile", "FROM node:20\n")
+	createFile(t, tmpDir, "package.json", "{}")
+	for _, dir := range []string{"node_modules", "web/node_modules"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		createFile(t, tmpDir, dir+"/package.json", "{}")
+	}
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	// Only the root's dependency directories count
+	if got := strings.Join(result.Profile.DependencyDirs, ","); got != "node_modules" {
+		t.Errorf("DependencyDirs = %v, want [node_modules]", result.Profile.DependencyDirs)
+	}
+	if result.HasProjectFile(scanner.FileTypeDockerignore) {
+		t.Error("expected no .dockerignore")
+	}
+
+	createFile(t, tmpDir, ".dockerignore", "node_modules\n")
+	result, err = scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !result.HasProjectFile(scanner.FileTypeDockerignore) || !containsString(result.Profile.Signals, ".dockerignore") {
+		t.Errorf("expected the .dockerignore signal, got %v", result.Profile.Signals)
+	}
+}
+
+func TestProjectProfile_GoWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	createFile(t, tmpDir, "go.work", `go 1.22
+
+use ./api // the HTTP API
+use (
+	./tools/cli
+	"./worker"
+	// ./old
+)
+
+replace example.com/x => ./x
+`)
+	for _, dir := range []string{"api", "tools/cli", "worker"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		createFile(t, tmpDir, dir+"/go.mod", "module example.com/"+filepath.Base(dir)+"\n")
+	}
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Profile.Stack != "go" {
+		t.Errorf("Stack = %q, want go", result.Profile.Stack)
+	}
+	if got := strings.Join(result.Profile.GoModules, ","); got != "api,tools/cli,worker" {
+		t.Errorf("GoModules = %v, want api, tools/cli and worker", result.Profile.GoModules)
+	}
+}
@@ -4,8 +4,11 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sony-level/readme-runner/internal/scanner"
@@ -543,3 +546,284 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+func TestParseReadme_CRLFAndBOM(t *testing.T) {
+	tmpDir := t.TempDir()
+	readmePath := filepath.Join(tmpDir, "README.md")
+	content := "\uFEFF# Test Project\r\n\r\n## Installation\r\n\r\n```bash\r\nnpm install\r\nnpm start\r\n```\r\n\r\n## Usage\rSee docs\r"
+	if err := os.WriteFile(readmePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	readme, err := scanner.ParseReadme(readmePath, "README.md")
+	if err != nil {
+		t.Fatalf("ParseReadme() error = %v", err)
+	}
+
+	if len(readme.Sections) != 3 || readme.Sections[0] != "Test Project" {
+		t.Errorf("Sections = %q, want [Test Project Installation Usage]", readme.Sections)
+	}
+	if !readme.HasInstall || !readme.HasUsage {
+		t.Error("Installation/Usage sections not detected")
+	}
+	if readme.CodeBlocks != 1 || readme.ShellCommands != 1 {
+		t.Errorf("CodeBlocks = %d, ShellCommands = %d, want 1, 1", readme.CodeBlocks, readme.ShellCommands)
+	}
+	if strings.ContainsAny(readme.Content, "\r\uFEFF") {
+		t.Errorf("Content not normalized: %q", readme.Content)
+	}
+
+	commands := scanner.GetShellCommands(readme.Content)
+	if len(commands) != 2 || commands[0] != "npm install" || commands[1] != "npm start" {
+		t.Errorf("commands = %q, want [npm install npm start]", commands)
+	}
+}
+
+func TestGetShellCommands_CRLF(t *testing.T) {
+	content := "\uFEFF```bash\r\n$ npm install\r\nnpm run build\r\n```\r\n"
+
+	commands := scanner.GetShellCommands(content)
+
+	if len(commands) != 2 || commands[0] != "npm install" || commands[1] != "npm run build" {
+		t.Errorf("commands = %q, want [npm install npm run build]", commands)
+	}
+}
+
+func TestGetShellCommands_ContinuationsAndHeredocs(t *testing.T) {
+	content := "```bash\n" +
+		"$ docker run \\\n" +
+		"    -p 8080:80 \\\n" +
+		"    nginx\n" +
+		"cat > .env <<'EOF'\n" +
+		"PORT=3000\n" +
+		"# not a comment line to skip\n" +
+		"EOF\n" +
+		"npm ci && npm run build\n" +
+		"make deps &&\n" +
+		"  make install\n" +
+		"cat <<-END\n" +
+		"\tbody\n" +
+		"\tEND\n" +
+		"echo done\n" +
+		"grep -c x <<< \"$text\"\n" +
+		"bc <<<EOF\n" +
+		"echo $((mask<<shift))\n" +
+		"echo last\n" +
+		"```\n"
+
+	commands := scanner.GetShellCommands(content)
+
+	want := []string{
+		"docker run -p 8080:80 nginx",
+		"cat > .env <<'EOF'",
+		"npm ci && npm run build",
+		"make deps && make install",
+		"cat <<-END",
+		"echo done",
+		"grep -c x <<< \"$text\"",
+		"bc <<<EOF",
+		"echo $((mask<<shift))",
+		"echo last",
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("commands = %q, want %q", commands, want)
+	}
+	for i := range want {
+		if commands[i] != want[i] {
+			t.Errorf("commands[%d] = %q, want %q", i, commands[i], want[i])
+		}
+	}
+}
+
+func TestReadmeFindCommand(t *testing.T) {
+	readme := &scanner.ReadmeInfo{
+		Content: "# Demo\n\nRun npm test before opening a PR.\n\n## Testing\n\n" +
+			"```bash\nnpm test -- --watch\n$ npm test\n```\n\n```python\nmake lint\n```\n",
+	}
+
+	tests := []struct {
+		cmd     string
+		line    int
+		section string
+		ok      bool
+	}{
+		{"npm test", 9, "Testing", true},
+		{"npm test && npm run lint", 9, "Testing", true},
+		{"make lint", 0, "", false},
+		{"npm", 0, "", false},
+	}
+	for _, tt := range tests {
+		source, ok := readme.FindCommand(tt.cmd)
+		if ok != tt.ok || source.Line != tt.line || source.Section != tt.section {
+			t.Errorf("FindCommand(%q) = %+v, %v, want line %d in %q, %v", tt.cmd, source, ok, tt.line, tt.section, tt.ok)
+		}
+	}
+}
+
+func TestExtractCodeBlocks_ShellDetection(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string
+		want  bool
+	}{
+		{"sh-session tag", "```sh-session\n$ make\n```\n", true},
+		{"tag with attributes", "```bash title=\"install\"\nmake\n```\n", true},
+		{"pwsh tag", "```pwsh\nInstall-Module Foo\n```\n", true},
+		{"untagged prompt", "```\n$ ./configure\n$ make\n```\n", true},
+		{"untagged known command", "```\nnpm install\nnpm start\n```\n", true},
+		{"untagged config", "```\nport: 8080\nhost: localhost\n```\n", false},
+		{"untagged mostly output", "```\ngo test ./...\nok  pkg  0.1s\nok  pkg2  0.2s\n```\n", false},
+		{"python tag", "```python\nimport os\n```\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := scanner.ExtractCodeBlocks(tt.block)
+			if len(blocks) != 1 {
+				t.Fatalf("len(blocks) = %d, want 1", len(blocks))
+			}
+			if blocks[0].IsShell != tt.want {
+				t.Errorf("IsShell = %v, want %v", blocks[0].IsShell, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReadme_CountsUntaggedShellBlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	readmePath := filepath.Join(tmpDir, "README.md")
+	content := "# Test\n\n```\n$ npm install\n```\n\n```console\n$ npm start\n```\n\n```\nkey = value\n```\n"
+	if err := os.WriteFile(readmePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	readme, err := scanner.ParseReadme(readmePath, "README.md")
+	if err != nil {
+		t.Fatalf("ParseReadme() error = %v", err)
+	}
+	if readme.CodeBlocks != 3 || readme.ShellCommands != 2 {
+		t.Errorf("CodeBlocks = %d, ShellCommands = %d, want 3, 2", readme.CodeBlocks, readme.ShellCommands)
+	}
+}
+
+func TestFindSections(t *testing.T) {
+	content := "# Project\nIntro\n## Installation\nrun this\n### From source\n```bash\n# not a header\nmake\n```\n## Usage\nuse it\n"
+
+	sections := scanner.FindSections(content)
+
+	if len(sections) != 4 {
+		t.Fatalf("len(sections) = %d, want 4", len(sections))
+	}
+	install := sections[1]
+	if install.Title != "Installation" || install.Level != 2 || install.Kind != scanner.SectionInstall {
+		t.Errorf("sections[1] = %+v, want Installation level 2 install", install)
+	}
+	readme := &scanner.ReadmeInfo{Content: content}
+	if got := readme.SectionContent(install); got != "## Installation\nrun this\n### From source\n```bash\n# not a header\nmake\n```\n" {
+		t.Errorf("Installation content = %q", got)
+	}
+	if sections[3].Kind != scanner.SectionUsage || sections[3].End != len(content) {
+		t.Errorf("sections[3] = %+v, want usage section ending at EOF", sections[3])
+	}
+	if sections[0].End != len(content) {
+		t.Errorf("level-1 section should span the document, End = %d", sections[0].End)
+	}
+}
+
+func TestScanWithContext_Cancelled(t *testing.T) {
+	tmpDir := createTestProject(t)
+	defer os.RemoveAll(tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := scanner.ScanWithContext(ctx, &scanner.ScanConfig{RootPath: tmpDir})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanWithContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestScan_ExcludeDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                         "module example.com/app\n",
+		"examples/web/package.json":      "{}",
+		"docs/demo/Cargo.toml":           "[package]\n",
+		"docs/guide/requirements.txt":    "flask\n",
+		"testdata/fixture/composer.json": "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The defaults skip testdata
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: tmpDir})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.HasProjectFile(scanner.FileTypeComposerJSON) {
+		t.Error("testdata/ should be excluded by default")
+	}
+	if len(result.ExcludedDirs) != 1 || result.ExcludedDirs[0] != "testdata" {
+		t.Errorf("ExcludedDirs = %v, want [testdata]", result.ExcludedDirs)
+	}
+
+	// A name matches at any depth, a path only from the root
+	result, err = scanner.Scan(&scanner.ScanConfig{
+		RootPath:    tmpDir,
+		ExcludeDirs: []string{"web", "./docs/demo/"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.HasProjectFile(scanner.FileTypePackageJSON) {
+		t.Error("examples/web should be excluded by name")
+	}
+	if result.HasProjectFile(scanner.FileTypeCargoToml) {
+		t.Error("docs/demo should be excluded by path")
+	}
+	if !result.HasProjectFile(scanner.FileTypeRequirements) {
+		t.Error("docs/guide should still be scanned")
+	}
+	if !result.HasProjectFile(scanner.FileTypeComposerJSON) {
+		t.Error("an explicit ExcludeDirs replaces the defaults")
+	}
+}
+
+func TestScan_EnvExample(t *testing.T) {
+	root := t.TempDir()
+	content := "# Database\nDATABASE_URL=postgres://localhost/app\nexport SECRET_KEY=\nAPI_TOKEN=\"\" # from the dashboard\nDEBUG=true # local only\nnot a variable\n"
+	if err := os.WriteFile(filepath.Join(root, ".env.example"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", ".env.sample"), []byte("OTHER=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.Scan(&scanner.ScanConfig{RootPath: root, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	env := result.EnvExample
+	if env == nil {
+		t.Fatal("expected the root .env.example to be detected")
+	}
+	if env.RelPath != ".env.example" {
+		t.Errorf("RelPath = %q, want .env.example", env.RelPath)
+	}
+	if strings.Join(env.Vars, ",") != "DATABASE_URL,SECRET_KEY,API_TOKEN,DEBUG" {
+		t.Errorf("Vars = %v", env.Vars)
+	}
+	if strings.Join(env.Empty, ",") != "SECRET_KEY,API_TOKEN" {
+		t.Errorf("Empty = %v, want [SECRET_KEY API_TOKEN]", env.Empty)
+	}
+}
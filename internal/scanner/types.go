@@ -4,6 +4,7 @@
 package scanner
 
 import (
+	"encoding/json"
 	"sort"
 	"time"
 )
@@ -14,8 +15,9 @@ const (
 	FileTypeReadme = "README.md"
 
 	// Docker
-	FileTypeDockerfile = "Dockerfile"
-	FileTypeCompose    = "docker-compose"
+	FileTypeDockerfile   = "Dockerfile"
+	FileTypeCompose      = "docker-compose"
+	FileTypeDockerignore = ".dockerignore"
 
 	// Node.js
 	FileTypePackageJSON  = "package.json"
@@ -24,6 +26,9 @@ const (
 	FileTypePnpmLock     = "pnpm-lock.yaml"
 	FileTypeBunLock      = "bun.lockb"
 
+	// Deno
+	FileTypeDenoJSON = "deno.json"
+
 	// Python
 	FileTypePyProject    = "pyproject.toml"
 	FileTypeRequirements = "requirements.txt"
@@ -41,8 +46,9 @@ const (
 	FileTypePyDunder   = "__main__.py"  // Package entry point
 
 	// Go
-	FileTypeGoMod = "go.mod"
-	FileTypeGoSum = "go.sum"
+	FileTypeGoMod  = "go.mod"
+	FileTypeGoSum  = "go.sum"
+	FileTypeGoWork = "go.work"
 
 	// Rust
 	FileTypeCargoToml = "Cargo.toml"
@@ -59,17 +65,28 @@ const (
 	FileTypeBuildGradle = "build.gradle"
 	FileTypeGradleKts  = "build.gradle.kts"
 	FileTypeSettingsGradle = "settings.gradle"
+	FileTypeGradleWrapper = "gradlew"
+	FileTypeMavenWrapper  = "mvnw"
 
 	// Kubernetes
 	FileTypeK8sManifest = "k8s-manifest"
+	FileTypeHelmChart   = "Chart.yaml"
+
+	// Infrastructure as code
+	FileTypeTerraform = "tf"
 
 	// Make/Build
 	FileTypeMakefile   = "Makefile"
 	FileTypeCMakeLists = "CMakeLists.txt"
+	FileTypeTaskfile   = "Taskfile.yml" // go-task (Taskfile.yml or Taskfile.yaml)
+	FileTypeProcfile   = "Procfile"     // Heroku-style process types
 
 	// Other
 	FileTypeGemfile    = "Gemfile"
 	FileTypeComposerJSON = "composer.json"
+	FileTypeArtisan      = "artisan" // Laravel
+	FileTypeMixExs       = "mix.exs"
+	FileTypeMixLock      = "mix.lock"
 )
 
 // ScanConfig holds configuration for scanning
@@ -78,20 +95,64 @@ type ScanConfig struct {
 	MaxDepth    int    // Maximum directory depth (default: 3)
 	FollowLinks bool   // Follow symbolic links (default: false)
 	Verbose     bool   // Enable verbose output
+
+	// ExcludeDirs are pruned during the walk: a name matches at any depth,
+	// a path with a slash (e.g. "docs/examples") from the root.
+	// nil means DefaultExcludeDirs.
+	ExcludeDirs []string
 }
 
-// ScanResult contains all detected files and metadata
+// ScanResult contains all detected files and metadata.
+//
+// Its JSON form ('rdr scan --json') is part of rdr's interface for other
+// tools: fields are only ever added. ScanDuration is encoded as
+// scan_duration_ms and Errors as messages (see MarshalJSON).
 type ScanResult struct {
-	RootPath        string              // Scanned root path
-	ReadmeFile      *ReadmeInfo         // Primary README.md info
-	ProjectFiles    map[string][]string // Map of file type to paths
-	TotalFiles      int                 // Total files scanned
-	TotalDirs       int                 // Total directories scanned
-	ScanDuration    time.Duration       // Time taken to scan
-	Errors          []error             // Non-fatal errors during scan
-	PackageManagers []string            // Detected package managers
-	BuildTools      []string            // Detected build tools
-	Profile         *ProjectProfile     // Project profile with signals
+	RootPath        string              `json:"root"`             // Scanned root path
+	ReadmeFile      *ReadmeInfo         `json:"readme"`           // Primary README.md info (null if none)
+	License         *LicenseInfo        `json:"license"`          // Root-level LICENSE/COPYING file (null if none)
+	EnvExample      *EnvExampleInfo     `json:"env_example"`      // Root-level .env.example/.env.sample (null if none)
+	ProjectFiles    map[string][]string `json:"project_files"`    // Map of file type to paths
+	TotalFiles      int                 `json:"total_files"`      // Total files scanned
+	TotalDirs       int                 `json:"total_dirs"`       // Total directories scanned
+	ScanDuration    time.Duration       `json:"-"`                // Time taken to scan
+	Errors          []error             `json:"-"`                // Non-fatal errors during scan
+	PackageManagers []string            `json:"package_managers"` // Detected package managers
+	BuildTools      []string            `json:"build_tools"`      // Detected build tools
+	Profile         *ProjectProfile     `json:"profile"`          // Project profile with signals
+	ExcludedDirs    []string            `json:"excluded_dirs"`    // Directories pruned by ExcludeDirs (relative, slash-separated)
+	DependencyDirs  []string            `json:"dependency_dirs"`  // Root-level installed dependencies and build output (node_modules, target, ...), not scanned
+}
+
+// MarshalJSON encodes the duration in milliseconds and the errors as
+// messages. Empty lists are encoded as [], not null.
+func (r ScanResult) MarshalJSON() ([]byte, error) {
+	type plain ScanResult
+	errs := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err.Error())
+	}
+	if r.ProjectFiles == nil {
+		r.ProjectFiles = map[string][]string{}
+	}
+	r.PackageManagers = nonNil(r.PackageManagers)
+	r.BuildTools = nonNil(r.BuildTools)
+	r.ExcludedDirs = nonNil(r.ExcludedDirs)
+	r.DependencyDirs = nonNil(r.DependencyDirs)
+
+	return json.Marshal(struct {
+		plain
+		ScanDurationMs int64    `json:"scan_duration_ms"`
+		Errors         []string `json:"errors"`
+	}{plain(r), r.ScanDuration.Milliseconds(), errs})
+}
+
+// nonNil returns s, or an empty slice for nil so it encodes as []
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
 }
 
 // ProjectProfile contains project metadata for AI processing
@@ -104,23 +165,53 @@ type ProjectProfile struct {
 	Stack      string   `json:"stack"`      // Primary technology stack
 	Containers []string `json:"containers"` // Container/orchestration files
 	Packages   []string `json:"packages"`   // Package manifest files
+	Framework  string   `json:"framework,omitempty"` // Web framework (django, flask, fastapi, rails, nextjs, phoenix)
+	ASGIApp    string   `json:"asgi_app,omitempty"`  // uvicorn import string, e.g. "main:app"
+	Manifests  []string `json:"manifests,omitempty"` // Kubernetes manifest paths (relative to root)
+	License    string   `json:"license,omitempty"`   // SPDX identifier of the root license file
+	Tasks      []string `json:"tasks,omitempty"`     // go-task task names from the root Taskfile
+	Processes  map[string]string `json:"processes,omitempty"` // Procfile commands by process type (web, worker, ...)
+	Executables []string `json:"executables,omitempty"` // add_executable targets of the root CMakeLists.txt
+	DependencyDirs []string `json:"dependency_dirs,omitempty"` // Root-level node_modules, target, ... (see ScanResult.DependencyDirs)
+	GoModules  []string `json:"go_modules,omitempty"` // Modules the root go.work uses (slash-separated dirs, "." for the root)
 }
 
-// ReadmeInfo contains README.md metadata
+// ReadmeInfo contains README.md metadata. Its JSON form leaves out the
+// absolute path and the content; the Has* flags and block counts are the
+// inputs of the README clarity score.
 type ReadmeInfo struct {
-	Path          string   // Absolute path to README
-	RelPath       string   // Relative path from root
-	Size          int64    // File size in bytes
-	Content       string   // Full content of README (for AI)
-	Sections      []string // Detected section headers
-	HasInstall    bool     // Has installation section
-	HasUsage      bool     // Has usage section
-	HasBuild      bool     // Has build section
-	HasQuickStart bool     // Has quick start section
-	CodeBlocks    int      // Number of code blocks
-	ShellCommands int      // Number of shell command blocks
-	Truncated     bool     // Whether content was truncated
-	OriginalSize  int64    // Original size before truncation
+	Path          string         `json:"-"`               // Absolute path to README
+	RelPath       string         `json:"path"`            // Relative path from root
+	Size          int64          `json:"size"`            // File size in bytes
+	Content       string         `json:"-"`               // Full content of README (for AI)
+	Sections      []string       `json:"sections"`        // Detected section headers
+	SectionRanges []SectionRange `json:"section_ranges"`  // Where each section lies in Content
+	HasInstall    bool           `json:"has_install"`     // Has installation section
+	HasUsage      bool           `json:"has_usage"`       // Has usage section
+	HasBuild      bool           `json:"has_build"`       // Has build section
+	HasQuickStart bool           `json:"has_quick_start"` // Has quick start section
+	CodeBlocks    int            `json:"code_blocks"`     // Number of code blocks
+	ShellCommands int            `json:"shell_commands"`  // Number of shell command blocks
+	Truncated     bool           `json:"truncated"`       // Whether content was truncated
+	OriginalSize  int64          `json:"original_size"`   // Original size before truncation
+}
+
+// SectionRange locates a README section in ReadmeInfo.Content. The range
+// covers the header line and the body, including subsections.
+type SectionRange struct {
+	Title string `json:"title"`
+	Level int    `json:"level"` // Header level (1-6)
+	Kind  string `json:"kind"`  // SectionInstall, SectionUsage, ... or "" for other sections
+	Start int    `json:"start"` // Byte offset of the header line
+	End   int    `json:"end"`   // Byte offset just past the section body
+}
+
+// SectionContent returns the text of a section range
+func (r *ReadmeInfo) SectionContent(section SectionRange) string {
+	if section.Start < 0 || section.End > len(r.Content) || section.Start > section.End {
+		return ""
+	}
+	return r.Content[section.Start:section.End]
 }
 
 // HasProjectFile checks if a specific file type was detected
@@ -162,8 +253,13 @@ func (r *ScanResult) DetectedStacks() []string {
 		}
 	}
 
+	// Deno
+	if r.HasProjectFile(FileTypeDenoJSON) {
+		stacks["deno"] = true
+	}
+
 	// Go
-	if r.HasProjectFile(FileTypeGoMod) {
+	if r.HasProjectFile(FileTypeGoMod) || r.HasProjectFile(FileTypeGoWork) {
 		stacks["go"] = true
 	}
 
@@ -207,6 +303,14 @@ func (r *ScanResult) DetectedStacks() []string {
 	if r.HasProjectFile(FileTypeK8sManifest) {
 		stacks["kubernetes"] = true
 	}
+	if r.HasProjectFile(FileTypeHelmChart) {
+		stacks["helm"] = true
+	}
+
+	// Terraform
+	if r.HasProjectFile(FileTypeTerraform) {
+		stacks["terraform"] = true
+	}
 
 	// Ruby
 	if r.HasProjectFile(FileTypeGemfile) {
@@ -220,6 +324,12 @@ func (r *ScanResult) DetectedStacks() []string {
 		stacks["composer"] = true
 	}
 
+	// Elixir
+	if r.HasProjectFile(FileTypeMixExs) {
+		stacks["elixir"] = true
+		stacks["mix"] = true
+	}
+
 	// Convert map to sorted slice
 	result := make([]string, 0, len(stacks))
 	for stack := range stacks {
@@ -66,18 +66,20 @@ func (c *PolicyChecker) ValidatePlan(plan *llm.RunPlan) *ValidationResult {
 
 		// Add warnings
 		for _, w := range analysis.Warnings {
-			result.AddWarning(fmt.Sprintf("Step %d (%s): %s", stepNum, step.ID, w))
+			w.Message = fmt.Sprintf("Step %d (%s): %s", stepNum, step.ID, w.Message)
+			result.AddWarning(w)
 		}
 
 		// Check sudo consistency
 		if analysis.RequiresSudo && !step.RequiresSudo {
-			result.AddWarning(fmt.Sprintf("Step %d (%s): command uses sudo but requires_sudo is false", stepNum, step.ID))
+			result.AddWarning(NewWarning(CodeSudoMismatch, SeverityWarning,
+				"Step %d (%s): command uses sudo but requires_sudo is false", stepNum, step.ID))
 		}
 
 		// Check risk consistency
 		if analysis.Risk != step.Risk {
-			result.AddWarning(fmt.Sprintf("Step %d (%s): detected risk '%s' differs from declared '%s'",
-				stepNum, step.ID, analysis.Risk, step.Risk))
+			result.AddWarning(NewWarning(CodeRiskDivergence, SeverityInfo,
+				"Step %d (%s): detected risk '%s' differs from declared '%s'", stepNum, step.ID, analysis.Risk, step.Risk))
 		}
 	}
 
@@ -89,7 +91,8 @@ func (c *PolicyChecker) ValidatePlan(plan *llm.RunPlan) *ValidationResult {
 			strings.Contains(lowerKey, "token") ||
 			strings.Contains(lowerKey, "api_key") ||
 			strings.Contains(lowerKey, "apikey") {
-			result.AddWarning(fmt.Sprintf("Environment variable '%s' may contain sensitive data", key))
+			result.AddWarning(NewWarning(CodeSensitiveEnv, SeverityWarning,
+				"Environment variable '%s' may contain sensitive data", key))
 		}
 	}
 
@@ -107,6 +110,7 @@ func (c *PolicyChecker) AnalyzeCommand(cmd string) *CommandAnalysis {
 		if strings.Contains(lowerCmd, strings.ToLower(blocked)) {
 			analysis.IsBlocked = true
 			analysis.BlockReason = fmt.Sprintf("matches blocked pattern: %s", blocked)
+			analysis.Categories = append(analysis.Categories, "blocked command")
 			analysis.Risk = llm.RiskCritical
 			return analysis
 		}
@@ -116,21 +120,42 @@ func (c *PolicyChecker) AnalyzeCommand(cmd string) *CommandAnalysis {
 	if c.detectsSudo(cmd) {
 		analysis.RequiresSudo = true
 		analysis.Risk = llm.RiskCritical
-		analysis.Warnings = append(analysis.Warnings, "command requires sudo privileges")
+		analysis.Categories = append(analysis.Categories, "sudo")
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodeSudo, SeverityHigh, "command requires sudo privileges"))
 	}
 
 	// Detect package managers (high risk)
 	if c.detectsPackageManager(cmd) {
-		if analysis.Risk < llm.RiskHigh {
+		analysis.Categories = append(analysis.Categories, "system package manager")
+		if analysis.Risk.Rank() < llm.RiskHigh.Rank() {
 			analysis.Risk = llm.RiskHigh
 		}
-		analysis.Warnings = append(analysis.Warnings, "system package manager command")
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodePackageManager, SeverityWarning, "system package manager command"))
+	}
+
+	// Detect cluster/infrastructure changes (high risk)
+	if c.detectsClusterMutation(cmd) {
+		analysis.Categories = append(analysis.Categories, "cluster change")
+		if analysis.Risk.Rank() < llm.RiskHigh.Rank() {
+			analysis.Risk = llm.RiskHigh
+		}
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodeClusterMutation, SeverityWarning, "modifies cluster resources in the current kube-context"))
+	}
+
+	// Detect database changes (medium risk)
+	if c.detectsDatabaseMutation(cmd) {
+		analysis.Categories = append(analysis.Categories, "database change")
+		if analysis.Risk.Rank() < llm.RiskMedium.Rank() {
+			analysis.Risk = llm.RiskMedium
+		}
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodeDatabase, SeverityWarning, "modifies the application database"))
 	}
 
 	// Detect remote scripts (critical risk)
 	if c.detectsRemoteScript(cmd) {
+		analysis.Categories = append(analysis.Categories, "remote script")
 		analysis.Risk = llm.RiskCritical
-		analysis.Warnings = append(analysis.Warnings, "remote script execution detected")
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodeRemoteScript, SeverityHigh, "remote script execution detected"))
 		if !c.isWhitelistedURL(cmd) {
 			analysis.IsBlocked = true
 			analysis.BlockReason = "remote script from non-whitelisted URL"
@@ -139,35 +164,31 @@ func (c *PolicyChecker) AnalyzeCommand(cmd string) *CommandAnalysis {
 
 	// Detect file modifications (medium risk)
 	if c.detectsFileModification(cmd) {
-		if analysis.Risk < llm.RiskMedium {
+		analysis.Categories = append(analysis.Categories, "file modification")
+		if analysis.Risk.Rank() < llm.RiskMedium.Rank() {
 			analysis.Risk = llm.RiskMedium
 		}
 	}
 
 	// Detect system directory access
 	if c.detectsSystemDirectoryAccess(cmd) {
-		if analysis.Risk < llm.RiskHigh {
+		analysis.Categories = append(analysis.Categories, "system directories")
+		if analysis.Risk.Rank() < llm.RiskHigh.Rank() {
 			analysis.Risk = llm.RiskHigh
 		}
-		analysis.Warnings = append(analysis.Warnings, "accesses system directories")
+		analysis.Warnings = append(analysis.Warnings, NewWarning(CodeSystemDirs, SeverityWarning, "accesses system directories"))
 	}
 
 	return analysis
 }
 
+// sudoPattern matches sudo as a command word anywhere in a command line:
+// at the start, after a separator (&&, ||, ;, |), in a subshell or $(...)
+var sudoPattern = regexp.MustCompile("(?:^|[\\s;&|(`])sudo(?:\\s|$)")
+
 // detectsSudo checks if command requires sudo
 func (c *PolicyChecker) detectsSudo(cmd string) bool {
-	// Direct sudo usage
-	if strings.HasPrefix(cmd, "sudo ") || strings.Contains(cmd, " sudo ") {
-		return true
-	}
-
-	// Piped sudo
-	if strings.Contains(cmd, "| sudo") || strings.Contains(cmd, "|sudo") {
-		return true
-	}
-
-	return false
+	return sudoPattern.MatchString(cmd)
 }
 
 // detectsPackageManager checks for system package manager commands
@@ -194,6 +215,34 @@ func (c *PolicyChecker) detectsPackageManager(cmd string) bool {
 	return false
 }
 
+// detectsClusterMutation checks for commands that change cluster state
+func (c *PolicyChecker) detectsClusterMutation(cmd string) bool {
+	mutations := []string{
+		"kubectl apply", "kubectl create", "kubectl delete",
+		"kubectl replace", "kubectl patch", "kubectl scale",
+		"kubectl rollout",
+		"helm install", "helm upgrade", "helm uninstall", "helm rollback",
+	}
+
+	lowerCmd := strings.ToLower(cmd)
+	for _, m := range mutations {
+		if strings.Contains(lowerCmd, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dbTaskPattern matches Rails/Rake database tasks (db:setup, db:migrate, ...)
+var dbTaskPattern = regexp.MustCompile(`(?i)\b(rails|rake)\s+db:\w+`)
+
+// detectsDatabaseMutation checks for commands that create, migrate or seed
+// the application database
+func (c *PolicyChecker) detectsDatabaseMutation(cmd string) bool {
+	return dbTaskPattern.MatchString(cmd)
+}
+
 // detectsRemoteScript checks for remote script execution patterns
 func (c *PolicyChecker) detectsRemoteScript(cmd string) bool {
 	patterns := []string{
@@ -0,0 +1,72 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Execution summary of what a plan changes on the host
+
+package security
+
+import (
+	"regexp"
+
+	"github.com/sony-level/readme-runner/internal/llm"
+)
+
+// networkPatterns match commands that download code or packages
+var networkPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`https?://`),
+	regexp.MustCompile(`\b(curl|wget)\s`),
+	regexp.MustCompile(`\bgit\s+(clone|fetch|pull)\b`),
+	regexp.MustCompile(`\b(docker|podman)\s+(pull|compose\s+pull)\b`),
+	regexp.MustCompile(`\b(npm|pnpm|bun)\s+(install|i|add|ci)\b`),
+	regexp.MustCompile(`\byarn(\s+(install|add)\b|\s*$)`),
+	regexp.MustCompile(`\b(pip3?|uv\s+pip)\s+install\b`),
+	regexp.MustCompile(`\b(poetry|pipenv|bundle|composer)\s+install\b`),
+	regexp.MustCompile(`\buv\s+sync\b`),
+	regexp.MustCompile(`\bgo\s+(mod\s+download|get|install)\b`),
+	regexp.MustCompile(`\bcargo\s+(fetch|install)\b`),
+	regexp.MustCompile(`\bmix\s+deps\.get\b`),
+	regexp.MustCompile(`\bdotnet\s+restore\b`),
+}
+
+// detectsNetworkAccess checks for commands that download code or packages
+func detectsNetworkAccess(cmd string) bool {
+	for _, pattern := range networkPatterns {
+		if pattern.MatchString(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanSummary groups the steps of a plan by what they do outside the
+// project directory, for a confirmation before execution. Step lists hold
+// step IDs in plan order.
+type PlanSummary struct {
+	Steps               int      // Steps that will run
+	SudoSteps           []string // Run with sudo
+	PackageManagerSteps []string // System package manager (apt, brew, ...)
+	NetworkSteps        []string // Download code or packages
+	RemoteScriptSteps   []string // Pipe a downloaded script into a shell
+}
+
+// SummarizePlan analyzes the steps of a plan that will run (those not in
+// skip, e.g. completed in a resumed run)
+func (c *PolicyChecker) SummarizePlan(plan *llm.RunPlan, skip map[string]bool) *PlanSummary {
+	summary := &PlanSummary{}
+	for _, step := range plan.Steps {
+		if skip[step.ID] {
+			continue
+		}
+		summary.Steps++
+		if step.RequiresSudo || c.detectsSudo(step.Cmd) {
+			summary.SudoSteps = append(summary.SudoSteps, step.ID)
+		}
+		if c.detectsPackageManager(step.Cmd) {
+			summary.PackageManagerSteps = append(summary.PackageManagerSteps, step.ID)
+		}
+		if c.detectsRemoteScript(step.Cmd) {
+			summary.RemoteScriptSteps = append(summary.RemoteScriptSteps, step.ID)
+		} else if detectsNetworkAccess(step.Cmd) {
+			summary.NetworkSteps = append(summary.NetworkSteps, step.ID)
+		}
+	}
+	return summary
+}
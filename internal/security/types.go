@@ -56,7 +56,7 @@ func DefaultPolicy() *PolicyConfig {
 type ValidationResult struct {
 	Valid       bool
 	Errors      []string
-	Warnings    []string
+	Warnings    []Warning
 	RiskSummary map[llm.RiskLevel]int
 }
 
@@ -65,7 +65,7 @@ func NewValidationResult() *ValidationResult {
 	return &ValidationResult{
 		Valid:       true,
 		Errors:      []string{},
-		Warnings:    []string{},
+		Warnings:    []Warning{},
 		RiskSummary: make(map[llm.RiskLevel]int),
 	}
 }
@@ -77,8 +77,8 @@ func (r *ValidationResult) AddError(msg string) {
 }
 
 // AddWarning adds a warning
-func (r *ValidationResult) AddWarning(msg string) {
-	r.Warnings = append(r.Warnings, msg)
+func (r *ValidationResult) AddWarning(warning Warning) {
+	r.Warnings = append(r.Warnings, warning)
 }
 
 // CommandAnalysis contains risk analysis for a single command
@@ -88,7 +88,8 @@ type CommandAnalysis struct {
 	RequiresSudo bool
 	IsBlocked    bool
 	BlockReason  string
-	Warnings     []string
+	Categories   []string  // What the command was detected as (e.g. "sudo"), in check order
+	Warnings     []Warning // Messages without the step prefix
 }
 
 // NewCommandAnalysis creates a new command analysis
@@ -96,7 +97,7 @@ func NewCommandAnalysis(cmd string) *CommandAnalysis {
 	return &CommandAnalysis{
 		Command:  cmd,
 		Risk:     llm.RiskLow,
-		Warnings: []string{},
+		Warnings: []Warning{},
 	}
 }
 
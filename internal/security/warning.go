@@ -0,0 +1,46 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Validation warnings with stable codes and severities
+
+package security
+
+import "fmt"
+
+// Severity is how much attention a warning deserves
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"    // Worth knowing, usually harmless
+	SeverityWarning Severity = "warning" // Review before running
+	SeverityHigh    Severity = "high"    // Runs with privileges or code from the network
+)
+
+// Security warning codes. Codes are stable: tools and --suppress refer to
+// them, so a code is never reused for another check.
+const (
+	CodeRemoteScript    = "SEC001"  // Pipes a downloaded script into a shell
+	CodeSudoMismatch    = "SEC002"  // Uses sudo but requires_sudo is false
+	CodeSudo            = "SEC003"  // Requires sudo privileges
+	CodePackageManager  = "SEC004"  // System package manager command
+	CodeClusterMutation = "SEC005"  // Modifies cluster resources
+	CodeDatabase        = "SEC006"  // Modifies the application database
+	CodeSystemDirs      = "SEC007"  // Accesses system directories
+	CodeSensitiveEnv    = "SEC008"  // Env variable may hold a secret
+	CodeRiskDivergence  = "PLAN003" // Detected risk differs from the declared one
+)
+
+// Warning is a validation finding that does not block the plan
+type Warning struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// NewWarning creates a warning with a formatted message
+func NewWarning(code string, severity Severity, format string, args ...any) Warning {
+	return Warning{Code: code, Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// String returns the warning as "[CODE] message"
+func (w Warning) String() string {
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
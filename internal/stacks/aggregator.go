@@ -11,20 +11,35 @@ import (
 	"github.com/sony-level/readme-runner/internal/scanner"
 )
 
+// DefaultPriorityOverrideMargin is the override margin of NewAggregator: a
+// lone Dockerfile, e.g. one used only for CI, scores 0.2-0.3 and so does not
+// outrank a language stack with twice the evidence
+const DefaultPriorityOverrideMargin = 0.25
+
 // Aggregator runs all detectors and determines the dominant stack
 type Aggregator struct {
 	detectors []Detector
+
+	// minConfidence keeps weaker matches from becoming dominant (0 = off)
+	minConfidence float64
+	// overrideMargin lets a lower-priority match win when its confidence
+	// beats the priority winner by at least this much (0 = off)
+	overrideMargin float64
 }
 
-// NewAggregator creates a new aggregator with all detectors
+// NewAggregator creates a new aggregator with all detectors and the default
+// priority override margin (no confidence threshold)
 func NewAggregator() *Aggregator {
 	return &Aggregator{
+		overrideMargin: DefaultPriorityOverrideMargin,
 		detectors: []Detector{
 			NewDockerDetector(),
 			NewNodeDetector(),
 			NewPythonDetector(),
 			NewGoDetector(),
 			NewRustDetector(),
+			NewRubyDetector(),
+			NewCMakeDetector(),
 		},
 	}
 }
@@ -41,6 +56,18 @@ func (a *Aggregator) AddDetector(detector Detector) {
 	a.detectors = append(a.detectors, detector)
 }
 
+// SetMinConfidence sets the confidence a match needs to become dominant.
+// Weaker matches are still reported in Matches and AllStacks.
+func (a *Aggregator) SetMinConfidence(min float64) {
+	a.minConfidence = min
+}
+
+// SetPriorityOverrideMargin lets a lower-priority stack become dominant when
+// its confidence is higher than the priority winner's by at least margin
+func (a *Aggregator) SetPriorityOverrideMargin(margin float64) {
+	a.overrideMargin = margin
+}
+
 // Detect runs all detectors and returns the analysis
 func (a *Aggregator) Detect(profile *scanner.ProjectProfile) DetectionResult {
 	if profile == nil {
@@ -59,16 +86,39 @@ func (a *Aggregator) Detect(profile *scanner.ProjectProfile) DetectionResult {
 		}
 	}
 
-	// Sort by priority (descending) then by confidence (descending)
-	sort.Slice(matches, func(i, j int) bool {
+	// Sort by priority (descending) then by confidence (descending).
+	// The sort is stable so full ties keep detector registration order.
+	sort.SliceStable(matches, func(i, j int) bool {
 		if matches[i].Priority != matches[j].Priority {
 			return matches[i].Priority > matches[j].Priority
 		}
 		return matches[i].Confidence > matches[j].Confidence
 	})
 
+	// Only matches above the confidence threshold compete for dominance
+	candidates, weak := a.splitByConfidence(matches)
+	if len(candidates) == 0 {
+		candidates, weak = matches, nil
+	}
+
 	// Determine dominant stack
-	dominant, isMixed, explanation := a.determineDominant(matches)
+	dominant, isMixed, explanation := a.determineDominant(candidates)
+
+	if override, ok := a.confidenceOverride(candidates, dominant); ok {
+		explanation = fmt.Sprintf("Selected %s as dominant stack: its confidence %.2f beats %s (%.2f) by at least %.2f, overriding priority (%d vs %d)",
+			override.Name, override.Confidence, dominant.Name, dominant.Confidence,
+			a.overrideMargin, override.Priority, dominant.Priority)
+		dominant = override
+	}
+
+	if len(weak) > 0 {
+		names := make([]string, 0, len(weak))
+		for _, match := range weak {
+			names = append(names, fmt.Sprintf("%s (%.2f)", match.Name, match.Confidence))
+		}
+		explanation += fmt.Sprintf(" Not eligible as dominant, confidence below %.2f: %s.",
+			a.minConfidence, strings.Join(names, ", "))
+	}
 
 	// Extract all unique stack names
 	allStacks := make([]string, 0, len(matches))
@@ -85,6 +135,30 @@ func (a *Aggregator) Detect(profile *scanner.ProjectProfile) DetectionResult {
 	}
 }
 
+// AssumeStack makes stack the dominant match of a detection result
+// (--assume-stack), keeping its detected confidence and reasons when the
+// stack was detected at all
+func AssumeStack(result DetectionResult, stack string) DetectionResult {
+	if stack == "" {
+		return result
+	}
+	detected := result.Dominant.Name
+
+	dominant := StackMatch{Name: stack, Confidence: 1.0}
+	for _, match := range result.Matches {
+		if match.Name == stack {
+			dominant = match
+			break
+		}
+	}
+	dominant.Reasons = append(append([]string{}, dominant.Reasons...), "assumed with --assume-stack")
+
+	result.Dominant = dominant
+	result.IsMixed = false
+	result.Explanation = fmt.Sprintf("Using %s as assumed with --assume-stack (detected: %s)", stack, detected)
+	return result
+}
+
 // determineDominant selects the primary stack based on rules
 func (a *Aggregator) determineDominant(matches []StackMatch) (StackMatch, bool, string) {
 	if len(matches) == 0 {
@@ -102,8 +176,8 @@ func (a *Aggregator) determineDominant(matches []StackMatch) (StackMatch, bool,
 				lower := strings.ToLower(signal)
 				if strings.Contains(lower, "compose") {
 					return match, true,
-						fmt.Sprintf("Docker Compose detected, Docker is dominant (also found: %s)",
-							a.otherStackNames(matches, StackDocker))
+						fmt.Sprintf("Docker Compose detected, Docker is dominant regardless of priority and confidence (also found: %s). %s",
+							a.otherStackNames(matches, StackDocker), a.explainRanking(matches, match))
 				}
 			}
 		}
@@ -112,8 +186,8 @@ func (a *Aggregator) determineDominant(matches []StackMatch) (StackMatch, bool,
 	// Rule 2: If Dockerfile exists and has highest priority, Docker is dominant
 	if matches[0].Name == StackDocker {
 		return matches[0], len(matches) > 1,
-			fmt.Sprintf("Dockerfile present, Docker is dominant (also found: %s)",
-				a.otherStackNames(matches, StackDocker))
+			fmt.Sprintf("Dockerfile present, Docker is dominant (also found: %s). %s",
+				a.otherStackNames(matches, StackDocker), a.explainRanking(matches, matches[0]))
 	}
 
 	// Rule 3: Check if multiple stacks have same priority (mixed project)
@@ -140,10 +214,81 @@ func (a *Aggregator) determineDominant(matches []StackMatch) (StackMatch, bool,
 	} else if len(matches) > 1 {
 		explanation.WriteString(fmt.Sprintf(" (priority over: %s)", a.otherStackNames(matches, dominant.Name)))
 	}
+	explanation.WriteString(". ")
+	explanation.WriteString(a.explainRanking(matches, dominant))
 
 	return dominant, isMixed, explanation.String()
 }
 
+// explainRanking compares the dominant stack with the runner-up: their
+// confidences, priorities and the rule that put the dominant one first
+func (a *Aggregator) explainRanking(matches []StackMatch, dominant StackMatch) string {
+	var runnerUp StackMatch
+	for _, match := range matches {
+		if match.Name != dominant.Name {
+			runnerUp = match
+			break
+		}
+	}
+	if runnerUp.Name == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Top matches: %s (confidence %.2f, priority %d) vs %s (confidence %.2f, priority %d), confidence gap %+.2f: ",
+		dominant.Name, dominant.Confidence, dominant.Priority,
+		runnerUp.Name, runnerUp.Confidence, runnerUp.Priority,
+		dominant.Confidence-runnerUp.Confidence))
+
+	switch {
+	case dominant.Priority != runnerUp.Priority:
+		sb.WriteString(fmt.Sprintf("%s ranks first on priority (%d vs %d)",
+			dominant.Name, dominant.Priority, runnerUp.Priority))
+		if runnerUp.Confidence > dominant.Confidence {
+			sb.WriteString(fmt.Sprintf(" even though %s has higher confidence", runnerUp.Name))
+		}
+	case dominant.Confidence != runnerUp.Confidence:
+		sb.WriteString(fmt.Sprintf("priorities are equal (%d), so the tie is broken by higher confidence",
+			dominant.Priority))
+	default:
+		sb.WriteString(fmt.Sprintf("priority and confidence are equal, so the tie is broken by detector order (%s is registered before %s)",
+			dominant.Name, runnerUp.Name))
+	}
+	sb.WriteString(".")
+
+	return sb.String()
+}
+
+// splitByConfidence separates matches that reach the minimum confidence
+func (a *Aggregator) splitByConfidence(matches []StackMatch) (eligible, weak []StackMatch) {
+	for _, match := range matches {
+		if match.Confidence < a.minConfidence {
+			weak = append(weak, match)
+		} else {
+			eligible = append(eligible, match)
+		}
+	}
+	return eligible, weak
+}
+
+// confidenceOverride returns the most confident lower-priority match that
+// beats the dominant one by the override margin
+func (a *Aggregator) confidenceOverride(matches []StackMatch, dominant StackMatch) (StackMatch, bool) {
+	if a.overrideMargin <= 0 {
+		return StackMatch{}, false
+	}
+
+	best := dominant
+	for _, match := range matches {
+		if match.Priority < dominant.Priority &&
+			match.Confidence >= dominant.Confidence+a.overrideMargin &&
+			match.Confidence > best.Confidence {
+			best = match
+		}
+	}
+	return best, best.Name != dominant.Name
+}
+
 // otherStackNames returns names of all stacks except the specified one
 func (a *Aggregator) otherStackNames(matches []StackMatch, exclude string) string {
 	var names []string
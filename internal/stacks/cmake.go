@@ -0,0 +1,55 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// C/C++ (CMake) stack detector
+
+package stacks
+
+import (
+	"strings"
+
+	"github.com/sony-level/readme-runner/internal/scanner"
+)
+
+// CMakeDetector detects C and C++ projects built with CMake
+type CMakeDetector struct {
+	BaseDetector
+}
+
+// NewCMakeDetector creates a new CMake detector
+func NewCMakeDetector() *CMakeDetector {
+	return &CMakeDetector{
+		BaseDetector: NewBaseDetector(StackCpp, PriorityCpp),
+	}
+}
+
+// Detect checks if the project is built with CMake
+func (d *CMakeDetector) Detect(profile *scanner.ProjectProfile) (StackMatch, bool) {
+	var signals []string
+	var reasons []string
+
+	// Check for CMakeLists.txt (required for CMake)
+	if !hasSignal(profile, "CMakeLists.txt") && !hasTool(profile, "cmake") {
+		return StackMatch{}, false
+	}
+
+	signals = append(signals, "CMakeLists.txt")
+	reasons = append(reasons, "C/C++ project detected (CMakeLists.txt)")
+
+	if hasTool(profile, "cmake") {
+		signals = append(signals, "cmake")
+	}
+	if hasTool(profile, "make") {
+		signals = append(signals, "make")
+	}
+
+	if len(profile.Executables) > 0 {
+		reasons = append(reasons, "Executable targets: "+strings.Join(profile.Executables, ", "))
+	}
+
+	if hasLanguage(profile, "cpp") {
+		reasons = append(reasons, "C++ enabled by project()")
+	} else if hasLanguage(profile, "c") {
+		reasons = append(reasons, "C enabled by project()")
+	}
+
+	return createMatch(StackCpp, d.Priority(), signals, reasons), true
+}
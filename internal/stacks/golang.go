@@ -22,14 +22,16 @@ func (d *GoDetector) Detect(profile *scanner.ProjectProfile) (StackMatch, bool)
 	var signals []string
 	var reasons []string
 
-	// Check for go.mod (required for Go modules)
+	// Check for go.mod (required for Go modules) or a go.work workspace
 	hasGoMod := hasPackage(profile, "go.mod") || hasSignal(profile, "go.mod")
-	if !hasGoMod {
+	if !hasGoMod && !hasSignal(profile, "go.work") {
 		return StackMatch{}, false
 	}
 
-	signals = append(signals, "go.mod")
-	reasons = append(reasons, "Go module detected")
+	if hasGoMod {
+		signals = append(signals, "go.mod")
+		reasons = append(reasons, "Go module detected")
+	}
 
 	// Check for go.sum
 	if hasSignal(profile, "go.sum") || hasPackage(profile, "go.sum") {
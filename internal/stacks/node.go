@@ -22,13 +22,21 @@ func (d *NodeDetector) Detect(profile *scanner.ProjectProfile) (StackMatch, bool
 	var signals []string
 	var reasons []string
 
-	// Check for package.json (required for Node.js detection)
-	if !hasPackage(profile, "package.json") && !hasSignal(profile, "package.json") {
+	// A package.json is required, except for Deno projects (deno.json)
+	hasPackageJSON := hasPackage(profile, "package.json") || hasSignal(profile, "package.json")
+	hasDenoJSON := hasPackage(profile, "deno.json") || hasSignal(profile, "deno.json") || hasSignal(profile, "deno.jsonc")
+	if !hasPackageJSON && !hasDenoJSON {
 		return StackMatch{}, false
 	}
 
-	signals = append(signals, "package.json")
-	reasons = append(reasons, "Node.js project detected (package.json)")
+	if hasPackageJSON {
+		signals = append(signals, "package.json")
+		reasons = append(reasons, "Node.js project detected (package.json)")
+	}
+	if hasDenoJSON {
+		signals = append(signals, "deno.json")
+		reasons = append(reasons, "Deno runtime in use")
+	}
 
 	// Check for lock files to determine package manager
 	lockFileDetected := false
@@ -58,12 +66,12 @@ func (d *NodeDetector) Detect(profile *scanner.ProjectProfile) (StackMatch, bool
 	}
 
 	// If no lock file, assume npm
-	if !lockFileDetected {
+	if !lockFileDetected && hasPackageJSON {
 		reasons = append(reasons, "npm assumed (no lock file)")
 	}
 
 	// Check for Node.js tools
-	nodeTools := []string{"npm", "yarn", "pnpm", "bun", "npx"}
+	nodeTools := []string{"npm", "yarn", "pnpm", "bun", "deno", "npx"}
 	for _, tool := range nodeTools {
 		if hasTool(profile, tool) {
 			signals = append(signals, tool)
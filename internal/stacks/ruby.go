@@ -0,0 +1,49 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Ruby stack detector
+
+package stacks
+
+import "github.com/sony-level/readme-runner/internal/scanner"
+
+// RubyDetector detects Ruby projects
+type RubyDetector struct {
+	BaseDetector
+}
+
+// NewRubyDetector creates a new Ruby detector
+func NewRubyDetector() *RubyDetector {
+	return &RubyDetector{
+		BaseDetector: NewBaseDetector(StackRuby, PriorityRuby),
+	}
+}
+
+// Detect checks if the project uses Ruby
+func (d *RubyDetector) Detect(profile *scanner.ProjectProfile) (StackMatch, bool) {
+	var signals []string
+	var reasons []string
+
+	// Check for Gemfile (required for Ruby)
+	hasGemfile := hasPackage(profile, "Gemfile") || hasSignal(profile, "Gemfile")
+	if !hasGemfile {
+		return StackMatch{}, false
+	}
+
+	signals = append(signals, "Gemfile")
+	reasons = append(reasons, "Ruby project detected (Gemfile)")
+
+	if hasTool(profile, "bundler") {
+		signals = append(signals, "bundler")
+	}
+
+	// Rails apps have bin/rails or config/application.rb
+	if profile.Framework == scanner.FrameworkRails {
+		signals = append(signals, "rails")
+		reasons = append(reasons, "Rails application detected")
+	}
+
+	if hasLanguage(profile, "ruby") {
+		reasons = append(reasons, "Ruby source files present")
+	}
+
+	return createMatch(StackRuby, d.Priority(), signals, reasons), true
+}
@@ -0,0 +1,52 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Golden test pinning the JSON form of DetectionResult
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sony-level/readme-runner/internal/scanner"
+	"github.com/sony-level/readme-runner/internal/stacks"
+)
+
+// update rewrites the golden files: go test ./internal/stacks/tests -update
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestDetectionResultJSONGolden(t *testing.T) {
+	profile := &scanner.ProjectProfile{
+		Root:       "/project",
+		Readme:     true,
+		Languages:  []string{"javascript", "python"},
+		Tools:      []string{"docker", "npm", "pip"},
+		Signals:    []string{"Dockerfile", "package.json", "requirements.txt"},
+		Containers: []string{"Dockerfile"},
+		Packages:   []string{"package.json", "requirements.txt"},
+	}
+	result := stacks.NewAggregator().Detect(profile)
+
+	got, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "detection_result.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("DetectionResult JSON changed; if that is intended, run with -update and review the diff\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
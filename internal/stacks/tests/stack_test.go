@@ -4,6 +4,7 @@
 package tests
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/sony-level/readme-runner/internal/scanner"
@@ -480,6 +481,141 @@ func TestDetectionResult_Explanation(t *testing.T) {
 	}
 }
 
+func TestDetectionResult_ExplanationTieBreak(t *testing.T) {
+	aggregator := stacks.NewAggregator()
+
+	profile := &scanner.ProjectProfile{
+		Signals:  []string{"package.json", "pyproject.toml"},
+		Packages: []string{"package.json", "pyproject.toml"},
+		Tools:    []string{"npm", "poetry"},
+	}
+
+	result := aggregator.Detect(profile)
+
+	for _, want := range []string{"node", "python", "priority 80", "tie is broken"} {
+		if !containsString(result.Explanation, want) {
+			t.Errorf("Explanation should mention %q: %s", want, result.Explanation)
+		}
+	}
+}
+
+func TestDetectionResult_ExplanationPriorityOverConfidence(t *testing.T) {
+	aggregator := stacks.NewAggregator()
+
+	profile := &scanner.ProjectProfile{
+		Signals:    []string{"Dockerfile", "package.json", "go.mod"},
+		Packages:   []string{"package.json", "go.mod"},
+		Containers: []string{"Dockerfile"},
+		Tools:      []string{"docker", "npm"},
+	}
+
+	result := aggregator.Detect(profile)
+
+	if !containsString(result.Explanation, "ranks first on priority (100 vs 80)") {
+		t.Errorf("Explanation should state the priority rule: %s", result.Explanation)
+	}
+	if !containsString(result.Explanation, "confidence gap") {
+		t.Errorf("Explanation should state the confidence gap: %s", result.Explanation)
+	}
+}
+
+// ciDockerfileGoProfile is a Go service with a Dockerfile used only for CI
+func ciDockerfileGoProfile() *scanner.ProjectProfile {
+	return &scanner.ProjectProfile{
+		Signals:    []string{"Dockerfile", "go.mod", "go.sum", "Makefile", "cmd", "internal"},
+		Packages:   []string{"go.mod", "go.sum"},
+		Containers: []string{"Dockerfile"},
+		Tools:      []string{"docker", "go", "make"},
+	}
+}
+
+func findMatch(result stacks.DetectionResult, name string) (stacks.StackMatch, bool) {
+	for _, match := range result.Matches {
+		if match.Name == name {
+			return match, true
+		}
+	}
+	return stacks.StackMatch{}, false
+}
+
+// priorityOnlyAggregator is NewAggregator without its default override
+// margin, so only priority picks the dominant stack
+func priorityOnlyAggregator() *stacks.Aggregator {
+	aggregator := stacks.NewAggregator()
+	aggregator.SetPriorityOverrideMargin(0)
+	return aggregator
+}
+
+func TestAggregator_DefaultsIgnoreCIDockerfile(t *testing.T) {
+	result := stacks.NewAggregator().Detect(ciDockerfileGoProfile())
+	if result.Dominant.Name != "go" {
+		t.Errorf("A CI-only Dockerfile should not dominate a Go service by default, got %s", result.Dominant.Name)
+	}
+}
+
+func TestAggregator_MinConfidence(t *testing.T) {
+	baseline := priorityOnlyAggregator().Detect(ciDockerfileGoProfile())
+	if baseline.Dominant.Name != "docker" {
+		t.Fatalf("Without a threshold Docker should dominate, got %s", baseline.Dominant.Name)
+	}
+	docker, _ := findMatch(baseline, "docker")
+	goMatch, _ := findMatch(baseline, "go")
+	if goMatch.Confidence <= docker.Confidence {
+		t.Fatalf("Test profile should give Go (%.2f) more confidence than Docker (%.2f)",
+			goMatch.Confidence, docker.Confidence)
+	}
+
+	aggregator := priorityOnlyAggregator()
+	aggregator.SetMinConfidence(docker.Confidence + 0.01)
+	result := aggregator.Detect(ciDockerfileGoProfile())
+
+	if result.Dominant.Name != "go" {
+		t.Errorf("Weak Docker match should not dominate, got %s", result.Dominant.Name)
+	}
+	if _, found := findMatch(result, "docker"); !found {
+		t.Error("Weak matches should stay in Matches")
+	}
+	if !containsString(strings.Join(result.AllStacks, ","), "docker") {
+		t.Errorf("Weak matches should stay in AllStacks: %v", result.AllStacks)
+	}
+	if !containsString(result.Explanation, "Not eligible as dominant") {
+		t.Errorf("Explanation should mention the ignored match: %s", result.Explanation)
+	}
+}
+
+func TestAggregator_MinConfidenceNoCandidates(t *testing.T) {
+	aggregator := priorityOnlyAggregator()
+	aggregator.SetMinConfidence(1.1)
+
+	result := aggregator.Detect(ciDockerfileGoProfile())
+
+	if result.Dominant.Name != "docker" {
+		t.Errorf("With no eligible match, selection should fall back to priority, got %s", result.Dominant.Name)
+	}
+}
+
+func TestAggregator_PriorityOverrideMargin(t *testing.T) {
+	baseline := stacks.NewAggregator().Detect(ciDockerfileGoProfile())
+	docker, _ := findMatch(baseline, "docker")
+	goMatch, _ := findMatch(baseline, "go")
+	gap := goMatch.Confidence - docker.Confidence
+
+	aggregator := stacks.NewAggregator()
+	aggregator.SetPriorityOverrideMargin(gap + 0.01)
+	if result := aggregator.Detect(ciDockerfileGoProfile()); result.Dominant.Name != "docker" {
+		t.Errorf("Gap below margin should keep priority order, got %s", result.Dominant.Name)
+	}
+
+	aggregator.SetPriorityOverrideMargin(gap / 2)
+	result := aggregator.Detect(ciDockerfileGoProfile())
+	if result.Dominant.Name != "go" {
+		t.Errorf("Much higher confidence should override priority, got %s", result.Dominant.Name)
+	}
+	if !containsString(result.Explanation, "overriding priority") {
+		t.Errorf("Explanation should mention the override: %s", result.Explanation)
+	}
+}
+
 // Helper function
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && findSubstring(s, substr))
@@ -493,3 +629,86 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRubyDetector_RailsApp(t *testing.T) {
+	detector := stacks.NewRubyDetector()
+
+	profile := &scanner.ProjectProfile{
+		Signals:   []string{"Gemfile"},
+		Packages:  []string{"Gemfile"},
+		Tools:     []string{"bundler"},
+		Languages: []string{"ruby"},
+		Framework: scanner.FrameworkRails,
+	}
+
+	match, found := detector.Detect(profile)
+	if !found {
+		t.Fatal("Ruby should be detected")
+	}
+
+	if match.Name != "ruby" {
+		t.Errorf("Name = %s, want ruby", match.Name)
+	}
+}
+
+func TestCMakeDetector(t *testing.T) {
+	detector := stacks.NewCMakeDetector()
+
+	profile := &scanner.ProjectProfile{
+		Signals:     []string{"CMakeLists.txt"},
+		Tools:       []string{"cmake"},
+		Languages:   []string{"c", "cpp"},
+		Executables: []string{"demo"},
+	}
+
+	match, found := detector.Detect(profile)
+	if !found {
+		t.Fatal("CMake project should be detected")
+	}
+	if match.Name != stacks.StackCpp {
+		t.Errorf("Name = %s, want cpp", match.Name)
+	}
+
+	if _, found := detector.Detect(&scanner.ProjectProfile{Signals: []string{"Makefile"}, Tools: []string{"make"}}); found {
+		t.Error("a Makefile alone should not be detected as CMake")
+	}
+
+	result := stacks.NewAggregator().Detect(profile)
+	if result.Dominant.Name != stacks.StackCpp {
+		t.Errorf("Dominant = %s, want cpp", result.Dominant.Name)
+	}
+}
+
+func TestAssumeStack(t *testing.T) {
+	profile := &scanner.ProjectProfile{
+		Signals:   []string{"go.mod", "package.json"},
+		Tools:     []string{"go", "npm"},
+		Languages: []string{"go", "javascript"},
+		Packages:  []string{"go.mod", "package.json"},
+	}
+	detected := stacks.NewAggregator().Detect(profile)
+
+	result := stacks.AssumeStack(detected, stacks.StackGo)
+	if result.Dominant.Name != stacks.StackGo {
+		t.Fatalf("Dominant = %s, want go", result.Dominant.Name)
+	}
+	if result.IsMixed {
+		t.Error("IsMixed = true, want false once a stack is assumed")
+	}
+	if !strings.Contains(result.Explanation, "--assume-stack") {
+		t.Errorf("Explanation = %q, want it to mention --assume-stack", result.Explanation)
+	}
+	if len(result.Matches) != len(detected.Matches) {
+		t.Errorf("Matches changed: %d, want %d", len(result.Matches), len(detected.Matches))
+	}
+
+	// A stack that was not detected at all is still honored
+	result = stacks.AssumeStack(detected, stacks.StackRuby)
+	if result.Dominant.Name != stacks.StackRuby || result.Dominant.Confidence != 1.0 {
+		t.Errorf("Dominant = %+v, want ruby", result.Dominant)
+	}
+
+	if unchanged := stacks.AssumeStack(detected, ""); unchanged.Dominant.Name != detected.Dominant.Name {
+		t.Errorf("empty stack changed the dominant stack to %s", unchanged.Dominant.Name)
+	}
+}
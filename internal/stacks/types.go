@@ -21,7 +21,8 @@ type StackMatch struct {
 	Priority   int      `json:"priority"`   // Detector priority (higher = more important)
 }
 
-// DetectionResult contains all detected stacks and the dominant choice
+// DetectionResult contains all detected stacks and the dominant choice.
+// Its JSON form is printed by 'rdr scan --json' and only gains fields.
 type DetectionResult struct {
 	Matches     []StackMatch `json:"matches"`
 	Dominant    StackMatch   `json:"dominant"`
@@ -41,6 +42,7 @@ const (
 	PriorityDotNet = 60
 	PriorityRuby   = 60
 	PriorityPHP    = 60
+	PriorityCpp    = 60
 	PriorityMixed  = 50
 )
 
@@ -55,5 +57,6 @@ const (
 	StackDotNet = "dotnet"
 	StackRuby   = "ruby"
 	StackPHP    = "php"
+	StackCpp    = "cpp"
 	StackMixed  = "mixed"
 )
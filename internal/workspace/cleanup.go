@@ -105,3 +105,42 @@ func CleanupStale(baseDir string, maxAgeHours int) (int, error) {
 
 	return cleaned, nil
 }
+
+// CleanupOlderThan removes workspaces whose run ID timestamp is older than
+// maxAge and returns the removed run IDs. Directories that are not named
+// like a run ID are left alone.
+func CleanupOlderThan(baseDir string, maxAge time.Duration) ([]string, error) {
+	tempDir := filepath.Join(baseDir, TempDirPrefix)
+
+	entries, err := os.ReadDir(tempDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var removed []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		created, err := ParseRunIDTime(entry.Name())
+		if err != nil || !created.Before(cutoff) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(tempDir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove workspace %s: %w", entry.Name(), err)
+		}
+		removed = append(removed, entry.Name())
+	}
+
+	// Try to remove the parent directory if empty
+	_ = os.Remove(tempDir)
+
+	return removed, nil
+}
@@ -0,0 +1,129 @@
+// Copyright © 2026 ソニーレベル <C7kali3@gmail.com>
+// Run metadata stored in the workspace and workspace listing
+
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MetaFileName is the metadata file written at the workspace root
+const MetaFileName = "meta.json"
+
+// Meta describes what a workspace was created for and how the run ended
+type Meta struct {
+	RunID      string `json:"run_id"`
+	Source     string `json:"source"`      // Input path or URL
+	SourceType string `json:"source_type"` // github, git, local, ...
+	Stack      string `json:"stack,omitempty"`
+	Provider   string `json:"provider,omitempty"` // LLM provider that produced the plan
+	Model      string `json:"model,omitempty"`    // Model the provider was configured with, when known
+	DryRun     bool   `json:"dry_run"`
+	InPlace    bool   `json:"in_place,omitempty"` // Ran in the source directory, not a copy
+
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // nil while running (or if rdr crashed)
+	Success   *bool      `json:"success,omitempty"`  // nil until the run ends
+	Error     string     `json:"error,omitempty"`    // Why the run failed
+}
+
+// Finish records the end time and outcome of a run
+func (m *Meta) Finish(runErr error) {
+	now := time.Now()
+	success := runErr == nil
+	m.EndedAt = &now
+	m.Success = &success
+	m.Error = ""
+	if runErr != nil {
+		m.Error = runErr.Error()
+	}
+}
+
+// MetaFile returns the path to the workspace metadata file
+func (w *Workspace) MetaFile() string {
+	return filepath.Join(w.Path, MetaFileName)
+}
+
+// WriteMeta saves run metadata into the workspace
+func (w *Workspace) WriteMeta(meta *Meta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode workspace metadata: %w", err)
+	}
+	if err := os.WriteFile(w.MetaFile(), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write workspace metadata: %w", err)
+	}
+	return nil
+}
+
+// ReadMeta loads a workspace metadata file
+func ReadMeta(path string) (*Meta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace metadata: %w", err)
+	}
+
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid workspace metadata %s: %w", path, err)
+	}
+	return &meta, nil
+}
+
+// Info summarizes a workspace found on disk
+type Info struct {
+	RunID     string
+	Path      string
+	CreatedAt time.Time // Parsed from the run ID (zero if unparseable)
+	Meta      *Meta     // nil if no metadata was recorded
+	HasPlan   bool      // Whether plan/run-plan.json was saved
+}
+
+// List returns the workspaces under baseDir, oldest first.
+// Directories that are not named like a run ID are skipped.
+func List(baseDir string) ([]Info, error) {
+	tempDir := filepath.Join(baseDir, TempDirPrefix)
+
+	entries, err := os.ReadDir(tempDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	var infos []Info
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		created, err := ParseRunIDTime(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		ws := &Workspace{RunID: entry.Name(), Path: filepath.Join(tempDir, entry.Name()), BaseDir: baseDir}
+		info := Info{
+			RunID:     ws.RunID,
+			Path:      ws.Path,
+			CreatedAt: created,
+		}
+		if meta, err := ReadMeta(ws.MetaFile()); err == nil {
+			info.Meta = meta
+		}
+		if _, err := os.Stat(ws.PlanFile()); err == nil {
+			info.HasPlan = true
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos, nil
+}
@@ -4,12 +4,14 @@
 package workspace_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sony-level/readme-runner/internal/workspace"
 )
@@ -191,6 +193,36 @@ func TestNew_NilConfig(t *testing.T) {
 	}
 }
 
+func TestResolveBaseDir(t *testing.T) {
+	flagDir := t.TempDir()
+	envDir := t.TempDir()
+
+	t.Setenv(workspace.EnvWorkspaceDir, envDir)
+	if got, _ := workspace.ResolveBaseDir(flagDir); got != flagDir {
+		t.Errorf("ResolveBaseDir(flag) = %s, want %s", got, flagDir)
+	}
+	if got, _ := workspace.ResolveBaseDir(""); got != envDir {
+		t.Errorf("ResolveBaseDir(env) = %s, want %s", got, envDir)
+	}
+
+	t.Setenv(workspace.EnvWorkspaceDir, "")
+	want, _ := filepath.Abs(os.TempDir())
+	if got, _ := workspace.ResolveBaseDir(""); got != want {
+		t.Errorf("ResolveBaseDir() = %s, want OS temp dir %s", got, want)
+	}
+}
+
+func TestNew_BaseDirNotWritable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := workspace.New(&workspace.WorkspaceConfig{BaseDir: file}); err == nil {
+		t.Error("New() with a file as BaseDir should fail")
+	}
+}
+
 func TestWorkspace_Keep(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "workspace-test-*")
 	if err != nil {
@@ -269,6 +301,42 @@ func TestWorkspace_PlanFile(t *testing.T) {
 	}
 }
 
+func TestOpen(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "workspace-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	config := &workspace.WorkspaceConfig{
+		BaseDir: tmpDir,
+		Keep:    true,
+	}
+
+	ws, err := workspace.New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	reopened, err := workspace.Open(config, ws.RunID)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if reopened.Path != ws.Path {
+		t.Errorf("Open().Path = %v, want %v", reopened.Path, ws.Path)
+	}
+	if reopened.StateFile() != filepath.Join(ws.PlanPath(), "execution-state.json") {
+		t.Errorf("StateFile() = %v", reopened.StateFile())
+	}
+
+	if _, err := workspace.Open(config, "rr-20000101-0000-abc"); err == nil {
+		t.Error("Open() should fail for a missing workspace")
+	}
+	if _, err := workspace.Open(config, "../"+ws.RunID); err == nil {
+		t.Error("Open() should reject run IDs containing a path")
+	}
+}
+
 func TestWorkspace_String(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "workspace-test-*")
 	if err != nil {
@@ -351,3 +419,113 @@ func TestCleanupAll_NonExistent(t *testing.T) {
 		t.Errorf("CleanupAll() on non-existent should not error, got %v", err)
 	}
 }
+
+func TestParseRunIDTime(t *testing.T) {
+	created, err := workspace.ParseRunIDTime("rr-20260203-1542-abc")
+	if err != nil {
+		t.Fatalf("ParseRunIDTime() error = %v", err)
+	}
+	if created.Year() != 2026 || created.Month() != 2 || created.Day() != 3 ||
+		created.Hour() != 15 || created.Minute() != 42 {
+		t.Errorf("ParseRunIDTime() = %v, want 2026-02-03 15:42", created)
+	}
+
+	for _, invalid := range []string{"", "rr-2026", "xx-20260203-1542-abc", "rr-2026020-1542-abc"} {
+		if _, err := workspace.ParseRunIDTime(invalid); err == nil {
+			t.Errorf("ParseRunIDTime(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestCleanupOlderThan(t *testing.T) {
+	tmpDir := t.TempDir()
+	tempDir := filepath.Join(tmpDir, workspace.TempDirPrefix)
+
+	for _, name := range []string{"rr-20200101-0000-abc", "not-a-workspace"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, name), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+
+	workspace.ResetRunIDState()
+	fresh, err := workspace.New(&workspace.WorkspaceConfig{BaseDir: tmpDir, Keep: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	removed, err := workspace.CleanupOlderThan(tmpDir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("CleanupOlderThan() error = %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "rr-20200101-0000-abc" {
+		t.Errorf("removed = %v, want [rr-20200101-0000-abc]", removed)
+	}
+	if !fresh.Exists() {
+		t.Error("recent workspace should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "not-a-workspace")); err != nil {
+		t.Error("directories not named like a run ID should be kept")
+	}
+}
+
+func TestList(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	workspace.ResetRunIDState()
+	ws, err := workspace.New(&workspace.WorkspaceConfig{BaseDir: tmpDir, Keep: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := ws.WriteMeta(&workspace.Meta{RunID: ws.RunID, Source: "https://github.com/user/repo"}); err != nil {
+		t.Fatalf("WriteMeta() error = %v", err)
+	}
+	if err := os.WriteFile(ws.PlanFile(), []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	old := filepath.Join(tmpDir, workspace.TempDirPrefix, "rr-20200101-0000-abc")
+	if err := os.MkdirAll(old, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	infos, err := workspace.List(tmpDir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("List() returned %d workspaces, want 2", len(infos))
+	}
+
+	if infos[0].RunID != "rr-20200101-0000-abc" || infos[0].Meta != nil || infos[0].HasPlan {
+		t.Errorf("infos[0] = %+v, want the old workspace without metadata or plan", infos[0])
+	}
+	if infos[1].RunID != ws.RunID || !infos[1].HasPlan {
+		t.Errorf("infos[1] = %+v, want %s with a plan", infos[1], ws.RunID)
+	}
+	if infos[1].Meta == nil || infos[1].Meta.Source != "https://github.com/user/repo" {
+		t.Errorf("infos[1].Meta = %+v, want the recorded source", infos[1].Meta)
+	}
+}
+
+func TestMeta_RoundTrip(t *testing.T) {
+	ws, err := workspace.New(&workspace.WorkspaceConfig{BaseDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	meta := &workspace.Meta{RunID: ws.RunID, Source: "/src/app", Stack: "node", Provider: "mock", StartedAt: time.Now()}
+	meta.Finish(errors.New("execution failed"))
+	if err := ws.WriteMeta(meta); err != nil {
+		t.Fatalf("WriteMeta() error = %v", err)
+	}
+
+	got, err := workspace.ReadMeta(ws.MetaFile())
+	if err != nil {
+		t.Fatalf("ReadMeta() error = %v", err)
+	}
+	if got.Source != "/src/app" || got.Stack != "node" || got.Provider != "mock" {
+		t.Errorf("ReadMeta() = %+v, want the written fields", got)
+	}
+	if got.EndedAt == nil || got.Success == nil || *got.Success || got.Error != "execution failed" {
+		t.Errorf("ReadMeta() outcome = %v/%v/%q, want a failed run", got.EndedAt, got.Success, got.Error)
+	}
+}
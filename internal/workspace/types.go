@@ -9,6 +9,9 @@ const (
 	RepoSubdir    = "repo"
 	PlanSubdir    = "plan"
 	LogsSubdir    = "logs"
+
+	// EnvWorkspaceDir overrides the default workspace base directory
+	EnvWorkspaceDir = "RDR_WORKSPACE_DIR"
 )
 
 // Workspace represents an isolated workspace for a single run
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -59,6 +60,57 @@ func GenerateRunID() (string, error) {
 	return fmt.Sprintf("%s-%s-%s", RunIDPrefix, timestamp, randomHex), nil
 }
 
+// ResolveBaseDir returns the directory workspaces are created under:
+// the --workspace-dir value, then $RDR_WORKSPACE_DIR, then the OS temp dir.
+// The result is absolute so workspaces do not depend on the CWD.
+func ResolveBaseDir(flagValue string) (string, error) {
+	dir := flagValue
+	if dir == "" {
+		dir = os.Getenv(EnvWorkspaceDir)
+	}
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid workspace directory %s: %w", dir, err)
+	}
+	return abs, nil
+}
+
+// CheckWritable verifies a workspace base directory exists (creating it if
+// needed) and that files can be created in it
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("workspace directory %s cannot be created: %w", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".rr-write-check-*")
+	if err != nil {
+		return fmt.Errorf("workspace directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	_ = os.Remove(probe.Name())
+
+	return nil
+}
+
+// ParseRunIDTime returns the creation time embedded in a run ID
+// (rr-YYYYMMDD-HHMM-xxx), in local time as used by GenerateRunID
+func ParseRunIDTime(runID string) (time.Time, error) {
+	parts := strings.SplitN(runID, "-", 4)
+	if len(parts) != 4 || parts[0] != RunIDPrefix {
+		return time.Time{}, fmt.Errorf("invalid run ID %q", runID)
+	}
+
+	created, err := time.ParseInLocation("20060102-1504", parts[1]+"-"+parts[2], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid run ID %q: %w", runID, err)
+	}
+	return created, nil
+}
+
 // New creates a new workspace with the given configuration
 // If config is nil, uses current working directory as base
 func New(config *WorkspaceConfig) (*Workspace, error) {
@@ -78,6 +130,10 @@ func New(config *WorkspaceConfig) (*Workspace, error) {
 		return nil, fmt.Errorf("failed to generate run ID: %w", err)
 	}
 
+	if err := CheckWritable(config.BaseDir); err != nil {
+		return nil, err
+	}
+
 	workspacePath := filepath.Join(config.BaseDir, TempDirPrefix, runID)
 
 	// Create workspace directory with subdirectories
@@ -106,6 +162,39 @@ func New(config *WorkspaceConfig) (*Workspace, error) {
 	return ws, nil
 }
 
+// Open reopens an existing workspace by run ID (used by --resume)
+// If config is nil, uses current working directory as base
+func Open(config *WorkspaceConfig, runID string) (*Workspace, error) {
+	if config == nil {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		config = &WorkspaceConfig{
+			BaseDir: cwd,
+			Keep:    false,
+		}
+	}
+
+	if runID == "" || runID != filepath.Base(runID) || runID == "." || runID == ".." {
+		return nil, fmt.Errorf("invalid run ID %q", runID)
+	}
+
+	ws := &Workspace{
+		RunID:   runID,
+		Path:    filepath.Join(config.BaseDir, TempDirPrefix, runID),
+		BaseDir: config.BaseDir,
+		keep:    config.Keep,
+	}
+
+	if !ws.Exists() {
+		return nil, fmt.Errorf("workspace %s not found in %s (was it kept with --keep?)",
+			runID, filepath.Join(config.BaseDir, TempDirPrefix))
+	}
+
+	return ws, nil
+}
+
 // RepoPath returns the path to the cloned/copied repository
 func (w *Workspace) RepoPath() string {
 	return filepath.Join(w.Path, RepoSubdir)
@@ -126,6 +215,16 @@ func (w *Workspace) PlanFile() string {
 	return filepath.Join(w.PlanPath(), "run-plan.json")
 }
 
+// LLMPlanFile returns the path to the plan as generated, before normalization
+func (w *Workspace) LLMPlanFile() string {
+	return filepath.Join(w.PlanPath(), "llm-plan.json")
+}
+
+// StateFile returns the path to the execution state file used by --resume
+func (w *Workspace) StateFile() string {
+	return filepath.Join(w.PlanPath(), "execution-state.json")
+}
+
 // LogFile returns the path to the main execution log file
 func (w *Workspace) LogFile() string {
 	return filepath.Join(w.LogsPath(), "execution.log")